@@ -70,10 +70,17 @@ const (
 	RdfFormat
 	// JsonFormat is a constant to denote the input to the live/bulk loader is in the JSON format.
 	JsonFormat
+	// CsvFormat is a constant to denote the input to the live/bulk loader is in the CSV format.
+	// It's loaded with the mapping given to NewChunker.
+	CsvFormat
+	// ParquetFormat is a constant to denote the input to the live/bulk loader is in the Parquet
+	// format. It's loaded with the mapping given to NewChunker.
+	ParquetFormat
 )
 
-// NewChunker returns a new chunker for the specified format.
-func NewChunker(inputFormat InputFormat, batchSize int) Chunker {
+// NewChunker returns a new chunker for the specified format. The CSV and Parquet files are loaded
+// with the mapping m, which the other formats don't need.
+func NewChunker(inputFormat InputFormat, batchSize int, m *Mapping) Chunker {
 	switch inputFormat {
 	case RdfFormat:
 		return &rdfChunker{
@@ -84,6 +91,16 @@ func NewChunker(inputFormat InputFormat, batchSize int) Chunker {
 		return &jsonChunker{
 			nqs: NewNQuadBuffer(batchSize),
 		}
+	case CsvFormat:
+		return &csvChunker{
+			nqs:     NewNQuadBuffer(batchSize),
+			mapping: m,
+		}
+	case ParquetFormat:
+		return &parquetChunker{
+			nqs:     NewNQuadBuffer(batchSize),
+			mapping: m,
+		}
 	default:
 		x.Panic(errors.New("unknown input format"))
 		return nil
@@ -408,8 +425,8 @@ func IsJSONData(r *bufio.Reader) (bool, error) {
 	return err == nil, nil
 }
 
-// DataFormat returns a file's data format (RDF, JSON, or unknown) based on the filename
-// or the user-provided format option. The file extension has precedence.
+// DataFormat returns a file's data format (RDF, JSON, CSV, Parquet or unknown) based on the
+// filename or the user-provided format option. The file extension has precedence.
 func DataFormat(filename string, format string) InputFormat {
 	format = strings.ToLower(format)
 	filename = strings.TrimSuffix(strings.ToLower(filename), ".gz")
@@ -418,6 +435,10 @@ func DataFormat(filename string, format string) InputFormat {
 		return RdfFormat
 	case strings.HasSuffix(filename, ".json") || format == "json":
 		return JsonFormat
+	case strings.HasSuffix(filename, ".csv") || format == "csv":
+		return CsvFormat
+	case strings.HasSuffix(filename, ".parquet") || format == "parquet":
+		return ParquetFormat
 	default:
 		return UnknownFormat
 	}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chunker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+type csvChunker struct {
+	nqs     *NQuadBuffer
+	mapping *Mapping
+
+	// reader reads the file being chunked, whose columns are header.
+	reader *csv.Reader
+	header []string
+}
+
+func (cc *csvChunker) NQuads() *NQuadBuffer {
+	return cc.nqs
+}
+
+// Chunk reads the next rows of the CSV file. The first call reads the header of the file, and the
+// following ones must be given the same reader.
+func (cc *csvChunker) Chunk(r *bufio.Reader) (*bytes.Buffer, error) {
+	if cc.mapping == nil {
+		return nil, errNoMapping
+	}
+	if cc.reader == nil {
+		// Some tools begin the files with a byte order mark.
+		if bom, _ := r.Peek(3); string(bom) == "\ufeff" {
+			if _, err := r.Discard(3); err != nil {
+				return nil, err
+			}
+		}
+		cc.reader = csv.NewReader(r)
+		cc.reader.Comma = cc.mapping.comma()
+		header, err := cc.reader.Read()
+		if err == io.EOF {
+			return nil, err
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "while reading the CSV header")
+		}
+		cc.header = header
+	}
+	return chunkRows(cc.header, cc.reader.Read)
+}
+
+// Parse is not thread-safe. Only call it serially.
+func (cc *csvChunker) Parse(chunkBuf *bytes.Buffer) error {
+	return parseRows(cc.mapping, cc.nqs, chunkBuf)
+}
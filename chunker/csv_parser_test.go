@@ -0,0 +1,165 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chunker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+// loadTabular chunks and parses the file with a chunker of the format and the mapping, and
+// returns the NQuads, formatted as strings.
+func loadTabular(t *testing.T, format InputFormat, mapping string, file []byte) (
+	[]string, *pb.Metadata) {
+
+	m, err := ParseMapping([]byte(mapping))
+	require.NoError(t, err)
+
+	chunker := NewChunker(format, 1000, m)
+	r := bufioReader(string(file))
+	for {
+		chunk, err := chunker.Chunk(r)
+		if err != io.EOF {
+			require.NoError(t, err)
+		}
+		if chunk != nil {
+			require.NoError(t, chunker.Parse(chunk))
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	nqs := chunker.NQuads()
+	nqs.Flush()
+	var rdfs []string
+	for batch := range nqs.Ch() {
+		for _, nq := range batch {
+			obj := "<" + nq.ObjectId + ">"
+			if nq.ObjectValue != nil {
+				obj = fmt.Sprintf("%v", nq.ObjectValue.Val)
+			}
+			rdfs = append(rdfs, fmt.Sprintf("%d <%s> <%s> %s", nq.Namespace, nq.Subject,
+				nq.Predicate, obj))
+		}
+	}
+	return rdfs, nqs.Metadata()
+}
+
+const testCSVMapping = `{
+	"delimiter": ";",
+	"tables": [
+		{
+			"xid": "company_id",
+			"prefix": "company.",
+			"type": "Company",
+			"columns": {"name": {"predicate": "name"}}
+		},
+		{
+			"xid": "id",
+			"prefix": "person.",
+			"namespace": "ns",
+			"columns": {
+				"name": {"predicate": "name", "type": "string"},
+				"age": {"predicate": "age", "type": "int"},
+				"friends": {"predicate": "friend", "type": "uid", "prefix": "person.",
+					"separator": "|"},
+				"company": {"predicate": "works_for", "type": "uid", "prefix": "company."}
+			}
+		}
+	]
+}`
+
+func TestCSVChunker(t *testing.T) {
+	people := "\ufeffid;ns;name;age;friends;company;unmapped\n" +
+		"1;0x1;Alice;30;2| 3;acme;a\n" +
+		"2;1;\"Bob; Jr.\";;1;;b\n"
+	rdfs, meta := loadTabular(t, CsvFormat, testCSVMapping, []byte(people))
+	require.Equal(t, []string{
+		"1 <person.1> <name> &{Alice}",
+		"1 <person.1> <age> &{30}",
+		"1 <person.1> <friend> <person.2>",
+		"1 <person.1> <friend> <person.3>",
+		"1 <person.1> <works_for> <company.acme>",
+		"1 <person.2> <name> &{Bob; Jr.}",
+		"1 <person.2> <friend> <person.1>",
+	}, rdfs)
+	require.Equal(t, map[string]pb.Metadata_HintType{
+		"name":      pb.Metadata_SINGLE,
+		"age":       pb.Metadata_SINGLE,
+		"friend":    pb.Metadata_LIST,
+		"works_for": pb.Metadata_SINGLE,
+	}, meta.PredHints)
+
+	companies := "company_id;name\nacme;Acme Inc.\n"
+	rdfs, _ = loadTabular(t, CsvFormat, testCSVMapping, []byte(companies))
+	require.Equal(t, []string{
+		"0 <company.acme> <dgraph.type> &{Company}",
+		"0 <company.acme> <name> &{Acme Inc.}",
+	}, rdfs)
+}
+
+func TestCSVChunkerErrors(t *testing.T) {
+	m, err := ParseMapping([]byte(testCSVMapping))
+	require.NoError(t, err)
+
+	tests := []struct {
+		csv string
+		err string
+	}{
+		{"name\nAlice\n", "no table of the mapping has its xid column"},
+		{"id,name\n1,Alice\n", "the namespace column ns"},
+		{"id,ns,age\n,0,30\n", "the xid column id is empty"},
+		{"id,ns,age\n1,0,thirty\n", `while converting the value "thirty" of the column age`},
+	}
+	for _, test := range tests {
+		chunker := &csvChunker{nqs: NewNQuadBuffer(1000), mapping: m}
+		// The chunks are always comma-separated.
+		err := chunker.Parse(bytes.NewBufferString(test.csv))
+		require.Error(t, err, test.csv)
+		require.Contains(t, err.Error(), test.err, test.csv)
+	}
+
+	_, err = NewChunker(CsvFormat, 1000, nil).Chunk(bufioReader("id\n1\n"))
+	require.Equal(t, errNoMapping, err)
+}
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		mapping string
+		err     string
+	}{
+		{`{"tables": []}`, "the mapping has no table"},
+		{`{"tables": [{"columns": {}}]}`, "must have an xid column"},
+		{`{"tables": [{"xid": "id", "columns": {"a": {}}}]}`, "the column a of the table with " +
+			"the xid column id has no predicate"},
+		{`{"tables": [{"xid": "id", "columns": {"a": {"predicate": "a", "type": "long"}}}]}`,
+			"unknown type long"},
+		{`{"delimiter": "||", "tables": [{"xid": "id"}]}`, "must be a single character"},
+	}
+	for _, test := range tests {
+		_, err := ParseMapping([]byte(test.mapping))
+		require.Error(t, err, test.mapping)
+		require.Contains(t, err.Error(), test.err, test.mapping)
+	}
+}
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chunker
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+// Mapping describes how the rows of CSV and Parquet files are loaded. Each row is a node, whose
+// xid is in one of the columns, and the other columns are the values of its predicates.
+//
+// For example, with this mapping:
+//
+//	{
+//	  "tables": [{
+//	    "xid": "id",
+//	    "prefix": "person.",
+//	    "type": "Person",
+//	    "columns": {
+//	      "name": {"predicate": "name", "type": "string"},
+//	      "age": {"predicate": "age", "type": "int"},
+//	      "friends": {"predicate": "friend", "type": "uid", "prefix": "person.", "separator": ";"}
+//	    }
+//	  }]
+//	}
+//
+// the row id=1,name=Alice,age=30,friends=2;3 is loaded as:
+//
+//	<person.1> <dgraph.type> "Person" .
+//	<person.1> <name> "Alice"^^<xs:string> .
+//	<person.1> <age> "30"^^<xs:int> .
+//	<person.1> <friend> <person.2> .
+//	<person.1> <friend> <person.3> .
+type Mapping struct {
+	// Delimiter is the field delimiter of the CSV files, a comma by default.
+	Delimiter string `json:"delimiter,omitempty"`
+	// Tables are the mappings of the different kinds of files. A file is loaded with the first
+	// table whose xid column it has, so that the files of different kinds of nodes can be loaded
+	// together.
+	Tables []*Table `json:"tables"`
+}
+
+// Table maps the columns of a kind of file to predicates.
+type Table struct {
+	// Xid is the column with the xids of the nodes. Prefix is prepended to them, so that the xids
+	// of different kinds of nodes don't collide, or to make them blank nodes with "_:". Note that
+	// the xids which are numbers are taken as uids by the loaders, unless new uids are assigned.
+	Xid    string `json:"xid"`
+	Prefix string `json:"prefix,omitempty"`
+	// Type is the dgraph.type of the nodes, if any.
+	Type string `json:"type,omitempty"`
+	// Namespace is the column with the namespaces of the nodes, if any.
+	Namespace string `json:"namespace,omitempty"`
+	// Columns maps the names of the columns to their predicates. The other columns aren't loaded,
+	// and the files don't need to have all of them.
+	Columns map[string]*Column `json:"columns"`
+}
+
+// Column maps a column to a predicate. The empty values aren't loaded.
+type Column struct {
+	Predicate string `json:"predicate"`
+	// Type is the type of the values, as in the schema. The values of the uid columns are the
+	// xids of other nodes, with Prefix prepended to them. The values without a type are loaded
+	// like the RDF literals without one.
+	Type   string `json:"type,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	// Separator splits the values of list predicates.
+	Separator string `json:"separator,omitempty"`
+}
+
+var errNoMapping = errors.New("a mapping is needed to load CSV and Parquet files")
+
+// ParseMapping parses and validates the JSON mapping of CSV and Parquet files.
+func ParseMapping(data []byte) (*Mapping, error) {
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(err, "while parsing the mapping")
+	}
+	if m.Delimiter != "" && utf8.RuneCountInString(m.Delimiter) != 1 {
+		return nil, errors.Errorf("the delimiter of the mapping must be a single character, "+
+			"got %q", m.Delimiter)
+	}
+	if len(m.Tables) == 0 {
+		return nil, errors.New("the mapping has no table")
+	}
+	for _, t := range m.Tables {
+		if t.Xid == "" {
+			return nil, errors.New("the tables of the mapping must have an xid column")
+		}
+		for name, c := range t.Columns {
+			if c.Predicate == "" {
+				return nil, errors.Errorf("the column %s of the table with the xid column %s "+
+					"has no predicate", name, t.Xid)
+			}
+			if _, err := columnType(c.Type); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &m, nil
+}
+
+func (m *Mapping) comma() rune {
+	if m.Delimiter == "" {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(m.Delimiter)
+	return r
+}
+
+func columnType(name string) (types.TypeID, error) {
+	if name == "" {
+		return types.DefaultID, nil
+	}
+	tid, ok := types.TypeForName(name)
+	if !ok {
+		return tid, errors.Errorf("unknown type %s in the mapping", name)
+	}
+	return tid, nil
+}
+
+// mappedTable is the table a file is loaded with, and the indexes of its columns in the file.
+type mappedTable struct {
+	*Table
+	xid int
+	// ns is -1 if the table has no namespace column.
+	ns      int
+	columns []mappedColumn
+}
+
+type mappedColumn struct {
+	*Column
+	idx int
+	tid types.TypeID
+}
+
+// tableFor returns the table of the file whose columns are header.
+func (m *Mapping) tableFor(header []string) (*mappedTable, error) {
+	idx := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := idx[name]; !ok {
+			idx[name] = i
+		}
+	}
+
+	for _, t := range m.Tables {
+		xid, ok := idx[t.Xid]
+		if !ok {
+			continue
+		}
+		mt := &mappedTable{Table: t, xid: xid, ns: -1}
+		if t.Namespace != "" {
+			if mt.ns, ok = idx[t.Namespace]; !ok {
+				return nil, errors.Errorf("the namespace column %s of the table with the xid "+
+					"column %s is missing", t.Namespace, t.Xid)
+			}
+		}
+		for name, c := range t.Columns {
+			i, ok := idx[name]
+			if !ok {
+				continue
+			}
+			tid, err := columnType(c.Type)
+			if err != nil {
+				return nil, err
+			}
+			mt.columns = append(mt.columns, mappedColumn{Column: c, idx: i, tid: tid})
+		}
+		sort.Slice(mt.columns, func(i, j int) bool {
+			return mt.columns[i].idx < mt.columns[j].idx
+		})
+		return mt, nil
+	}
+	return nil, errors.Errorf("no table of the mapping has its xid column in the columns %q",
+		header)
+}
+
+// used returns the indexes of the columns which are loaded, in order.
+func (mt *mappedTable) used() []int {
+	used := []int{mt.xid}
+	if mt.ns >= 0 {
+		used = append(used, mt.ns)
+	}
+	for _, c := range mt.columns {
+		used = append(used, c.idx)
+	}
+	sort.Ints(used)
+
+	var dedup []int
+	for i, idx := range used {
+		if i == 0 || idx != used[i-1] {
+			dedup = append(dedup, idx)
+		}
+	}
+	return dedup
+}
+
+// parseRow pushes the NQuads of the row to nqs.
+func (mt *mappedTable) parseRow(row []string, nqs *NQuadBuffer) error {
+	xid := row[mt.xid]
+	if xid == "" {
+		return errors.Errorf("the xid column %s is empty in the row %q", mt.Xid, row)
+	}
+	subject := mt.Prefix + xid
+
+	var ns uint64
+	if mt.ns >= 0 {
+		var err error
+		if ns, err = strconv.ParseUint(row[mt.ns], 0, 64); err != nil {
+			return errors.Wrapf(err, "invalid namespace in the row %q", row)
+		}
+	}
+
+	if mt.Type != "" {
+		nqs.Push(&api.NQuad{
+			Subject:     subject,
+			Predicate:   "dgraph.type",
+			ObjectValue: &api.Value{Val: &api.Value_DefaultVal{DefaultVal: mt.Type}},
+			Namespace:   ns,
+		})
+	}
+
+	for _, c := range mt.columns {
+		val := row[c.idx]
+		if val == "" {
+			continue
+		}
+		vals, hint := []string{val}, pb.Metadata_SINGLE
+		if c.Separator != "" {
+			vals, hint = strings.Split(val, c.Separator), pb.Metadata_LIST
+		}
+		for _, v := range vals {
+			if c.Separator != "" {
+				if v = strings.TrimSpace(v); v == "" {
+					continue
+				}
+			}
+			nq := &api.NQuad{
+				Subject:   subject,
+				Predicate: c.Predicate,
+				Namespace: ns,
+			}
+			if c.tid == types.UidID {
+				nq.ObjectId = c.Prefix + v
+			} else {
+				var err error
+				if nq.ObjectValue, err = objectValue(c.tid, v); err != nil {
+					return errors.Wrapf(err, "while converting the value %q of the column %s",
+						v, c.Predicate)
+				}
+			}
+			nqs.Push(nq)
+		}
+		nqs.PushPredHint(c.Predicate, hint)
+	}
+	return nil
+}
+
+// objectValue converts the value like the ones of typed RDF literals.
+func objectValue(tid types.TypeID, val string) (*api.Value, error) {
+	if tid == types.DefaultID {
+		return &api.Value{Val: &api.Value_DefaultVal{DefaultVal: val}}, nil
+	}
+	src := types.ValueForType(types.StringID)
+	src.Value = []byte(val)
+	// The passwords are already encrypted, as in RDF.
+	if tid == types.PasswordID {
+		src.Tid = tid
+	}
+	p, err := types.Convert(src, tid)
+	if err != nil {
+		return nil, err
+	}
+	return types.ObjectValue(tid, p.Value)
+}
+
+// maxRowsPerChunk is the number of rows in the chunks of CSV and Parquet files.
+const maxRowsPerChunk = 1e4
+
+// chunkRows writes the header and the next rows to a CSV chunk, until there are maxRowsPerChunk
+// rows or next returns an error. The chunks have the header so that they can be parsed on their
+// own.
+func chunkRows(header []string, next func() ([]string, error)) (*bytes.Buffer, error) {
+	batch := new(bytes.Buffer)
+	w := csv.NewWriter(batch)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	var err error
+	for rows := 0; rows < maxRowsPerChunk; rows++ {
+		var row []string
+		if row, err = next(); err != nil {
+			break
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if werr := w.Error(); werr != nil {
+		return nil, werr
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return batch, err
+}
+
+// parseRows parses the CSV chunk of chunkRows with the mapping m.
+func parseRows(m *Mapping, nqs *NQuadBuffer, chunkBuf *bytes.Buffer) error {
+	if chunkBuf == nil || chunkBuf.Len() == 0 {
+		return nil
+	}
+	if m == nil {
+		return errNoMapping
+	}
+
+	r := csv.NewReader(chunkBuf)
+	header, err := r.Read()
+	if err != nil {
+		return errors.Wrap(err, "while reading the header of the chunk")
+	}
+	mt, err := m.tableFor(header)
+	if err != nil {
+		return err
+	}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := mt.parseRow(row, nqs); err != nil {
+			return err
+		}
+	}
+}
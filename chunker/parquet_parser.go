@@ -0,0 +1,353 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chunker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// parquetChunker turns the rows of a Parquet file into CSV chunks, which are parsed like the ones
+// of CSV files. Only the columns of the table the file is loaded with are read. The values of the
+// repeated columns are joined with the separator of their column in the mapping, which they need.
+// Like the empty values, the null ones aren't loaded.
+//
+// The metadata of Parquet files is at their end, so the file is first copied to a temporary file,
+// from which the columns are read a chunk at a time.
+type parquetChunker struct {
+	nqs     *NQuadBuffer
+	mapping *Mapping
+
+	// tmpFile is the copy of the file, which reader reads. header are the names of the columns
+	// read, and columns are the columns themselves.
+	tmpFile string
+	file    source.ParquetFile
+	reader  *reader.ParquetReader
+	header  []string
+	columns []*parquetColumn
+
+	// rows are the rows read from the columns, of which row is the next one.
+	rows [][]string
+	row  int
+}
+
+func (pc *parquetChunker) NQuads() *NQuadBuffer {
+	return pc.nqs
+}
+
+// Chunk reads the next rows of the Parquet file.
+func (pc *parquetChunker) Chunk(r *bufio.Reader) (*bytes.Buffer, error) {
+	if pc.mapping == nil {
+		return nil, errNoMapping
+	}
+	if pc.reader == nil {
+		if err := pc.open(r); err != nil {
+			pc.close()
+			return nil, err
+		}
+	}
+	chunk, err := chunkRows(pc.header, pc.nextRow)
+	if err != nil {
+		pc.close()
+	}
+	return chunk, err
+}
+
+// open copies the file to a temporary file, and reads the columns of the table it's loaded with.
+func (pc *parquetChunker) open(r io.Reader) error {
+	f, err := ioutil.TempFile("", "dgraph-parquet-")
+	if err != nil {
+		return err
+	}
+	pc.tmpFile = f.Name()
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "while copying the Parquet file")
+	}
+
+	if pc.file, err = local.NewLocalFileReader(pc.tmpFile); err != nil {
+		return err
+	}
+	if pc.reader, err = reader.NewParquetColumnReader(pc.file, 1); err != nil {
+		return errors.Wrap(err, "while reading the metadata of the Parquet file")
+	}
+
+	// The columns in groups are named after their path, like a.b.
+	sh := pc.reader.SchemaHandler
+	names := make([]string, 0, len(sh.ValueColumns))
+	for _, path := range sh.ValueColumns {
+		exPath := common.StrToPath(sh.InPathToExPath[path])
+		names = append(names, strings.Join(exPath[1:], "."))
+	}
+	mt, err := pc.mapping.tableFor(names)
+	if err != nil {
+		return err
+	}
+	separators := make(map[int]string)
+	for _, c := range mt.columns {
+		separators[c.idx] = c.Separator
+	}
+
+	for _, idx := range mt.used() {
+		path := sh.ValueColumns[idx]
+		c := newParquetColumn(names[idx], path, sh.SchemaElements[sh.MapIndex[path]])
+		maxRep, err := sh.MaxRepetitionLevel(common.StrToPath(path))
+		if err != nil {
+			return err
+		}
+		if maxRep > 0 {
+			if c.separator = separators[idx]; c.separator == "" {
+				return errors.Errorf("the Parquet column %s is repeated, so its column in the "+
+					"mapping needs a separator", c.name)
+			}
+		}
+		pc.header = append(pc.header, c.name)
+		pc.columns = append(pc.columns, c)
+	}
+	return nil
+}
+
+// close closes and removes the temporary file.
+func (pc *parquetChunker) close() {
+	if pc.reader != nil {
+		pc.reader.ReadStop()
+	}
+	if pc.file != nil {
+		pc.file.Close()
+	}
+	if pc.tmpFile != "" {
+		os.Remove(pc.tmpFile)
+	}
+	pc.reader, pc.file, pc.tmpFile = nil, nil, ""
+}
+
+func (pc *parquetChunker) nextRow() ([]string, error) {
+	if pc.row == len(pc.rows) {
+		if err := pc.readRows(); err != nil {
+			return nil, err
+		}
+	}
+	row := pc.rows[pc.row]
+	pc.row++
+	return row, nil
+}
+
+// readRows reads the next maxRowsPerChunk rows of the columns.
+func (pc *parquetChunker) readRows() error {
+	pc.rows, pc.row = nil, 0
+	for i, c := range pc.columns {
+		values, rls, _, err := pc.reader.ReadColumnByPath(c.path, maxRowsPerChunk)
+		if err != nil {
+			return errors.Wrapf(err, "while reading the Parquet column %s", c.name)
+		}
+
+		// The values of a row start with a repetition level of 0, and the null values are nil.
+		row := -1
+		for j, v := range values {
+			if rls[j] == 0 {
+				row++
+				if i == 0 {
+					pc.rows = append(pc.rows, make([]string, len(pc.columns)))
+				} else if row == len(pc.rows) {
+					return errors.Errorf("the Parquet column %s has more rows than the column %s",
+						c.name, pc.columns[0].name)
+				}
+			}
+			if row < 0 {
+				return errors.Errorf("the Parquet column %s is corrupt", c.name)
+			}
+			if v == nil {
+				continue
+			}
+			cell := &pc.rows[row][i]
+			if *cell != "" {
+				*cell += c.separator
+			}
+			*cell += c.format(v)
+		}
+		if row+1 != len(pc.rows) {
+			return errors.Errorf("the Parquet column %s has less rows than the column %s",
+				c.name, pc.columns[0].name)
+		}
+	}
+	if len(pc.rows) == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+// Parse is not thread-safe. Only call it serially.
+func (pc *parquetChunker) Parse(chunkBuf *bytes.Buffer) error {
+	return parseRows(pc.mapping, pc.nqs, chunkBuf)
+}
+
+// parquetColumn is a leaf column of a Parquet file, whose path is its path in the schema of the
+// reader.
+type parquetColumn struct {
+	name      string
+	path      string
+	typ       parquet.Type
+	separator string
+
+	// How the values are formatted, from their logical or converted types. unit is the number of
+	// nanoseconds in the unit of the timestamps, 0 if the values aren't timestamps. scale is the
+	// scale of the decimals, -1 if the values aren't decimals.
+	date     bool
+	unit     int64
+	scale    int
+	unsigned bool
+	uuid     bool
+}
+
+func newParquetColumn(name, path string, elem *parquet.SchemaElement) *parquetColumn {
+	c := &parquetColumn{name: name, path: path, typ: elem.GetType(), scale: -1}
+
+	if elem.IsSetConvertedType() {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_DECIMAL:
+			c.scale = int(elem.GetScale())
+		case parquet.ConvertedType_DATE:
+			c.date = true
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			c.unit = int64(time.Millisecond)
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			c.unit = int64(time.Microsecond)
+		case parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16,
+			parquet.ConvertedType_UINT_32, parquet.ConvertedType_UINT_64:
+			c.unsigned = true
+		}
+	}
+
+	// The logical types are a union, which supersedes the converted types.
+	logical := elem.GetLogicalType()
+	switch {
+	case logical == nil:
+	case logical.IsSetDECIMAL():
+		c.scale = int(logical.DECIMAL.GetScale())
+	case logical.IsSetDATE():
+		c.date = true
+	case logical.IsSetTIMESTAMP():
+		unit := logical.TIMESTAMP.GetUnit()
+		switch {
+		case unit.IsSetMILLIS():
+			c.unit = int64(time.Millisecond)
+		case unit.IsSetMICROS():
+			c.unit = int64(time.Microsecond)
+		case unit.IsSetNANOS():
+			c.unit = int64(time.Nanosecond)
+		}
+	case logical.IsSetINTEGER():
+		c.unsigned = !logical.INTEGER.GetIsSigned()
+	case logical.IsSetUUID():
+		c.uuid = true
+	}
+	return c
+}
+
+// format formats a value of the column like the ones of CSV files.
+func (c *parquetColumn) format(v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int32:
+		if c.unsigned {
+			return c.formatInt(int64(uint32(v)))
+		}
+		return c.formatInt(int64(v))
+	case int64:
+		return c.formatInt(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		if c.typ == parquet.Type_INT96 {
+			// The legacy timestamps.
+			return types.INT96ToTime(v).Format(time.RFC3339Nano)
+		}
+		return c.formatBytes([]byte(v))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c *parquetColumn) formatInt(v int64) string {
+	switch {
+	case c.date:
+		return time.Unix(v*24*60*60, 0).UTC().Format("2006-01-02")
+	case c.unit > 0:
+		return time.Unix(0, v*c.unit).UTC().Format(time.RFC3339Nano)
+	case c.scale >= 0:
+		return formatDecimal(big.NewInt(v), c.scale)
+	case c.unsigned:
+		return strconv.FormatUint(uint64(v), 10)
+	default:
+		return strconv.FormatInt(v, 10)
+	}
+}
+
+func (c *parquetColumn) formatBytes(b []byte) string {
+	switch {
+	case c.scale >= 0:
+		// The decimals are big-endian two's complement integers.
+		v := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+		return formatDecimal(v, c.scale)
+	case c.uuid && len(b) == 16:
+		h := hex.EncodeToString(b)
+		return fmt.Sprintf("%s-%s-%s-%s-%s", h[:8], h[8:12], h[12:16], h[16:20], h[20:])
+	default:
+		return string(b)
+	}
+}
+
+func formatDecimal(v *big.Int, scale int) string {
+	if scale == 0 {
+		return v.String()
+	}
+	digits := new(big.Int).Abs(v).String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type testPerson struct {
+	ID      string       `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Age     *int32       `parquet:"name=age, type=INT32"`
+	Friends []string     `parquet:"name=friends, type=BYTE_ARRAY, repetitiontype=REPEATED"`
+	Joined  int64        `parquet:"name=joined, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Address *testAddress `parquet:"name=address"`
+
+	// The scores are decimals with a scale of 2.
+	Score int64 `parquet:"name=score, type=INT64, convertedtype=DECIMAL, scale=2, precision=10"`
+}
+
+type testAddress struct {
+	City *string `parquet:"name=city, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// testParquetFile writes the rows to a Parquet file compressed with the codec. The row groups are
+// of about rowGroupSize bytes.
+func testParquetFile(t *testing.T, codec parquet.CompressionCodec, rowGroupSize int64,
+	rows ...interface{}) []byte {
+
+	var buf bytes.Buffer
+	w, err := writer.NewParquetWriterFromWriter(&buf, rows[0], 1)
+	require.NoError(t, err)
+	w.CompressionType = codec
+	w.RowGroupSize = rowGroupSize
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.WriteStop())
+	return buf.Bytes()
+}
+
+func TestParquetChunker(t *testing.T) {
+	age := func(v int32) *int32 { return &v }
+	paris := "Paris"
+	day := (24 * time.Hour).Milliseconds()
+	people := []interface{}{
+		&testPerson{ID: "1", Age: age(30), Friends: []string{"2", "3"}, Score: -150,
+			Address: &testAddress{City: &paris}},
+		&testPerson{ID: "2", Friends: []string{"1"}, Joined: day, Score: 1234},
+		&testPerson{ID: "3", Age: age(40), Joined: 2 * day, Score: 5,
+			Address: &testAddress{}},
+	}
+
+	m, err := ParseMapping([]byte(`{"tables": [{
+		"xid": "id",
+		"prefix": "person.",
+		"type": "Person",
+		"columns": {
+			"friends": {"predicate": "friend", "type": "uid", "prefix": "person.",
+				"separator": ";"},
+			"age": {"predicate": "age", "type": "int"},
+			"score": {"predicate": "score", "type": "float"},
+			"address.city": {"predicate": "city"}
+		}
+	}]}`))
+	require.NoError(t, err)
+
+	codecs := []parquet.CompressionCodec{parquet.CompressionCodec_UNCOMPRESSED,
+		parquet.CompressionCodec_SNAPPY, parquet.CompressionCodec_GZIP,
+		parquet.CompressionCodec_ZSTD, parquet.CompressionCodec_LZ4}
+	for _, codec := range codecs {
+		file := testParquetFile(t, codec, 128<<20, people...)
+		chunker := &parquetChunker{nqs: NewNQuadBuffer(1000), mapping: m}
+		chunk, err := chunker.Chunk(bufioReader(string(file)))
+		require.Equal(t, io.EOF, err, codec.String())
+		// The joined column isn't mapped, and the values of the repeated friends column are
+		// joined with its separator.
+		require.Equal(t, "id,age,friends,address.city,score\n"+
+			"1,30,2;3,Paris,-1.50\n"+
+			"2,,1,,12.34\n"+
+			"3,40,,,0.05\n", chunk.String(), codec.String())
+		// The temporary copy of the file is removed.
+		require.Empty(t, chunker.tmpFile)
+
+		require.NoError(t, chunker.Parse(chunk))
+		nqs := chunker.NQuads()
+		nqs.Flush()
+		var count int
+		for batch := range nqs.Ch() {
+			count += len(batch)
+		}
+		// The types, 2 ages, 3 friends, 3 scores and a city.
+		require.Equal(t, 12, count)
+	}
+
+	file := testParquetFile(t, parquet.CompressionCodec_SNAPPY, 128<<20, people...)
+	m.Tables[0].Columns["joined"] = &Column{Predicate: "joined", Type: "datetime"}
+	chunker := &parquetChunker{nqs: NewNQuadBuffer(1000), mapping: m}
+	chunk, err := chunker.Chunk(bufioReader(string(file)))
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "id,age,friends,joined,address.city,score\n"+
+		"1,30,2;3,1970-01-01T00:00:00Z,Paris,-1.50\n"+
+		"2,,1,1970-01-02T00:00:00Z,,12.34\n"+
+		"3,40,,1970-01-03T00:00:00Z,,0.05\n", chunk.String())
+
+	// The repeated columns need a separator.
+	m.Tables[0].Columns["friends"].Separator = ""
+	chunker = &parquetChunker{nqs: NewNQuadBuffer(1000), mapping: m}
+	_, err = chunker.Chunk(bufioReader(string(file)))
+	require.EqualError(t, err, "the Parquet column friends is repeated, so its column in the "+
+		"mapping needs a separator")
+	require.Empty(t, chunker.tmpFile)
+}
+
+func TestParquetChunkerRowGroups(t *testing.T) {
+	// More rows than in a chunk, in many row groups.
+	var people []interface{}
+	for i := 0; i <= maxRowsPerChunk; i++ {
+		people = append(people, &testPerson{ID: strconv.Itoa(i)})
+	}
+	file := testParquetFile(t, parquet.CompressionCodec_SNAPPY, 4<<10, people...)
+
+	m, err := ParseMapping([]byte(`{"tables": [{"xid": "id", "columns": {}}]}`))
+	require.NoError(t, err)
+	chunker := &parquetChunker{nqs: NewNQuadBuffer(1000), mapping: m}
+	r := bufioReader(string(file))
+	chunk, err := chunker.Chunk(r)
+	require.NoError(t, err)
+	rows := strings.Split(strings.TrimSuffix(chunk.String(), "\n"), "\n")
+	require.Len(t, rows, maxRowsPerChunk+1)
+	require.Equal(t, []string{"id", "0", "1"}, rows[:3])
+	require.Equal(t, "9999", rows[maxRowsPerChunk])
+
+	chunk, err = chunker.Chunk(r)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "id\n10000\n", chunk.String())
+}
@@ -74,6 +74,7 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"contains",
 		"count",
 		"delete",
+		"distinct",
 		"eq",
 		"exact",
 		"exp",
@@ -93,6 +94,7 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"lt",
 		"math",
 		"max",
+		"median",
 		"min",
 		"mutation",
 		"near",
@@ -101,6 +103,7 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"or",
 		"orderasc",
 		"orderdesc",
+		"percentile",
 		"pow",
 		"recurse",
 		"regexp",
@@ -109,11 +112,13 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"since",
 		"set",
 		"sqrt",
+		"stddev",
 		"sum",
 		"term",
 		"tokenizer",
 		"type",
 		"uid",
+		"variance",
 		"within",
 		"upsert",
 	}
@@ -157,6 +157,11 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
 		return
 	}
+	isExplainMode, err := parseBool(r, "explain")
+	if err != nil {
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
 	queryTimeout, err := parseDuration(r, "timeout")
 	if err != nil {
 		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
@@ -206,6 +211,12 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := context.WithValue(r.Context(), query.DebugKey, isDebugMode)
+	// In explain mode, the plan of the query gets returned in the extensions.
+	var plan *query.Plan
+	if isExplainMode {
+		plan = &query.Plan{}
+		ctx = context.WithValue(ctx, query.ExplainKey, plan)
+	}
 	ctx = x.AttachAccessJwt(ctx, r)
 	ctx = x.AttachRemoteIP(ctx, r)
 
@@ -255,6 +266,10 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		req.RespFormat = api.Request_RDF
 	}
 
+	// Collect the cursors of the paginated blocks to return them in the extensions.
+	cursors := make(map[string]string)
+	ctx = context.WithValue(ctx, edgraph.QueryCursors, cursors)
+
 	// Core processing happens here.
 	resp, err := (&edgraph.Server{}).Query(ctx, &req)
 	if err != nil {
@@ -268,6 +283,8 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		Txn:     resp.Txn,
 		Latency: resp.Latency,
 		Metrics: resp.Metrics,
+		Cursors: cursors,
+		Plan:    plan,
 	}
 	js, err := json.Marshal(e)
 	if err != nil {
@@ -1004,3 +1004,44 @@ func TestQueryBackwardCompatibleWithGraphqlPlusMinusHeader(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "2", resp.Header.Get(x.DgraphCostHeader))
 }
+
+// BenchmarkQueryPlanner runs queries that the query planner rewrites, with the planner disabled,
+// which is the default, and in explain mode, where it always runs. The planner trades the round
+// trips of its estimates against the UIDs that the rewritten query doesn't have to check.
+func BenchmarkQueryPlanner(b *testing.B) {
+	require.NoError(b, dropAll())
+	require.NoError(b, alterSchema(`
+		bench_email: string @index(exact) .
+		bench_status: string @index(exact) .`))
+	var m strings.Builder
+	m.WriteString("{\n set {\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&m, "_:u%d <bench_email> \"user%d@dgraph.io\" .\n", i, i)
+		fmt.Fprintf(&m, "_:u%d <bench_status> \"active\" .\n", i)
+	}
+	m.WriteString(" }\n}")
+	require.NoError(b, runMutation(m.String()))
+
+	queries := map[string]string{
+		// The has function at root is swapped with the eq filter on the email.
+		"has": `{ q(func: has(bench_status))
+			@filter(eq(bench_email, "user7@dgraph.io") AND eq(bench_status, "active")) { uid } }`,
+		// The eq filter on the email runs first, so the other filter only checks its result.
+		"and": `{ q(func: eq(bench_status, "active"))
+			@filter(eq(bench_status, "active") AND eq(bench_email, "user7@dgraph.io")) { uid } }`,
+	}
+	for name, q := range queries {
+		for _, mode := range []string{"default", "explain"} {
+			url := addr + "/query"
+			if mode == "explain" {
+				url += "?explain=true"
+			}
+			b.Run(name+"/"+mode, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, _, err := runWithRetries("POST", "application/dql", url, q)
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
@@ -220,6 +220,10 @@ they form a Raft group and provide synchronous replication.
 			"for minio, aws, etc.").
 		Flag("max-splits", "How many splits can a single key have, before it is forbidden. "+
 			"Also known as Jupiter key.").
+		Flag("query-planner", "The maximum number of index estimates that the query planner "+
+			"can make for a query block, each of which is a round trip to the group serving the "+
+			"predicate. The planner is disabled if set to 0. The explain mode reports the "+
+			"estimates without changing the plan.").
 		String())
 
 	flag.String("graphql", worker.GraphQLDefaults, z.NewSuperFlagHelp(worker.GraphQLDefaults).
@@ -232,7 +236,23 @@ they form a Raft group and provide synchronous replication.
 		Flag("extensions",
 			"Enables extensions in GraphQL response body.").
 		Flag("poll-interval",
-			"The polling interval for GraphQL subscription.").
+			"The interval at which GraphQL subscriptions are checked for expiry and schema "+
+				"changes. Subscriptions are run again when a commit writes the predicates they "+
+				"read.").
+		Flag("max-depth",
+			"The maximum depth of the fields in a GraphQL operation. 0 means no limit.").
+		Flag("max-breadth",
+			"The maximum value of the first argument of the fields in a GraphQL operation. It's "+
+				"also the number of items that list fields without first are estimated to "+
+				"return. 0 means no limit.").
+		Flag("max-cost",
+			"The maximum estimated cost of a GraphQL operation, which is the number of values it "+
+				"can fetch. The cost is reported in the extensions of the response. 0 means no "+
+				"limit.").
+		Flag("persisted-queries-only",
+			"Only serve the GraphQL operations in the persisted query registry of the namespace, "+
+				"which is uploaded with the updatePersistedQueries admin mutation. Clients can't "+
+				"add queries to it with automatic persisted queries.").
 		String())
 
 	flag.String("lambda", worker.LambdaDefaults, z.NewSuperFlagHelp(worker.LambdaDefaults).
@@ -263,11 +283,48 @@ they form a Raft group and provide synchronous replication.
 		Flag("sasl-mechanism",
 			"The SASL mechanism for Kafka (PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512)").
 		Flag("ca-cert",
-			"The path to CA cert file for TLS encryption.").
+			"The path to CA cert file for TLS encryption. The gRPC sink requires the "+
+				"certificates of the consumers to be signed by it.").
 		Flag("client-cert",
-			"The path to client cert file for TLS encryption.").
+			"The path to client cert file for TLS encryption. The gRPC sink is served with it.").
 		Flag("client-key",
 			"The path to client key file for TLS encryption.").
+		Flag("webhook",
+			"The HTTP(S) URL the events are posted to, in JSON arrays.").
+		Flag("webhook-secret",
+			"The secret the webhook requests are signed with. The HMAC-SHA256 of the body is "+
+				"sent in the X-Dgraph-Signature header, as sha256=<hex digest>.").
+		Flag("webhook-batch",
+			"The maximum number of events posted in a single webhook request.").
+		Flag("webhook-retries",
+			"The number of times a failed webhook request is retried, with backoff.").
+		Flag("grpc",
+			"The address the events are served at over gRPC, with the pb.CDC service. The "+
+				"consumers stream the events committed after a timestamp.").
+		Flag("grpc-buffer",
+			"The number of commits whose events are kept for the gRPC consumers which resume.").
+		Flag("grpc-token",
+			"The token the gRPC consumers authenticate with, in the authorization metadata "+
+				"as Bearer <token>. Either it or ca-cert is required by the gRPC sink.").
+		Flag("subscriptions",
+			"The path of a JSON file with the subscriptions which route the events to topics, "+
+				"filtered by namespace, predicate and type. The subscriptions set with the "+
+				"updateCDCSubscriptions mutation of /admin are stored for all the alphas, and "+
+				"replace them.").
+		Flag("before-image",
+			"If true, the mutation events carry the values the predicate had for the node "+
+				"before the txn, so that updates can be told apart from inserts. They're left "+
+				"out while CDC lags behind the last snapshot, whose older versions can be "+
+				"discarded.").
+		String())
+
+	flag.String("stats", worker.StatsDefaults, z.NewSuperFlagHelp(worker.StatsDefaults).
+		Head("Predicate statistics options").
+		Flag("interval",
+			"How often the statistics of the predicates served by the alpha are collected. "+
+				"Set it to 0 to disable the collection.").
+		Flag("top-tokens",
+			"The number of tokens with the longest posting lists reported for each index.").
 		String())
 
 	flag.String("audit", worker.AuditDefaults, z.NewSuperFlagHelp(worker.AuditDefaults).
@@ -811,6 +868,11 @@ func run() {
 		glog.Info("ACL secret key loaded successfully.")
 	}
 
+	stats := z.NewSuperFlag(Alpha.Conf.GetString("stats")).MergeAndCheckDefault(
+		worker.StatsDefaults)
+	opts.StatsInterval = stats.GetDuration("interval")
+	opts.StatsTopTokens = int(stats.GetInt64("top-tokens"))
+
 	x.Config.Limit = z.NewSuperFlag(Alpha.Conf.GetString("limit")).MergeAndCheckDefault(
 		worker.LimitDefaults)
 	abortDur := x.Config.Limit.GetDuration("txn-abort-after")
@@ -875,6 +937,7 @@ func run() {
 	x.Config.QueryTimeout = x.Config.Limit.GetDuration("query-timeout")
 	x.Config.MaxRetries = x.Config.Limit.GetInt64("max-retries")
 	x.Config.SharedInstance = x.Config.Limit.GetBool("shared-instance")
+	x.Config.LimitQueryPlanner = int(x.Config.Limit.GetInt64("query-planner"))
 
 	graphql := z.NewSuperFlag(Alpha.Conf.GetString("graphql")).MergeAndCheckDefault(
 		worker.GraphQLDefaults)
@@ -883,6 +946,11 @@ func run() {
 		Debug:         graphql.GetBool("debug"),
 		Extensions:    graphql.GetBool("extensions"),
 		PollInterval:  graphql.GetDuration("poll-interval"),
+		MaxDepth:      int(graphql.GetInt64("max-depth")),
+		MaxBreadth:    int(graphql.GetInt64("max-breadth")),
+		MaxCost:       graphql.GetUint64("max-cost"),
+
+		PersistedQueriesOnly: graphql.GetBool("persisted-queries-only"),
 	}
 	lambda := z.NewSuperFlag(Alpha.Conf.GetString("lambda")).MergeAndCheckDefault(
 		worker.LambdaDefaults)
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"hash/adler32"
@@ -52,6 +53,7 @@ import (
 type options struct {
 	DataFiles        string
 	DataFormat       string
+	MappingFile      string
 	SchemaFile       string
 	GqlSchemaFile    string
 	OutDir           string
@@ -100,6 +102,8 @@ type state struct {
 	tmpDbs        []*badger.DB // Temporary DB to write the split lists to avoid ordering issues.
 	writeTs       uint64       // All badger writes use this timestamp
 	namespaces    *sync.Map    // To store the encountered namespaces.
+	// mapping is the mapping the CSV and Parquet files are loaded with.
+	mapping *chunker.Mapping
 }
 
 type loader struct {
@@ -237,20 +241,25 @@ func (ld *loader) mapStage() {
 
 	fs := filestore.NewFileStore(ld.opt.DataFiles)
 
-	files := fs.FindDataFiles(ld.opt.DataFiles, []string{".rdf", ".rdf.gz", ".json", ".json.gz"})
+	files := fs.FindDataFiles(ld.opt.DataFiles, []string{".rdf", ".rdf.gz", ".json", ".json.gz",
+		".csv", ".csv.gz", ".parquet"})
 	if len(files) == 0 {
 		fmt.Printf("No data files found in %s.\n", ld.opt.DataFiles)
 		os.Exit(1)
 	}
 
 	// Because mappers must handle chunks that may be from different input files, they must all
-	// assume the same data format, either RDF or JSON. Use the one specified by the user or by
-	// the first load file.
+	// assume the same data format, either RDF, JSON, CSV or Parquet. Use the one specified by the
+	// user or by the first load file.
 	loadType := chunker.DataFormat(files[0], ld.opt.DataFormat)
-	if loadType == chunker.UnknownFormat {
+	switch loadType {
+	case chunker.UnknownFormat:
 		// Dont't try to detect JSON input in bulk loader.
-		fmt.Printf("Need --format=rdf or --format=json to load %s", files[0])
+		fmt.Printf("Need --format=rdf, --format=json, --format=csv or --format=parquet to load %s",
+			files[0])
 		os.Exit(1)
+	case chunker.CsvFormat, chunker.ParquetFormat:
+		ld.setMapping()
 	}
 
 	var mapperWg sync.WaitGroup
@@ -278,7 +287,7 @@ func (ld *loader) mapStage() {
 			r, cleanup := fs.ChunkReader(file, key)
 			defer cleanup()
 
-			chunk := chunker.NewChunker(loadType, 1000)
+			chunk := chunker.NewChunker(loadType, 1000, ld.mapping)
 			for {
 				chunkBuf, err := chunk.Chunk(r)
 				if chunkBuf != nil && chunkBuf.Len() > 0 {
@@ -334,6 +343,37 @@ func parseGqlSchema(s string) map[uint64]*x.ExportedGQLSchema {
 	return schemaMap
 }
 
+// gqlSchemaTable maps the chunks of the GraphQL schemas when loading CSV or Parquet files, like
+// the RDF and JSON ones of processGqlSchema.
+var gqlSchemaTable = &chunker.Table{
+	Xid:       "dgraph.graphql.xid",
+	Prefix:    "_:",
+	Type:      "dgraph.graphql",
+	Namespace: "namespace",
+	Columns: map[string]*chunker.Column{
+		"dgraph.graphql.xid":    {Predicate: "dgraph.graphql.xid"},
+		"dgraph.graphql.schema": {Predicate: "dgraph.graphql.schema"},
+	},
+}
+
+// setMapping sets the mapping the CSV and Parquet files are loaded with.
+func (ld *loader) setMapping() {
+	if ld.opt.MappingFile == "" {
+		fmt.Printf("Need --mapping to load CSV and Parquet files.\n")
+		os.Exit(1)
+	}
+
+	f, err := filestore.Open(ld.opt.MappingFile)
+	x.Check(err)
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	x.Check(err)
+	m, err := chunker.ParseMapping(data)
+	x.Check(err)
+	m.Tables = append([]*chunker.Table{gqlSchemaTable}, m.Tables...)
+	ld.mapping = m
+}
+
 func (ld *loader) processGqlSchema(loadType chunker.InputFormat) {
 	if ld.opt.GqlSchemaFile == "" {
 		return
@@ -391,6 +431,12 @@ func (ld *loader) processGqlSchema(loadType chunker.InputFormat) {
 			x.Check2(gqlBuf.Write([]byte(fmt.Sprintf(rdfSchema, ns, ns, quotedSch, ns))))
 		case chunker.JsonFormat:
 			x.Check2(gqlBuf.Write([]byte(fmt.Sprintf(jsonSchema, ns, quotedSch))))
+		case chunker.CsvFormat, chunker.ParquetFormat:
+			w := csv.NewWriter(gqlBuf)
+			x.Check(w.WriteAll([][]string{
+				{"namespace", "dgraph.graphql.xid", "dgraph.graphql.schema"},
+				{fmt.Sprintf("%#x", ns), "dgraph.graphql.schema", string(b)},
+			}))
 		}
 		ld.readerChunkCh <- gqlBuf
 	}
@@ -60,6 +60,13 @@ func newSchemaStore(initial *schema.ParsedSchema, opt *options, state *state) *s
 			fmt.Printf("Predicate %q already exists in schema\n", p)
 			continue
 		}
+		if len(sch.Composite) > 0 {
+			// The mappers build the index entries of each edge on its own, which can't be done
+			// for composite indexes. They can be added with an alter once the data is loaded.
+			fmt.Printf("Dropping composite indexes of predicate %q. They must be added after "+
+				"the load\n", p)
+			sch.Composite = nil
+		}
 		s.checkAndSetInitialSchema(x.ParseNamespace(p))
 		s.schemaMap[p] = sch
 	}
@@ -200,6 +200,7 @@ EOF
   dgraph debug -p out/1/p 2>|/dev/null | grep '{s}' | cut -d' ' -f3 >> all_dbs.out
   diff <(LC_ALL=C sort all_dbs.out | uniq -c) - <<EOF
       1 dgraph.acl.rule
+      1 dgraph.cdc.subscriptions
       1 dgraph.cors
       1 dgraph.drop.op
       1 dgraph.graphql.p_query
@@ -35,6 +35,7 @@ import (
 	"github.com/dgraph-io/badger/v3/y"
 	"github.com/dgraph-io/dgo/v210"
 	"github.com/dgraph-io/dgo/v210/protos/api"
+	"github.com/dgraph-io/dgraph/chunker"
 	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/protos/pb"
 	"github.com/dgraph-io/dgraph/tok"
@@ -89,6 +90,8 @@ type loader struct {
 	zeroconn   *grpc.ClientConn
 	schema     *schema
 	namespaces map[uint64]struct{}
+	// mapping is the mapping the CSV and Parquet files are loaded with.
+	mapping *chunker.Mapping
 
 	upsertLock sync.RWMutex
 }
@@ -64,6 +64,7 @@ type options struct {
 	dataFiles       string
 	dataFormat      string
 	schemaFile      string
+	mappingFile     string
 	zero            string
 	concurrent      int
 	batchSize       int
@@ -146,10 +147,13 @@ func init() {
 	// --tls SuperFlag
 	x.RegisterClientTLSFlags(flag)
 
-	flag.StringP("files", "f", "", "Location of *.rdf(.gz) or *.json(.gz) file(s) to load")
+	flag.StringP("files", "f", "", "Location of *.rdf(.gz), *.json(.gz), *.csv(.gz) or "+
+		"*.parquet file(s) to load")
 	flag.StringP("schema", "s", "", "Location of schema file")
-	flag.String("format", "", "Specify file format (rdf or json) instead of getting it "+
-		"from filename")
+	flag.String("format", "", "Specify file format (rdf, json, csv or parquet) instead of "+
+		"getting it from filename")
+	flag.String("mapping", "", "Location of the JSON mapping of the columns of the CSV and "+
+		"Parquet files to predicates.")
 	flag.StringP("alpha", "a", "127.0.0.1:9080",
 		"Comma-separated list of Dgraph alpha gRPC server addresses")
 	flag.StringP("zero", "z", "127.0.0.1:5080", "Dgraph zero gRPC server address")
@@ -265,6 +269,21 @@ func (l *loader) processSchemaFile(ctx context.Context, file string, key x.Sensi
 	return dgraphClient.Alter(ctx, op)
 }
 
+// readMappingFile reads the mapping of the columns of the CSV and Parquet files to predicates.
+func readMappingFile(file string) (*chunker.Mapping, error) {
+	f, err := filestore.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return chunker.ParseMapping(b)
+}
+
 func (l *loader) uid(val string, ns uint64) string {
 	// Attempt to parse as a UID (in the same format that dgraph outputs - a
 	// hex number prefixed by "0x"). If parsing succeeds, then this is assumed
@@ -471,12 +490,17 @@ func (l *loader) processFile(ctx context.Context, fs filestore.FileStore, filena
 			if isJson {
 				loadType = chunker.JsonFormat
 			} else {
-				return errors.Errorf("need --format=rdf or --format=json to load %s", filename)
+				return errors.Errorf("need --format=rdf, --format=json, --format=csv or "+
+					"--format=parquet to load %s", filename)
 			}
 		}
 	}
+	if (loadType == chunker.CsvFormat || loadType == chunker.ParquetFormat) &&
+		opt.mappingFile == "" {
+		return errors.Errorf("need --mapping to load %s", filename)
+	}
 
-	return l.processLoadFile(ctx, rd, chunker.NewChunker(loadType, opt.batchSize))
+	return l.processLoadFile(ctx, rd, chunker.NewChunker(loadType, opt.batchSize, l.mapping))
 }
 
 func (l *loader) processLoadFile(ctx context.Context, rd *bufio.Reader, ck chunker.Chunker) error {
@@ -708,6 +732,7 @@ func run() error {
 		dataFiles:       Live.Conf.GetString("files"),
 		dataFormat:      Live.Conf.GetString("format"),
 		schemaFile:      Live.Conf.GetString("schema"),
+		mappingFile:     Live.Conf.GetString("mapping"),
 		zero:            zero,
 		concurrent:      Live.Conf.GetInt("conc"),
 		batchSize:       Live.Conf.GetInt("batch"),
@@ -823,9 +848,17 @@ func run() error {
 		return errors.New("RDF or JSON file(s) location must be specified")
 	}
 
+	if len(opt.mappingFile) > 0 {
+		if l.mapping, err = readMappingFile(opt.mappingFile); err != nil {
+			fmt.Printf("Error while processing mapping file %q: %s\n", opt.mappingFile, err)
+			return err
+		}
+	}
+
 	fs := filestore.NewFileStore(opt.dataFiles)
 
-	filesList := fs.FindDataFiles(opt.dataFiles, []string{".rdf", ".rdf.gz", ".json", ".json.gz"})
+	filesList := fs.FindDataFiles(opt.dataFiles, []string{".rdf", ".rdf.gz", ".json", ".json.gz",
+		".csv", ".csv.gz", ".parquet"})
 	totalFiles := len(filesList)
 	if totalFiles == 0 {
 		return errors.Errorf("No data files found in %s", opt.dataFiles)
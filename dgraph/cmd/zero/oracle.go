@@ -275,14 +275,40 @@ func (o *Oracle) updateCommitStatus(index uint64, src *api.TxnContext) {
 	// TODO: We should check if the tablet is in read-only status here.
 	if o.updateCommitStatusHelper(index, src) {
 		delta := new(pb.OracleDelta)
-		delta.Txns = append(delta.Txns, &pb.TxnStatus{
+		status := &pb.TxnStatus{
 			StartTs:  src.StartTs,
 			CommitTs: o.commitTs(src.StartTs),
-		})
+		}
+		if status.CommitTs > 0 {
+			status.Preds = txnPreds(src)
+		}
+		delta.Txns = append(delta.Txns, status)
 		o.updates <- delta
 	}
 }
 
+// txnPreds returns the predicates written by the txn. The group id in front of each of them is
+// dropped.
+func txnPreds(src *api.TxnContext) []string {
+	if len(src.Preds) == 0 {
+		return nil
+	}
+	preds := make([]string, 0, len(src.Preds))
+	seen := make(map[string]struct{}, len(src.Preds))
+	for _, pkey := range src.Preds {
+		splits := strings.SplitN(pkey, "-", 2)
+		if len(splits) < 2 {
+			continue
+		}
+		if _, ok := seen[splits[1]]; ok {
+			continue
+		}
+		seen[splits[1]] = struct{}{}
+		preds = append(preds, splits[1])
+	}
+	return preds
+}
+
 func (o *Oracle) commitTs(startTs uint64) uint64 {
 	o.RLock()
 	defer o.RUnlock()
@@ -320,6 +346,11 @@ func (s *Server) proposeTxn(ctx context.Context, src *api.TxnContext) error {
 		CommitTs: src.CommitTs,
 		Aborted:  src.Aborted,
 	}
+	if !src.Aborted {
+		// The predicates are sent to the Alphas along with the commit, so that they know what
+		// changed.
+		zp.Txn.Preds = src.Preds
+	}
 
 	// NOTE: It is important that we continue retrying proposeTxn until we succeed. This should
 	// happen, irrespective of what the user context timeout might be. We check for it before
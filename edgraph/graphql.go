@@ -22,6 +22,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/dgraph-io/dgo/v210/protos/api"
 	"github.com/dgraph-io/dgraph/graphql/schema"
@@ -41,15 +45,22 @@ import (
 //		    i)  If query is not provided then update gqlRes with the found query and proceed
 //			ii) If query is provided then match query retrieved, if identical do nothing else
 //				throw "query does not match persisted query"
-func ProcessPersistedQuery(ctx context.Context, gqlReq *schema.Request) error {
+//
+// If registryOnly is true, the persisted queries form a registry which the operations must be
+// in: the queries sent without a sha256Hash are looked up by their hash, and the queries which
+// aren't found are rejected instead of being stored.
+//
+// The persisted queries which are found are cached, see persistedQueryCache.
+func ProcessPersistedQuery(ctx context.Context, gqlReq *schema.Request, registryOnly bool) error {
 	query := gqlReq.Query
 	sha256Hash := gqlReq.Extensions.PersistedQuery.Sha256Hash
 
 	if sha256Hash == "" {
-		return nil
-	}
-
-	if x.WorkerConfig.AclEnabled {
+		if !registryOnly || query == "" {
+			return nil
+		}
+		sha256Hash = queryHash(query)
+	} else if x.WorkerConfig.AclEnabled {
 		accessJwt, err := x.ExtractJwt(ctx)
 		if err != nil {
 			return err
@@ -59,6 +70,14 @@ func ProcessPersistedQuery(ctx context.Context, gqlReq *schema.Request) error {
 		}
 	}
 
+	ns, err := x.ExtractNamespace(ctx)
+	cached := err == nil
+	if cached {
+		if gotQuery, ok := persistedQueries.get(ns, sha256Hash); ok {
+			return usePersistedQuery(gqlReq, gotQuery)
+		}
+	}
+
 	join := sha256Hash + query
 
 	queryForSHA := `query Me($join: string){
@@ -101,6 +120,9 @@ func ProcessPersistedQuery(ctx context.Context, gqlReq *schema.Request) error {
 		if query == "" {
 			return errors.New("PersistedQueryNotFound")
 		}
+		if registryOnly {
+			return errors.New("operation is not in the persisted query registry")
+		}
 		if match, err := hashMatches(query, sha256Hash); err != nil {
 			return err
 		} else if !match {
@@ -145,14 +167,193 @@ func ProcessPersistedQuery(ctx context.Context, gqlReq *schema.Request) error {
 	if len(shaQueryRes.Me[0].PersistedQuery) >= 64 {
 		gotQuery = shaQueryRes.Me[0].PersistedQuery[64:]
 	}
+	if cached {
+		persistedQueries.set(ns, sha256Hash, gotQuery)
+	}
+	return usePersistedQuery(gqlReq, gotQuery)
+}
 
-	if len(query) > 0 && gotQuery != query {
+// usePersistedQuery sets the query of gqlReq to the persisted query gotQuery. The query of
+// gqlReq, if any, must be the same.
+func usePersistedQuery(gqlReq *schema.Request, gotQuery string) error {
+	if len(gqlReq.Query) > 0 && gotQuery != gqlReq.Query {
 		return errors.New("query does not match persisted query")
 	}
-
 	gqlReq.Query = gotQuery
 	return nil
+}
+
+const (
+	// persistedQueryTTL is how long a persisted query stays cached. The queries removed from the
+	// registry through another alpha are still served by this one for up to that long.
+	persistedQueryTTL = time.Minute
+	// maxCachedPersistedQueries is the number of persisted queries which are cached at most.
+	maxCachedPersistedQueries = 10000
+)
+
+type persistedQueryKey struct {
+	ns         uint64
+	sha256Hash string
+}
+
+type cachedPersistedQuery struct {
+	query  string
+	expiry time.Time
+}
+
+// persistedQueryCache caches the persisted queries found by their hash, so that serving them,
+// and every operation in the registry-only mode, doesn't cost a query each. Only the queries
+// which are found are cached, so that the ones added to the registry are served at once.
+type persistedQueryCache struct {
+	sync.Mutex
+	queries map[persistedQueryKey]cachedPersistedQuery
+}
+
+var persistedQueries = &persistedQueryCache{
+	queries: make(map[persistedQueryKey]cachedPersistedQuery),
+}
+
+func (c *persistedQueryCache) get(ns uint64, sha256Hash string) (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	key := persistedQueryKey{ns: ns, sha256Hash: sha256Hash}
+	q, ok := c.queries[key]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(q.expiry) {
+		delete(c.queries, key)
+		return "", false
+	}
+	return q.query, true
+}
+
+func (c *persistedQueryCache) set(ns uint64, sha256Hash, query string) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.queries) >= maxCachedPersistedQueries {
+		c.queries = make(map[persistedQueryKey]cachedPersistedQuery)
+	}
+	c.queries[persistedQueryKey{ns: ns, sha256Hash: sha256Hash}] = cachedPersistedQuery{
+		query:  query,
+		expiry: time.Now().Add(persistedQueryTTL),
+	}
+}
+
+// clear removes the persisted queries of the namespace ns from the cache.
+func (c *persistedQueryCache) clear(ns uint64) {
+	c.Lock()
+	defer c.Unlock()
+	for key := range c.queries {
+		if key.ns == ns {
+			delete(c.queries, key)
+		}
+	}
+}
+
+// UpdatePersistedQueries stores the queries of a manifest, keyed by their SHA-256 hash, in the
+// persisted query registry of the namespace. The queries which are already in the registry are
+// kept as they are. If replace is true, the queries of the registry which aren't in the manifest
+// are removed.
+func UpdatePersistedQueries(ctx context.Context, queries map[string]string, replace bool) error {
+	if !x.WorkerConfig.AclEnabled {
+		ctx = x.AttachNamespace(ctx, x.GalaxyNamespace)
+	}
+
+	hashes := make([]string, 0, len(queries))
+	for sha256Hash, query := range queries {
+		if match, err := hashMatches(query, sha256Hash); err != nil {
+			return err
+		} else if !match {
+			return errors.Errorf("provided sha %s does not match query", sha256Hash)
+		}
+		hashes = append(hashes, sha256Hash)
+	}
+	sort.Strings(hashes)
+
+	// Each query is only added if it isn't in the registry yet, eg.
+	//	q0 as var(func: eq(dgraph.graphql.p_query, "<sha256Hash><query>"))
+	// with the mutation adding it conditioned on @if(eq(len(q0), 0)).
+	var blocks strings.Builder
+	vars := make(map[string]string, len(hashes))
+	mutations := make([]*api.Mutation, 0, len(hashes)+1)
+	queryVars := make([]string, 0, len(hashes))
+	for i, sha256Hash := range hashes {
+		varName := fmt.Sprintf("q%d", i)
+		fmt.Fprintf(&blocks, "\t%s as var(func: eq(dgraph.graphql.p_query, $%s))\n", varName,
+			varName)
+		vars["$"+varName] = sha256Hash + queries[sha256Hash]
+		queryVars = append(queryVars, varName)
+
+		blank := "_:" + varName
+		mutations = append(mutations, &api.Mutation{
+			Set: []*api.NQuad{
+				{
+					Subject:   blank,
+					Predicate: "dgraph.graphql.p_query",
+					ObjectValue: &api.Value{Val: &api.Value_StrVal{
+						StrVal: sha256Hash + queries[sha256Hash]}},
+				},
+				{
+					Subject:   blank,
+					Predicate: "dgraph.type",
+					ObjectValue: &api.Value{Val: &api.Value_StrVal{
+						StrVal: "dgraph.graphql.persisted_query"}},
+				},
+			},
+			Cond: fmt.Sprintf("@if(eq(len(%s), 0))", varName),
+		})
+	}
+	if replace {
+		blocks.WriteString("\tstale as var(func: type(dgraph.graphql.persisted_query))")
+		if len(queryVars) > 0 {
+			fmt.Fprintf(&blocks, " @filter(NOT uid(%s))", strings.Join(queryVars, ", "))
+		}
+		blocks.WriteString("\n")
+		star := &api.Value{Val: &api.Value_DefaultVal{DefaultVal: x.Star}}
+		mutations = append(mutations, &api.Mutation{
+			Del: []*api.NQuad{
+				{Subject: "uid(stale)", Predicate: "dgraph.graphql.p_query", ObjectValue: star},
+				{Subject: "uid(stale)", Predicate: "dgraph.type", ObjectValue: star},
+			},
+		})
+	}
+	if len(mutations) == 0 {
+		return nil
+	}
+
+	header := "query {\n"
+	if len(queryVars) > 0 {
+		varDefs := make([]string, 0, len(queryVars))
+		for _, varName := range queryVars {
+			varDefs = append(varDefs, "$"+varName+": string")
+		}
+		header = fmt.Sprintf("query Registry(%s) {\n", strings.Join(varDefs, ", "))
+	}
+	req := &Request{
+		req: &api.Request{
+			Query:     header + blocks.String() + "}",
+			Vars:      vars,
+			Mutations: mutations,
+			CommitNow: true,
+		},
+		doAuth: NoAuthorize,
+	}
+	ctx = context.WithValue(ctx, IsGraphql, true)
+	if _, err := (&Server{}).doQuery(ctx, req); err != nil {
+		return err
+	}
+	// The queries removed from the registry mustn't be served from the cache of this alpha.
+	if ns, err := x.ExtractNamespace(ctx); err == nil && replace {
+		persistedQueries.clear(ns)
+	}
+	return nil
+}
 
+// queryHash returns the hex encoded SHA-256 hash of query, which is what it's persisted with.
+func queryHash(query string) string {
+	hash := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(hash[:])
 }
 
 func hashMatches(query, sha256Hash string) (bool, error) {
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package edgraph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestPersistedQueryCache(t *testing.T) {
+	c := &persistedQueryCache{queries: make(map[persistedQueryKey]cachedPersistedQuery)}
+	query := `query { queryCountry { name } }`
+	sha256Hash := queryHash(query)
+
+	_, ok := c.get(1, sha256Hash)
+	require.False(t, ok)
+	c.set(1, sha256Hash, query)
+	got, ok := c.get(1, sha256Hash)
+	require.True(t, ok)
+	require.Equal(t, query, got)
+	// The queries are cached per namespace.
+	_, ok = c.get(2, sha256Hash)
+	require.False(t, ok)
+
+	c.set(2, sha256Hash, query)
+	c.clear(1)
+	_, ok = c.get(1, sha256Hash)
+	require.False(t, ok)
+	_, ok = c.get(2, sha256Hash)
+	require.True(t, ok)
+
+	// The expired queries are looked up again.
+	key := persistedQueryKey{ns: 2, sha256Hash: sha256Hash}
+	c.queries[key] = cachedPersistedQuery{query: query, expiry: time.Now().Add(-time.Second)}
+	_, ok = c.get(2, sha256Hash)
+	require.False(t, ok)
+	require.NotContains(t, c.queries, key)
+}
+
+func TestProcessPersistedQueryFromCache(t *testing.T) {
+	query := `query { queryCountry { name } }`
+	sha256Hash := queryHash(query)
+	ctx := x.AttachNamespace(context.Background(), x.GalaxyNamespace)
+	persistedQueries.set(x.GalaxyNamespace, sha256Hash, query)
+	defer persistedQueries.clear(x.GalaxyNamespace)
+
+	// The query is found by its hash, which is computed if it isn't given in the registry-only
+	// mode.
+	for _, registryOnly := range []bool{false, true} {
+		req := &schema.Request{}
+		req.Extensions.PersistedQuery.Sha256Hash = sha256Hash
+		require.NoError(t, ProcessPersistedQuery(ctx, req, registryOnly))
+		require.Equal(t, query, req.Query)
+	}
+	req := &schema.Request{Query: query}
+	require.NoError(t, ProcessPersistedQuery(ctx, req, true))
+	require.Equal(t, query, req.Query)
+
+	req = &schema.Request{Query: `query { queryCountry { id } }`}
+	req.Extensions.PersistedQuery.Sha256Hash = sha256Hash
+	require.EqualError(t, ProcessPersistedQuery(ctx, req, true),
+		"query does not match persisted query")
+}
@@ -74,6 +74,9 @@ const (
 	IsGraphql GraphqlContextKey = iota
 	// Authorize is used to set if the request requires validation.
 	Authorize
+	// QueryCursors is used to collect the cursors of the paginated blocks of a DQL query. The
+	// value is a map[string]string which gets filled with the cursors keyed by the block path.
+	QueryCursors
 )
 
 type AuthMode int
@@ -1187,6 +1190,8 @@ type queryContext struct {
 	// 1B) and resulting in OOM. We are limiting number of nquads which can be inserted in
 	// a single request.
 	nquadsCount int
+	// cursors holds the cursors of the paginated blocks of the query.
+	cursors map[string]string
 }
 
 // Request represents a query request sent to the doQuery() method on the Server.
@@ -1519,6 +1524,14 @@ func (s *Server) doQuery(ctx context.Context, req *Request) (resp *api.Response,
 		TotalNs:           uint64((time.Since(l.Start)).Nanoseconds()),
 	}
 	md := metadata.Pairs(x.DgraphCostHeader, fmt.Sprint(resp.Metrics.NumUids["_total"]))
+	for block, cursor := range qc.cursors {
+		md.Append(x.DgraphCursorHeader, block+"="+cursor)
+	}
+	if cursors, ok := ctx.Value(QueryCursors).(map[string]string); ok {
+		for block, cursor := range qc.cursors {
+			cursors[block] = cursor
+		}
+	}
 	grpc.SendHeader(ctx, md)
 	return resp, gqlErrs
 }
@@ -1539,6 +1552,11 @@ func processQuery(ctx context.Context, qc *queryContext) (*api.Response, error)
 		Latency:  qc.latency,
 		GqlQuery: &qc.gqlRes,
 	}
+	// The cursors of the nodes of a connection query are built from the values of their sort
+	// keys, which are fetched while processing the query.
+	if q, ok := qc.gqlField.(gqlSchema.Query); ok && q.QueryType() == gqlSchema.ConnectionQuery {
+		qr.CursorBlock = q.DgraphAlias()
+	}
 
 	// Here we try our best effort to not contact Zero for a timestamp. If we succeed,
 	// then we use the max known transaction ts value (from ProcessDelta) for a read-only query.
@@ -1657,6 +1675,7 @@ func processQuery(ctx context.Context, qc *queryContext) (*api.Response, error)
 	resp.Metrics = &api.Metrics{
 		NumUids: er.Metrics,
 	}
+	qc.cursors = er.Cursors
 
 	var total uint64
 	for _, num := range resp.Metrics.NumUids {
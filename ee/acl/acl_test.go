@@ -2346,6 +2346,10 @@ func TestSchemaQueryWithACL(t *testing.T) {
       "type": "uid",
       "list": true
 	},
+	{
+		"predicate":"dgraph.cdc.subscriptions",
+		"type":"string"
+	},
 	{
 		"predicate":"dgraph.drop.op",
 		"type":"string"
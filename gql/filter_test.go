@@ -377,6 +377,156 @@ func TestParseFilter_Geo4(t *testing.T) {
 	require.Contains(t, err.Error(), "\"]\"")
 }
 
+func TestParseSimilarTo(t *testing.T) {
+	query := `
+	query test($vec: string = "[0.5, 1.5]") {
+		me(func: similar_to(embedding, 3, $vec)) {
+			name
+		}
+		you(func: similar_to(embedding, 2, [0.1 , 0.2])) {
+			name
+		}
+	}
+`
+	resp, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, "similar_to", resp.Query[0].Func.Name)
+	require.Equal(t, "embedding", resp.Query[0].Func.Attr)
+	require.Equal(t, "3", resp.Query[0].Func.Args[0].Value)
+	require.Equal(t, "[0.5, 1.5]", resp.Query[0].Func.Args[1].Value)
+	require.Equal(t, "[0.1,0.2]", resp.Query[1].Func.Args[1].Value)
+}
+
+func TestParseFilter_uidInSubquery(t *testing.T) {
+	query := `
+	query test($company: string = "Dgraph") {
+		me(func: has(name)) @filter(uid_in(works_at, {
+			q(func: eq(name, $company), first: 10) @filter(has(address))
+		})) {
+			name
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	fn := res.Query[0].Filter.Func
+	require.Equal(t, "uid_in", fn.Name)
+	require.Equal(t, "works_at", fn.Attr)
+	require.Empty(t, fn.Args)
+	require.NotNil(t, fn.Subquery)
+	require.Equal(t, "eq", fn.Subquery.Func.Name)
+	require.Equal(t, "Dgraph", fn.Subquery.Func.Args[0].Value)
+	require.Equal(t, "10", fn.Subquery.Args["first"])
+	require.Equal(t, `(has address)`, fn.Subquery.Filter.debugString())
+}
+
+func TestParseFilter_uidInSubqueryVars(t *testing.T) {
+	query := `
+	{
+		c as var(func: eq(name, "Dgraph"))
+		me(func: has(name)) @filter(uid_in(works_at, { q(func: uid(c)) })) {
+			name
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, res.QueryVars[1].Needs)
+
+	query = `
+	{
+		me(func: has(name)) @filter(uid_in(works_at, { c as q(func: eq(name, "Dgraph")) })) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Variables can't be defined in a nested query block")
+}
+
+func TestParseFilter_subqueryError(t *testing.T) {
+	query := `
+	{
+		me(func: has(name)) @filter(uid_in(works_at, 0x1, { q(func: eq(name, "X")) })) {
+			name
+		}
+	}
+`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "A query block is only allowed as the second argument")
+
+	query = `
+	{
+		me(func: has(name)) @filter(eq(name, { q(func: eq(name, "X")) })) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "A query block is only allowed as the second argument")
+
+	query = `
+	{
+		me(func: has(name)) @filter(uid_in(works_at, { q(func: eq(name, "X")) { name } })) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unexpected item in a nested query block")
+}
+
+func TestParseFilter_exists(t *testing.T) {
+	query := `
+	{
+		me(func: has(name)) @filter(exists(friend @filter(eq(age, 30) and has(name))) or exists(pet)) {
+			name
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	filter := res.Query[0].Filter
+	require.Equal(t, "or", filter.Op)
+
+	fn := filter.Child[0].Func
+	require.Equal(t, "exists", fn.Name)
+	require.Equal(t, "friend", fn.Attr)
+	require.Equal(t, "friend", fn.Subquery.Attr)
+	require.Equal(t, `(AND (eq age "30") (has name))`, fn.Subquery.Filter.debugString())
+
+	fn = filter.Child[1].Func
+	require.Equal(t, "exists", fn.Name)
+	require.Equal(t, "pet", fn.Attr)
+	require.Nil(t, fn.Subquery)
+
+	query = `
+	{
+		me(func: has(name)) @filter(exists(name@en)) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Only @filter is allowed after the predicate of exists")
+
+	query = `
+	{
+		me(func: exists(friend)) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Function exists is only allowed in filters")
+}
+
 // Test if empty brackets will lead to errors.
 func TestParseFilter_emptyargument(t *testing.T) {
 	query := `
@@ -31,12 +31,14 @@ import (
 )
 
 const (
-	uidFunc   = "uid"
-	valueFunc = "val"
-	typFunc   = "type"
-	lenFunc   = "len"
-	countFunc = "count"
-	uidInFunc = "uid_in"
+	uidFunc      = "uid"
+	valueFunc    = "val"
+	typFunc      = "type"
+	lenFunc      = "len"
+	countFunc    = "count"
+	uidInFunc    = "uid_in"
+	existsFunc   = "exists"
+	distinctFunc = "distinct"
 )
 
 var (
@@ -67,6 +69,7 @@ type GraphQuery struct {
 	Normalize        bool
 	Recurse          bool
 	RecurseArgs      RecurseArgs
+	GraphAlgo        *GraphAlgoArgs
 	ShortestPathArgs ShortestPathArgs
 	Cascade          []string
 	IgnoreReflex     bool
@@ -96,6 +99,19 @@ type RecurseArgs struct {
 	// argument in the substitution part.
 }
 
+// GraphAlgoArgs stores the arguments of the graph algorithm directives, i.e. @pagerank,
+// @components and @triangles.
+type GraphAlgoArgs struct {
+	// Algo is the name of the algorithm run by the block.
+	Algo string
+	// Pred is the predicate whose edges form the graph.
+	Pred string
+	// Iterations is the number of iterations of pagerank.
+	Iterations int
+	// Damping is the damping factor of pagerank.
+	Damping float64
+}
+
 // ShortestPathArgs stores the arguments needed to process the shortest path query.
 type ShortestPathArgs struct {
 	// From, To can have a uid or a uid function as the argument.
@@ -104,6 +120,16 @@ type ShortestPathArgs struct {
 	// 3. from: uid(p) // a variable
 	From *Function
 	To   *Function
+	// Cost and Heuristic are optional and hold a value variable or a predicate which is read for
+	// every node reached by the path.
+	// 1. cost: val(c) // a value variable, which can also be the result of math()
+	// 2. cost: price  // a value predicate on the node
+	// Cost is the cost of entering the node, used instead of the facet on the edge. Heuristic is
+	// an estimate of the remaining cost from the node to the destination, which turns the
+	// search into A*. It must never overestimate the remaining cost, otherwise the path found
+	// might not be the shortest one.
+	Cost      *Function
+	Heuristic *Function
 }
 
 // GroupByAttr stores the arguments needed to process the @groupby directive.
@@ -185,6 +211,10 @@ type Function struct {
 	IsCount    bool         // gt(count(friends),0)
 	IsValueVar bool         // eq(val(s), 5)
 	IsLenVar   bool         // eq(len(s), 5)
+	// Subquery is the query block nested in the function. It's the block given to uid_in, as in
+	// uid_in(pred, { q(func: ...) }), or the filter on the edges of exists, as in
+	// exists(pred @filter(...)).
+	Subquery *GraphQuery
 }
 
 // filterOpPrecedence is a map from filterOp (a string) to its precedence.
@@ -223,7 +253,7 @@ var mathOpPrecedence = map[string]int{
 
 // IsAggregator returns true if the function name is an aggregation function.
 func (f *Function) IsAggregator() bool {
-	return isAggregator(f.Name)
+	return isAggregator(f.Name) || f.Name == distinctFunc
 }
 
 // IsPasswordVerifier returns true if the function name is "checkpwd".
@@ -504,6 +534,11 @@ func substituteVariablesFilter(f *FilterTree, vmap varMap) error {
 				}
 			}
 		}
+		if f.Func.Subquery != nil {
+			if err := substituteVariables(f.Func.Subquery, vmap); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, fChild := range f.Child {
@@ -774,6 +809,9 @@ func (f *FilterTree) collectVars(v *Vars) {
 		for _, va := range f.Func.NeedsVar {
 			v.Needs = append(v.Needs, va.Name)
 		}
+		if f.Func.Subquery != nil {
+			f.Func.Subquery.collectVars(v)
+		}
 	}
 	for _, fch := range f.Child {
 		fch.collectVars(v)
@@ -935,6 +973,77 @@ func parseRecurseArgs(it *lex.ItemIterator, gq *GraphQuery) error {
 	return nil
 }
 
+const (
+	defaultPageRankIterations = 20
+	defaultPageRankDamping    = 0.85
+)
+
+// parseGraphAlgoArgs parses the arguments of the directive running the graph algorithm algo,
+// e.g. @pagerank(pred: follows, iterations: 20, damping: 0.85).
+func parseGraphAlgoArgs(it *lex.ItemIterator, gq *GraphQuery, algo string) error {
+	if gq.GraphAlgo != nil {
+		return it.Errorf("Only one graph algorithm can be run by a block")
+	}
+	if ok := trySkipItemTyp(it, itemLeftRound); !ok {
+		return it.Errorf("Expected arguments inside @%s()", algo)
+	}
+
+	args := &GraphAlgoArgs{
+		Algo:       algo,
+		Iterations: defaultPageRankIterations,
+		Damping:    defaultPageRankDamping,
+	}
+	for it.Next() {
+		item := it.Item()
+		if item.Typ != itemName {
+			return item.Errorf("Expected key inside @%s()", algo)
+		}
+		key := strings.ToLower(item.Val)
+
+		if ok := trySkipItemTyp(it, itemColon); !ok {
+			return it.Errorf("Expected colon(:) after %s", key)
+		}
+		if !it.Next() {
+			return it.Errorf("Expected argument")
+		}
+		item = it.Item()
+		if item.Typ != itemName {
+			return item.Errorf("Expected value inside @%s() for key: %s", algo, key)
+		}
+		val := item.Val
+		switch {
+		case key == "pred":
+			args.Pred = val
+		case key == "iterations" && algo == "pagerank":
+			iterations, err := strconv.ParseUint(val, 0, 32)
+			if err != nil {
+				return errors.New("Value inside iterations should be type of integer")
+			}
+			args.Iterations = int(iterations)
+		case key == "damping" && algo == "pagerank":
+			damping, err := strconv.ParseFloat(val, 64)
+			if err != nil || damping < 0 || damping > 1 {
+				return errors.New("Value inside damping should be a number between 0 and 1")
+			}
+			args.Damping = damping
+		default:
+			return item.Errorf("Unexpected key: [%s] inside @%s", key, algo)
+		}
+
+		if _, ok := tryParseItemType(it, itemRightRound); ok {
+			break
+		}
+		if _, ok := tryParseItemType(it, itemComma); !ok {
+			return it.Errorf("Expected comma after value: %s inside @%s", val, algo)
+		}
+	}
+	if args.Pred == "" {
+		return it.Errorf("pred is required inside @%s", algo)
+	}
+	gq.GraphAlgo = args
+	return nil
+}
+
 // getQuery creates a GraphQuery object tree by calling getRoot
 // and goDeep functions by looking at '{'.
 func getQuery(it *lex.ItemIterator) (gq *GraphQuery, rerr error) {
@@ -991,6 +1100,10 @@ L:
 				if err := parseRecurseArgs(it, gq); err != nil {
 					return nil, err
 				}
+			case "pagerank", "components", "triangles":
+				if err := parseGraphAlgoArgs(it, gq, strings.ToLower(item.Val)); err != nil {
+					return nil, err
+				}
 			default:
 				return nil, item.Errorf("Unknown directive [%s]", item.Val)
 			}
@@ -1636,7 +1749,7 @@ func validFuncName(name string) bool {
 
 	switch name {
 	case "regexp", "anyofterms", "allofterms", "alloftext", "anyoftext",
-		"has", "uid", "uid_in", "anyof", "allof", "type", "match":
+		"has", "uid", "uid_in", "anyof", "allof", "type", "match", "similar_to":
 		return true
 	}
 	return false
@@ -1767,8 +1880,35 @@ L:
 					return nil, itemInFunc.Errorf("Invalid usage of '@' in function " +
 						"argument, must only appear immediately after attr.")
 				}
+				if function.Name == existsFunc {
+					// exists(pred @filter(...)) only counts the edges that pass the filter.
+					if !trySkipItemVal(it, "filter") {
+						return nil, itemInFunc.Errorf("Only @filter is allowed after the " +
+							"predicate of exists")
+					}
+					filter, err := parseFilter(it)
+					if err != nil {
+						return nil, err
+					}
+					function.Subquery = &GraphQuery{Attr: function.Attr, Filter: filter}
+					expectArg = false
+					continue
+				}
 				expectLang = true
 				continue
+			case itemLeftCurl:
+				if function.Name != uidInFunc || len(function.Attr) == 0 || !expectArg ||
+					len(function.Args) > 0 || function.Subquery != nil {
+					return nil, itemInFunc.Errorf("A query block is only allowed as the " +
+						"second argument of uid_in")
+				}
+				sub, err := parseSubquery(it)
+				if err != nil {
+					return nil, err
+				}
+				function.Subquery = sub
+				expectArg = false
+				continue
 			case itemMathOp:
 				val = itemInFunc.Val
 				it.Next()
@@ -1943,9 +2083,53 @@ L:
 		return nil, it.Errorf("type function only supports one argument. Got: %v", function.Args)
 	}
 
+	if function.Subquery != nil && (len(function.Args) > 0 || len(function.NeedsVar) > 0) {
+		return nil, it.Errorf("Function %s can't have other arguments with a query block",
+			function.Name)
+	}
+
 	return function, nil
 }
 
+// parseSubquery parses a query block nested in a function, like { q(func: eq(name, "X")) }.
+// The block only selects UIDs, so it only takes the arguments at root and a filter.
+func parseSubquery(it *lex.ItemIterator) (*GraphQuery, error) {
+	gq, err := getRoot(it)
+	if err != nil {
+		return nil, err
+	}
+	if gq.Var != "" {
+		return nil, it.Errorf("Variables can't be defined in a nested query block")
+	}
+	if gq.Alias == "shortest" {
+		return nil, it.Errorf("Shortest path can't be used in a nested query block")
+	}
+
+	for it.Next() {
+		item := it.Item()
+		switch item.Typ {
+		case itemRightCurl:
+			return gq, nil
+		case itemAt:
+			if !trySkipItemVal(it, "filter") {
+				return nil, item.Errorf("Only @filter is allowed in a nested query block")
+			}
+			if gq.Filter != nil {
+				return nil, item.Errorf("Use AND, OR and round brackets instead" +
+					" of multiple filter directives.")
+			}
+			filter, err := parseFilter(it)
+			if err != nil {
+				return nil, err
+			}
+			gq.Filter = filter
+		default:
+			return nil, item.Errorf("Unexpected item in a nested query block: %v", item)
+		}
+	}
+	return nil, it.Errorf("Unclosed nested query block")
+}
+
 type facetRes struct {
 	f           *pb.FacetParams
 	ft          *FilterTree
@@ -2612,9 +2796,10 @@ func parseLanguageList(it *lex.ItemIterator) ([]string, error) {
 
 func validKeyAtRoot(k string) bool {
 	switch k {
-	case "func", "orderasc", "orderdesc", "first", "offset", "after", "random":
+	case "func", "orderasc", "orderdesc", "first", "offset", "after", "random", "cursor":
 		return true
-	case "from", "to", "numpaths", "minweight", "maxweight":
+	case "from", "to", "numpaths", "minweight", "maxweight", "cost", "heuristic", "allpaths",
+		"pattern":
 		// Specific to shortest path
 		return true
 	case "depth":
@@ -2626,7 +2811,7 @@ func validKeyAtRoot(k string) bool {
 // Check for validity of key at non-root nodes.
 func validKey(k string) bool {
 	switch k {
-	case "orderasc", "orderdesc", "first", "offset", "after", "random":
+	case "orderasc", "orderdesc", "first", "offset", "after", "random", "cursor":
 		return true
 	}
 	return false
@@ -2714,6 +2899,9 @@ loop:
 		if !validKeyAtRoot(key) {
 			return nil, item.Errorf("Got invalid keyword: %s at root", key)
 		}
+		if (key == "allpaths" || key == "pattern") && gq.Alias != "shortest" {
+			return nil, item.Errorf("%s only allowed for shortest path queries", key)
+		}
 
 		if !it.Next() {
 			return nil, item.Errorf("Invalid query")
@@ -2736,6 +2924,11 @@ loop:
 			if !validFuncName(gen.Name) {
 				return nil, item.Errorf("Function name: %s is not valid.", gen.Name)
 			}
+			if gen.Name == existsFunc {
+				// exists checks the edges of the nodes being filtered, so there's nothing it
+				// could match at root.
+				return nil, item.Errorf("Function %s is only allowed in filters", gen.Name)
+			}
 			gq.Func = gen
 			gq.NeedsVar = append(gq.NeedsVar, gen.NeedsVar...)
 		case "from", "to":
@@ -2786,6 +2979,19 @@ loop:
 						" Got: %s", val)
 			}
 			assignShortestPathFn(fn, key)
+		case "cost", "heuristic":
+			if gq.Alias != "shortest" {
+				return gq, item.Errorf("%s only allowed for shortest path queries", key)
+			}
+			fn, err := parseNodeWeight(it, gq)
+			if err != nil {
+				return nil, err
+			}
+			if key == "cost" {
+				gq.ShortestPathArgs.Cost = fn
+			} else {
+				gq.ShortestPathArgs.Heuristic = fn
+			}
 
 		default:
 			var val string
@@ -2878,6 +3084,14 @@ loop:
 			if _, ok := gq.Args[key]; ok {
 				return gq, it.Errorf("Repeated key %q at root", key)
 			}
+			if key == "pattern" {
+				// Path patterns are given as strings, e.g. pattern: "friend+/works_at".
+				pattern, err := unquoteIfQuoted(val)
+				if err != nil {
+					return nil, err
+				}
+				val = pattern
+			}
 			gq.Args[key] = val
 		}
 	}
@@ -2885,6 +3099,34 @@ loop:
 	return gq, nil
 }
 
+// parseNodeWeight parses the value of the cost or heuristic argument of a shortest path query,
+// which can either be a value variable or a predicate.
+func parseNodeWeight(it *lex.ItemIterator, gq *GraphQuery) (*Function, error) {
+	if !it.Next() {
+		return nil, it.Errorf("Invalid query")
+	}
+	item := it.Item()
+	if item.Typ != itemName {
+		return nil, item.Errorf("Expected a value variable or a predicate. Got: %v", item.Val)
+	}
+	fn := &Function{}
+	if item.Val == valueFunc {
+		count, err := parseVarList(it, gq)
+		if err != nil {
+			return nil, err
+		}
+		if count != 1 {
+			return nil, item.Errorf("Expected only one variable but got: %d", count)
+		}
+		gq.NeedsVar[len(gq.NeedsVar)-1].Typ = ValueVar
+		fn.Name = valueFunc
+		fn.NeedsVar = append(fn.NeedsVar, gq.NeedsVar[len(gq.NeedsVar)-1])
+		return fn, nil
+	}
+	fn.Attr = collectName(it, item.Val)
+	return fn, nil
+}
+
 func isSortkey(k string) bool {
 	return k == "orderasc" || k == "orderdesc"
 }
@@ -2914,7 +3156,13 @@ func validateEmptyBlockItem(it *lex.ItemIterator, val string) error {
 		fname = item.Val
 	}
 	ok := trySkipItemTyp(it, itemLeftRound)
-	if !ok || (!isMathBlock(fname) && !isAggregator(fname)) {
+	if ok && fname == countFunc {
+		// count(distinct val(x)) is an aggregation too.
+		if item, isName := tryParseItemType(it, itemName); isName && item.Val == distinctFunc {
+			fname = distinctFunc
+		}
+	}
+	if !ok || (!isMathBlock(fname) && !isAggregator(fname) && fname != distinctFunc) {
 		return it.Errorf("Only aggregation/math functions allowed inside empty blocks."+
 			" Got: %v", fname)
 	}
@@ -3026,40 +3274,9 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 					goto Fall
 				}
 				it.Next()
-				if gq.IsGroupby {
-					item = it.Item()
-					attr := collectName(it, item.Val)
-					// Get language list, if present
-					items, err := it.Peek(1)
-					if err == nil && items[0].Typ == itemAt {
-						it.Next() // consume '@'
-						it.Next() // move forward
-						if child.Langs, err = parseLanguageList(it); err != nil {
-							return err
-						}
-					}
-					child.Attr = attr
-					child.IsInternal = false
-				} else {
-					if it.Item().Val != valueFunc {
-						return it.Errorf("Only variables allowed in aggregate functions. Got: %v",
-							it.Item().Val)
-					}
-					count, err := parseVarList(it, child)
-					if err != nil {
-						return err
-					}
-					if count != 1 {
-						return it.Errorf("Expected one variable inside val() of"+
-							" aggregator but got %v", count)
-					}
-					child.NeedsVar[len(child.NeedsVar)-1].Typ = ValueVar
-				}
-				child.Func = &Function{
-					Name:     valLower,
-					NeedsVar: child.NeedsVar,
+				if err := parseAggregatorArgs(it, gq, child, valLower); err != nil {
+					return err
 				}
-				it.Next() // Skip the closing ')'
 				gq.Children = append(gq.Children, child)
 				curp = nil
 				continue
@@ -3153,6 +3370,25 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 				switch {
 				case peekIt[0].Typ == itemRightRound:
 					return it.Errorf("Cannot use count(), please use count(uid)")
+				case peekIt[0].Val == distinctFunc && peekIt[1].Typ != itemRightRound:
+					// count(distinct val(x)) or count(distinct pred) inside @groupby.
+					count = notSeen
+					child := &GraphQuery{
+						Attr:       valueFunc,
+						Args:       make(map[string]string),
+						Var:        varName,
+						IsInternal: true,
+						Alias:      alias,
+					}
+					varName, alias = "", ""
+					it.Next() // Consume distinct
+					it.Next()
+					if err := parseAggregatorArgs(it, gq, child, distinctFunc); err != nil {
+						return err
+					}
+					gq.Children = append(gq.Children, child)
+					curp = nil
+					continue
 				case peekIt[0].Val == uidFunc && peekIt[1].Typ == itemRightRound:
 					if gq.IsGroupby {
 						// count(uid) case which occurs inside @groupby
@@ -3314,8 +3550,70 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 	return nil
 }
 
+// parseAggregatorArgs parses the arguments of the aggregation function fname into child. The
+// iterator is expected to be at the first item after the opening bracket of the function. Inside
+// @groupby the aggregation is done over a predicate, elsewhere it is done over a value variable.
+func parseAggregatorArgs(it *lex.ItemIterator, gq, child *GraphQuery, fname string) error {
+	if gq.IsGroupby {
+		item := it.Item()
+		attr := collectName(it, item.Val)
+		// Get language list, if present
+		items, err := it.Peek(1)
+		if err == nil && items[0].Typ == itemAt {
+			it.Next() // consume '@'
+			it.Next() // move forward
+			if child.Langs, err = parseLanguageList(it); err != nil {
+				return err
+			}
+		}
+		child.Attr = attr
+		child.IsInternal = false
+	} else {
+		if it.Item().Val != valueFunc {
+			return it.Errorf("Only variables allowed in aggregate functions. Got: %v",
+				it.Item().Val)
+		}
+		count, err := parseVarList(it, child)
+		if err != nil {
+			return err
+		}
+		if count != 1 {
+			return it.Errorf("Expected one variable inside val() of"+
+				" aggregator but got %v", count)
+		}
+		child.NeedsVar[len(child.NeedsVar)-1].Typ = ValueVar
+	}
+	child.Func = &Function{
+		Name:     fname,
+		NeedsVar: child.NeedsVar,
+	}
+	if fname == "percentile" {
+		// percentile takes the percent to compute as its second argument.
+		it.Next()
+		if it.Item().Typ != itemComma {
+			return it.Errorf("Expected a percent argument for percentile")
+		}
+		it.Next()
+		item := it.Item()
+		if item.Typ != itemName {
+			return item.Errorf("Expected a percent argument for percentile. Got: %v", item.Val)
+		}
+		if p, err := strconv.ParseFloat(item.Val, 64); err != nil || p < 0 || p > 100 {
+			return item.Errorf("Percent for percentile should be a number between 0 and 100."+
+				" Got: %v", item.Val)
+		}
+		child.Func.Args = append(child.Func.Args, Arg{Value: item.Val})
+	}
+	it.Next() // Skip the closing ')'
+	return nil
+}
+
 func isAggregator(fname string) bool {
-	return fname == "min" || fname == "max" || fname == "sum" || fname == "avg"
+	switch fname {
+	case "min", "max", "sum", "avg", "percentile", "median", "stddev", "variance":
+		return true
+	}
+	return false
 }
 
 func isExpandFunc(name string) bool {
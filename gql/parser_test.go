@@ -18,6 +18,7 @@ package gql
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"runtime/debug"
 	"testing"
@@ -96,6 +97,86 @@ func TestParseShortestPath(t *testing.T) {
 	require.Equal(t, "6", res.Query[0].Args["maxweight"])
 }
 
+func TestParseShortestPathWithCost(t *testing.T) {
+	query := `
+	{
+		var(func: uid(0x0a)) {
+			road {
+				c as toll
+				h as math(c * 2)
+			}
+		}
+
+		shortest(from: 0x0a, to: 0x0b, cost: val(c), heuristic: val(h)) {
+			road
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(res.Query))
+	args := res.Query[1].ShortestPathArgs
+	require.Equal(t, "c", args.Cost.NeedsVar[0].Name)
+	require.Equal(t, ValueVar, args.Cost.NeedsVar[0].Typ)
+	require.Equal(t, "h", args.Heuristic.NeedsVar[0].Name)
+	require.Empty(t, args.Cost.Attr)
+	require.Equal(t, 2, len(res.Query[1].NeedsVar))
+}
+
+func TestParseShortestPathWithCostPredicate(t *testing.T) {
+	query := `
+	{
+		shortest(from: 0x0a, to: 0x0b, cost: travel-time) {
+			road
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, "travel-time", res.Query[0].ShortestPathArgs.Cost.Attr)
+	require.Nil(t, res.Query[0].ShortestPathArgs.Heuristic)
+}
+
+func TestParseCostOutsideShortestPath(t *testing.T) {
+	query := `
+	{
+		me(func: uid(0x0a), cost: toll) {
+			road
+		}
+	}
+`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cost only allowed for shortest path queries")
+}
+
+func TestParseShortestPathWithPattern(t *testing.T) {
+	query := `
+	{
+		shortest(from: 0x0a, to: 0x0b, allpaths: true, pattern: "friend+/works_at") {
+			friend
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, "true", res.Query[0].Args["allpaths"])
+	require.Equal(t, "friend+/works_at", res.Query[0].Args["pattern"])
+}
+
+func TestParsePatternOutsideShortestPath(t *testing.T) {
+	query := `
+	{
+		me(func: uid(0x0a), pattern: "friend+") {
+			friend
+		}
+	}
+`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "pattern only allowed for shortest path queries")
+}
+
 func TestParseShortestPathInvalidFnError(t *testing.T) {
 	query := `{
 		shortest(from: eq(a), to: uid(b)) {
@@ -326,6 +407,21 @@ func TestParseAfter(t *testing.T) {
 	require.Equal(t, res.Query[0].Children[1].Args["after"], "3")
 }
 
+func TestParseCursor(t *testing.T) {
+	query := `
+	query {
+		user(func: has(name), orderasc: name, first: 10, cursor: "eyJ1IjoxfQ") {
+			friends (first: 10, cursor: "eyJ1IjoyfQ") {
+				name
+			}
+		}
+	}`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, `"eyJ1IjoxfQ"`, res.Query[0].Args["cursor"])
+	require.Equal(t, `"eyJ1IjoyfQ"`, res.Query[0].Children[0].Args["cursor"])
+}
+
 func TestParseOffset(t *testing.T) {
 	query := `
 	query {
@@ -1329,6 +1425,55 @@ func TestRecurseWithError(t *testing.T) {
 	require.Contains(t, err.Error(), "Value inside loop should be type of boolean")
 }
 
+func TestGraphAlgo(t *testing.T) {
+	query := `
+	{
+		rank as me(func: has(follows)) @pagerank(pred: follows, iterations: 10, damping: 0.5) {
+			name
+		}
+		comp as you(func: has(follows)) @components(pred: follows) {
+			name
+		}
+		pagerank(func: uid(rank)) {
+			name
+		}
+	}`
+	gq, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, &GraphAlgoArgs{Algo: "pagerank", Pred: "follows", Iterations: 10,
+		Damping: 0.5}, gq.Query[0].GraphAlgo)
+	require.Equal(t, &GraphAlgoArgs{Algo: "components", Pred: "follows",
+		Iterations: defaultPageRankIterations, Damping: defaultPageRankDamping},
+		gq.Query[1].GraphAlgo)
+	require.Nil(t, gq.Query[2].GraphAlgo)
+}
+
+func TestGraphAlgoWithError(t *testing.T) {
+	tests := []struct {
+		directive string
+		err       string
+	}{
+		{`@pagerank`, "Expected arguments inside @pagerank()"},
+		{`@triangles(iterations: 10)`, "Unexpected key: [iterations] inside @triangles"},
+		{`@components(damping: 0.5, pred: follows)`, "Unexpected key: [damping]"},
+		{`@pagerank(iterations: 10)`, "pred is required inside @pagerank"},
+		{`@pagerank(pred: follows, damping: 2)`, "should be a number between 0 and 1"},
+		{`@pagerank(pred: follows) @triangles(pred: follows)`,
+			"Only one graph algorithm can be run by a block"},
+	}
+	for _, tc := range tests {
+		query := fmt.Sprintf(`
+		{
+			rank as me(func: has(follows)) %s {
+				name
+			}
+		}`, tc.directive)
+		_, err := Parse(Request{Str: query})
+		require.Error(t, err, tc.directive)
+		require.Contains(t, err.Error(), tc.err, tc.directive)
+	}
+}
+
 func TestLexQueryWithValidQuery(t *testing.T) {
 	query := `{
 		q(func: allofterms(<name:is>, "hey you there"), first:20, offset:0, orderasc:Pokemon.id){
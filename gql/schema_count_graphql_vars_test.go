@@ -478,6 +478,77 @@ func TestParseGroupbyWithMaxVar(t *testing.T) {
 	require.Equal(t, []string{"en", "ta"}, res.Query[0].Children[0].Children[0].Langs)
 	require.Equal(t, "a", res.Query[0].Children[0].Children[0].Var)
 }
+
+func TestParseGroupbyWithStatAggregators(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x1)) {
+			friends @groupby(school) {
+				p as percentile(age, 95)
+				median(age)
+				stddev(age)
+				count(distinct name)
+			}
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	children := res.Query[0].Children[0].Children
+	require.Equal(t, 4, len(children))
+	require.Equal(t, "age", children[0].Attr)
+	require.Equal(t, "percentile", children[0].Func.Name)
+	require.Equal(t, []Arg{{Value: "95"}}, children[0].Func.Args)
+	require.Equal(t, "p", children[0].Var)
+	require.Equal(t, "median", children[1].Func.Name)
+	require.Equal(t, "stddev", children[2].Func.Name)
+	require.Equal(t, "name", children[3].Attr)
+	require.Equal(t, "distinct", children[3].Func.Name)
+	require.True(t, children[3].Func.IsAggregator())
+}
+
+func TestParseStatAggregatorsOverVar(t *testing.T) {
+	query := `
+	query {
+		var(func: uid(0x1)) {
+			friends {
+				a as age
+			}
+		}
+
+		me() {
+			percentile(val(a), 99.5)
+			variance(val(a))
+			count(distinct val(a))
+		}
+	}
+`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	children := res.Query[1].Children
+	require.Equal(t, 3, len(children))
+	require.Equal(t, "percentile", children[0].Func.Name)
+	require.Equal(t, []Arg{{Value: "99.5"}}, children[0].Func.Args)
+	require.Equal(t, "a", children[0].NeedsVar[0].Name)
+	require.Equal(t, "variance", children[1].Func.Name)
+	require.Equal(t, "distinct", children[2].Func.Name)
+	require.Equal(t, ValueVar, children[2].NeedsVar[0].Typ)
+}
+
+func TestParsePercentileInvalidPercent(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x1)) {
+			friends @groupby(school) {
+				percentile(age, 120)
+			}
+		}
+	}
+`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Percent for percentile should be a number between 0 and 100")
+}
 func TestParseFacetsError1(t *testing.T) {
 	query := `
 	query {
@@ -354,6 +354,12 @@ func lexFuncOrArg(l *lex.Lexer) lex.StateFn {
 			l.Emit(itemLeftSquare)
 		case r == rightSquare:
 			l.Emit(itemRightSquare)
+		case r == leftCurl:
+			// Query block nested in a function, like uid_in(pred, { q(func: ...) }).
+			empty = false
+			l.Emit(itemLeftCurl)
+		case r == rightCurl:
+			l.Emit(itemRightCurl)
 		case r == '#':
 			return lexComment
 		case r == '.':
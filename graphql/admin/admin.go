@@ -233,9 +233,80 @@ const (
 		script: String!
 	}
 
+	input PersistedQueryInput {
+		"""
+		The SHA-256 hash of the query, which clients send in the persistedQuery extension.
+		"""
+		sha256Hash: String!
+		query: String!
+	}
+
+	input UpdatePersistedQueriesInput {
+		"""
+		The persisted queries of the manifest.
+		"""
+		queries: [PersistedQueryInput!]!
+
+		"""
+		Remove the queries of the registry which aren't in the manifest.
+		"""
+		replace: Boolean
+	}
+
+	type UpdatePersistedQueriesPayload {
+		response: Response
+	}
+
+	input CDCSubscriptionInput {
+		name: String!
+
+		"""
+		The namespaces whose events are sent. If none is given, all of them are.
+		"""
+		namespaces: [UInt64!]
+
+		"""
+		The predicates whose events are sent. If none is given, all of them are, except for the
+		excluded ones.
+		"""
+		includePredicates: [String!]
+		excludePredicates: [String!]
+
+		"""
+		Restrict the events to the ones of the predicates of these types, and of the types.
+		"""
+		types: [String!]
+
+		"""
+		The template of the topic the events are sent to, e.g. "cdc.{namespace}.{predicate}".
+		{namespace}, {predicate} and {type} are replaced with the ones of each event.
+		The default topic is "dgraph-cdc".
+		"""
+		topic: String
+	}
+
+	input UpdateCDCSubscriptionsInput {
+		subscriptions: [CDCSubscriptionInput!]!
+	}
+
+	type UpdateCDCSubscriptionsPayload {
+		response: Response
+	}
+
+	type CDCSubscription {
+		name: String!
+		namespaces: [UInt64!]
+		includePredicates: [String!]
+		excludePredicates: [String!]
+		types: [String!]
+		topic: String
+	}
+
 	input ExportInput {
 		"""
-		Data format for the export, e.g. "rdf" or "json" (default: "rdf")
+		Data format for the export, e.g. "rdf" or "json" (default: "rdf"). The "graphql" and
+		"csv" formats export a file per GraphQL type, with the objects shaped by the GraphQL
+		schema instead of the predicates.
 		"""
 		format: String
 
@@ -630,6 +701,55 @@ const (
 		type: String
 	}
 
+	type PredicateStats {
+		predicate: String
+
+		"""
+		Number of nodes that have the predicate.
+		"""
+		count: UInt64
+
+		"""
+		Estimated number of distinct values or UIDs the predicate points to.
+		"""
+		distinctValues: UInt64
+
+		"""
+		Number of posting lists by length.
+		"""
+		histogram: [StatsBucket]
+
+		indexes: [IndexStats]
+
+		"""
+		When the statistics were collected.
+		"""
+		collectedAt: DateTime
+	}
+
+	type StatsBucket {
+		"""
+		Posting lists in the bucket have more than half of maxLength, and up to maxLength postings.
+		"""
+		maxLength: UInt64
+		count: UInt64
+	}
+
+	type IndexStats {
+		tokenizer: String
+		numTokens: UInt64
+
+		"""
+		The tokens with the longest posting lists.
+		"""
+		topTokens: [TokenCount]
+	}
+
+	type TokenCount {
+		token: String
+		count: UInt64
+	}
+
 	` + adminTypes + `
 
 	type Query {
@@ -643,6 +763,14 @@ const (
 		Get the information about the backups at a given location.
 		"""
 		listBackups(input: ListBackupsInput!) : [Manifest]
+		"""
+		Get the statistics last collected for the given predicates, or for all of them.
+		"""
+		predicateStats(predicates: [String]): [PredicateStats]
+		"""
+		Get the Change Data Capture subscriptions of the alpha.
+		"""
+		cdcSubscriptions: [CDCSubscription]
 		` + adminQueries + `
 	}
 
@@ -659,9 +787,23 @@ const (
 		"""
 		updateLambdaScript(input: UpdateLambdaScriptInput!) : UpdateLambdaScriptPayload
 
+		"""
+		Upload a manifest of persisted queries to the registry of the namespace. If Dgraph runs
+		with --graphql persisted-queries-only, the registry is the only operations /graphql serves.
+		"""
+		updatePersistedQueries(input: UpdatePersistedQueriesInput!) : UpdatePersistedQueriesPayload
+
+		"""
+		Replace the Change Data Capture subscriptions, which route the events to topics
+		filtered by namespace, predicate and type. The subscriptions are stored in the cluster,
+		so they apply to all the alphas, and are kept after restarts. The Kafka sink sends the
+		events to each topic they match, the other sinks send them once, with the topics.
+		"""
+		updateCDCSubscriptions(input: UpdateCDCSubscriptionsInput!) : UpdateCDCSubscriptionsPayload
+
 		"""
 		Starts an export of all data in the cluster.  Export format should be 'rdf' (the default
-		if no format is given), or 'json'.
+		if no format is given), 'json', 'graphql' or 'csv'.
 		See : https://dgraph.io/docs/deploy/#export-database
 		"""
 		export(input: ExportInput!): ExportPayload
@@ -767,6 +909,9 @@ var (
 		"listBackups":     gogQryMWs,
 		"getGQLSchema":    stdAdminQryMWs,
 		"getLambdaScript": stdAdminQryMWs,
+		"predicateStats":  stdAdminQryMWs,
+
+		"cdcSubscriptions": gogQryMWs,
 		// for queries and mutations related to User/Group, dgraph handles Guardian auth,
 		// so no need to apply GuardianAuth Middleware
 		"queryUser":      minimalAdminQryMWs,
@@ -800,6 +945,9 @@ var (
 		"updateGroup": minimalAdminMutMWs,
 		"deleteUser":  minimalAdminMutMWs,
 		"deleteGroup": minimalAdminMutMWs,
+
+		"updatePersistedQueries": stdAdminMutMWs,
+		"updateCDCSubscriptions": gogMutMWs,
 	}
 	// mainHealthStore stores the health of the main GraphQL server.
 	mainHealthStore = &GraphQLHealthStore{}
@@ -874,7 +1022,7 @@ func NewServers(withIntrospection bool, globalEpoch map[uint64]*uint64,
 
 	resolvers := resolve.New(gqlSchema, resolverFactoryWithErrorMsg(errNoGraphQLSchema))
 	e := globalEpoch[x.GalaxyNamespace]
-	mainServer := NewServer()
+	mainServer := newServer(x.Config.GraphQL.PersistedQueriesOnly)
 	mainServer.Set(x.GalaxyNamespace, e, resolvers)
 
 	fns := &resolve.ResolverFns{
@@ -885,6 +1033,8 @@ func NewServers(withIntrospection bool, globalEpoch map[uint64]*uint64,
 		Ex:  resolve.NewDgraphExecutor(),
 	}
 	adminResolvers := newAdminResolver(mainServer, fns, withIntrospection, globalEpoch, closer)
+	// The typed exports are shaped by the GraphQL schemas, which the worker can't read.
+	worker.TypedExport = exportTypes
 	e = globalEpoch[x.GalaxyNamespace]
 	adminServer := NewServer()
 	adminServer.Set(x.GalaxyNamespace, e, adminResolvers)
@@ -1037,6 +1187,9 @@ func newAdminResolverFactory() resolve.ResolverFactory {
 		"shutdown":           resolveShutdown,
 		"updateLambdaScript": resolveUpdateLambda,
 
+		"updatePersistedQueries": resolveUpdatePersistedQueries,
+		"updateCDCSubscriptions": resolveUpdateCDCSubscriptions,
+
 		"removeNode":        resolveRemoveNode,
 		"moveTablet":        resolveMoveTablet,
 		"assign":            resolveAssign,
@@ -1064,6 +1217,12 @@ func newAdminResolverFactory() resolve.ResolverFactory {
 		WithQueryResolver("getLambdaScript", func(q schema.Query) resolve.QueryResolver {
 			return resolve.QueryResolverFunc(resolveGetLambda)
 		}).
+		WithQueryResolver("predicateStats", func(q schema.Query) resolve.QueryResolver {
+			return resolve.QueryResolverFunc(resolvePredicateStats)
+		}).
+		WithQueryResolver("cdcSubscriptions", func(q schema.Query) resolve.QueryResolver {
+			return resolve.QueryResolverFunc(resolveGetCDCSubscriptions)
+		}).
 		WithQueryResolver("getGQLSchema", func(q schema.Query) resolve.QueryResolver {
 			return resolve.QueryResolverFunc(
 				func(ctx context.Context, query schema.Query) *resolve.Resolved {
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"github.com/dgraph-io/dgraph/graphql/resolve"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/worker"
+)
+
+type cdcSubscriptionsInput struct {
+	Subscriptions []struct {
+		Name string
+		// The UInt64 values can be given as strings or as numbers.
+		Namespaces        []json.Number
+		IncludePredicates []string
+		ExcludePredicates []string
+		Types             []string
+		Topic             string
+	}
+}
+
+func resolveUpdateCDCSubscriptions(ctx context.Context, m schema.Mutation) (*resolve.Resolved,
+	bool) {
+	glog.Info("Got updateCDCSubscriptions request through GraphQL admin API")
+
+	input, err := getCDCSubscriptionsInput(m)
+	if err != nil {
+		return resolve.EmptyResult(m, err), false
+	}
+
+	subs := make([]*worker.CDCSubscription, 0, len(input.Subscriptions))
+	for _, in := range input.Subscriptions {
+		sub := &worker.CDCSubscription{
+			Name:              in.Name,
+			IncludePredicates: in.IncludePredicates,
+			ExcludePredicates: in.ExcludePredicates,
+			Types:             in.Types,
+			Topic:             in.Topic,
+		}
+		for _, n := range in.Namespaces {
+			ns, err := parseAsUint64(n)
+			if err != nil {
+				err = schema.GQLWrapf(err, "can't convert the namespace %s of subscription %s "+
+					"to uint64", n, in.Name)
+				return resolve.EmptyResult(m, inputArgError(err)), false
+			}
+			sub.Namespaces = append(sub.Namespaces, ns)
+		}
+		subs = append(subs, sub)
+	}
+	if err = worker.UpdateCDCSubscriptions(ctx, subs); err != nil {
+		return resolve.EmptyResult(m, err), false
+	}
+
+	return resolve.DataResult(
+		m,
+		map[string]interface{}{m.Name(): response("Success",
+			fmt.Sprintf("CDC subscriptions updated successfully, there are %d of them.",
+				len(subs)))},
+		nil,
+	), true
+}
+
+func resolveGetCDCSubscriptions(ctx context.Context, q schema.Query) *resolve.Resolved {
+	subs := worker.GetCDCSubscriptions()
+	results := make([]map[string]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		b, err := json.Marshal(sub)
+		if err != nil {
+			return resolve.EmptyResult(q, err)
+		}
+		var result map[string]interface{}
+		if err := schema.Unmarshal(b, &result); err != nil {
+			return resolve.EmptyResult(q, err)
+		}
+		results = append(results, result)
+	}
+
+	return resolve.DataResult(
+		q,
+		map[string]interface{}{q.Name(): results},
+		nil,
+	)
+}
+
+func getCDCSubscriptionsInput(m schema.Mutation) (*cdcSubscriptionsInput, error) {
+	inputArg := m.ArgValue(schema.InputArgName)
+	inputByts, err := json.Marshal(inputArg)
+	if err != nil {
+		return nil, schema.GQLWrapf(err, "couldn't get input argument")
+	}
+
+	var input cdcSubscriptionsInput
+	err = json.Unmarshal(inputByts, &input)
+	return &input, schema.GQLWrapf(err, "couldn't get input argument")
+}
@@ -0,0 +1,376 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	dgoapi "github.com/dgraph-io/dgo/v210/protos/api"
+	"github.com/dgraph-io/gqlparser/v2/ast"
+	"github.com/dgraph-io/gqlparser/v2/parser"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/edgraph"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// exportPageSize is the number of objects which are queried at once by the typed exports.
+const exportPageSize = 1000
+
+// exportType is a GraphQL type in the typed exports.
+type exportType struct {
+	name       string
+	dgraphName string
+	fields     []*exportField
+}
+
+// exportField is a field of a GraphQL type in the typed exports. The fields without a predicate
+// are the uids of the objects, like the ID fields.
+type exportField struct {
+	name string
+	pred string
+	// keys are set for the fields whose values are objects, which are exported as references
+	// keyed by these fields of their type.
+	keys []*exportField
+}
+
+// exportTypes exports the objects of each GraphQL type of the namespace ns at readTs. It's the
+// worker.TypedExport of the alphas.
+//
+// The graphql format writes an array of JSON objects per type, shaped like the results of the
+// GraphQL queries. The csv format writes a CSV file per type, with a column per field, and with
+// the lists written as JSON arrays. The objects of the other types are referenced by their @id
+// fields and their ID field, of which the CSV files only write the first.
+func exportTypes(ctx context.Context, ns, readTs uint64, format string,
+	create func(typeName string) (io.Writer, error)) error {
+	ctx = x.AttachNamespace(context.WithValue(ctx, edgraph.Authorize, false), ns)
+	query := func(q string, res interface{}) error {
+		resp, err := (&edgraph.Server{}).Query(ctx,
+			&dgoapi.Request{Query: q, StartTs: readTs, ReadOnly: true})
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(resp.GetJson()))
+		dec.UseNumber()
+		return dec.Decode(res)
+	}
+
+	// The GraphQL schema is read at readTs too, so that it matches the data.
+	var schemas struct {
+		Q []struct {
+			Schema string `json:"dgraph.graphql.schema"`
+		} `json:"q"`
+	}
+	err := query(`{ q(func: has(dgraph.graphql.schema)) { dgraph.graphql.schema } }`, &schemas)
+	if err != nil {
+		return errors.Wrap(err, "while reading the GraphQL schema")
+	}
+	if len(schemas.Q) == 0 {
+		return nil
+	}
+	if len(schemas.Q) > 1 {
+		return errors.New("found multiple GraphQL schemas")
+	}
+	sch, _ := worker.ParseAsSchemaAndScript([]byte(schemas.Q[0].Schema))
+	if strings.TrimSpace(sch) == "" {
+		return nil
+	}
+	types, err := parseExportTypes(sch, ns)
+	if err != nil {
+		return err
+	}
+
+	for _, typ := range types {
+		w, err := create(typ.name)
+		if err != nil {
+			return err
+		}
+		var write func(obj map[string]interface{}) error
+		cw := csv.NewWriter(w)
+		switch format {
+		case "graphql":
+			first := true
+			write = func(obj map[string]interface{}) error {
+				b, err := json.Marshal(typ.object(obj))
+				if err != nil {
+					return err
+				}
+				if !first {
+					if _, err := w.Write([]byte(",\n")); err != nil {
+						return err
+					}
+				}
+				first = false
+				_, err = w.Write(b)
+				return err
+			}
+		case "csv":
+			header := make([]string, 0, len(typ.fields))
+			for _, f := range typ.fields {
+				header = append(header, f.name)
+			}
+			if err := cw.Write(header); err != nil {
+				return err
+			}
+			write = func(obj map[string]interface{}) error {
+				row, err := typ.row(obj)
+				if err != nil {
+					return err
+				}
+				return cw.Write(row)
+			}
+		default:
+			return errors.Errorf("invalid typed export format: %s", format)
+		}
+
+		var after uint64
+		for {
+			var page struct {
+				Q []map[string]interface{} `json:"q"`
+			}
+			if err := query(typ.query(after), &page); err != nil {
+				return errors.Wrapf(err, "while querying the objects of the type %s", typ.name)
+			}
+			for _, obj := range page.Q {
+				if err := write(obj); err != nil {
+					return err
+				}
+			}
+			if len(page.Q) < exportPageSize {
+				break
+			}
+			uid, _ := page.Q[len(page.Q)-1]["uid"].(string)
+			if after, err = strconv.ParseUint(uid, 0, 64); err != nil {
+				return errors.Wrapf(err, "while paginating the objects of the type %s", typ.name)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseExportTypes returns the object types of the GraphQL schema sch which are stored in
+// Dgraph.
+func parseExportTypes(sch string, ns uint64) ([]*exportType, error) {
+	handler, err := schema.NewHandler(sch, false)
+	if err != nil {
+		return nil, err
+	}
+	gqlSchema, err := schema.FromString(handler.GQLSchema(), ns)
+	if err != nil {
+		return nil, err
+	}
+	// The complete schema has many generated types, so the types are taken from the input.
+	doc, gqlErr := parser.ParseSchema(&ast.Source{Input: sch})
+	if gqlErr != nil {
+		return nil, gqlErr
+	}
+
+	var types []*exportType
+	for _, def := range doc.Definitions {
+		switch {
+		case def.Kind != ast.Object:
+			continue
+		case def.Name == "Query" || def.Name == "Mutation" || def.Name == "Subscription":
+			continue
+		case def.Directives.ForName("remote") != nil:
+			continue
+		}
+		t := gqlSchema.Type(def.Name)
+		if t == nil {
+			continue
+		}
+
+		typ := &exportType{name: t.Name(), dgraphName: t.DgraphName()}
+		if t.IDField() == nil && len(t.XIDFields()) == 0 {
+			// Without an ID field, the objects are still referenced by their uids.
+			typ.fields = append(typ.fields, &exportField{name: "uid"})
+		}
+		for _, fd := range t.Fields() {
+			ft := fd.Type()
+			f := &exportField{name: fd.Name()}
+			switch {
+			case ft.IsAggregateResult():
+				continue
+			case fd.IsID() && !fd.IsExternal():
+			case fd.DgraphPredicate() == "":
+				continue
+			default:
+				f.pred = fd.DgraphPredicate()
+				if !ft.IsInbuiltOrEnumType() && !ft.IsGeo() {
+					f.keys = exportKeys(ft)
+				}
+			}
+			typ.fields = append(typ.fields, f)
+		}
+		types = append(types, typ)
+	}
+	return types, nil
+}
+
+// exportKeys returns the fields which the references to the objects of the type t are keyed by:
+// their @id fields and their ID field, or their uid if they have neither.
+func exportKeys(t schema.Type) []*exportField {
+	var keys []*exportField
+	for _, fd := range t.XIDFields() {
+		keys = append(keys, &exportField{name: fd.Name(), pred: t.DgraphPredicate(fd.Name())})
+	}
+	if id := t.IDField(); id != nil {
+		keys = append(keys, &exportField{name: id.Name()})
+	}
+	if len(keys) == 0 {
+		keys = append(keys, &exportField{name: "uid"})
+	}
+	return keys
+}
+
+// query returns the DQL query of the page of objects of the type which follows the uid after.
+// The predicates are aliased by the names of their fields.
+func (typ *exportType) query(after uint64) string {
+	var b strings.Builder
+	x.Check2(fmt.Fprintf(&b, "{\n  q(func: type(%s), first: %d, after: %#x) {\n    uid\n",
+		typ.dgraphName, exportPageSize, after))
+	for _, f := range typ.fields {
+		if f.pred == "" {
+			continue
+		}
+		x.Check2(fmt.Fprintf(&b, "    %s : %s", f.name, f.pred))
+		if f.keys != nil {
+			x.Check2(b.WriteString(" {\n      uid\n"))
+			for _, k := range f.keys {
+				if k.pred != "" {
+					x.Check2(fmt.Fprintf(&b, "      %s : %s\n", k.name, k.pred))
+				}
+			}
+			x.Check2(b.WriteString("    }"))
+		}
+		x.Check2(b.WriteString("\n"))
+	}
+	x.Check2(b.WriteString("  }\n}"))
+	return b.String()
+}
+
+// value returns the value of the field f in the object obj of the query results.
+func (f *exportField) value(obj map[string]interface{}) (interface{}, bool) {
+	if f.pred == "" {
+		v, ok := obj["uid"]
+		return v, ok
+	}
+	v, ok := obj[f.name]
+	if !ok || f.keys == nil {
+		return v, ok
+	}
+
+	ref := func(v interface{}) interface{} {
+		refObj, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		keys := make(map[string]interface{}, len(f.keys))
+		for _, k := range f.keys {
+			if kv, ok := k.value(refObj); ok {
+				keys[k.name] = kv
+			}
+		}
+		return keys
+	}
+	if list, ok := v.([]interface{}); ok {
+		refs := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			refs = append(refs, ref(item))
+		}
+		return refs, true
+	}
+	return ref(v), true
+}
+
+// object returns the object obj of the query results shaped like the GraphQL type.
+func (typ *exportType) object(obj map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(typ.fields))
+	for _, f := range typ.fields {
+		if v, ok := f.value(obj); ok {
+			res[f.name] = v
+		}
+	}
+	return res
+}
+
+// row returns the CSV row of the object obj of the query results. The references are written as
+// their first key.
+func (typ *exportType) row(obj map[string]interface{}) ([]string, error) {
+	row := make([]string, 0, len(typ.fields))
+	for _, f := range typ.fields {
+		v, _ := f.value(obj)
+		cell := func(v interface{}) interface{} {
+			if ref, ok := v.(map[string]interface{}); ok && f.keys != nil {
+				return ref[f.keys[0].name]
+			}
+			return v
+		}
+
+		switch val := v.(type) {
+		case nil:
+			row = append(row, "")
+		case []interface{}:
+			items := make([]interface{}, 0, len(val))
+			for _, item := range val {
+				items = append(items, cell(item))
+			}
+			b, err := json.Marshal(items)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, string(b))
+		default:
+			s, err := csvCell(cell(val))
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, s)
+		}
+	}
+	return row, nil
+}
+
+func csvCell(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		// The geo values are written as GeoJSON.
+		b, err := json.Marshal(val)
+		return string(b), err
+	}
+}
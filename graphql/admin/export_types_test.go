@@ -0,0 +1,160 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exportTypesSchema = `
+type Author {
+	id: ID!
+	name: String! @id
+	posts: [Post] @hasInverse(field: author)
+}
+
+type Post {
+	title: String! @id
+	score: Float
+	author: Author
+	tags: [Tag]
+}
+
+type Tag {
+	label: String
+}`
+
+func TestParseExportTypes(t *testing.T) {
+	types, err := parseExportTypes(exportTypesSchema, 0)
+	require.NoError(t, err)
+	require.Len(t, types, 3)
+
+	fieldNames := func(typ *exportType) []string {
+		var names []string
+		for _, f := range typ.fields {
+			names = append(names, f.name)
+		}
+		return names
+	}
+	author, post, tag := types[0], types[1], types[2]
+	require.Equal(t, "Author", author.name)
+	// The aggregate fields aren't exported.
+	require.Equal(t, []string{"id", "name", "posts"}, fieldNames(author))
+	require.Equal(t, []string{"title", "score", "author", "tags"}, fieldNames(post))
+	// The objects without an ID or @id field are exported with their uids.
+	require.Equal(t, []string{"uid", "label"}, fieldNames(tag))
+
+	// The references are keyed by the @id fields and the ID field, which is the uid, or by the
+	// uid alone.
+	require.Equal(t, `{
+  q(func: type(Post), first: 1000, after: 0x2a) {
+    uid
+    title : Post.title
+    score : Post.score
+    author : Post.author {
+      uid
+      name : Author.name
+    }
+    tags : Post.tags {
+      uid
+    }
+  }
+}`, post.query(42))
+	require.Equal(t, `{
+  q(func: type(Author), first: 1000, after: 0x0) {
+    uid
+    name : Author.name
+    posts : Author.posts {
+      uid
+      title : Post.title
+    }
+  }
+}`, author.query(0))
+}
+
+func TestExportTypesObjectAndRow(t *testing.T) {
+	types, err := parseExportTypes(exportTypesSchema, 0)
+	require.NoError(t, err)
+	author, post, tag := types[0], types[1], types[2]
+
+	// The query results are decoded with json.Number, like in exportTypes.
+	results := func(s string) map[string]interface{} {
+		var obj map[string]interface{}
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		require.NoError(t, dec.Decode(&obj))
+		return obj
+	}
+
+	a := results(`{"uid": "0x1", "name": "Alice",
+		"posts": [{"uid": "0x2", "title": "Hello"}, {"uid": "0x3", "title": "Bye"}]}`)
+	b, err := json.Marshal(author.object(a))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": "0x1", "name": "Alice",
+		"posts": [{"title": "Hello"}, {"title": "Bye"}]}`, string(b))
+	row, err := author.row(a)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0x1", "Alice", `["Hello","Bye"]`}, row)
+
+	p := results(`{"uid": "0x2", "title": "Hello", "score": 4.5,
+		"author": {"uid": "0x1", "name": "Alice"}, "tags": [{"uid": "0x4"}]}`)
+	b, err = json.Marshal(post.object(p))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title": "Hello", "score": 4.5, "author": {"name": "Alice", "id": "0x1"},
+		"tags": [{"uid": "0x4"}]}`, string(b))
+	// The CSV files reference the objects by their first key.
+	row, err = post.row(p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Hello", "4.5", "Alice", `["0x4"]`}, row)
+
+	// The missing values are left out of the objects, and are empty in the CSV files.
+	p = results(`{"uid": "0x3", "title": "Bye"}`)
+	b, err = json.Marshal(post.object(p))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"title": "Bye"}`, string(b))
+	row, err = post.row(p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bye", "", "", ""}, row)
+
+	tg := results(`{"uid": "0x4", "label": "news"}`)
+	b, err = json.Marshal(tag.object(tg))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"uid": "0x4", "label": "news"}`, string(b))
+}
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		cell string
+	}{
+		{nil, ""},
+		{"Alice", "Alice"},
+		{json.Number("12.5"), "12.5"},
+		{true, "true"},
+		{map[string]interface{}{"type": "Point", "coordinates": []interface{}{1.5, 2}},
+			`{"coordinates":[1.5,2],"type":"Point"}`},
+	}
+	for _, test := range tests {
+		cell, err := csvCell(test.v)
+		require.NoError(t, err)
+		require.Equal(t, test.cell, cell)
+	}
+}
@@ -65,13 +65,21 @@ type graphqlHandler struct {
 	poller      map[uint64]*subscription.Poller
 	resolverMux sync.RWMutex // protects resolver from RW races
 	pollerMux   sync.RWMutex // protects poller from RW races
+	// registryOnly is true if only the operations in the persisted query registry of the
+	// namespace are served.
+	registryOnly bool
 }
 
 // NewServer returns a new IServeGraphQL that can serve the given resolvers
 func NewServer() IServeGraphQL {
+	return newServer(false)
+}
+
+func newServer(registryOnly bool) *graphqlHandler {
 	gh := &graphqlHandler{
-		resolver: make(map[uint64]*resolve.RequestResolver),
-		poller:   make(map[uint64]*subscription.Poller),
+		resolver:     make(map[uint64]*resolve.RequestResolver),
+		poller:       make(map[uint64]*subscription.Poller),
+		registryOnly: registryOnly,
 	}
 	gh.handler = recoveryHandler(commonHeaders(gh.Handler()))
 	return gh
@@ -207,6 +215,12 @@ func (gs *graphqlSubscription) Subscribe(
 		glog.Errorf("namespace: %d. graphqlSubscription not initialized: %s", namespace, err)
 		return nil, errors.New(resolve.ErrInternal)
 	}
+	if gs.registryOnly {
+		err = edgraph.ProcessPersistedQuery(x.AttachNamespace(ctx, namespace), req, true)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	gs.graphqlHandler.pollerMux.RLock()
 	poller := gs.graphqlHandler.poller[namespace]
@@ -271,7 +285,7 @@ func (gh *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = edgraph.ProcessPersistedQuery(ctx, gqlReq); err != nil {
+	if err = edgraph.ProcessPersistedQuery(ctx, gqlReq, gh.registryOnly); err != nil {
 		WriteErrorResponse(w, r, err)
 		return
 	}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/dgraph/edgraph"
+	"github.com/dgraph-io/dgraph/graphql/resolve"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/golang/glog"
+)
+
+type persistedQueriesInput struct {
+	Queries []struct {
+		Sha256Hash string
+		Query      string
+	}
+	Replace bool
+}
+
+func resolveUpdatePersistedQueries(ctx context.Context, m schema.Mutation) (*resolve.Resolved,
+	bool) {
+	glog.Info("Got updatePersistedQueries request through GraphQL admin API")
+
+	input, err := getPersistedQueriesInput(m)
+	if err != nil {
+		return resolve.EmptyResult(m, err), false
+	}
+
+	queries := make(map[string]string, len(input.Queries))
+	for _, q := range input.Queries {
+		if other, ok := queries[q.Sha256Hash]; ok && other != q.Query {
+			return resolve.EmptyResult(m, fmt.Errorf("sha %s is given for different queries",
+				q.Sha256Hash)), false
+		}
+		queries[q.Sha256Hash] = q.Query
+	}
+	if err = edgraph.UpdatePersistedQueries(ctx, queries, input.Replace); err != nil {
+		return resolve.EmptyResult(m, err), false
+	}
+
+	return resolve.DataResult(
+		m,
+		map[string]interface{}{m.Name(): response("Success",
+			fmt.Sprintf("Persisted queries updated successfully, the manifest has %d queries.",
+				len(queries)))},
+		nil,
+	), true
+}
+
+func getPersistedQueriesInput(m schema.Mutation) (*persistedQueriesInput, error) {
+	inputArg := m.ArgValue(schema.InputArgName)
+	inputByts, err := json.Marshal(inputArg)
+	if err != nil {
+		return nil, schema.GQLWrapf(err, "couldn't get input argument")
+	}
+
+	var input persistedQueriesInput
+	err = json.Unmarshal(inputByts, &input)
+	return &input, schema.GQLWrapf(err, "couldn't get input argument")
+}
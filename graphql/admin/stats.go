@@ -0,0 +1,130 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/dgraph/graphql/resolve"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type predicateStats struct {
+	Predicate      string         `json:"predicate,omitempty"`
+	Count          uint64         `json:"count"`
+	DistinctValues uint64         `json:"distinctValues"`
+	Histogram      []*statsBucket `json:"histogram"`
+	Indexes        []*indexStats  `json:"indexes"`
+	CollectedAt    string         `json:"collectedAt,omitempty"`
+}
+
+type statsBucket struct {
+	MaxLength uint64 `json:"maxLength"`
+	Count     uint64 `json:"count"`
+}
+
+type indexStats struct {
+	Tokenizer string        `json:"tokenizer"`
+	NumTokens uint64        `json:"numTokens"`
+	TopTokens []*tokenCount `json:"topTokens"`
+}
+
+type tokenCount struct {
+	Token string `json:"token"`
+	Count uint64 `json:"count"`
+}
+
+func resolvePredicateStats(ctx context.Context, q schema.Query) *resolve.Resolved {
+	ns, err := x.ExtractNamespace(ctx)
+	if err != nil {
+		return resolve.EmptyResult(q, err)
+	}
+	var preds []string
+	if arg, ok := q.ArgValue("predicates").([]interface{}); ok {
+		for _, pred := range arg {
+			if s, ok := pred.(string); ok {
+				preds = append(preds, x.NamespaceAttr(ns, s))
+			}
+		}
+	}
+
+	nodes, err := worker.GetSchemaOverNetwork(ctx,
+		&pb.SchemaRequest{Predicates: preds, Fields: []string{"stats"}})
+	if err != nil {
+		return resolve.EmptyResult(q, err)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Predicate < nodes[j].Predicate
+	})
+
+	results := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		// Without a list of predicates, all the namespaces are returned.
+		if node.Stats == nil || x.ParseNamespace(node.Predicate) != ns {
+			continue
+		}
+		b, err := json.Marshal(convertPredicateStats(x.ParseAttr(node.Predicate), node.Stats))
+		if err != nil {
+			return resolve.EmptyResult(q, err)
+		}
+		var result map[string]interface{}
+		if err := schema.Unmarshal(b, &result); err != nil {
+			return resolve.EmptyResult(q, err)
+		}
+		results = append(results, result)
+	}
+
+	return resolve.DataResult(
+		q,
+		map[string]interface{}{q.Name(): results},
+		nil,
+	)
+}
+
+func convertPredicateStats(pred string, stats *pb.PredicateStats) *predicateStats {
+	res := &predicateStats{
+		Predicate:      pred,
+		Count:          stats.Count,
+		DistinctValues: stats.DistinctValues,
+		Histogram:      make([]*statsBucket, 0, len(stats.Histogram)),
+		Indexes:        make([]*indexStats, 0, len(stats.Indexes)),
+	}
+	if stats.CollectedAt > 0 {
+		res.CollectedAt = time.Unix(stats.CollectedAt, 0).UTC().Format(time.RFC3339)
+	}
+	for _, b := range stats.Histogram {
+		res.Histogram = append(res.Histogram, &statsBucket{MaxLength: b.MaxLength, Count: b.Count})
+	}
+	for _, idx := range stats.Indexes {
+		out := &indexStats{
+			Tokenizer: idx.Tokenizer,
+			NumTokens: idx.NumTokens,
+			TopTokens: make([]*tokenCount, 0, len(idx.TopTokens)),
+		}
+		for _, t := range idx.TopTokens {
+			out.TopTokens = append(out.TopTokens, &tokenCount{Token: t.Token, Count: t.Count})
+		}
+		res.Indexes = append(res.Indexes, out)
+	}
+	return res
+}
@@ -314,6 +314,8 @@ func (c *CustomClaims) validateAudience() error {
 	return nil
 }
 
+// ExtractCustomClaims returns the claims of the JWT in ctx. Their auth variables are what the
+// @auth rules of the types, and of the fields, are evaluated with.
 func (a *AuthMeta) ExtractCustomClaims(ctx context.Context) (*CustomClaims, error) {
 	if a == nil {
 		return &CustomClaims{}, nil
@@ -289,7 +289,8 @@ func writeFilter(b *strings.Builder, ft *gql.FilterTree) {
 func hasOrderOrPage(q *gql.GraphQuery) bool {
 	_, hasFirst := q.Args["first"]
 	_, hasOffset := q.Args["offset"]
-	return len(q.Order) > 0 || hasFirst || hasOffset
+	_, hasCursor := q.Args["cursor"]
+	return len(q.Order) > 0 || hasFirst || hasOffset || hasCursor
 }
 
 func IsValueVar(attr string, q *gql.GraphQuery) bool {
@@ -302,7 +303,7 @@ func IsValueVar(attr string, q *gql.GraphQuery) bool {
 }
 
 func writeOrderAndPage(b *strings.Builder, query *gql.GraphQuery, root bool) {
-	var wroteOrder, wroteFirst bool
+	var wroteOrder, wroteFirst, wroteOffset bool
 
 	for _, ord := range query.Order {
 		if root || wroteOrder {
@@ -338,5 +339,13 @@ func writeOrderAndPage(b *strings.Builder, query *gql.GraphQuery, root bool) {
 		}
 		x.Check2(b.WriteString("offset: "))
 		x.Check2(b.WriteString(offset))
+		wroteOffset = true
+	}
+
+	if cursor, ok := query.Args["cursor"]; ok {
+		if root || wroteOrder || wroteFirst || wroteOffset {
+			x.Check2(b.WriteString(", "))
+		}
+		x.Check2(fmt.Fprintf(b, "cursor: %q", cursor))
 	}
 }
@@ -858,6 +858,7 @@ func RunAll(t *testing.T) {
 	t.Run("filter in queries with array for AND/OR", filterInQueriesWithArrayForAndOr)
 	t.Run("query geo near filter", queryGeoNearFilter)
 	t.Run("persisted query", persistedQuery)
+	t.Run("persisted query manifest", persistedQueryManifest)
 	t.Run("query aggregate without filter", queryAggregateWithoutFilter)
 	t.Run("query aggregate with filter", queryAggregateWithFilter)
 	t.Run("query aggregate on empty data", queryAggregateOnEmptyData)
@@ -18,6 +18,8 @@ package common
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -3124,6 +3126,51 @@ func persistedQuery(t *testing.T) {
 	RequireNoGQLErrors(t, gqlResponse)
 }
 
+func persistedQueryManifest(t *testing.T) {
+	query := `query {
+		queryCountry(filter: {name: {eq: "Angola"}}) {
+			name
+		}
+	}`
+	hash := sha256.Sum256([]byte(query))
+	sha256Hash := hex.EncodeToString(hash[:])
+
+	updateParams := &GraphQLParams{
+		Query: `mutation ($queries: [PersistedQueryInput!]!) {
+			updatePersistedQueries(input: {queries: $queries}) {
+				response { code }
+			}
+		}`,
+		Variables: map[string]interface{}{"queries": []interface{}{
+			map[string]interface{}{"sha256Hash": "incorrectSha", "query": query},
+		}},
+	}
+	gqlResponse := updateParams.ExecuteAsPost(t, GraphqlAdminURL)
+	require.Len(t, gqlResponse.Errors, 1)
+	require.Contains(t, gqlResponse.Errors[0].Message, "does not match query")
+
+	updateParams.Variables["queries"] = []interface{}{
+		map[string]interface{}{"sha256Hash": sha256Hash, "query": query},
+	}
+	gqlResponse = updateParams.ExecuteAsPost(t, GraphqlAdminURL)
+	RequireNoGQLErrors(t, gqlResponse)
+	testutil.CompareJSON(t, `{"updatePersistedQueries": {"response": {"code": "Success"}}}`,
+		string(gqlResponse.Data))
+
+	// Uploading the manifest again keeps a single copy of the query.
+	gqlResponse = updateParams.ExecuteAsPost(t, GraphqlAdminURL)
+	RequireNoGQLErrors(t, gqlResponse)
+
+	queryParams := &GraphQLParams{
+		Extensions: &schema.RequestExtensions{PersistedQuery: schema.PersistedQuery{
+			Sha256Hash: sha256Hash,
+		}},
+	}
+	gqlResponse = queryParams.ExecuteAsPost(t, GraphqlURL)
+	RequireNoGQLErrors(t, gqlResponse)
+	testutil.CompareJSON(t, `{"queryCountry": [{"name": "Angola"}]}`, string(gqlResponse.Data))
+}
+
 func queryAggregateWithFilter(t *testing.T) {
 	queryPostParams := &GraphQLParams{
 		Query: `query {
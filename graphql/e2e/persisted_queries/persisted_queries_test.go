@@ -0,0 +1,92 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package persisted_queries
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/graphql/e2e/common"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/testutil"
+)
+
+const notInRegistry = "operation is not in the persisted query registry"
+
+// The alpha runs with --graphql "persisted-queries-only=true;", so it only serves the operations
+// in the persisted query registry.
+func TestPersistedQueriesOnly(t *testing.T) {
+	common.SafelyUpdateGQLSchemaOnAlpha1(t, `type Country {
+		id: ID!
+		name: String! @search(by: [hash])
+	}`)
+
+	query := `query {
+		queryCountry(filter: {name: {eq: "Angola"}}) {
+			name
+		}
+	}`
+	hash := sha256.Sum256([]byte(query))
+	sha256Hash := hex.EncodeToString(hash[:])
+	byHash := &common.GraphQLParams{
+		Extensions: &schema.RequestExtensions{PersistedQuery: schema.PersistedQuery{
+			Sha256Hash: sha256Hash,
+		}},
+	}
+
+	// The operations which aren't in the registry are rejected.
+	byQuery := &common.GraphQLParams{Query: query}
+	requireError(t, byQuery.ExecuteAsPost(t, common.GraphqlURL), notInRegistry)
+
+	// The clients can't add operations to the registry with automatic persisted queries.
+	apq := &common.GraphQLParams{Query: query, Extensions: byHash.Extensions}
+	requireError(t, apq.ExecuteAsPost(t, common.GraphqlURL), notInRegistry)
+	requireError(t, byHash.ExecuteAsPost(t, common.GraphqlURL), "PersistedQueryNotFound")
+
+	updateParams := &common.GraphQLParams{
+		Query: `mutation ($queries: [PersistedQueryInput!]!) {
+			updatePersistedQueries(input: {queries: $queries}) {
+				response { code }
+			}
+		}`,
+		Variables: map[string]interface{}{"queries": []interface{}{
+			map[string]interface{}{"sha256Hash": sha256Hash, "query": query},
+		}},
+	}
+	gqlResponse := updateParams.ExecuteAsPost(t, common.GraphqlAdminURL)
+	common.RequireNoGQLErrors(t, gqlResponse)
+
+	// Once in the registry, the operation is served both by its hash, and when it's sent
+	// without it, in which case it's looked up by the hash of the query.
+	for _, params := range []*common.GraphQLParams{byHash, byQuery, apq} {
+		gqlResponse = params.ExecuteAsPost(t, common.GraphqlURL)
+		common.RequireNoGQLErrors(t, gqlResponse)
+		testutil.CompareJSON(t, `{"queryCountry": []}`, string(gqlResponse.Data))
+	}
+
+	// The other operations are still rejected.
+	other := &common.GraphQLParams{Query: `query { queryCountry { id } }`}
+	requireError(t, other.ExecuteAsPost(t, common.GraphqlURL), notInRegistry)
+}
+
+func requireError(t *testing.T, resp *common.GraphQLResponse, msg string) {
+	require.Len(t, resp.Errors, 1)
+	require.Contains(t, resp.Errors[0].Message, msg)
+}
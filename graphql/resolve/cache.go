@@ -0,0 +1,317 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolve
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgryski/go-farm"
+
+	"github.com/dgraph-io/dgraph/graphql/authorization"
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// maxCachedResponses is the number of responses kept in the cache at most. Once it's full, the
+// expired responses are dropped first, and then the ones which expire the soonest.
+const maxCachedResponses = 1000
+
+// A responseCache keeps the responses of the queries with @cacheControl for their max-age, so that
+// a query asked again and again doesn't run each time. A response is dropped as soon as a txn
+// which writes one of the predicates it read is committed.
+type responseCache struct {
+	sync.Mutex
+	entries map[cacheKey]*cachedResponse
+	// watcher collects the predicates written by the commits. It's started with the first query
+	// which gets cached.
+	watcher *worker.CommitWatcher
+	// seq is increased each time predicates are taken from watcher. written is the last seq at
+	// which each namespaced predicate was written, and anyWritten the last one at which any was.
+	seq        uint64
+	written    map[string]uint64
+	anyWritten uint64
+}
+
+type cacheKey struct {
+	// The responses are kept per resolver, so that the ones of a previous schema aren't used.
+	resolver *RequestResolver
+	hash     uint64
+}
+
+type cachedResponse struct {
+	data      []byte
+	expiresAt time.Time
+	namespace uint64
+	// preds are the predicates read by the query. It's nil if they aren't known, then the
+	// response is dropped by any commit.
+	preds map[string]struct{}
+}
+
+var queryCache = &responseCache{
+	entries: make(map[cacheKey]*cachedResponse),
+	written: make(map[string]uint64),
+}
+
+// reads returns whether the cached query read the namespaced predicate pred.
+func (c *cachedResponse) reads(pred string) bool {
+	ns, attr := x.ParseNamespaceAttr(pred)
+	if ns != c.namespace {
+		return false
+	}
+	if c.preds == nil {
+		return true
+	}
+	_, ok := c.preds[attr]
+	return ok
+}
+
+// begin is called before a query which might get cached runs. It returns the seq to pass to put
+// along with its response, so that the commits which happen while it runs are taken into account.
+func (rc *responseCache) begin() uint64 {
+	rc.Lock()
+	defer rc.Unlock()
+	if rc.watcher == nil {
+		rc.watcher = worker.WatchCommits()
+	}
+	rc.invalidate()
+	return rc.seq
+}
+
+// invalidate drops the responses which read a predicate written since the last call. It must be
+// called with rc locked.
+func (rc *responseCache) invalidate() {
+	if rc.watcher != nil {
+		rc.drop(rc.watcher.Take())
+	}
+}
+
+// drop records that the namespaced predicates preds were written, and drops the responses which
+// read any of them. It must be called with rc locked.
+func (rc *responseCache) drop(preds map[string]struct{}) {
+	if len(preds) == 0 {
+		return
+	}
+	rc.seq++
+	rc.anyWritten = rc.seq
+	for pred := range preds {
+		rc.written[pred] = rc.seq
+	}
+	for key, entry := range rc.entries {
+		for pred := range preds {
+			if entry.reads(pred) {
+				delete(rc.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// get returns the cached data of the response for key, if there's one which didn't expire.
+func (rc *responseCache) get(key cacheKey) ([]byte, bool) {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.invalidate()
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// put caches entry for key, unless a predicate it read was written since the query started at
+// seq since.
+func (rc *responseCache) put(key cacheKey, entry *cachedResponse, since uint64) {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.invalidate()
+	if rc.anyWritten > since {
+		for pred, seq := range rc.written {
+			if seq > since && entry.reads(pred) {
+				return
+			}
+		}
+	}
+
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= maxCachedResponses {
+		now := time.Now()
+		var soonest cacheKey
+		var soonestAt time.Time
+		for k, e := range rc.entries {
+			if now.After(e.expiresAt) {
+				delete(rc.entries, k)
+			} else if soonestAt.IsZero() || e.expiresAt.Before(soonestAt) {
+				soonest, soonestAt = k, e.expiresAt
+			}
+		}
+		if len(rc.entries) >= maxCachedResponses {
+			delete(rc.entries, soonest)
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// resolveCached resolves op, which has @cacheControl, from the response cache if it can, and
+// with resolveQueries otherwise. The responses without errors are cached for the max-age of op.
+// They're cached per query, variables and auth claims, as those can all change the response.
+func (r *RequestResolver) resolveCached(
+	ctx context.Context,
+	gqlReq *schema.Request,
+	op schema.Operation,
+	resp *schema.Response,
+	resolveQueries func()) {
+
+	maxAge, err := authorization.ParseMaxAge(op.CacheControl())
+	if err != nil || maxAge <= 0 {
+		resolveQueries()
+		return
+	}
+	namespace, _ := x.ExtractNamespace(ctx)
+	hash, err := r.cacheHash(ctx, gqlReq, namespace)
+	if err != nil {
+		resolveQueries()
+		return
+	}
+	key := cacheKey{resolver: r, hash: hash}
+	if data, ok := queryCache.get(key); ok {
+		resp.AddData(data)
+		return
+	}
+
+	since := queryCache.begin()
+	resolveQueries()
+	if len(resp.Errors) > 0 {
+		return
+	}
+	queryCache.put(key, &cachedResponse{
+		data:      append([]byte(nil), resp.Data.Bytes()...),
+		expiresAt: time.Now().Add(time.Duration(maxAge) * time.Second),
+		namespace: namespace,
+		preds:     ReadPredicates(op),
+	}, since)
+}
+
+// cacheHash returns the hash which identifies the response to gqlReq in the cache.
+func (r *RequestResolver) cacheHash(
+	ctx context.Context,
+	gqlReq *schema.Request,
+	namespace uint64) (uint64, error) {
+
+	customClaims, err := r.schema.Meta().AuthMeta().ExtractCustomClaims(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Maps are marshalled with their keys sorted, so the same values always give the same JSON.
+	vars, err := json.Marshal(gqlReq.Variables)
+	if err != nil {
+		return 0, err
+	}
+	authVars, err := json.Marshal(customClaims.AuthVariables)
+	if err != nil {
+		return 0, err
+	}
+	// The ACL permissions of the user can change the response too.
+	accessJwt, _ := x.ExtractJwt(ctx)
+
+	// Each part is prefixed with its length, so that the parts can't run into each other.
+	var buf []byte
+	var n [binary.MaxVarintLen64]byte
+	for _, part := range [][]byte{[]byte(normalizeQuery(gqlReq.Query)),
+		[]byte(gqlReq.OperationName), vars, authVars, []byte(accessJwt)} {
+		buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(part)))]...)
+		buf = append(buf, part...)
+	}
+	buf = append(buf, n[:binary.PutUvarint(n[:], namespace)]...)
+	return farm.Fingerprint64(buf), nil
+}
+
+// normalizeQuery drops what isn't significant in query: the whitespace, commas and comments
+// between the tokens. A space is only kept between two names or numbers, and strings are kept
+// as they are, so that different queries never give the same result.
+func normalizeQuery(query string) string {
+	isWord := func(c byte) bool {
+		return c == '_' || c == '-' || c == '+' || c == '.' ||
+			(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+
+	var b strings.Builder
+	var last byte
+	var separated bool
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			separated = true
+			i++
+			continue
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+			separated = true
+			continue
+		}
+
+		if separated && isWord(last) && isWord(c) {
+			b.WriteByte(' ')
+		}
+		separated = false
+
+		if c != '"' {
+			b.WriteByte(c)
+			last = c
+			i++
+			continue
+		}
+		// Copy the string up to its closing quote(s), skipping the escaped ones.
+		end := i + 1
+		if strings.HasPrefix(query[i:], `"""`) {
+			end = i + 3
+			for end < len(query) && !strings.HasPrefix(query[end:], `"""`) {
+				if strings.HasPrefix(query[end:], `\"""`) {
+					end += 3
+				}
+				end++
+			}
+			end += 3
+		} else {
+			for end < len(query) && query[end] != '"' && query[end] != '\n' {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end++
+		}
+		if end > len(query) {
+			end = len(query)
+		}
+		b.WriteString(query[i:end])
+		last = '"'
+		i = end
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	query := `query q($id: ID!) @cacheControl(maxAge: 10) {
+		# the author
+		getAuthor(id: $id) { name, posts(first: 10) { title } }
+	}`
+	require.Equal(t,
+		`query q($id:ID!)@cacheControl(maxAge:10){getAuthor(id:$id){name posts(first:10){title}}}`,
+		normalizeQuery(query))
+	require.Equal(t, normalizeQuery(query), normalizeQuery(`query q($id: ID!)
+		@cacheControl(maxAge: 10) { getAuthor(id: $id) { name posts(first: 10) { title } } }`))
+
+	// The strings are kept as they are.
+	require.Equal(t, `{queryAuthor(filter:{name:{eq:"a,  # b"}}){name}}`,
+		normalizeQuery(`{ queryAuthor(filter: {name: {eq: "a,  # b"}}) { name } }`))
+	require.NotEqual(t, normalizeQuery(`{ q(s: "a\" b") { f } }`),
+		normalizeQuery(`{ q(s: "a\"  b") { f } }`))
+	require.Equal(t, `{q(s:"""a  "b" """){f}}`, normalizeQuery(`{ q(s: """a  "b" """) { f } }`))
+}
+
+func TestResponseCache(t *testing.T) {
+	rc := &responseCache{
+		entries: make(map[cacheKey]*cachedResponse),
+		written: make(map[string]uint64),
+	}
+	entry := func(data string) *cachedResponse {
+		return &cachedResponse{
+			data:      []byte(data),
+			expiresAt: time.Now().Add(time.Minute),
+			preds:     map[string]struct{}{"dgraph.type": {}, "Author.name": {}},
+		}
+	}
+	key := cacheKey{hash: 1}
+
+	rc.put(key, entry(`{"a":1}`), 0)
+	data, ok := rc.get(key)
+	require.True(t, ok)
+	require.Equal(t, `{"a":1}`, string(data))
+
+	// The writes to other predicates or in other namespaces don't drop the response.
+	rc.drop(map[string]struct{}{x.NamespaceAttr(0, "Post.title"): {},
+		x.NamespaceAttr(1, "Author.name"): {}})
+	_, ok = rc.get(key)
+	require.True(t, ok)
+
+	rc.drop(map[string]struct{}{x.NamespaceAttr(0, "Author.name"): {}})
+	_, ok = rc.get(key)
+	require.False(t, ok)
+
+	// A response isn't cached if a predicate it read was written while the query ran.
+	since := rc.seq
+	rc.drop(map[string]struct{}{x.NamespaceAttr(0, "Author.name"): {}})
+	rc.put(key, entry(`{"a":2}`), since)
+	_, ok = rc.get(key)
+	require.False(t, ok)
+
+	since = rc.seq
+	rc.drop(map[string]struct{}{x.NamespaceAttr(0, "Post.title"): {}})
+	rc.put(key, entry(`{"a":3}`), since)
+	data, ok = rc.get(key)
+	require.True(t, ok)
+	require.Equal(t, `{"a":3}`, string(data))
+
+	expired := entry(`{"a":4}`)
+	expired.expiresAt = time.Now().Add(-time.Second)
+	rc.put(key, expired, rc.seq)
+	_, ok = rc.get(key)
+	require.False(t, ok)
+}
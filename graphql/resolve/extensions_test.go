@@ -44,6 +44,7 @@ func TestQueriesPropagateExtensions(t *testing.T) {
 	require.NotNil(t, resp.Extensions)
 
 	require.Equal(t, uint64(2), resp.Extensions.TouchedUids)
+	require.Equal(t, uint64(2), resp.Extensions.Cost)
 	require.NotNil(t, resp.Extensions.Tracing)
 
 	require.Equal(t, resp.Extensions.Tracing.Version, 1)
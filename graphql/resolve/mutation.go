@@ -221,8 +221,19 @@ func (mr *dgraphResolver) rewriteAndExecute(
 	req := &dgoapi.Request{}
 	commit := false
 
+	// With @transaction on the operation, the mutation runs in the transaction of the operation,
+	// which is committed or aborted once all its mutations are resolved.
+	txn := mutationTxnFrom(ctx)
+	queryRequest := func(query string) *dgoapi.Request {
+		req := &dgoapi.Request{Query: query, ReadOnly: true}
+		if txn != nil {
+			return txn.request(req)
+		}
+		return req
+	}
+
 	defer func() {
-		if !commit && mutResp != nil && mutResp.Txn != nil {
+		if txn == nil && !commit && mutResp != nil && mutResp.Txn != nil {
 			mutResp.Txn.Aborted = true
 			_, err := mr.executor.CommitOrAbort(ctx, mutResp.Txn)
 			if err != nil {
@@ -279,6 +290,9 @@ func (mr *dgraphResolver) rewriteAndExecute(
 	// Execute queries and parse its result into a map
 	qry := dgraph.AsString(queries)
 	req.Query = qry
+	if txn != nil {
+		txn.request(req)
+	}
 
 	// The query will be empty in case there is no reference XID / UID in the mutation.
 	// Don't execute the query in those cases.
@@ -289,6 +303,9 @@ func (mr *dgraphResolver) rewriteAndExecute(
 		queryTimer.Start()
 		mutResp, err = mr.executor.Execute(ctx, req, nil)
 		queryTimer.Stop()
+		if txn != nil {
+			txn.join(mr.executor, mutResp.GetTxn())
+		}
 		if err != nil {
 			gqlErr := schema.GQLWrapLocationf(
 				err, mutation.Location(), "mutation %s failed", mutation.Name())
@@ -393,8 +410,7 @@ func (mr *dgraphResolver) rewriteAndExecute(
 
 			queryTimer := newtimer(ctx, &dgraphPostMutationQueryDuration.OffsetDuration)
 			queryTimer.Start()
-			qryResp, err = mr.executor.Execute(ctx, &dgoapi.Request{Query: dgraph.AsString(dgQuery),
-				ReadOnly: true}, qryField)
+			qryResp, err = mr.executor.Execute(ctx, queryRequest(dgraph.AsString(dgQuery)), qryField)
 			queryTimer.Stop()
 
 			if err != nil && !x.IsGqlErrorList(err) {
@@ -416,7 +432,13 @@ func (mr *dgraphResolver) rewriteAndExecute(
 	for _, upsert := range upserts {
 		req.Query = dgraph.AsString(upsert.Query)
 		req.Mutations = upsert.Mutations
+		if txn != nil {
+			txn.request(req)
+		}
 		mutResp, err = mr.executor.Execute(ctx, req, nil)
+		if txn != nil {
+			txn.join(mr.executor, mutResp.GetTxn())
+		}
 		if err != nil {
 			gqlErr := schema.GQLWrapLocationf(
 				err, mutation.Location(), "mutation %s failed", mutation.Name())
@@ -493,27 +515,39 @@ func (mr *dgraphResolver) rewriteAndExecute(
 		return emptyResult(queryErrs), resolverFailed
 	}
 
-	txnCtx, err := mr.executor.CommitOrAbort(ctx, mutResp.Txn)
-	if err != nil {
-		return emptyResult(
-				schema.GQLWrapf(err, "mutation failed, couldn't commit transaction")),
-			resolverFailed
-	}
-	commit = true
-
-	// once committed, send async updates to configured webhooks, if any.
+	var rootUIDs []string
 	if mutation.HasLambdaOnMutate() {
-		rootUIDs := mr.mutationRewriter.MutatedRootUIDs(mutation, mutResp.GetUids(), result)
-		// NOTE: This is an async operation. We can't extract logs from webhooks.
-		go sendWebhookEvent(ctx, mutation, txnCtx.CommitTs, rootUIDs)
+		rootUIDs = mr.mutationRewriter.MutatedRootUIDs(mutation, mutResp.GetUids(), result)
+	}
+	if txn != nil {
+		// The webhooks are sent once the transaction of the operation is committed.
+		if mutation.HasLambdaOnMutate() {
+			txn.onCommit = append(txn.onCommit, func(commitTs uint64) {
+				go sendWebhookEvent(ctx, mutation, commitTs, rootUIDs)
+			})
+		}
+	} else {
+		txnCtx, err := mr.executor.CommitOrAbort(ctx, mutResp.Txn)
+		if err != nil {
+			return emptyResult(
+					schema.GQLWrapf(err, "mutation failed, couldn't commit transaction")),
+				resolverFailed
+		}
+		commit = true
+
+		// once committed, send async updates to configured webhooks, if any.
+		if mutation.HasLambdaOnMutate() {
+			// NOTE: This is an async operation. We can't extract logs from webhooks.
+			go sendWebhookEvent(ctx, mutation, txnCtx.CommitTs, rootUIDs)
+		}
 	}
 
 	// For delete mutation, we would have already populated qryResp if query field was requested.
 	if mutation.MutationType() != schema.DeleteMutation {
 		queryTimer := newtimer(ctx, &dgraphPostMutationQueryDuration.OffsetDuration)
 		queryTimer.Start()
-		qryResp, err = mr.executor.Execute(ctx, &dgoapi.Request{Query: dgraph.AsString(dgQuery),
-			ReadOnly: true}, mutation.QueryField())
+		qryResp, err = mr.executor.Execute(ctx, queryRequest(dgraph.AsString(dgQuery)),
+			mutation.QueryField())
 		queryTimer.Stop()
 
 		if !x.IsGqlErrorList(err) {
@@ -68,6 +68,9 @@ type Rewriter struct {
 
 type AddRewriter struct {
 	frags []*mutationFragment
+	// conflictVars stores, for each input object of an upsert mutation, the variable of the
+	// query finding the existing node with the same conflict keys.
+	conflictVars []string
 	Rewriter
 }
 type UpdateRewriter struct {
@@ -328,8 +331,33 @@ func (arw *AddRewriter) RewriteQueries(
 	var retTypes []string
 	var retErrors error
 
+	onConflict, _ := m.ArgValue(schema.OnConflictArgName).(map[string]interface{})
+	arw.conflictVars = nil
+	// The conflict queries only find the existing nodes, so the input objects with the same
+	// values for the conflict keys would each add a node.
+	seenConflicts := make(map[string]bool)
+
 	for _, i := range val {
 		obj := i.(map[string]interface{})
+		if onConflict != nil {
+			keys, _ := onConflict["keys"].([]interface{})
+			query, variable, err := conflictQuery(mutatedType, arw.VarGen, obj, keys)
+			if err == nil {
+				conflict := conflictValues(obj, keys)
+				if seenConflicts[conflict] {
+					err = errors.Errorf("duplicate conflict keys found: %s", conflict)
+				}
+				seenConflicts[conflict] = true
+			}
+			if err != nil {
+				retErrors = schema.AppendGQLErrs(retErrors, schema.GQLWrapf(schema.AsGQLErrors(err),
+					"failed to rewrite mutation payload"))
+			} else {
+				ret = append(ret, query)
+				retTypes = append(retTypes, mutatedType.DgraphName())
+			}
+			arw.conflictVars = append(arw.conflictVars, variable)
+		}
 		queries, typs, errs := existenceQueries(ctx, mutatedType, nil, arw.VarGen, obj, arw.XidMetadata)
 		if len(errs) > 0 {
 			var gqlErrors x.GqlErrorList
@@ -514,9 +542,57 @@ func (arw *AddRewriter) Rewrite(
 		mutationType = AddWithUpsert
 	}
 
-	for _, i := range val {
+	// For upsert mutations, the objects whose conflict keys match an existing node are merged
+	// into it. mergeFrags stores the mutations setting the fields which must be preserved and
+	// the fields with update rules of their own, they aren't root fragments of the mutation.
+	onConflict, _ := m.ArgValue(schema.OnConflictArgName).(map[string]interface{})
+	var mergeFrags []*mutationFragment
+
+	// The upserts update the existing nodes, so they are checked against the update rules.
+	var authRw *authRewriter
+	if upsert || onConflict != nil {
+		customClaims, err := m.GetAuthMeta().ExtractCustomClaims(ctx)
+		if err != nil {
+			return ret, err
+		}
+		authRw = &authRewriter{
+			authVariables: customClaims.AuthVariables,
+			varGen:        varGen,
+			selector:      updateAuthSelector,
+			parentVarName: m.MutatedType().Name() + "Root",
+		}
+		authRw.hasAuthRules = hasAuthRules(m.QueryField(), authRw)
+	}
+
+	for idx, i := range val {
 		obj := i.(map[string]interface{})
-		fragment, upsertVar, errs := rewriteObject(ctx, mutatedType, nil, "", varGen, obj, xidMetadata, idExistence, mutationType)
+		var fragment *mutationFragment
+		var upsertVar string
+		var errs []error
+		if conflictVar := arw.conflictVar(idx); conflictVar != "" && idExistence[conflictVar] != "" {
+			var frags []*mutationFragment
+			fragment, frags, errs = rewriteConflict(ctx, mutatedType, authRw, conflictVar, varGen,
+				obj, onConflict, xidMetadata, idExistence)
+			mergeFrags = append(mergeFrags, frags...)
+			upsertVar = conflictVar
+		} else {
+			fragment, upsertVar, errs = rewriteObject(ctx, mutatedType, nil, "", varGen, obj, xidMetadata, idExistence, mutationType)
+			if upsertVar != "" && len(errs) == 0 {
+				// The fields with update rules of their own are taken out of the fragment
+				// updating the existing node, and written by fragments of their own.
+				var frags []*mutationFragment
+				var rest map[string]interface{}
+				rest, frags, errs = rewriteFieldAuth(ctx, mutatedType, authRw, upsertVar, obj,
+					UpdateWithSet, xidMetadata, idExistence)
+				for fld := range obj {
+					if _, ok := rest[fld]; !ok {
+						delete(fragment.fragment.(map[string]interface{}),
+							mutatedType.DgraphPredicate(fld))
+					}
+				}
+				mergeFrags = append(mergeFrags, frags...)
+			}
+		}
 		if len(errs) > 0 {
 			var gqlErrors x.GqlErrorList
 			for _, err := range errs {
@@ -531,19 +607,6 @@ func (arw *AddRewriter) Rewrite(
 		// top level exists. upsertVar in this case contains variable name of the node
 		// which is going to be updated. Eg. State3 .
 		if upsertVar != "" {
-			// Add auth queries for upsert mutation.
-			customClaims, err := m.GetAuthMeta().ExtractCustomClaims(ctx)
-			if err != nil {
-				return ret, err
-			}
-
-			authRw := &authRewriter{
-				authVariables: customClaims.AuthVariables,
-				varGen:        varGen,
-				selector:      updateAuthSelector,
-				parentVarName: m.MutatedType().Name() + "Root",
-			}
-			authRw.hasAuthRules = hasAuthRules(m.QueryField(), authRw)
 			// Get upsert query of the form,
 			// State1 as addState(func: uid(0x11)) @filter(type(State)) {
 			// 		uid
@@ -562,7 +625,8 @@ func (arw *AddRewriter) Rewrite(
 		}
 	}
 
-	for _, frag := range arw.frags {
+	allFrags := append(append([]*mutationFragment{}, arw.frags...), mergeFrags...)
+	for _, frag := range allFrags {
 		mutation, _ := mutationFromFragment(
 			frag,
 			func(frag *mutationFragment) ([]byte, error) {
@@ -676,6 +740,30 @@ func (urw *UpdateRewriter) Rewrite(
 		return ret, nil
 	}
 
+	// The fields with update rules of their own are written by separate mutations.
+	var fieldFrags, fieldDelFrags []*mutationFragment
+	var fieldErrs []error
+	if len(objSet) != 0 {
+		var errs []error
+		objSet, fieldFrags, errs = rewriteFieldAuth(ctx, mutatedType, authRw, MutationQueryVar,
+			objSet, UpdateWithSet, xidMetadata, idExistence)
+		fieldErrs = append(fieldErrs, errs...)
+	}
+	if len(objDel) != 0 {
+		var errs []error
+		objDel, fieldDelFrags, errs = rewriteFieldAuth(ctx, mutatedType, authRw, MutationQueryVar,
+			objDel, UpdateWithRemove, xidMetadata, idExistence)
+		fieldErrs = append(fieldErrs, errs...)
+	}
+	if len(fieldErrs) > 0 {
+		var gqlErrors x.GqlErrorList
+		for _, err := range fieldErrs {
+			gqlErrors = append(gqlErrors, schema.AsGQLErrors(err)...)
+		}
+		retErrors = schema.AppendGQLErrs(retErrors, schema.GQLWrapf(gqlErrors,
+			"failed to rewrite mutation payload"))
+	}
+
 	if setArg != nil {
 		if len(objSet) != 0 {
 			fragment, _, errs := rewriteObject(ctx, mutatedType, nil, srcUID, varGen, objSet, xidMetadata, idExistence, UpdateWithSet)
@@ -750,6 +838,40 @@ func (urw *UpdateRewriter) Rewrite(
 		queries = append(queries, urw.delFrag.queries...)
 	}
 
+	for _, frag := range fieldFrags {
+		mutSet, errSet := mutationFromFragment(
+			frag,
+			func(frag *mutationFragment) ([]byte, error) {
+				return json.Marshal(frag.fragment)
+			},
+			func(frag *mutationFragment) ([]byte, error) {
+				return nil, nil
+			})
+
+		if mutSet != nil {
+			mutations = append(mutations, mutSet)
+		}
+		retErrors = schema.AppendGQLErrs(retErrors, errSet)
+		queries = append(queries, frag.queries...)
+	}
+
+	for _, frag := range fieldDelFrags {
+		mutDel, errDel := mutationFromFragment(
+			frag,
+			func(frag *mutationFragment) ([]byte, error) {
+				return nil, nil
+			},
+			func(frag *mutationFragment) ([]byte, error) {
+				return json.Marshal(frag.fragment)
+			})
+
+		if mutDel != nil {
+			mutations = append(mutations, mutDel)
+		}
+		retErrors = schema.AppendGQLErrs(retErrors, errDel)
+		queries = append(queries, frag.queries...)
+	}
+
 	if urw.setFrag != nil {
 		copyTypeMap(urw.setFrag.newNodes, newNodes)
 	}
@@ -767,6 +889,264 @@ func (urw *UpdateRewriter) Rewrite(
 	return ret, retErrors
 }
 
+// rewriteFieldAuth takes out the fields of obj, which is written to the nodes in srcVar, that have
+// update rules of their own. Each of them is written by a fragment of its own, which only writes
+// it to the nodes that satisfy the rule, eg. for a field salary of type Employee:
+//
+//	Employee_4 as var(func: uid(x)) @filter(uid(Employee_5))
+//	Employee_5 as var(func: uid(x)) @cascade { ...auth query... }
+//
+// with the condition gt(len(Employee_4), 0). A field is dropped if no node can satisfy its rule.
+// It returns obj without those fields, along with the fragments.
+func rewriteFieldAuth(
+	ctx context.Context,
+	typ schema.Type,
+	authRw *authRewriter,
+	srcVar string,
+	obj map[string]interface{},
+	mutationType MutationType,
+	xidMetadata *xidMetadata,
+	idExistence map[string]string) (map[string]interface{}, []*mutationFragment, []error) {
+
+	rules := typ.AuthRules()
+	if rules == nil || len(rules.Fields) == 0 {
+		return obj, nil, nil
+	}
+	var fields []string
+	for fld := range obj {
+		if fldRules := rules.Fields[fld]; fldRules != nil && fldRules.Update != nil {
+			fields = append(fields, fld)
+		}
+	}
+	if len(fields) == 0 {
+		return obj, nil, nil
+	}
+	sort.Strings(fields)
+
+	rest := make(map[string]interface{}, len(obj))
+	for fld, val := range obj {
+		rest[fld] = val
+	}
+	var frags []*mutationFragment
+	for _, fld := range fields {
+		rn := rules.Fields[fld].Update
+		switch rn.EvaluateStatic(authRw.authVariables) {
+		case schema.Positive:
+			continue
+		case schema.Negative:
+			delete(rest, fld)
+			continue
+		}
+		delete(rest, fld)
+
+		fieldVar := authRw.varGen.Next(typ, "", "", false)
+		ruleQueries, filter := (&authRewriter{
+			authVariables: authRw.authVariables,
+			varGen:        authRw.varGen,
+			isWritingAuth: true,
+			varName:       srcVar,
+			selector:      func(t schema.Type) *schema.RuleNode { return rn },
+		}).rewriteRuleNode(typ, rn)
+		if filter == nil {
+			continue
+		}
+
+		frag, _, errs := rewriteObject(ctx, typ, nil, fmt.Sprintf("uid(%s)", fieldVar),
+			authRw.varGen, map[string]interface{}{fld: obj[fld]}, xidMetadata, idExistence,
+			mutationType)
+		if len(errs) > 0 {
+			return nil, nil, errs
+		}
+		frag.queries = append(frag.queries, &gql.GraphQuery{
+			Var:  fieldVar,
+			Attr: "var",
+			Func: &gql.Function{
+				Name: "uid",
+				Args: []gql.Arg{{Value: srcVar}},
+			},
+			Filter: filter,
+		})
+		frag.queries = append(frag.queries, ruleQueries...)
+		frag.conditions = append(frag.conditions, fmt.Sprintf("gt(len(%s), 0)", fieldVar))
+		frags = append(frags, frag)
+	}
+	return rest, frags, nil
+}
+
+// conflictVar returns the variable of the query finding the node with the same conflict keys
+// as the input object at idx, or "" if the mutation isn't an upsert mutation.
+func (arw *AddRewriter) conflictVar(idx int) string {
+	if idx < len(arw.conflictVars) {
+		return arw.conflictVars[idx]
+	}
+	return ""
+}
+
+// conflictQuery builds the query finding the node of typ with the same values as obj for the
+// conflict keys of an upsert mutation. Eg. for the keys [name, dob] it would be:
+//
+//	Author_1(func: eq(Author.name, "A.N. Author")) @filter(eq(Author.dob, "2000-01-01")) {
+//	  uid
+//	  dgraph.type
+//	}
+func conflictQuery(
+	typ schema.Type,
+	varGen *VariableGenerator,
+	obj map[string]interface{},
+	keys []interface{}) (*gql.GraphQuery, string, error) {
+
+	if len(keys) == 0 {
+		return nil, "", errors.Errorf("at least one conflict key must be given")
+	}
+	variable := varGen.Next(typ, "", "", false)
+	query := &gql.GraphQuery{
+		Attr:     variable,
+		Children: []*gql.GraphQuery{{Attr: "uid"}, {Attr: "dgraph.type"}},
+	}
+	for _, key := range keys {
+		fld, _ := key.(string)
+		val, ok := obj[fld]
+		if !ok || val == nil {
+			return nil, "", errors.Errorf("conflict key %s must be given in the input", fld)
+		}
+		fn := &gql.Function{
+			Name: "eq",
+			Args: []gql.Arg{
+				{Value: typ.DgraphPredicate(fld)},
+				{Value: schema.MaybeQuoteArg("eq", val)},
+			},
+		}
+		if query.Func == nil {
+			query.Func = fn
+		} else {
+			addToFilterTree(query, &gql.FilterTree{Func: fn})
+		}
+	}
+	return query, variable, nil
+}
+
+// conflictValues returns the values of obj for the conflict keys of an upsert mutation, which are
+// the same for the input objects merged into the same node. Eg. name: "A.N. Author".
+func conflictValues(obj map[string]interface{}, keys []interface{}) string {
+	vals := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fld, _ := key.(string)
+		vals = append(vals, fmt.Sprintf("%s: %s", fld, schema.MaybeQuoteArg("eq", obj[fld])))
+	}
+	return strings.Join(vals, ", ")
+}
+
+// rewriteConflict rewrites obj of an upsert mutation, whose conflict keys match the existing
+// node in variable, into a merge with that node. The fields listed in the update of onConflict
+// (all of them if it's not given) and the edges are written to the node. The other scalar
+// fields are only written if the node has no value for them, using a conditional mutation each,
+// which is returned along with the fragment for the node. The conflict keys and the @id fields
+// of the node aren't changed. The fields with update rules of their own are only written if the
+// node satisfies them, see rewriteFieldAuth.
+func rewriteConflict(
+	ctx context.Context,
+	typ schema.Type,
+	authRw *authRewriter,
+	variable string,
+	varGen *VariableGenerator,
+	obj map[string]interface{},
+	onConflict map[string]interface{},
+	xidMetadata *xidMetadata,
+	idExistence map[string]string) (*mutationFragment, []*mutationFragment, []error) {
+
+	unchanged := make(map[string]bool)
+	for _, key := range onConflict["keys"].([]interface{}) {
+		unchanged[key.(string)] = true
+	}
+	for _, xid := range typ.XIDFields() {
+		unchanged[xid.Name()] = true
+	}
+	update, updateAll := make(map[string]bool), true
+	if fields, ok := onConflict["update"].([]interface{}); ok {
+		updateAll = false
+		for _, fld := range fields {
+			update[fld.(string)] = true
+		}
+	}
+
+	setObj := make(map[string]interface{}, len(obj))
+	preserved := make(map[string]interface{})
+	for fld, val := range obj {
+		switch {
+		case unchanged[fld]:
+		case updateAll || update[fld] || !typ.Field(fld).Type().IsInbuiltOrEnumType():
+			setObj[fld] = val
+		case val != nil:
+			preserved[fld] = val
+		}
+	}
+
+	setObj, frags, errs := rewriteFieldAuth(ctx, typ, authRw, variable, setObj, UpdateWithSet,
+		xidMetadata, idExistence)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	srcUID := fmt.Sprintf("uid(%s)", variable)
+	fragment, _, errs := rewriteObject(ctx, typ, nil, srcUID, varGen, setObj, xidMetadata,
+		idExistence, UpdateWithSet)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	if len(setObj) == 0 {
+		// Nothing is overwritten, but the node still needs a mutation, so that it's part of the
+		// result. Its types are set again for that.
+		dgraphTypes := append([]string{typ.DgraphName()}, typ.Interfaces()...)
+		fragment.fragment.(map[string]interface{})["dgraph.type"] = dgraphTypes
+	}
+
+	// Each preserved field is written by a mutation which only runs if the node has no value
+	// for it. Eg. for the field dob:
+	// Author_5 as var(func: uid(Author_1)) @filter(NOT has(Author.dob))
+	// with the condition gt(len(Author_5), 0).
+	fields := make([]string, 0, len(preserved))
+	for fld := range preserved {
+		fields = append(fields, fld)
+	}
+	sort.Strings(fields)
+	for _, fld := range fields {
+		fieldVar := varGen.Next(typ, "", "", false)
+		query := &gql.GraphQuery{
+			Attr: "var",
+			Var:  fieldVar,
+			Func: &gql.Function{Name: "uid", Args: []gql.Arg{{Value: variable}}},
+			Filter: &gql.FilterTree{
+				Op: "not",
+				Child: []*gql.FilterTree{{Func: &gql.Function{
+					Name: "has",
+					Args: []gql.Arg{{Value: typ.DgraphPredicate(fld)}},
+				}}},
+			},
+		}
+		fieldObj, ruleFrags, errs := rewriteFieldAuth(ctx, typ, authRw, fieldVar,
+			map[string]interface{}{fld: preserved[fld]}, UpdateWithSet, xidMetadata, idExistence)
+		if len(errs) > 0 {
+			return nil, nil, errs
+		}
+		if len(fieldObj) == 0 {
+			// The field is written by the fragment of its update rule, if any.
+			if len(ruleFrags) > 0 {
+				ruleFrags[0].queries = append([]*gql.GraphQuery{query}, ruleFrags[0].queries...)
+				frags = append(frags, ruleFrags...)
+			}
+			continue
+		}
+		frag, _, errs := rewriteObject(ctx, typ, nil, fmt.Sprintf("uid(%s)", fieldVar), varGen,
+			fieldObj, xidMetadata, idExistence, UpdateWithSet)
+		if len(errs) > 0 {
+			return nil, nil, errs
+		}
+		frag.queries = append(frag.queries, query)
+		frag.conditions = append(frag.conditions, fmt.Sprintf("gt(len(%s), 0)", fieldVar))
+		frags = append(frags, frag)
+	}
+	return fragment, frags, nil
+}
+
 // FromMutationResult rewrites the query part of a GraphQL add mutation into a Dgraph query.
 func (arw *AddRewriter) FromMutationResult(
 	ctx context.Context,
@@ -788,10 +1168,10 @@ func (arw *AddRewriter) FromMutationResult(
 	// Find out if its an upsert with Add mutation.
 	// In this case, it may happen that no new node is created, but there may still
 	// be some updated nodes. We don't throw an error in this case.
-	upsert := false
+	upsert := mutation.ArgValue(schema.OnConflictArgName) != nil
 	upsertVal := mutation.ArgValue(schema.UpsertArgName)
 	if upsertVal != nil {
-		upsert = upsertVal.(bool)
+		upsert = upsert || upsertVal.(bool)
 	}
 
 	// This error is only relevant in case this is not an Upsert with Add Mutation.
@@ -999,6 +1379,7 @@ func RewriteUpsertQueryFromMutation(
 	// If it is set to empty, this is either a delete or update mutation.
 	// In that case, we extract the IDs on which to apply this mutation using
 	// extractMutationFilter.
+	var varQueries []*gql.GraphQuery
 	if nodeID == "" {
 		filter := extractMutationFilter(m)
 		if ids := idFilter(filter, m.MutatedType().IDField()); ids != nil {
@@ -1007,6 +1388,8 @@ func RewriteUpsertQueryFromMutation(
 			addTypeFunc(dgQuery[0], m.MutatedType().DgraphName())
 		}
 
+		filter, varQueries = rewriteAggregateFilters(m.MutatedType(), filter, authRw,
+			typeScope(m.MutatedType()))
 		_ = addFilter(dgQuery[0], m.MutatedType(), filter)
 	} else {
 		// It means this is called from upsert with Add mutation.
@@ -1024,6 +1407,7 @@ func RewriteUpsertQueryFromMutation(
 		addTypeFilter(dgQuery[0], m.MutatedType())
 	}
 	dgQuery = authRw.addAuthQueries(m.MutatedType(), dgQuery, rbac)
+	dgQuery = append(dgQuery, varQueries...)
 
 	return dgQuery
 }
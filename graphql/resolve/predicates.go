@@ -0,0 +1,78 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolve
+
+import (
+	"strings"
+
+	"github.com/dgraph-io/dgraph/graphql/schema"
+)
+
+// ReadPredicates returns the Dgraph predicates which the operation can read. All the
+// predicates of the types in the selection sets are taken, as the filters and the order can use
+// any of them, along with dgraph.type. It returns nil if the predicates can't be known: DQL
+// queries and auth rules can read any predicate.
+func ReadPredicates(op schema.Operation) map[string]struct{} {
+	preds := map[string]struct{}{"dgraph.type": {}}
+	seen := make(map[string]bool)
+
+	var addType func(typ schema.Type) bool
+	addType = func(typ schema.Type) bool {
+		if typ == nil || typ.IsInbuiltOrEnumType() || seen[typ.Name()] {
+			return true
+		}
+		seen[typ.Name()] = true
+		if rules := typ.AuthRules(); rules != nil && (rules.Rules != nil || len(rules.Fields) > 0) {
+			return false
+		}
+		for _, fd := range typ.Fields() {
+			if pred := strings.TrimPrefix(fd.DgraphPredicate(), "~"); pred != "" {
+				preds[pred] = struct{}{}
+			}
+		}
+		members := typ.ImplementingTypes()
+		if typ.IsUnion() {
+			members = typ.UnionMembers(nil)
+		}
+		for _, member := range members {
+			if !addType(member) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var addField func(f schema.Field) bool
+	addField = func(f schema.Field) bool {
+		if !addType(f.ConstructedFor()) {
+			return false
+		}
+		for _, child := range f.SelectionSet() {
+			if !addField(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, q := range op.Queries() {
+		if q.QueryType() == schema.DQLQuery || !addField(q) {
+			return nil
+		}
+	}
+	return preds
+}
@@ -50,6 +50,9 @@ type authRewriter struct {
 	hasAuthRules bool
 	// `hasCascade` indicates if any of fields in the complete query hierarchy has cascade directive.
 	hasCascade bool
+	// `filterScope` is the scope of the nodes at the current level of the query hierarchy. The
+	// aggregate filters and orders of their fields only compute the aggregates of their edges.
+	filterScope varScope
 }
 
 // The struct is used as a return type for buildCommonAuthQueries function.
@@ -170,6 +173,8 @@ func (qr *queryRewriter) Rewrite(
 		return passwordQuery(gqlQuery, authRw)
 	case schema.AggregateQuery:
 		return aggregateQuery(gqlQuery, authRw), nil
+	case schema.ConnectionQuery:
+		return connectionQuery(gqlQuery, authRw)
 	case schema.EntitiesQuery:
 		return entitiesQuery(gqlQuery, authRw)
 	case schema.DQLQuery:
@@ -267,9 +272,12 @@ func aggregateQuery(query schema.Query, authRw *authRewriter) []*gql.GraphQuery
 
 	// Add filter
 	filter, _ := query.ArgValue("filter").(map[string]interface{})
+	filter, varQueries := rewriteAggregateFilters(mainType, filter, authRw,
+		typeScope(mainType))
 	_ = addFilter(dgQuery[0], mainType, filter)
 
 	dgQuery = authRw.addAuthQueries(mainType, dgQuery, rbac)
+	dgQuery = append(dgQuery, varQueries...)
 
 	// mainQuery is the query with Attr: query.Name()
 	// It is the first query in dgQuery list.
@@ -485,7 +493,7 @@ func rewriteAsQueryByIds(
 		addUIDFunc(dgQuery[0], intersection(ids, uids))
 	}
 
-	addArgumentsToField(dgQuery[0], field)
+	varQueries := addArgumentsToField(dgQuery[0], field, authRw)
 
 	// The function getQueryByIds is called for passwordQuery or fetching query result types
 	// after making a mutation. In both cases, we want the selectionSet to use the `query` auth
@@ -501,6 +509,7 @@ func rewriteAsQueryByIds(
 	addCascadeDirective(dgQuery[0], field)
 
 	dgQuery = authRw.addAuthQueries(field.Type(), dgQuery, rbac)
+	dgQuery = append(dgQuery, varQueries...)
 
 	if len(selectionAuth) > 0 {
 		dgQuery = append(dgQuery, selectionAuth...)
@@ -510,12 +519,18 @@ func rewriteAsQueryByIds(
 }
 
 // addArgumentsToField adds various different arguments to a field, such as
-// filter, order and pagination.
-func addArgumentsToField(dgQuery *gql.GraphQuery, field schema.Field) {
+// filter, order and pagination. It returns the var blocks needed by the filter and the order.
+func addArgumentsToField(
+	dgQuery *gql.GraphQuery,
+	field schema.Field,
+	authRw *authRewriter) []*gql.GraphQuery {
 	filter, _ := field.ArgValue("filter").(map[string]interface{})
+	scope := typeScope(field.Type())
+	filter, varQueries := rewriteAggregateFilters(field.Type(), filter, authRw, scope)
 	_ = addFilter(dgQuery, field.Type(), filter)
-	addOrder(dgQuery, field)
+	varQueries = append(varQueries, addOrder(dgQuery, field, authRw, scope)...)
 	addPagination(dgQuery, field)
+	return varQueries
 }
 
 func addTopLevelTypeFilter(query *gql.GraphQuery, field schema.Field) {
@@ -851,7 +866,7 @@ func rewriteAsQuery(field schema.Field, authRw *authRewriter) []*gql.GraphQuery
 		return dgQuery
 	}
 
-	addArgumentsToField(dgQuery[0], field)
+	varQueries := addArgumentsToField(dgQuery[0], field, authRw)
 	selectionAuth := addSelectionSetFrom(dgQuery[0], field, authRw)
 	// we don't need to query uid for auth queries, as they always have at least one field in their
 	// selection set.
@@ -861,6 +876,7 @@ func rewriteAsQuery(field schema.Field, authRw *authRewriter) []*gql.GraphQuery
 	addCascadeDirective(dgQuery[0], field)
 
 	dgQuery = authRw.addAuthQueries(field.Type(), dgQuery, rbac)
+	dgQuery = append(dgQuery, varQueries...)
 
 	if len(selectionAuth) > 0 {
 		return append(dgQuery, selectionAuth...)
@@ -870,9 +886,65 @@ func rewriteAsQuery(field schema.Field, authRw *authRewriter) []*gql.GraphQuery
 	return dgQuery
 }
 
+// connectionQuery rewrites a Relay connection query into a query for the nodes in the page it
+// asks for. The node of the edges gives the selection set. The page starts after the DQL cursor
+// it was given, which the GraphQL encoder builds from the sort keys of the nodes. One more node
+// than the page has is queried, so that the encoder can tell whether there is a next page.
+func connectionQuery(query schema.Query, authRw *authRewriter) ([]*gql.GraphQuery, error) {
+	after, first, err := query.ConnectionArgs()
+	if err != nil {
+		return nil, err
+	}
+	node, err := query.ConnectionNode()
+	if err != nil {
+		return nil, err
+	}
+
+	mainType := query.ConstructedFor()
+	dgQuery, rbac := addCommonRules(query, mainType, authRw)
+	if rbac == schema.Negative {
+		return dgQuery, nil
+	}
+
+	filter, _ := query.ArgValue("filter").(map[string]interface{})
+	scope := typeScope(mainType)
+	filter, varQueries := rewriteAggregateFilters(mainType, filter, authRw, scope)
+	_ = addFilter(dgQuery[0], mainType, filter)
+	varQueries = append(varQueries, addOrderForType(dgQuery[0], mainType,
+		query.ArgValue("order"), authRw, scope)...)
+	dgQuery[0].Args = make(map[string]string)
+	if first >= 0 {
+		dgQuery[0].Args["first"] = strconv.FormatInt(first+1, 10)
+	}
+	if after != "" {
+		dgQuery[0].Args["cursor"] = after
+	}
+
+	var selectionAuth []*gql.GraphQuery
+	if node != nil {
+		selectionAuth = addSelectionSetFrom(dgQuery[0], node, authRw)
+	}
+	// The nodes are needed for the cursors and the page info, even if none of their fields are.
+	if len(dgQuery[0].Children) == 0 {
+		dgQuery[0].Children = append(dgQuery[0].Children,
+			&gql.GraphQuery{Attr: "uid", Alias: "dgraph.uid"})
+	}
+	addUID(dgQuery[0])
+	addCascadeDirective(dgQuery[0], query)
+
+	dgQuery = authRw.addAuthQueries(mainType, dgQuery, rbac)
+	dgQuery = append(dgQuery, varQueries...)
+
+	if len(selectionAuth) > 0 {
+		return append(dgQuery, selectionAuth...), nil
+	}
+	return rootQueryOptimization(dgQuery), nil
+}
+
 func rootQueryOptimization(dgQuery []*gql.GraphQuery) []*gql.GraphQuery {
 	if dgQuery[0].Filter != nil && dgQuery[0].Filter.Func != nil &&
-		dgQuery[0].Filter.Func.Name == "eq" && dgQuery[0].Func.Name == "type" {
+		dgQuery[0].Filter.Func.Name == "eq" && dgQuery[0].Func.Name == "type" &&
+		!isValueVarComparison(dgQuery[0].Filter.Func) {
 		rootFunc := dgQuery[0].Func
 		dgQuery[0].Func = dgQuery[0].Filter.Func
 		dgQuery[0].Filter.Func = rootFunc
@@ -880,6 +952,12 @@ func rootQueryOptimization(dgQuery []*gql.GraphQuery) []*gql.GraphQuery {
 	return dgQuery
 }
 
+// isValueVarComparison returns true if fn compares a value var, like eq(val(Author_1_count), 5)
+// does. Those can't be used at root.
+func isValueVarComparison(fn *gql.Function) bool {
+	return len(fn.Args) > 0 && strings.HasPrefix(fn.Args[0].Value, "val(")
+}
+
 func (authRw *authRewriter) writingAuth() bool {
 	return authRw != nil && authRw.isWritingAuth
 
@@ -1138,6 +1216,123 @@ func (authRw *authRewriter) rewriteAuthQueries(typ schema.Type) ([]*gql.GraphQue
 	}).rewriteRuleNode(typ, authRw.selector(typ))
 }
 
+// fieldAuthRules returns the auth rules that the field f has of its own, if any.
+func fieldAuthRules(f schema.Field) *schema.AuthContainer {
+	typ := f.Operation().Schema().Type(f.GetObjectName())
+	if typ == nil || typ.AuthRules() == nil {
+		return nil
+	}
+	return typ.AuthRules().Fields[f.Name()]
+}
+
+// fieldQueryRule returns the query rule that the field fld of typ has of its own, if any.
+func fieldQueryRule(typ schema.Type, fld string) *schema.RuleNode {
+	if typ == nil || typ.AuthRules() == nil || typ.AuthRules().Fields[fld] == nil {
+		return nil
+	}
+	return typ.AuthRules().Fields[fld].Query
+}
+
+// fieldRuleNodes returns the var of the nodes of scope which satisfy rn, the query rule that a
+// field of typ has of its own, along with the blocks computing it. eg. for a field salary of
+// type Employee:
+//
+//	var(func: type(Employee)) {
+//	  Employee_1 as uid
+//	}
+//	Employee_2 as var(func: uid(Employee_1)) @filter(uid(Employee_Auth3))
+//	Employee_Auth3 as var(func: uid(Employee_1)) @cascade { ...auth query... }
+//
+// The var is set by var(func: uid()), so that it's empty, if no node can satisfy rn.
+func (authRw *authRewriter) fieldRuleNodes(
+	typ schema.Type,
+	rn *schema.RuleNode,
+	scope varScope) (string, []*gql.GraphQuery) {
+
+	if rn.EvaluateStatic(authRw.authVariables) != schema.Negative {
+		nodesVar := authRw.varGen.Next(typ, "", "", false)
+		rulesVar := authRw.varGen.Next(typ, "", "", false)
+		ruleQueries, filter := (&authRewriter{
+			authVariables: authRw.authVariables,
+			varGen:        authRw.varGen,
+			isWritingAuth: true,
+			varName:       nodesVar,
+			selector:      func(t schema.Type) *schema.RuleNode { return rn },
+		}).rewriteRuleNode(typ, rn)
+		if filter != nil {
+			return rulesVar, append([]*gql.GraphQuery{
+				scope([]*gql.GraphQuery{{Var: nodesVar, Attr: "uid"}}),
+				{
+					Var:  rulesVar,
+					Attr: "var",
+					Func: &gql.Function{
+						Name: "uid",
+						Args: []gql.Arg{{Value: nodesVar}},
+					},
+					Filter: filter,
+				},
+			}, ruleQueries...)
+		}
+	}
+
+	rulesVar := authRw.varGen.Next(typ, "", "", false)
+	return rulesVar, []*gql.GraphQuery{{
+		Var:  rulesVar,
+		Attr: "var",
+		Func: &gql.Function{Name: "uid"},
+	}}
+}
+
+// fieldRuleValues returns the value var holding the values of pred for the nodes of scope which
+// satisfy rn, the query rule that the field of typ has of its own, along with the blocks computing
+// it. eg. for a field salary of type Employee, along with the blocks of fieldRuleNodes:
+//
+//	var(func: uid(Employee_2)) {
+//	  Employee_4 as Employee.salary
+//	}
+func (authRw *authRewriter) fieldRuleValues(
+	typ schema.Type,
+	pred string,
+	rn *schema.RuleNode,
+	scope varScope) (string, []*gql.GraphQuery) {
+
+	rulesVar, queries := authRw.fieldRuleNodes(typ, rn, scope)
+	valueVar := authRw.varGen.Next(typ, "", "", false)
+	return valueVar, append(queries, &gql.GraphQuery{
+		Attr: "var",
+		Func: &gql.Function{
+			Name: "uid",
+			Args: []gql.Arg{{Value: rulesVar}},
+		},
+		Children: []*gql.GraphQuery{{Var: valueVar, Attr: pred}},
+	})
+}
+
+// rewriteFieldAuth rewrites child, the query of the field f which has the query rule rn of its
+// own, so that it only has a value for the nodes of scope which satisfy rn. The value is taken
+// from the value var of fieldRuleValues, and the field is queried as eg.
+// Employee.salary : val(Employee_4). It returns false if no node can satisfy rn, in which case
+// the field mustn't be queried at all.
+func (authRw *authRewriter) rewriteFieldAuth(
+	child *gql.GraphQuery,
+	f schema.Field,
+	rn *schema.RuleNode,
+	scope varScope) (bool, []*gql.GraphQuery) {
+
+	switch rn.EvaluateStatic(authRw.authVariables) {
+	case schema.Positive:
+		return true, nil
+	case schema.Negative:
+		return false, nil
+	}
+
+	typ := f.Operation().Schema().Type(f.GetObjectName())
+	valueVar, queries := authRw.fieldRuleValues(typ, child.Attr, rn, scope)
+	child.Attr = "val"
+	child.NeedsVar = []gql.VarContext{{Name: valueVar, Typ: gql.ValueVar}}
+	return true, queries
+}
+
 func (authRw *authRewriter) evaluateStaticRules(typ schema.Type) schema.RuleResult {
 	if authRw == nil || authRw.isWritingAuth {
 		return schema.Uncertain
@@ -1228,7 +1423,8 @@ func (authRw *authRewriter) rewriteRuleNode(
 			r1[0].Cascade = append(r1[0].Cascade, "__all__")
 		}
 
-		return []*gql.GraphQuery{r1[0]}, &gql.FilterTree{
+		// The rest of r1 are the var blocks needed by its filter and order, if any.
+		return r1, &gql.FilterTree{
 			Func: &gql.Function{
 				Name: "uid",
 				Args: []gql.Arg{{Value: varName}},
@@ -1334,10 +1530,11 @@ func buildCommonAuthQueries(
 
 // buildAggregateFields builds DQL queries for aggregate fields like count, avg, max etc.
 // It returns related DQL fields and Auth Queries which are then added to the final DQL query
-// by the caller.
+// by the caller. scope is the scope of the nodes which have the aggregate field.
 func buildAggregateFields(
 	f schema.Field,
-	auth *authRewriter) ([]*gql.GraphQuery, []*gql.GraphQuery) {
+	auth *authRewriter,
+	scope varScope) ([]*gql.GraphQuery, []*gql.GraphQuery) {
 	constructedForType := f.ConstructedFor()
 	constructedForDgraphPredicate := f.ConstructedForDgraphPredicate()
 
@@ -1365,6 +1562,8 @@ func buildAggregateFields(
 	// Filter for aggregate Fields. This is added to all count aggregate fields
 	// and mainField
 	fieldFilter, _ := f.ArgValue("filter").(map[string]interface{})
+	fieldFilter, varQueries := rewriteAggregateFilters(constructedForType, fieldFilter,
+		auth, edgeScope(scope, constructedForDgraphPredicate))
 	_ = addFilter(mainField, constructedForType, fieldFilter)
 
 	// Add type filter in case the Dgraph predicate for which the aggregate
@@ -1496,6 +1695,7 @@ func buildAggregateFields(
 	// not added to them.
 	aggregateChildren = append(aggregateChildren, otherAggregateChildren...)
 	retAuthQueries = append(retAuthQueries, fieldAuth...)
+	retAuthQueries = append(retAuthQueries, varQueries...)
 	return aggregateChildren, retAuthQueries
 }
 
@@ -1551,6 +1751,14 @@ func addSelectionSetFrom(
 	// It tells whether a field with that dgraph alias has been added to DQL query or not.
 	fieldAdded := make(map[string]bool)
 
+	// The var blocks of the aggregate filters and orders of the fields only compute the
+	// aggregates of the edges of the nodes of q.
+	parentScope := auth.filterScope
+	scope := parentScope
+	if scope == nil {
+		scope = rootScope(q, field.Type())
+	}
+
 	for _, f := range field.SelectionSet() {
 		if f.IsCustomHTTP() {
 			for dgAlias, fieldDef := range f.CustomRequiredFields() {
@@ -1569,7 +1777,7 @@ func addSelectionSetFrom(
 
 		// Handle aggregation queries
 		if f.IsAggregateField() {
-			aggregateChildren, aggregateAuthQueries := buildAggregateFields(f, auth)
+			aggregateChildren, aggregateAuthQueries := buildAggregateFields(f, auth, scope)
 
 			authQueries = append(authQueries, aggregateAuthQueries...)
 			q.Children = append(q.Children, aggregateChildren...)
@@ -1589,8 +1797,25 @@ func addSelectionSetFrom(
 		} else {
 			child.Attr = f.DgraphPredicate()
 		}
+		// name(lang: "en") -> Author.name@en
+		if lang, _ := f.ArgValue("lang").(string); lang != "" {
+			child.Attr += "@" + lang
+		}
+
+		// A field with a query rule of its own only has a value for the nodes which satisfy it.
+		if rules := fieldAuthRules(f); rules != nil && rules.Query != nil && auth != nil &&
+			!auth.isWritingAuth {
+			fieldAdded[f.DgraphAlias()] = true
+			include, fieldAuthQueries := auth.rewriteFieldAuth(child, f, rules.Query, scope)
+			if !include {
+				continue
+			}
+			authQueries = append(authQueries, fieldAuthQueries...)
+		}
 
 		filter, _ := f.ArgValue("filter").(map[string]interface{})
+		childScope := edgeScope(scope, f.DgraphPredicate())
+		filter, varQueries := rewriteAggregateFilters(f.Type(), filter, auth, childScope)
 		// if this field has been filtered out by the filter, then don't add it in DQL query
 		if includeField := addFilter(child, f.Type(), filter); !includeField {
 			continue
@@ -1601,7 +1826,7 @@ func addSelectionSetFrom(
 			addTypeFilter(child, f.Type())
 		}
 
-		addOrder(child, f)
+		varQueries = append(varQueries, addOrder(child, f, auth, childScope)...)
 		addPagination(child, f)
 		addCascadeDirective(child, f)
 		rbac := auth.evaluateStaticRules(f.Type())
@@ -1618,7 +1843,9 @@ func addSelectionSetFrom(
 
 		var selectionAuth []*gql.GraphQuery
 		if !f.Type().IsGeo() {
+			auth.filterScope = childScope
 			selectionAuth = addSelectionSetFrom(child, f, auth)
+			auth.filterScope = parentScope
 		}
 
 		restoreAuthState := func() {
@@ -1685,6 +1912,7 @@ func addSelectionSetFrom(
 		}
 		authQueries = append(authQueries, selectionAuth...)
 		authQueries = append(authQueries, fieldAuth...)
+		authQueries = append(authQueries, varQueries...)
 		restoreAuthState()
 	}
 
@@ -1885,24 +2113,79 @@ func addAuthQueriesOnSelectionSet(
 	return authQueries
 }
 
-func addOrder(q *gql.GraphQuery, field schema.Field) {
-	orderArg := field.ArgValue("order")
+func addOrder(q *gql.GraphQuery, field schema.Field, auth *authRewriter,
+	scope varScope) []*gql.GraphQuery {
+	return addOrderForType(q, field.Type(), field.ArgValue("order"), auth, scope)
+}
+
+// addOrderForType adds the order given by orderArg over the fields of typ to q. Ordering by the
+// count of the values of a field, like postsCount, orders by a value var, which is computed for
+// the nodes of scope by the var block it returns. Dgraph only orders by one value var, so such an
+// order can't be combined with others, which the validation of the order argument ensures.
+//
+// Ordering by a field with a query rule of its own also orders by a value var, which is only set
+// for the nodes satisfying the rule, unless the user satisfies it whatever the node. The nodes
+// which don't satisfy it come last, as if they had no value. As the rule depends on the user,
+// the validation can't prevent combining such an order with others: it's only applied if it comes
+// first, and the order stops at it otherwise.
+func addOrderForType(
+	q *gql.GraphQuery,
+	typ schema.Type,
+	orderArg interface{},
+	auth *authRewriter,
+	scope varScope) []*gql.GraphQuery {
 	order, ok := orderArg.(map[string]interface{})
 	for ok {
 		ascArg := order["asc"]
 		descArg := order["desc"]
 		thenArg := order["then"]
 
+		fld, isDesc := "", false
 		if asc, ok := ascArg.(string); ok {
-			q.Order = append(q.Order,
-				&pb.Order{Attr: field.Type().DgraphPredicate(asc)})
+			fld = asc
 		} else if desc, ok := descArg.(string); ok {
-			q.Order = append(q.Order,
-				&pb.Order{Attr: field.Type().DgraphPredicate(desc), Desc: true})
+			fld, isDesc = desc, true
+		}
+
+		rn := fieldQueryRule(typ, fld)
+		if rn != nil && !auth.isWritingAuth &&
+			rn.EvaluateStatic(auth.authVariables) != schema.Positive {
+			if len(q.Order) > 0 {
+				return nil
+			}
+			// salary ->
+			// var(func: uid(Employee_2)) {
+			//   Employee_4 as Employee.salary
+			// }
+			// and orderdesc: val(Employee_4), with the blocks of fieldRuleNodes.
+			varName, varQueries := auth.fieldRuleValues(typ, typ.DgraphPredicate(fld), rn, scope)
+			q.Order = append(q.Order, &pb.Order{Attr: varName, Desc: isDesc})
+			q.NeedsVar = append(q.NeedsVar, gql.VarContext{Name: varName, Typ: gql.ValueVar})
+			return varQueries
+		}
+
+		if pred := typ.DgraphPredicate(fld); pred != "" {
+			q.Order = append(q.Order, &pb.Order{Attr: pred, Desc: isDesc})
+		} else if countField := strings.TrimSuffix(fld, "Count"); countField != fld &&
+			len(q.Order) == 0 {
+			// postsCount ->
+			// var(func: type(Author)) {
+			//   Author_1 as count(Author.posts)
+			// }
+			// and orderdesc: val(Author_1)
+			varName := auth.varGen.Next(typ, "", "", false)
+			varQuery := scope([]*gql.GraphQuery{{
+				Var:  varName,
+				Attr: "count(" + typ.DgraphPredicate(countField) + ")",
+			}})
+			q.Order = append(q.Order, &pb.Order{Attr: varName, Desc: isDesc})
+			q.NeedsVar = append(q.NeedsVar, gql.VarContext{Name: varName, Typ: gql.ValueVar})
+			return []*gql.GraphQuery{varQuery}
 		}
 
 		order, ok = thenArg.(map[string]interface{})
 	}
+	return nil
 }
 
 func addPagination(q *gql.GraphQuery, field schema.Field) {
@@ -1995,6 +2278,282 @@ func addFilter(q *gql.GraphQuery, typ schema.Type, filter map[string]interface{}
 	return true
 }
 
+// rewriteAggregateFilters rewrites the filters over the aggregates of the edges of typ, like
+// postsAggregate: { count: { gt: 5 } }, into comparisons of value vars. The value vars are
+// computed for the nodes of scope by var blocks, which are returned along with the filter to
+// build instead of the given one. eg: the Authors with more than 5 posts are filtered by
+// gt(val(Author_1_count), 5) with
+//
+//	var(func: type(Author)) {
+//	  Author_1_count as count(Author.posts)
+//	}
+//
+// A filter on a field with a query rule of its own only matches the nodes satisfying the rule,
+// unless the user satisfies it whatever the node, as if the others had no value. eg: the
+// Employees with a salary over 1000 are filtered by
+// (gt(Employee.salary, 1000) AND uid(Employee_2)) with the blocks of fieldRuleNodes.
+//
+// The given filter isn't changed, it's copied if anything has to be rewritten.
+func rewriteAggregateFilters(
+	typ schema.Type,
+	filter map[string]interface{},
+	auth *authRewriter,
+	scope varScope) (map[string]interface{}, []*gql.GraphQuery) {
+	if len(filter) == 0 || typ.IsUnion() {
+		return filter, nil
+	}
+
+	// Get a stable ordering so that the same vars are generated each time.
+	var keys []string
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var varQueries []*gql.GraphQuery
+	rewritten := make(map[string]interface{}, len(filter))
+	for _, key := range keys {
+		rewritten[key] = filter[key]
+		var queries []*gql.GraphQuery
+		if rn := fieldQueryRule(typ, key); rn != nil && !auth.isWritingAuth &&
+			rn.EvaluateStatic(auth.authVariables) != schema.Positive {
+			ft := buildFilter(typ, map[string]interface{}{key: filter[key]})
+			if ft == nil {
+				continue
+			}
+			var rulesVar string
+			rulesVar, queries = auth.fieldRuleNodes(typ, rn, scope)
+			rewritten[key] = &gql.FilterTree{
+				Op: "and",
+				Child: []*gql.FilterTree{ft, {
+					Func: &gql.Function{
+						Name: "uid",
+						Args: []gql.Arg{{Value: rulesVar}},
+					},
+				}},
+			}
+			varQueries = append(varQueries, queries...)
+			continue
+		}
+		switch v := filter[key].(type) {
+		case map[string]interface{}:
+			switch {
+			case key == "and" || key == "or" || key == "not":
+				rewritten[key], queries = rewriteAggregateFilters(typ, v, auth, scope)
+			case strings.HasSuffix(key, "Aggregate") && typ.DgraphPredicate(key) == "":
+				fld := strings.TrimSuffix(key, "Aggregate")
+				if typ.DgraphPredicate(fld) == "" {
+					continue
+				}
+				var ft *gql.FilterTree
+				ft, queries = buildAggregateFilter(typ, typ.Field(fld), v, auth, scope)
+				if ft == nil {
+					delete(rewritten, key)
+				} else {
+					rewritten[key] = ft
+				}
+			}
+		case []interface{}:
+			if key != "and" && key != "or" {
+				continue
+			}
+			list := make([]interface{}, 0, len(v))
+			for _, obj := range v {
+				if m, ok := obj.(map[string]interface{}); ok {
+					var objQueries []*gql.GraphQuery
+					obj, objQueries = rewriteAggregateFilters(typ, m, auth, scope)
+					queries = append(queries, objQueries...)
+				}
+				list = append(list, obj)
+			}
+			rewritten[key] = list
+		}
+		varQueries = append(varQueries, queries...)
+	}
+
+	if len(varQueries) == 0 {
+		return filter, nil
+	}
+	return rewritten, varQueries
+}
+
+// buildAggregateFilter builds the filter for the aggregates over the edges of fd given by
+// aggFilter, eg: { filter: { ... }, count: { gt: 5 }, numLikesSum: { ge: 100 } }. It returns
+// the filter along with the var blocks computing the value vars it compares for the nodes of
+// scope.
+func buildAggregateFilter(
+	typ schema.Type,
+	fd schema.FieldDefinition,
+	aggFilter map[string]interface{},
+	auth *authRewriter,
+	scope varScope) (*gql.FilterTree, []*gql.GraphQuery) {
+	edgeType := fd.Type()
+	pred := fd.DgraphPredicate()
+	varPrefix := auth.varGen.Next(typ, "", "", false)
+
+	edgeFilter, _ := aggFilter["filter"].(map[string]interface{})
+	edgeFilter, varQueries := rewriteAggregateFilters(edgeType, edgeFilter, auth,
+		edgeScope(scope, pred))
+	addEdgeFilter := func(q *gql.GraphQuery) {
+		_ = addFilter(q, edgeType, edgeFilter)
+		// Add type filter in case the Dgraph predicate is a reverse edge
+		if strings.HasPrefix(pred, "~") {
+			addTypeFilter(q, edgeType)
+		}
+	}
+
+	// mainField has the vars for the values of the edges which are aggregated. eg:
+	// Author.posts @filter(...) {
+	//   Author_1_numLikesVar as Post.numLikes
+	// }
+	mainField := &gql.GraphQuery{Attr: pred}
+	addEdgeFilter(mainField)
+	isAggregateVarAdded := make(map[string]bool)
+
+	var aggregates []*gql.GraphQuery
+	var ands []*gql.FilterTree
+	var keys []string
+	for key := range aggFilter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cond, ok := aggFilter[key].(map[string]interface{})
+		if !ok || key == "filter" {
+			continue
+		}
+		varName := varPrefix + "_" + key
+		ft := buildValueVarFilter(varName, cond)
+		if ft == nil {
+			continue
+		}
+
+		if key == "count" {
+			// Author_1_count as count(Author.posts) @filter(...)
+			aggregate := &gql.GraphQuery{Var: varName, Attr: "count(" + pred + ")"}
+			addEdgeFilter(aggregate)
+			aggregates = append(aggregates, aggregate)
+			ands = append(ands, ft)
+			continue
+		}
+
+		// numLikesSum -> Author_1_numLikesSum as sum(val(Author_1_numLikesVar))
+		// All the aggregation functions have length 3.
+		if len(key) <= 3 {
+			continue
+		}
+		fldName, function := key[:len(key)-3], strings.ToLower(key[len(key)-3:])
+		fldVar := varPrefix + "_" + fldName + "Var"
+		if !isAggregateVarAdded[fldName] {
+			mainField.Children = append(mainField.Children,
+				&gql.GraphQuery{Var: fldVar, Attr: edgeType.DgraphPredicate(fldName)})
+			isAggregateVarAdded[fldName] = true
+		}
+		aggregates = append(aggregates,
+			&gql.GraphQuery{Var: varName, Attr: function + "(val(" + fldVar + "))"})
+		ands = append(ands, ft)
+	}
+
+	if len(ands) == 0 {
+		// Nothing uses the vars in the filter for the edges.
+		return nil, nil
+	}
+
+	var children []*gql.GraphQuery
+	if len(mainField.Children) > 0 {
+		children = append(children, mainField)
+	}
+	varQueries = append(varQueries, scope(append(children, aggregates...)))
+
+	if len(ands) == 1 {
+		return ands[0], varQueries
+	}
+	return &gql.FilterTree{Op: "and", Child: ands}, varQueries
+}
+
+// varScope builds the var block computing the value vars of the blocks in children for the nodes
+// of the scope, by wrapping children into the blocks which reach these nodes.
+type varScope func(children []*gql.GraphQuery) *gql.GraphQuery
+
+// typeScope is the scope of all the nodes of typ.
+func typeScope(typ schema.Type) varScope {
+	return func(children []*gql.GraphQuery) *gql.GraphQuery {
+		q := &gql.GraphQuery{Attr: "var", Children: children}
+		addTypeFunc(q, typ.DgraphName())
+		return q
+	}
+}
+
+// rootScope is the scope of the nodes of the root query q, which are of the type typ: the nodes
+// matched by its function, or all the nodes of typ if it doesn't have one.
+func rootScope(q *gql.GraphQuery, typ schema.Type) varScope {
+	if q.Func == nil {
+		return typeScope(typ)
+	}
+	fn := *q.Func
+	return func(children []*gql.GraphQuery) *gql.GraphQuery {
+		rootFn := fn
+		return &gql.GraphQuery{Attr: "var", Func: &rootFn, Children: children}
+	}
+}
+
+// edgeScope is the scope of the nodes which the nodes of the scope parent link to by pred. eg:
+//
+//	var(func: type(Author)) {
+//	  Author.posts {
+//	    ...
+//	  }
+//	}
+func edgeScope(parent varScope, pred string) varScope {
+	return func(children []*gql.GraphQuery) *gql.GraphQuery {
+		return parent([]*gql.GraphQuery{{Attr: pred, Children: children}})
+	}
+}
+
+// buildValueVarFilter builds the filter comparing the value var to the values given by the
+// IntFilter, FloatFilter etc. in cond, eg: { gt: 5 } -> gt(val(Author_1_count), 5).
+func buildValueVarFilter(varName string, cond map[string]interface{}) *gql.FilterTree {
+	compare := func(fn string, val interface{}) *gql.FilterTree {
+		return &gql.FilterTree{
+			Func: &gql.Function{
+				Name: fn,
+				Args: []gql.Arg{
+					{Value: "val(" + varName + ")"},
+					{Value: schema.MaybeQuoteArg(fn, val)},
+				},
+			},
+		}
+	}
+
+	fn, val := first(cond)
+	if val == nil {
+		return nil
+	}
+	switch fn {
+	case "in":
+		// Value vars can only be compared to one value at a time.
+		// in: [1, 2] -> eq(val(v), 1) OR eq(val(v), 2)
+		vals := val.([]interface{})
+		if len(vals) == 0 {
+			return nil
+		}
+		ors := make([]*gql.FilterTree, 0, len(vals))
+		for _, v := range vals {
+			ors = append(ors, compare("eq", v))
+		}
+		return &gql.FilterTree{Op: "or", Child: ors}
+	case "between":
+		// between: { min: 1, max: 2 } -> ge(val(v), 1) AND le(val(v), 2)
+		vals := val.(map[string]interface{})
+		return &gql.FilterTree{
+			Op:    "and",
+			Child: []*gql.FilterTree{compare("ge", vals["min"]), compare("le", vals["max"])},
+		}
+	default:
+		return compare(fn, val)
+	}
+}
+
 // buildFilter builds a Dgraph gql.FilterTree from a GraphQL 'filter' arg.
 //
 // All the 'filter' args built by the GraphQL layer look like
@@ -2110,17 +2669,27 @@ func buildFilter(typ schema.Type, filter map[string]interface{}) *gql.FilterTree
 				// numLikes: { le: 10 } -> le(Post.numLikes, 10)
 
 				fn, val := first(dgFunc)
+				pred, isCount := typ.DgraphPredicate(field), false
+				if countField := strings.TrimSuffix(field, "Count"); pred == "" &&
+					countField != field {
+					// postsCount: { gt: 5 } -> gt(count(Author.posts), 5)
+					pred, isCount = "count("+typ.DgraphPredicate(countField)+")", true
+				}
+				if pred == "" {
+					// An aggregate filter which rewriteAggregateFilters didn't rewrite.
+					continue
+				}
 				if val == nil {
 					// If it is `eq` filter for eg: {filter: { title: {eq: null }}} then
 					// it will be interpreted as {filter: {not: {has: title}}}, rest of
 					// the filters with null values will be ignored in query rewriting.
-					if fn == "eq" {
+					if fn == "eq" && !isCount {
 						hasFilterMap := map[string]interface{}{"not": map[string]interface{}{"has": []interface{}{field}}}
 						ands = append(ands, buildFilter(typ, hasFilterMap))
 					}
 					continue
 				}
-				args := []gql.Arg{{Value: typ.DgraphPredicate(field)}}
+				args := []gql.Arg{{Value: pred}}
 				switch fn {
 				// in takes List of Scalars as argument, for eg:
 				// code : { in: ["abc", "def", "ghi"] } -> eq(State.code,"abc","def","ghi")
@@ -2229,6 +2798,10 @@ func buildFilter(typ schema.Type, filter map[string]interface{}) *gql.FilterTree
 						})
 					}
 				}
+			case *gql.FilterTree:
+				// postsAggregate: { count: { gt: 5 } } -> gt(val(Author_1_count), 5), as
+				// rewritten by rewriteAggregateFilters.
+				ands = append(ands, dgFunc)
 			case interface{}:
 				// isPublished: true -> eq(Post.isPublished, true)
 				// OR an enum case
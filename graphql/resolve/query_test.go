@@ -76,6 +76,25 @@ func TestQueryRewriting(t *testing.T) {
 	}
 }
 
+func TestOrderByCountValidation(t *testing.T) {
+	gqlSchema := test.LoadSchemaFromFile(t, "schema.graphql")
+	combined := "Can't order by postsCount along with other fields in queryAuthor."
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{`queryAuthor(order: { asc: name, then: { desc: postsCount } }) { name }`, combined},
+		{`queryAuthor(order: { desc: postsCount, then: { asc: name } }) { name }`, combined},
+		{`queryAuthorConnection(order: { desc: postsCount }) { edges { cursor } }`,
+			"Can't order by postsCount in the connection query queryAuthorConnection."},
+	}
+	for _, tc := range tests {
+		_, err := gqlSchema.Operation(&schema.Request{Query: "query { " + tc.query + " }"})
+		require.Error(t, err, tc.query)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
 type HTTPRewritingCase struct {
 	Name             string
 	GQLQuery         string
@@ -44,6 +44,7 @@ const (
 	methodResolve = "RequestResolver.Resolve"
 
 	resolveStartTime resolveCtxKey = "resolveStartTime"
+	mutationTxnKey   resolveCtxKey = "mutationTxn"
 
 	resolverFailed    = false
 	resolverSucceeded = true
@@ -238,6 +239,7 @@ func (rf *resolverFactory) WithConventionResolvers(
 	queries := append(s.Queries(schema.GetQuery), s.Queries(schema.FilterQuery)...)
 	queries = append(queries, s.Queries(schema.PasswordQuery)...)
 	queries = append(queries, s.Queries(schema.AggregateQuery)...)
+	queries = append(queries, s.Queries(schema.ConnectionQuery)...)
 	for _, q := range queries {
 		rf.WithQueryResolver(q, func(q schema.Query) QueryResolver {
 			return NewQueryResolver(fns.Qrw, fns.Ex)
@@ -508,6 +510,7 @@ func (r *RequestResolver) Resolve(ctx context.Context, gqlReq *schema.Request) (
 		resp.Errors = schema.AsGQLErrors(err)
 		return
 	}
+	resp.Extensions.Cost = op.Cost()
 
 	if glog.V(3) {
 		// don't log the introspection queries they are sent too frequently
@@ -569,8 +572,12 @@ func (r *RequestResolver) Resolve(ctx context.Context, gqlReq *schema.Request) (
 			resp.Header = make(map[string][]string)
 			resp.Header.Set(schema.CacheControlHeader, op.CacheControl())
 			resp.Header.Set("Vary", "Accept-Encoding")
+			r.resolveCached(ctx, gqlReq, op, resp, resolveQueries)
+			break
 		}
 		resolveQueries()
+	case op.IsMutation() && op.Transactional():
+		r.resolveTransaction(ctx, op, resp)
 	case op.IsMutation():
 		// A mutation operation can contain any number of mutation fields.  Those should be executed
 		// serially.
@@ -58,6 +58,9 @@ type executor struct {
 	// first request, 2 = succeed once and then fail on 2nd request, etc.)
 	failQuery    int
 	failMutation int
+
+	// aborted is set once a transaction is aborted.
+	aborted bool
 }
 
 type QueryCase struct {
@@ -125,6 +128,7 @@ func (ex *executor) Execute(ctx context.Context, req *dgoapi.Request,
 	return &dgoapi.Response{
 		Json: []byte(res),
 		Uids: ex.assigned,
+		Txn:  &dgoapi.TxnContext{StartTs: 1},
 		Metrics: &dgoapi.Metrics{
 			NumUids: map[string]uint64{touchedUidsKey: ex.mutationTouched}},
 	}, nil
@@ -133,6 +137,9 @@ func (ex *executor) Execute(ctx context.Context, req *dgoapi.Request,
 
 func (ex *executor) CommitOrAbort(ctx context.Context,
 	tc *dgoapi.TxnContext) (*dgoapi.TxnContext, error) {
+	if tc != nil && tc.Aborted {
+		ex.aborted = true
+	}
 	return &dgoapi.TxnContext{}, nil
 }
 
@@ -444,6 +451,111 @@ func TestManyMutationsWithError(t *testing.T) {
 	}
 }
 
+func TestTransactionWithError(t *testing.T) {
+	// add1 - succeeds, but is rolled back
+	// add2 - fails or succeeds
+	// add3 - is never executed, or succeeds
+	multiMutation := `mutation multipleMutations($id: ID!) @transaction {
+			add1: addPost(input: [{title: "A Post", text: "Some text", author: {id: "0x1"}}]) {
+				post { title }
+			}
+
+			add2: addPost(input: [{title: "A Post", text: "Some text", author: {id: $id}}]) {
+				post { title }
+			}
+
+			add3: addPost(input: [{title: "A Post", text: "Some text", author: {id: "0x1"}}]) {
+				post { title }
+			}
+		}`
+
+	tests := map[string]struct {
+		explanation  string
+		failMutation int
+		expected     string
+		errors       x.GqlErrorList
+		aborted      bool
+	}{
+		"Dgraph fail": {
+			explanation:  "the failed mutation aborts the transaction of all the mutations",
+			failMutation: 2,
+			expected: `{
+				"add1": null,
+				"add2" : null
+			}`,
+			errors: x.GqlErrorList{
+				&x.GqlError{Message: `Mutation add1 was rolled back because of an error ` +
+					`in a later mutation of the transaction.`,
+					Locations: []x.Location{{Line: 2, Column: 4}},
+					Path:      []interface{}{"add1"}},
+				&x.GqlError{Message: `mutation addPost failed because ` +
+					`Dgraph mutation failed because _bad stuff happend_`,
+					Locations: []x.Location{{Line: 6, Column: 4}},
+					Path:      []interface{}{"add2"}},
+				&x.GqlError{Message: `Mutation add3 was not executed because of ` +
+					`a previous error.`,
+					Locations: []x.Location{{Line: 10, Column: 4}},
+					Path:      []interface{}{"add3"}}},
+			aborted: true,
+		},
+		"No fail": {
+			explanation: "the transaction is committed once all the mutations succeeded",
+			expected: `{
+				"add1": { "post": [{ "title": "A Post" }] },
+				"add2": { "post": [{ "title": "A Post" }] },
+				"add3": { "post": [{ "title": "A Post" }] }
+			}`,
+		},
+	}
+
+	gqlSchema := test.LoadSchemaFromString(t, testGQLSchema)
+
+	for name, tcase := range tests {
+		t.Run(name, func(t *testing.T) {
+			ex := &executor{
+				existenceQueriesResp: `{ "Author_1": [{"uid":"0x1", "dgraph.type":["Author"]}]}`,
+				resp:                 `{"post": [{ "title": "A Post" } ] }`,
+				assigned:             map[string]string{"Post_2": "0x2"},
+				failMutation:         tcase.failMutation}
+			resp := resolveWithClient(gqlSchema, multiMutation,
+				map[string]interface{}{"id": "0x1"}, ex)
+
+			if diff := cmp.Diff(tcase.errors, resp.Errors); diff != "" {
+				t.Errorf("errors mismatch (-want +got):\n%s", diff)
+			}
+			require.JSONEq(t, tcase.expected, resp.Data.String())
+			require.Equal(t, tcase.aborted, ex.aborted)
+		})
+	}
+}
+
+func TestUpsertMutationWithMultipleConflictMatches(t *testing.T) {
+	// The @id field is a conflict key without @search. If the keys match several nodes, the
+	// upsert can't pick the one to merge into, so it fails instead of changing any of them.
+	gqlSchema := test.LoadSchemaFromString(t, `
+	type Customer @generate(mutation: {upsert: true}) {
+		id: ID!
+		email: String! @id
+		name: String
+	}`)
+	mutation := `mutation {
+		upsertCustomer(input: [{email: "a@b.c", name: "A"}], onConflict: {keys: [email]}) {
+			customer { email }
+		}
+	}`
+
+	ex := &executor{
+		existenceQueriesResp: `{ "Customer_1": [
+			{"uid":"0x1", "dgraph.type":["Customer"]},
+			{"uid":"0x2", "dgraph.type":["Customer"]}]}`,
+	}
+	resp := resolveWithClient(gqlSchema, mutation, nil, ex)
+
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "mutation upsertCustomer failed because Found multiple nodes with ID: 0x2",
+		resp.Errors[0].Message)
+}
+
 func TestSubscriptionErrorWhenNoneDefined(t *testing.T) {
 	gqlSchema := test.LoadSchemaFromString(t, testGQLSchema)
 	resp := resolveWithClient(gqlSchema, `subscription { foo }`, nil, nil)
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolve
+
+import (
+	"context"
+
+	dgoapi "github.com/dgraph-io/dgo/v210/protos/api"
+	"github.com/golang/glog"
+
+	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A mutationTxn is the Dgraph transaction that all the mutation fields of an operation with
+// @transaction run in. The mutation resolvers don't commit what they did, the transaction is
+// committed once all of them succeeded, and aborted otherwise.
+type mutationTxn struct {
+	executor DgraphExecutor
+	txn      *dgoapi.TxnContext
+	// onCommit is run with the commit timestamp once the transaction is committed, eg. to send
+	// the events of the lambda webhooks.
+	onCommit []func(commitTs uint64)
+}
+
+// mutationTxnFrom returns the transaction the mutations resolved with ctx must run in, or nil if
+// each of them runs in its own transaction.
+func mutationTxnFrom(ctx context.Context) *mutationTxn {
+	txn, _ := ctx.Value(mutationTxnKey).(*mutationTxn)
+	return txn
+}
+
+// join records tc, returned by ex for a request which ran in the transaction, so that its
+// changes get committed or aborted along with the transaction.
+func (t *mutationTxn) join(ex DgraphExecutor, tc *dgoapi.TxnContext) {
+	if tc == nil || tc.StartTs == 0 {
+		return
+	}
+	t.executor = ex
+	if t.txn == nil {
+		t.txn = &dgoapi.TxnContext{StartTs: tc.StartTs, Hash: tc.Hash}
+	}
+	t.txn.Keys = append(t.txn.Keys, tc.Keys...)
+	t.txn.Preds = append(t.txn.Preds, tc.Preds...)
+}
+
+// request sets up req to run in the transaction, so that it sees the changes of the previous
+// mutations.
+func (t *mutationTxn) request(req *dgoapi.Request) *dgoapi.Request {
+	if t.txn != nil {
+		req.StartTs = t.txn.StartTs
+		req.Hash = t.txn.Hash
+		req.ReadOnly = false
+	}
+	return req
+}
+
+func (t *mutationTxn) commit(ctx context.Context) error {
+	if t.txn == nil {
+		// Nothing was run.
+		return nil
+	}
+	txnCtx, err := t.executor.CommitOrAbort(ctx, t.txn)
+	if err != nil {
+		return err
+	}
+	for _, f := range t.onCommit {
+		f(txnCtx.CommitTs)
+	}
+	return nil
+}
+
+func (t *mutationTxn) abort(ctx context.Context) {
+	if t.txn == nil {
+		return
+	}
+	t.txn.Aborted = true
+	if _, err := t.executor.CommitOrAbort(ctx, t.txn); err != nil {
+		glog.Errorf("Error occurred while aborting transaction: %s", err)
+	}
+}
+
+// resolveTransaction resolves the mutations of op, which has @transaction, serially in a single
+// Dgraph transaction. The transaction is only committed if all the mutations succeeded. Otherwise,
+// none of their changes are kept, and none of their results are returned.
+func (r *RequestResolver) resolveTransaction(
+	ctx context.Context,
+	op schema.Operation,
+	resp *schema.Response) {
+
+	mutations := op.Mutations()
+	for _, m := range mutations {
+		// Custom mutations are resolved by remote endpoints, which can't take part in the
+		// transaction.
+		if m.IsCustomHTTP() {
+			resp.WithError(x.GqlErrorf(
+				"Mutation %s can't be run in a transaction, as it has a custom resolver.",
+				m.ResponseName()).
+				WithLocations(m.Location()).
+				WithPath([]interface{}{m.ResponseName()}))
+			return
+		}
+	}
+
+	txn := &mutationTxn{}
+	ctx = context.WithValue(ctx, mutationTxnKey, txn)
+	allResolved := make([]*Resolved, 0, len(mutations))
+	allSuccessful := true
+	for _, m := range mutations {
+		res, success := r.resolvers.mutationResolverFor(m).Resolve(ctx, m)
+		allResolved = append(allResolved, res)
+		if !success {
+			allSuccessful = false
+			break
+		}
+	}
+
+	var commitErr error
+	if allSuccessful {
+		commitErr = txn.commit(ctx)
+	} else {
+		txn.abort(ctx)
+	}
+
+	for i, m := range mutations {
+		var err *x.GqlError
+		switch {
+		case i >= len(allResolved):
+			err = x.GqlErrorf("Mutation %s was not executed because of a previous error.",
+				m.ResponseName())
+		case commitErr != nil:
+			err = x.GqlErrorf("Mutation %s was rolled back, as the transaction couldn't be "+
+				"committed: %s", m.ResponseName(), commitErr)
+		case allSuccessful || i == len(allResolved)-1:
+			// The results of the failed mutation only have its errors.
+			addResult(resp, allResolved[i])
+			continue
+		default:
+			err = x.GqlErrorf("Mutation %s was rolled back because of an error in a later "+
+				"mutation of the transaction.", m.ResponseName())
+		}
+		if i < len(allResolved) {
+			addResult(resp, &Resolved{
+				Data:       m.NullResponse(),
+				Field:      m,
+				Extensions: allResolved[i].Extensions,
+			})
+		}
+		resp.WithError(err.
+			WithLocations(m.Location()).
+			WithPath([]interface{}{m.ResponseName()}))
+	}
+}
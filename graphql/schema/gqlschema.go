@@ -49,6 +49,11 @@ const (
 	lambdaDirective         = "lambda"
 	lambdaOnMutateDirective = "lambdaOnMutate"
 	defaultDirective        = "default"
+	langDirective           = "lang"
+	langArg                 = "lang"
+	countDirective          = "count"
+	upsertDirective         = "upsert"
+	countFilterName         = "CountFilter"
 
 	generateDirective       = "generate"
 	generateQueryArg        = "query"
@@ -56,10 +61,12 @@ const (
 	generateQueryField      = "query"
 	generatePasswordField   = "password"
 	generateAggregateField  = "aggregate"
+	generateConnectionField = "connection"
 	generateMutationArg     = "mutation"
 	generateAddField        = "add"
 	generateUpdateField     = "update"
 	generateDeleteField     = "delete"
+	generateUpsertField     = "upsert"
 	generateSubscriptionArg = "subscription"
 
 	cascadeDirective = "cascade"
@@ -68,6 +75,8 @@ const (
 	cacheControlDirective = "cacheControl"
 	CacheControlHeader    = "Cache-Control"
 
+	transactionDirective = "transaction"
+
 	// Directives to support Apollo Federation
 	apolloKeyDirective      = "key"
 	apolloKeyArg            = "fields"
@@ -270,12 +279,14 @@ input GenerateQueryParams {
 	query: Boolean
 	password: Boolean
 	aggregate: Boolean
+	connection: Boolean
 }
 
 input GenerateMutationParams {
 	add: Boolean
 	update: Boolean
 	delete: Boolean
+	upsert: Boolean
 }
 `
 	directiveDefs = `
@@ -284,6 +295,9 @@ directive @search(by: [DgraphIndex!]) on FIELD_DEFINITION
 directive @dgraph(type: String, pred: String) on OBJECT | INTERFACE | FIELD_DEFINITION
 directive @id(interface: Boolean) on FIELD_DEFINITION
 directive @default(add: DgraphDefault, update: DgraphDefault) on FIELD_DEFINITION
+directive @lang on FIELD_DEFINITION
+directive @count on FIELD_DEFINITION
+directive @upsert on FIELD_DEFINITION
 directive @withSubscription on OBJECT | INTERFACE | FIELD_DEFINITION
 directive @secret(field: String!, pred: String) on OBJECT | INTERFACE
 directive @auth(
@@ -291,7 +305,7 @@ directive @auth(
 	query: AuthRule,
 	add: AuthRule,
 	update: AuthRule,
-	delete: AuthRule) on OBJECT | INTERFACE
+	delete: AuthRule) on OBJECT | INTERFACE | FIELD_DEFINITION
 directive @custom(http: CustomHTTP, dql: String) on FIELD_DEFINITION
 directive @remote on OBJECT | INTERFACE | UNION | INPUT_OBJECT | ENUM
 directive @remoteResponse(name: String) on FIELD_DEFINITION
@@ -299,6 +313,7 @@ directive @cascade(fields: [String]) on FIELD
 directive @lambda on FIELD_DEFINITION
 directive @lambdaOnMutate(add: Boolean, update: Boolean, delete: Boolean) on OBJECT | INTERFACE
 directive @cacheControl(maxAge: Int!) on QUERY
+directive @transaction on MUTATION
 directive @generate(
 	query: GenerateQueryParams,
 	mutation: GenerateMutationParams,
@@ -316,6 +331,9 @@ directive @search(by: [DgraphIndex!]) on FIELD_DEFINITION
 directive @dgraph(type: String, pred: String) on OBJECT | INTERFACE | FIELD_DEFINITION
 directive @id(interface: Boolean) on FIELD_DEFINITION
 directive @default(add: DgraphDefault, update: DgraphDefault) on FIELD_DEFINITION
+directive @lang on FIELD_DEFINITION
+directive @count on FIELD_DEFINITION
+directive @upsert on FIELD_DEFINITION
 directive @withSubscription on OBJECT | INTERFACE | FIELD_DEFINITION
 directive @secret(field: String!, pred: String) on OBJECT | INTERFACE
 directive @remote on OBJECT | INTERFACE | UNION | INPUT_OBJECT | ENUM
@@ -513,6 +531,14 @@ var summable = map[string]bool{
 	"Float": true,
 }
 
+// The filters for the aggregates of the GraphQL types, in the AggregateFilter inputs.
+var aggregateFilterTypes = map[string]string{
+	"Int":      "IntFilter",
+	"Int64":    "Int64Filter",
+	"Float":    "FloatFilter",
+	"DateTime": "DateTimeFilter",
+}
+
 var enumDirectives = map[string]bool{
 	"trigram": true,
 	"hash":    true,
@@ -572,12 +598,15 @@ var directiveValidators = map[string]directiveValidator{
 	idDirective:             idValidation,
 	subscriptionDirective:   ValidatorNoOp,
 	secretDirective:         passwordValidation,
-	authDirective:           ValidatorNoOp, // Just to get it printed into generated schema
+	authDirective:           fieldAuthValidation,
 	customDirective:         customDirectiveValidation,
 	remoteDirective:         ValidatorNoOp,
 	deprecatedDirective:     ValidatorNoOp,
 	lambdaDirective:         lambdaDirectiveValidation,
 	defaultDirective:        defaultDirectiveValidation,
+	langDirective:           langDirectiveValidation,
+	countDirective:          countDirectiveValidation,
+	upsertDirective:         upsertDirectiveValidation,
 	lambdaOnMutateDirective: ValidatorNoOp,
 	generateDirective:       ValidatorNoOp,
 	apolloKeyDirective:      ValidatorNoOp,
@@ -602,6 +631,9 @@ var directiveLocationMap = map[string]map[ast.DefinitionKind]bool{
 	remoteDirective: {ast.Object: true, ast.Interface: true, ast.Union: true,
 		ast.InputObject: true, ast.Enum: true},
 	lambdaDirective:         nil,
+	langDirective:           nil,
+	countDirective:          nil,
+	upsertDirective:         nil,
 	lambdaOnMutateDirective: {ast.Object: true, ast.Interface: true},
 	generateDirective:       {ast.Object: true, ast.Interface: true},
 	apolloKeyDirective:      {ast.Object: true, ast.Interface: true},
@@ -615,26 +647,30 @@ var directiveLocationMap = map[string]map[ast.DefinitionKind]bool{
 
 // Struct to store parameters of @generate directive
 type GenerateDirectiveParams struct {
-	generateGetQuery       bool
-	generateFilterQuery    bool
-	generatePasswordQuery  bool
-	generateAggregateQuery bool
-	generateAddMutation    bool
-	generateUpdateMutation bool
-	generateDeleteMutation bool
-	generateSubscription   bool
+	generateGetQuery        bool
+	generateFilterQuery     bool
+	generatePasswordQuery   bool
+	generateAggregateQuery  bool
+	generateConnectionQuery bool
+	generateAddMutation     bool
+	generateUpdateMutation  bool
+	generateDeleteMutation  bool
+	generateUpsertMutation  bool
+	generateSubscription    bool
 }
 
 func parseGenerateDirectiveParams(defn *ast.Definition) *GenerateDirectiveParams {
 	ret := &GenerateDirectiveParams{
-		generateGetQuery:       true,
-		generateFilterQuery:    true,
-		generatePasswordQuery:  true,
-		generateAggregateQuery: true,
-		generateAddMutation:    true,
-		generateUpdateMutation: true,
-		generateDeleteMutation: true,
-		generateSubscription:   false,
+		generateGetQuery:        true,
+		generateFilterQuery:     true,
+		generatePasswordQuery:   true,
+		generateAggregateQuery:  true,
+		generateConnectionQuery: false,
+		generateAddMutation:     true,
+		generateUpdateMutation:  true,
+		generateDeleteMutation:  true,
+		generateUpsertMutation:  false,
+		generateSubscription:    false,
 	}
 
 	if dir := defn.Directives.ForName(generateDirective); dir != nil {
@@ -660,6 +696,11 @@ func parseGenerateDirectiveParams(defn *ast.Definition) *GenerateDirectiveParams
 					ret.generateAggregateQuery = aggregateFieldVal.(bool)
 				}
 			}
+			if connectionField := queryArg.Value.Children.ForName(generateConnectionField); connectionField != nil {
+				if connectionFieldVal, err := connectionField.Value(nil); err == nil {
+					ret.generateConnectionQuery = connectionFieldVal.(bool)
+				}
+			}
 		}
 
 		if mutationArg := dir.Arguments.ForName(generateMutationArg); mutationArg != nil {
@@ -678,6 +719,11 @@ func parseGenerateDirectiveParams(defn *ast.Definition) *GenerateDirectiveParams
 					ret.generateDeleteMutation = deleteFieldVal.(bool)
 				}
 			}
+			if upsertField := mutationArg.Value.Children.ForName(generateUpsertField); upsertField != nil {
+				if upsertFieldVal, err := upsertField.Value(nil); err == nil {
+					ret.generateUpsertMutation = upsertFieldVal.(bool)
+				}
+			}
 		}
 
 		if subscriptionArg := dir.Arguments.ForName(generateSubscriptionArg); subscriptionArg != nil {
@@ -1026,6 +1072,9 @@ func completeSchema(sch *ast.Schema, definitions []string, providesFieldsMap map
 			if params.generateAddMutation {
 				addInputType(sch, defn, providesTypeMap)
 				addAddPayloadType(sch, defn, providesTypeMap)
+				if params.generateUpsertMutation {
+					addUpsertTypes(sch, defn)
+				}
 			}
 			addMutations(sch, defn, params)
 		}
@@ -1034,6 +1083,7 @@ func completeSchema(sch *ast.Schema, definitions []string, providesFieldsMap map
 		addFilterType(sch, defn, providesTypeMap)
 		addTypeOrderable(sch, defn, providesTypeMap)
 		addFieldFilters(sch, defn, providesTypeMap, apolloServiceQuery)
+		addLangArguments(defn)
 		addAggregationResultType(sch, defn, providesTypeMap)
 		// Don't expose queries for the @extends type to the gateway
 		// as it is resolved through `_entities` resolver.
@@ -1121,7 +1171,8 @@ func cleanSchema(sch *ast.Schema) {
 	for _, field := range sch.Mutation.Fields {
 		custom := field.Directives.ForName("custom")
 		// We would only modify add/update
-		if custom != nil || !(strings.HasPrefix(field.Name, "add") || strings.HasPrefix(field.Name, "update")) {
+		if custom != nil || !(strings.HasPrefix(field.Name, "add") ||
+			strings.HasPrefix(field.Name, "upsert") || strings.HasPrefix(field.Name, "update")) {
 			sch.Mutation.Fields[i] = field
 			i++
 			continue
@@ -1134,6 +1185,10 @@ func cleanSchema(sch *ast.Schema) {
 		if strings.HasPrefix(field.Name, "add") {
 			typeName = field.Name[3:]
 			input = "Add" + typeName + "Input"
+		} else if strings.HasPrefix(field.Name, "upsert") {
+			// upsertT takes the same input as addT.
+			typeName = field.Name[6:]
+			input = "Add" + typeName + "Input"
 		} else if strings.HasPrefix(field.Name, "update") {
 			typeName = field.Name[6:]
 			input = "Update" + typeName + "Input"
@@ -1444,7 +1499,7 @@ func addTypeHasFilter(schema *ast.Schema, defn *ast.Definition, providesTypeMap
 
 func addOrderArgument(schema *ast.Schema, fld *ast.FieldDefinition, providesTypeMap map[string]bool) {
 	fldType := fld.Type.Name()
-	if hasOrderables(schema.Types[fldType], providesTypeMap) {
+	if hasOrderables(schema, schema.Types[fldType], providesTypeMap) {
 		fld.Arguments = append(fld.Arguments,
 			&ast.ArgumentDefinition{
 				Name: "order",
@@ -1453,6 +1508,77 @@ func addOrderArgument(schema *ast.Schema, fld *ast.FieldDefinition, providesType
 	}
 }
 
+// addCountFilterType adds the CountFilter input to the schema, if it isn't there already.
+// It's only added for the schemas which use @count, so that the others don't change.
+func addCountFilterType(schema *ast.Schema) {
+	if schema.Types[countFilterName] != nil {
+		return
+	}
+	filter := &ast.Definition{
+		Kind: ast.InputObject,
+		Name: countFilterName,
+	}
+	for _, fn := range []string{"eq", "le", "lt", "ge", "gt"} {
+		filter.Fields = append(filter.Fields,
+			&ast.FieldDefinition{Name: fn, Type: &ast.Type{NamedType: "Int"}})
+	}
+	schema.Types[countFilterName] = filter
+}
+
+// addAggregateFilterType adds the input to filter by the aggregates over the edges to defn,
+// if it isn't there already. It has the aggregates of the AggregateResult of defn that can be
+// compared, along with the filter for the edges to aggregate. eg: for a type Post
+//
+//	input PostAggregateFilter {
+//		filter: PostFilter
+//		count: IntFilter
+//		numLikesMin: IntFilter
+//		...
+//	}
+func addAggregateFilterType(schema *ast.Schema, defn *ast.Definition) {
+	filterName := defn.Name + "AggregateFilter"
+	if schema.Types[filterName] != nil {
+		return
+	}
+
+	filter := &ast.Definition{
+		Kind: ast.InputObject,
+		Name: filterName,
+		Fields: ast.FieldList{
+			{Name: "filter", Type: &ast.Type{NamedType: defn.Name + "Filter"}},
+			{Name: "count", Type: &ast.Type{NamedType: "IntFilter"}},
+		},
+	}
+	for _, fld := range defn.Fields {
+		fldFilter := aggregateFilterTypes[fld.Type.NamedType]
+		if fldFilter == "" || externalAndNonKeyField(fld, defn, nil) || hasFieldAuth(fld) {
+			continue
+		}
+		if isOrderable(fld, defn, nil) {
+			filter.Fields = append(filter.Fields,
+				&ast.FieldDefinition{Name: fld.Name + "Min", Type: &ast.Type{NamedType: fldFilter}},
+				&ast.FieldDefinition{Name: fld.Name + "Max", Type: &ast.Type{NamedType: fldFilter}})
+		}
+		if isSummable(fld, defn, nil) {
+			filter.Fields = append(filter.Fields,
+				&ast.FieldDefinition{Name: fld.Name + "Sum", Type: &ast.Type{NamedType: fldFilter}},
+				&ast.FieldDefinition{Name: fld.Name + "Avg", Type: &ast.Type{NamedType: "FloatFilter"}})
+		}
+	}
+	schema.Types[filterName] = filter
+}
+
+// addLangArguments adds the lang argument to the fields with @lang directive, so that
+// a language variant of the value can be queried. eg: name(lang: "en") reads name@en.
+func addLangArguments(defn *ast.Definition) {
+	for _, fld := range defn.Fields {
+		if hasLang(fld) {
+			fld.Arguments = append(fld.Arguments,
+				&ast.ArgumentDefinition{Name: langArg, Type: &ast.Type{NamedType: "String"}})
+		}
+	}
+}
+
 func addPaginationArguments(fld *ast.FieldDefinition) {
 	fld.Arguments = append(fld.Arguments,
 		&ast.ArgumentDefinition{Name: "first", Type: &ast.Type{NamedType: "Int"}},
@@ -1460,6 +1586,16 @@ func addPaginationArguments(fld *ast.FieldDefinition) {
 	)
 }
 
+// addConnectionArguments adds the arguments of a connection query. Only forward pagination is
+// supported, so the last and before arguments of Relay aren't added, and the queries using them
+// are rejected by the validation.
+func addConnectionArguments(fld *ast.FieldDefinition) {
+	fld.Arguments = append(fld.Arguments,
+		&ast.ArgumentDefinition{Name: "first", Type: &ast.Type{NamedType: "Int"}},
+		&ast.ArgumentDefinition{Name: "after", Type: &ast.Type{NamedType: "String"}},
+	)
+}
+
 // getFilterTypes converts search arguments of a field to graphql filter types.
 func getFilterTypes(schema *ast.Schema, fld *ast.FieldDefinition, filterName string) []string {
 	searchArgs := getSearchArgs(fld)
@@ -1577,6 +1713,28 @@ func addFilterType(schema *ast.Schema, defn *ast.Definition, providesTypeMap map
 
 			mergeAndAddFilters(filterTypes, schema, filterName)
 		}
+
+		// The @count index lets Dgraph filter on the number of values of the field,
+		// eg: postsCount: { gt: 5 } -> gt(count(Author.posts), 5)
+		if hasCount(fld) {
+			filter.Fields = append(filter.Fields,
+				&ast.FieldDefinition{
+					Name: fld.Name + "Count",
+					Type: &ast.Type{NamedType: countFilterName},
+				})
+			addCountFilterType(schema)
+		}
+
+		// The edges can also be filtered by the aggregates over them,
+		// eg: postsAggregate: { filter: { ... }, count: { gt: 5 } }
+		if hasAggregateFilter(schema, fld) {
+			filter.Fields = append(filter.Fields,
+				&ast.FieldDefinition{
+					Name: fld.Name + "Aggregate",
+					Type: &ast.Type{NamedType: fld.Type.Name() + "AggregateFilter"},
+				})
+			addAggregateFilterType(schema, schema.Types[fld.Type.Name()])
+		}
 	}
 
 	// Has filter makes sense only if there is atleast one non ID field in the defn
@@ -1630,12 +1788,50 @@ func isEnumList(fld *ast.FieldDefinition, sch *ast.Schema) bool {
 	return typeDefn.Kind == "ENUM" && fld.Type.Elem != nil
 }
 
-func hasOrderables(defn *ast.Definition, providesTypeMap map[string]bool) bool {
+func hasOrderables(schema *ast.Schema, defn *ast.Definition,
+	providesTypeMap map[string]bool) bool {
 	return fieldAny(defn.Fields, func(fld *ast.FieldDefinition) bool {
-		return isOrderable(fld, defn, providesTypeMap)
+		return isOrderable(fld, defn, providesTypeMap) || isCountOrderable(schema, fld)
 	})
 }
 
+// isCountOrderable returns true if the values of the field can be ordered by their count,
+// eg: order: { desc: postsCount }. The field must have the @count directive.
+func isCountOrderable(schema *ast.Schema, fld *ast.FieldDefinition) bool {
+	return hasCount(fld) && !hasCustomOrLambda(fld) && !hasAuthRules(schema, fld.Type.Name())
+}
+
+// hasAggregateFilter returns true if the type of fld can be filtered by the aggregates over
+// the edges of fld, eg: postsAggregate: { count: { gt: 5 } }. The aggregates are computed over
+// all the edges, so the edges to types with @auth can't be used.
+func hasAggregateFilter(schema *ast.Schema, fld *ast.FieldDefinition) bool {
+	if !isCountOrderable(schema, fld) {
+		return false
+	}
+	kind := schema.Types[fld.Type.Name()].Kind
+	return kind == ast.Object || kind == ast.Interface
+}
+
+// hasFieldAuth returns true if fld has auth rules of its own. The values of such a field can't be
+// aggregated, as that would show them to everyone. The filters and orders on it only consider the
+// nodes which satisfy its query rule.
+func hasFieldAuth(fld *ast.FieldDefinition) bool {
+	return fld.Directives.ForName(authDirective) != nil
+}
+
+// hasAuthRules returns true if the type, or any of the types implementing it, has @auth.
+func hasAuthRules(schema *ast.Schema, typName string) bool {
+	if defn := schema.Types[typName]; defn != nil && defn.Directives.ForName(authDirective) != nil {
+		return true
+	}
+	for _, impl := range schema.PossibleTypes[typName] {
+		if impl.Name != typName && impl.Directives.ForName(authDirective) != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func isOrderable(fld *ast.FieldDefinition, defn *ast.Definition,
 	providesTypeMap map[string]bool) bool {
 	// lists can't be ordered and NamedType will be empty for lists,
@@ -1765,7 +1961,7 @@ func getSearchArgs(fld *ast.FieldDefinition) []string {
 // `order: { asc: datePublished, then: { asc: title } }`.
 // a further `then` would be a third ordering, etc.
 func addTypeOrderable(schema *ast.Schema, defn *ast.Definition, providesTypeMap map[string]bool) {
-	if !hasOrderables(defn, providesTypeMap) {
+	if !hasOrderables(schema, defn, providesTypeMap) {
 		return
 	}
 
@@ -1793,6 +1989,10 @@ func addTypeOrderable(schema *ast.Schema, defn *ast.Definition, providesTypeMap
 			order.EnumValues = append(order.EnumValues,
 				&ast.EnumValueDefinition{Name: fld.Name})
 		}
+		if isCountOrderable(schema, fld) {
+			order.EnumValues = append(order.EnumValues,
+				&ast.EnumValueDefinition{Name: fld.Name + "Count"})
+		}
 	}
 
 	schema.Types[orderableName] = order
@@ -1893,6 +2093,10 @@ func addAggregationResultType(schema *ast.Schema, defn *ast.Definition, provides
 	// Maximum and Minimum fields are added for fields which are of type int, int64,
 	// float, string, datetime .
 	for _, fld := range defn.Fields {
+		if hasFieldAuth(fld) {
+			continue
+		}
+
 		// Creating aggregateFieldType to store type of the aggregate fields like
 		// max, min, avg, sum of scalar fields.
 		aggregateFieldType := &ast.Type{
@@ -2040,6 +2244,67 @@ func addAggregationQuery(schema *ast.Schema, defn *ast.Definition, generateSubsc
 
 }
 
+// addConnectionTypes adds the types of a Relay connection over the nodes of defn, and the
+// PageInfo type shared by all the connections.
+func addConnectionTypes(schema *ast.Schema, defn *ast.Definition) {
+	if _, ok := schema.Types["PageInfo"]; !ok {
+		schema.Types["PageInfo"] = &ast.Definition{
+			Kind: ast.Object,
+			Name: "PageInfo",
+			Fields: []*ast.FieldDefinition{
+				{Name: "startCursor", Type: &ast.Type{NamedType: "String"}},
+				{Name: "endCursor", Type: &ast.Type{NamedType: "String"}},
+				{Name: "hasNextPage", Type: &ast.Type{NamedType: "Boolean", NonNull: true}},
+				{Name: "hasPreviousPage", Type: &ast.Type{NamedType: "Boolean", NonNull: true}},
+			},
+		}
+	}
+
+	schema.Types[defn.Name+"Edge"] = &ast.Definition{
+		Kind: ast.Object,
+		Name: defn.Name + "Edge",
+		Fields: []*ast.FieldDefinition{
+			{Name: "node", Type: &ast.Type{NamedType: defn.Name, NonNull: true}},
+			{Name: "cursor", Type: &ast.Type{NamedType: "String", NonNull: true}},
+		},
+	}
+
+	schema.Types[defn.Name+"Connection"] = &ast.Definition{
+		Kind: ast.Object,
+		Name: defn.Name + "Connection",
+		Fields: []*ast.FieldDefinition{
+			{
+				Name: "edges",
+				Type: &ast.Type{
+					Elem:    &ast.Type{NamedType: defn.Name + "Edge", NonNull: true},
+					NonNull: true,
+				},
+			},
+			{Name: "pageInfo", Type: &ast.Type{NamedType: "PageInfo", NonNull: true}},
+		},
+	}
+}
+
+func addConnectionQuery(schema *ast.Schema, defn *ast.Definition, providesTypeMap map[string]bool) {
+	qry := &ast.FieldDefinition{
+		Name: "query" + defn.Name + "Connection",
+		Type: &ast.Type{
+			NamedType: defn.Name + "Connection",
+		},
+	}
+	addFilterArgumentForField(schema, qry, defn.Name)
+	if hasOrderables(schema, defn, providesTypeMap) {
+		qry.Arguments = append(qry.Arguments,
+			&ast.ArgumentDefinition{
+				Name: "order",
+				Type: &ast.Type{NamedType: defn.Name + "Order"},
+			})
+	}
+	addConnectionArguments(qry)
+
+	schema.Query.Fields = append(schema.Query.Fields, qry)
+}
+
 func addPasswordQuery(schema *ast.Schema, defn *ast.Definition, providesTypeMap map[string]bool) {
 	hasIDField := hasID(defn)
 	hasXIDField := hasXID(defn)
@@ -2094,6 +2359,11 @@ func addQueries(schema *ast.Schema, defn *ast.Definition, providesTypeMap map[st
 	if params.generateAggregateQuery {
 		addAggregationQuery(schema, defn, params.generateSubscription)
 	}
+
+	if params.generateConnectionQuery {
+		addConnectionTypes(schema, defn)
+		addConnectionQuery(schema, defn, providesTypeMap)
+	}
 }
 
 func addAddMutation(schema *ast.Schema, defn *ast.Definition) {
@@ -2128,6 +2398,112 @@ func addAddMutation(schema *ast.Schema, defn *ast.Definition) {
 
 }
 
+// addUpsertMutation adds the upsert mutation of defn. It takes the same input as the add
+// mutation, along with the keys which identify the existing nodes to merge the input into.
+func addUpsertMutation(schema *ast.Schema, defn *ast.Definition) {
+	if schema.Types["Add"+defn.Name+"Input"] == nil || schema.Types[defn.Name+"OnConflict"] == nil {
+		return
+	}
+
+	upsert := &ast.FieldDefinition{
+		Name: "upsert" + defn.Name,
+		Type: &ast.Type{
+			NamedType: "Add" + defn.Name + "Payload",
+		},
+		Arguments: []*ast.ArgumentDefinition{
+			{
+				Name: "input",
+				Type: &ast.Type{
+					NamedType: "[Add" + defn.Name + "Input!]",
+					NonNull:   true,
+				},
+			},
+			{
+				Name: "onConflict",
+				Type: &ast.Type{
+					NamedType: defn.Name + "OnConflict",
+					NonNull:   true,
+				},
+			},
+		},
+	}
+	schema.Mutation.Fields = append(schema.Mutation.Fields, upsert)
+}
+
+// addUpsertTypes adds the input for the onConflict argument of the upsert mutation of defn, along
+// with the enums of the fields it takes. eg: for a type Author
+//
+//	input AuthorOnConflict {
+//		keys: [AuthorUpsertKey!]!
+//		update: [AuthorUpsertField!]
+//	}
+//
+// The existing node with the same values for all the keys as an input object gets the values of
+// the update fields overwritten, and the other fields are only set if it has no value for them.
+func addUpsertTypes(schema *ast.Schema, defn *ast.Definition) {
+	input := schema.Types["Add"+defn.Name+"Input"]
+	if input == nil {
+		return
+	}
+
+	keys := &ast.Definition{Kind: ast.Enum, Name: defn.Name + "UpsertKey"}
+	fields := &ast.Definition{Kind: ast.Enum, Name: defn.Name + "UpsertField"}
+	for _, fld := range input.Fields {
+		fd := defn.Fields.ForName(fld.Name)
+		if fd == nil || (schema.Types[fld.Type.Name()].Kind != ast.Scalar &&
+			schema.Types[fld.Type.Name()].Kind != ast.Enum) {
+			continue
+		}
+		fields.EnumValues = append(fields.EnumValues, &ast.EnumValueDefinition{Name: fld.Name})
+		if isUpsertKey(fd) {
+			keys.EnumValues = append(keys.EnumValues, &ast.EnumValueDefinition{Name: fld.Name})
+		}
+	}
+	if len(keys.EnumValues) == 0 {
+		return
+	}
+
+	schema.Types[keys.Name] = keys
+	schema.Types[fields.Name] = fields
+	schema.Types[defn.Name+"OnConflict"] = &ast.Definition{
+		Kind: ast.InputObject,
+		Name: defn.Name + "OnConflict",
+		Fields: ast.FieldList{
+			{
+				Name: "keys",
+				Type: &ast.Type{Elem: &ast.Type{NamedType: keys.Name, NonNull: true}, NonNull: true},
+			},
+			{
+				Name: "update",
+				Type: &ast.Type{Elem: &ast.Type{NamedType: fields.Name, NonNull: true}},
+			},
+		},
+	}
+}
+
+// isUpsertKey returns true if fld can be a conflict key of upsert mutations. The nodes must be
+// looked up by its value with eq, and its predicate must have @upsert, so that the concurrent
+// upserts with the same keys conflict instead of both adding a node. The @id fields always get
+// both; the other fields need @upsert and an index supporting eq.
+func isUpsertKey(fld *ast.FieldDefinition) bool {
+	if fld.Type.Elem != nil || hasLang(fld) || hasCustomOrLambda(fld) {
+		return false
+	}
+	if hasIDDirective(fld) {
+		return true
+	}
+	if fld.Directives.ForName(upsertDirective) == nil {
+		return false
+	}
+	for _, index := range getSearchArgs(fld) {
+		switch index {
+		case "hash", "exact", "int", "int64", "float", "bool":
+			return true
+		}
+	}
+	return false
+}
+
 func addUpdateMutation(schema *ast.Schema, defn *ast.Definition) {
 	if !hasFilterable(defn) {
 		return
@@ -2178,6 +2554,9 @@ func addDeleteMutation(schema *ast.Schema, defn *ast.Definition) {
 func addMutations(schema *ast.Schema, defn *ast.Definition, params *GenerateDirectiveParams) {
 	if params.generateAddMutation {
 		addAddMutation(schema, defn)
+		if params.generateUpsertMutation {
+			addUpsertMutation(schema, defn)
+		}
 	}
 	if params.generateUpdateMutation {
 		addUpdateMutation(schema, defn)
@@ -87,8 +87,14 @@ func (s *schema) Operation(req *Request) (Operation, error) {
 		return nil, gqlErr
 	}
 
+	cost, listErr := complexityCheck(op, vars)
+	if len(listErr) != 0 {
+		return nil, listErr
+	}
+
 	operation := &operation{op: op,
 		vars:                    vars,
+		cost:                    cost,
 		query:                   req.Query,
 		header:                  req.Header,
 		doc:                     doc,
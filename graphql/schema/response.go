@@ -181,7 +181,9 @@ func (r *Response) Output() interface{} {
 // Extensions represents GraphQL extensions
 type Extensions struct {
 	TouchedUids uint64 `json:"touched_uids,omitempty"`
-	Tracing     *Trace `json:"tracing,omitempty"`
+	// Cost is the estimated cost of the operation, which is checked against --graphql max-cost.
+	Cost    uint64 `json:"cost,omitempty"`
+	Tracing *Trace `json:"tracing,omitempty"`
 }
 
 // GetTouchedUids returns TouchedUids
@@ -199,6 +201,7 @@ func (e *Extensions) Merge(ext *Extensions) {
 	}
 
 	e.TouchedUids += ext.TouchedUids
+	e.Cost += ext.Cost
 
 	if e.Tracing == nil {
 		e.Tracing = ext.Tracing
@@ -42,7 +42,7 @@ func init() {
 		remoteTypeValidation, generateDirectiveValidation, apolloKeyValidation,
 		apolloExtendsValidation, lambdaOnMutateValidation)
 	fieldValidations = append(fieldValidations, listValidityCheck, fieldArgumentCheck,
-		fieldNameCheck, isValidFieldForList, hasAuthDirective, fieldDirectiveCheck)
+		fieldNameCheck, isValidFieldForList, fieldDirectiveCheck)
 
 	validator.AddRule("Check variable type is correct", variableTypeCheck)
 	validator.AddRule("Check arguments of cascade directive", directiveArgumentsCheck)
@@ -53,6 +53,8 @@ func init() {
 	// up the order to a high value so that it will be executed last.
 	validator.AddRuleWithOrder("Input Coercion to List", 100, listInputCoercion)
 	validator.AddRule("Check filter functions", filterCheck)
+	validator.AddRule("Check lang argument", langArgumentCheck)
+	validator.AddRule("Check order argument", orderArgumentCheck)
 
 }
 
@@ -717,18 +719,6 @@ func idCountCheck(schema *ast.Schema, typ *ast.Definition) gqlerror.List {
 	return errs
 }
 
-func hasAuthDirective(typ *ast.Definition, field *ast.FieldDefinition) gqlerror.List {
-	for _, directive := range field.Directives {
-		if directive.Name != authDirective {
-			continue
-		}
-		return []*gqlerror.Error{gqlerror.ErrorPosf(field.Position,
-			"Type %s; Field %s: @%s directive is not allowed on fields",
-			typ.Name, field.Name, authDirective)}
-	}
-	return nil
-}
-
 func isValidFieldForList(typ *ast.Definition, field *ast.FieldDefinition) gqlerror.List {
 	if field.Type.Elem == nil && field.Type.NamedType != "" {
 		return nil
@@ -1373,6 +1363,166 @@ func defaultDirectiveValidation(sch *ast.Schema,
 	return nil
 }
 
+// fieldAuthValidation validates @auth on a field. A field can only have query and update rules,
+// which hide its value from the nodes which don't satisfy them, and only let those nodes be
+// updated. So they can only be on nullable scalar fields.
+func fieldAuthValidation(sch *ast.Schema,
+	typ *ast.Definition,
+	field *ast.FieldDefinition,
+	dir *ast.Directive,
+	secrets map[string]x.Sensitive) gqlerror.List {
+	errorf := func(message string, args ...interface{}) gqlerror.List {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(dir.Position,
+			"Type %s; Field %s: "+message, append([]interface{}{typ.Name, field.Name}, args...)...)}
+	}
+
+	if typ.Kind != ast.Object {
+		return errorf("@auth directive can only be used on fields of object types.")
+	}
+	for _, name := range typ.Interfaces {
+		if sch.Types[name].Fields.ForName(field.Name) != nil {
+			return errorf("@auth directive can't be used on fields inherited from interface %s.",
+				name)
+		}
+	}
+	for _, arg := range dir.Arguments {
+		if arg.Name != "query" && arg.Name != "update" {
+			return errorf("@auth directive on fields can only have query and update rules, "+
+				"but found %s.", arg.Name)
+		}
+	}
+	kind := sch.Types[field.Type.Name()].Kind
+	if (kind != ast.Scalar && kind != ast.Enum) || field.Type.Elem != nil ||
+		isIDField(typ, field) || hasCustomOrLambda(field) {
+		return errorf("@auth directive can only be used on fields of scalar and enum types.")
+	}
+	if field.Type.NonNull {
+		return errorf("@auth directive can only be used on nullable fields, as the field is null " +
+			"for the nodes which don't satisfy its query rule.")
+	}
+	return nil
+}
+
+func langDirectiveValidation(sch *ast.Schema,
+	typ *ast.Definition,
+	field *ast.FieldDefinition,
+	dir *ast.Directive,
+	secrets map[string]x.Sensitive) gqlerror.List {
+	if typ.Directives.ForName(remoteDirective) != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @lang directive on a @remote type",
+			typ.Name, field.Name)}
+	}
+	if field.Type.Name() != "String" || field.Type.Elem != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: @lang directive can only be used on fields of type String",
+			typ.Name, field.Name)}
+	}
+	if field.Directives.ForName(idDirective) != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @lang directive on field with @id directive",
+			typ.Name, field.Name)}
+	}
+	if hasCustomOrLambda(field) {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @lang directive on field with @custom or @lambda "+
+				"directive",
+			typ.Name, field.Name)}
+	}
+	if dgraph := field.Directives.ForName(dgraphDirective); dgraph != nil {
+		pred := dgraph.Arguments.ForName(dgraphPredArg)
+		if pred != nil && strings.Contains(pred.Value.Raw, "@") {
+			return []*gqlerror.Error{gqlerror.ErrorPosf(
+				dir.Position,
+				"Type %s; Field %s: cannot use @lang directive on a language tagged predicate",
+				typ.Name, field.Name)}
+		}
+	}
+	return nil
+}
+
+func countDirectiveValidation(sch *ast.Schema,
+	typ *ast.Definition,
+	field *ast.FieldDefinition,
+	dir *ast.Directive,
+	secrets map[string]x.Sensitive) gqlerror.List {
+	if typ.Directives.ForName(remoteDirective) != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @count directive on a @remote type",
+			typ.Name, field.Name)}
+	}
+	if field.Type.Elem == nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: @count directive can only be used on fields of list type",
+			typ.Name, field.Name)}
+	}
+	if hasCustomOrLambda(field) {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @count directive on field with @custom or @lambda "+
+				"directive",
+			typ.Name, field.Name)}
+	}
+	if fld := typ.Fields.ForName(field.Name + "Count"); fld != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @count directive as the type already has a field "+
+				"named %s",
+			typ.Name, field.Name, fld.Name)}
+	}
+	if sch.Types[countFilterName] != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @count directive as %s is a reserved type name "+
+				"for its filter",
+			typ.Name, field.Name, countFilterName)}
+	}
+	return nil
+}
+
+func upsertDirectiveValidation(sch *ast.Schema,
+	typ *ast.Definition,
+	field *ast.FieldDefinition,
+	dir *ast.Directive,
+	secrets map[string]x.Sensitive) gqlerror.List {
+	if typ.Directives.ForName(remoteDirective) != nil {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @upsert directive on a @remote type",
+			typ.Name, field.Name)}
+	}
+	if !isScalar(field.Type.Name()) && sch.Types[field.Type.Name()].Kind != ast.Enum {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @upsert directive on field with non-scalar type %s",
+			typ.Name, field.Name, field.Type.Name())}
+	}
+	if hasCustomOrLambda(field) {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: cannot use @upsert directive on field with @custom or @lambda "+
+				"directive",
+			typ.Name, field.Name)}
+	}
+	// Dgraph needs an index to detect the conflicts, enums are always indexed.
+	if field.Directives.ForName(searchDirective) == nil &&
+		field.Directives.ForName(idDirective) == nil &&
+		sch.Types[field.Type.Name()].Kind != ast.Enum {
+		return []*gqlerror.Error{gqlerror.ErrorPosf(
+			dir.Position,
+			"Type %s; Field %s: @upsert directive needs the field to be indexed, "+
+				"use it along with @search or @id directive",
+			typ.Name, field.Name)}
+	}
+	return nil
+}
+
 func lambdaOnMutateValidation(sch *ast.Schema, typ *ast.Definition) gqlerror.List {
 	dir := typ.Directives.ForName(lambdaOnMutateDirective)
 	if dir == nil {
@@ -1461,6 +1611,26 @@ func generateDirectiveValidation(schema *ast.Schema, typ *ast.Definition) gqlerr
 					"only be true/false, found: `%s",
 				typ.Name, aggregateField.Raw))
 		}
+
+		connectionField := queryArg.Value.Children.ForName(generateConnectionField)
+		if connectionField != nil && connectionField.Kind != ast.BooleanValue {
+			errs = append(errs, gqlerror.ErrorPosf(
+				connectionField.Position,
+				"Type %s; connection field inside query argument of @generate directive can "+
+					"only be true/false, found: `%s",
+				typ.Name, connectionField.Raw))
+		}
+		if connectionField != nil && connectionField.Raw == "true" {
+			for _, name := range []string{typ.Name + "Connection", typ.Name + "Edge", "PageInfo"} {
+				if schema.Types[name] != nil {
+					errs = append(errs, gqlerror.ErrorPosf(
+						connectionField.Position,
+						"Type %s; can't generate a connection query, as the schema already "+
+							"has a type named %s.",
+						typ.Name, name))
+				}
+			}
+		}
 	}
 
 	mutationArg := dir.Arguments.ForName(generateMutationArg)
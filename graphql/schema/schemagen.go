@@ -524,6 +524,7 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 		upsert  string
 		reverse string
 		lang    bool
+		count   bool
 	}
 
 	type field struct {
@@ -622,10 +623,12 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 							forwardEdge := fname[1:]
 							forwardPred := dgPreds[forwardEdge]
 							forwardPred.reverse = "@reverse "
+							forwardPred.count = forwardPred.count || hasCount(f)
 							dgPreds[forwardEdge] = forwardPred
 						} else {
 							pred := dgPreds[fname]
 							pred.typ = typStr
+							pred.count = pred.count || hasCount(f)
 							dgPreds[fname] = pred
 						}
 					}
@@ -656,8 +659,11 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 					}
 
 					id := f.Directives.ForName(idDirective)
-					if id != nil || f.Type.Name() == "ID" {
+					if id != nil || f.Type.Name() == "ID" ||
+						f.Directives.ForName(upsertDirective) != nil {
 						upsertStr = "@upsert "
+					}
+					if id != nil || f.Type.Name() == "ID" {
 						switch f.Type.Name() {
 						case "Int", "Int64":
 							indexes = append(indexes, "int")
@@ -670,12 +676,14 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 
 					if parentInt == nil {
 						// if field name contains @ then it is a language tagged field.
-						isLang := false
+						isLang := hasLang(f)
 						if strings.Contains(fname, "@") {
 							fname = strings.Split(fname, "@")[0]
 							isLang = true
 						}
-						dgPreds[fname] = getUpdatedPred(fname, typStr, upsertStr, indexes, isLang)
+						pred := getUpdatedPred(fname, typStr, upsertStr, indexes, isLang)
+						pred.count = pred.count || hasCount(f)
+						dgPreds[fname] = pred
 					}
 					typ.fields = append(typ.fields, field{fname, parentInt != nil})
 				case ast.Enum:
@@ -689,8 +697,14 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 							indexes = getAllSearchIndexes(arg.Value)
 						}
 					}
+					upsertStr := ""
+					if f.Directives.ForName(upsertDirective) != nil {
+						upsertStr = "@upsert "
+					}
 					if parentInt == nil {
-						dgPreds[fname] = getUpdatedPred(fname, typStr, "", indexes, false)
+						pred := getUpdatedPred(fname, typStr, upsertStr, indexes, false)
+						pred.count = pred.count || hasCount(f)
+						dgPreds[fname] = pred
 					}
 					typ.fields = append(typ.fields, field{fname, parentInt != nil})
 				}
@@ -739,8 +753,12 @@ func genDgSchema(gqlSch *ast.Schema, definitions []string,
 				if f.lang {
 					langStr = " @lang"
 				}
-				fmt.Fprintf(&preds, "%s: %s%s%s %s%s.\n", fld.name, f.typ, indexStr, langStr, f.upsert,
-					f.reverse)
+				countStr := ""
+				if f.count {
+					countStr = " @count"
+				}
+				fmt.Fprintf(&preds, "%s: %s%s%s%s %s%s.\n", fld.name, f.typ, indexStr, langStr,
+					countStr, f.upsert, f.reverse)
 				predWritten[fld.name] = true
 			}
 		}
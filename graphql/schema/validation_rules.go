@@ -19,7 +19,9 @@ package schema
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/dgraph-io/gqlparser/v2/ast"
@@ -29,7 +31,7 @@ import (
 
 var allowedFilters = []string{"StringHashFilter", "StringExactFilter", "StringFullTextFilter",
 	"StringRegExpFilter", "StringTermFilter", "DateTimeFilter", "FloatFilter", "Int64Filter", "IntFilter", "PointGeoFilter",
-	"ContainsFilter", "IntersectsFilter", "PolygonGeoFilter"}
+	"ContainsFilter", "IntersectsFilter", "PolygonGeoFilter", "CountFilter"}
 
 func listInputCoercion(observers *validator.Events, addError validator.AddErrFunc) {
 	observers.OnValue(func(walker *validator.Walker, value *ast.Value) {
@@ -70,6 +72,99 @@ func filterCheck(observers *validator.Events, addError validator.AddErrFunc) {
 	})
 }
 
+// langArgumentCheck checks the lang argument of the fields with @lang directive. It's written
+// as it is into the DQL query, so it can only be a list of language tags separated by `:`.
+func langArgumentCheck(observers *validator.Events, addError validator.AddErrFunc) {
+	observers.OnField(func(walker *validator.Walker, field *ast.Field) {
+		if field.Definition == nil || field.Definition.Directives.ForName(langDirective) == nil {
+			return
+		}
+		lang, _ := field.ArgumentMap(walker.Variables)[langArg].(string)
+		if !isValidLangList(lang) {
+			addError(validator.Message("Invalid value `%s` for argument lang of field `%s`. It "+
+				"must be a language tag like `en`, or a list of them separated by `:`.",
+				lang, field.Name), validator.At(field.Position))
+		}
+	})
+}
+
+// orderArgumentCheck checks the order argument of the fields. An order by the count of the values
+// of a field, like postsCount, is an order by a value variable in DQL, so it can't be combined
+// with other orders, and it can't be used by the connection queries, whose cursors hold the values
+// of the sort keys.
+func orderArgumentCheck(observers *validator.Events, addError validator.AddErrFunc) {
+	observers.OnField(func(walker *validator.Walker, field *ast.Field) {
+		if field.Definition == nil {
+			return
+		}
+		arg := field.Definition.Arguments.ForName("order")
+		if arg == nil {
+			return
+		}
+		// The order of the type T is the input TOrder.
+		defn := walker.Schema.Types[strings.TrimSuffix(arg.Type.Name(), "Order")]
+
+		var orders []string
+		order, _ := field.ArgumentMap(walker.Variables)["order"].(map[string]interface{})
+		for order != nil {
+			if asc, ok := order["asc"].(string); ok {
+				orders = append(orders, asc)
+			} else if desc, ok := order["desc"].(string); ok {
+				orders = append(orders, desc)
+			}
+			order, _ = order["then"].(map[string]interface{})
+		}
+
+		for _, o := range orders {
+			if !isCountOrder(defn, o) {
+				continue
+			}
+			switch {
+			case len(orders) > 1:
+				addError(validator.Message("Can't order by %s along with other fields in %s. "+
+					"An order by a count must be the only order.", o, field.Name),
+					validator.At(field.Position))
+			case field.Definition.Type.Elem == nil &&
+				strings.HasSuffix(field.Definition.Type.Name(), "Connection"):
+				addError(validator.Message("Can't order by %s in the connection query %s.",
+					o, field.Name), validator.At(field.Position))
+			}
+			return
+		}
+	})
+}
+
+// isCountOrder returns true if the value o of the order of the type defn orders by the count of
+// the values of a field, like postsCount.
+func isCountOrder(defn *ast.Definition, o string) bool {
+	if defn == nil || defn.Fields.ForName(o) != nil || !strings.HasSuffix(o, "Count") {
+		return false
+	}
+	return defn.Fields.ForName(strings.TrimSuffix(o, "Count")) != nil
+}
+
+func isValidLangList(langs string) bool {
+	if langs == "" {
+		return true
+	}
+	for _, lang := range strings.Split(langs, ":") {
+		// `.` stands for any language.
+		if lang == "." {
+			continue
+		}
+		if lang == "" {
+			return false
+		}
+		for _, r := range lang {
+			if !(r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+				(r >= '0' && r <= '9')) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func variableTypeCheck(observers *validator.Events, addError validator.AddErrFunc) {
 	observers.OnValue(func(walker *validator.Walker, value *ast.Value) {
 		if value.Definition == nil || value.ExpectedType == nil ||
@@ -193,6 +288,92 @@ func intRangeCheck(observers *validator.Events, addError validator.AddErrFunc) {
 	})
 }
 
+// defaultListBreadth is the number of items that a list field without a first argument is
+// estimated to return, if no max breadth is configured.
+const defaultListBreadth = 100
+
+// complexityCheck estimates the cost of op, as the number of values it can fetch: a field fetches
+// a value for each of the items its parent fetched, and a list field fetches as many items as its
+// first argument allows. It returns the cost, along with the errors for the limits in
+// x.Config.GraphQL that op goes over. Introspection fields are resolved from the schema, so they
+// don't count.
+func complexityCheck(op *ast.OperationDefinition, vars map[string]interface{}) (uint64,
+	gqlerror.List) {
+	limits := x.Config.GraphQL
+	var errs gqlerror.List
+	var depthErr bool
+
+	var selectionCost func(sel ast.SelectionSet, depth int) uint64
+	selectionCost = func(sel ast.SelectionSet, depth int) uint64 {
+		var cost uint64
+		for _, s := range sel {
+			switch s := s.(type) {
+			case *ast.Field:
+				if strings.HasPrefix(s.Name, "__") {
+					continue
+				}
+				if limits.MaxDepth > 0 && depth > limits.MaxDepth && !depthErr {
+					depthErr = true
+					errs = append(errs, gqlerror.ErrorPosf(s.Position,
+						"Field %s is nested %d levels deep, more than the maximum depth of %d.",
+						s.Name, depth, limits.MaxDepth))
+				}
+
+				breadth := uint64(1)
+				if s.Definition != nil && s.Definition.Type.Elem != nil {
+					breadth = defaultListBreadth
+					if limits.MaxBreadth > 0 {
+						breadth = uint64(limits.MaxBreadth)
+					}
+				}
+				if arg := s.ArgumentMap(vars)["first"]; arg != nil {
+					if first, err := strconv.ParseInt(fmt.Sprintf("%v", arg), 10, 64); err == nil &&
+						first >= 0 {
+						if limits.MaxBreadth > 0 && first > int64(limits.MaxBreadth) {
+							errs = append(errs, gqlerror.ErrorPosf(s.Position,
+								"Field %s asks for %d items, more than the maximum breadth of %d.",
+								s.Name, first, limits.MaxBreadth))
+						}
+						breadth = uint64(first)
+					}
+				}
+				cost = addCost(cost, mulCost(breadth, addCost(1, selectionCost(s.SelectionSet,
+					depth+1))))
+			case *ast.InlineFragment:
+				cost = addCost(cost, selectionCost(s.SelectionSet, depth))
+			case *ast.FragmentSpread:
+				if s.Definition != nil {
+					cost = addCost(cost, selectionCost(s.Definition.SelectionSet, depth))
+				}
+			}
+		}
+		return cost
+	}
+
+	cost := selectionCost(op.SelectionSet, 1)
+	if limits.MaxCost > 0 && cost > limits.MaxCost {
+		errs = append(errs, gqlerror.ErrorPosf(op.Position,
+			"The operation has an estimated cost of %d, more than the maximum cost of %d.",
+			cost, limits.MaxCost))
+	}
+	return cost, errs
+}
+
+// addCost and mulCost add and multiply costs, without overflowing.
+func addCost(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+func mulCost(a, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
+
 func valueKindToString(valKind ast.ValueKind) string {
 	switch valKind {
 	case ast.Variable:
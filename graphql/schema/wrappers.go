@@ -18,6 +18,7 @@ package schema
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -89,6 +90,7 @@ type EntityRepresentations struct {
 const (
 	GetQuery             QueryType    = "get"
 	FilterQuery          QueryType    = "query"
+	ConnectionQuery      QueryType    = "connection"
 	AggregateQuery       QueryType    = "aggregate"
 	SchemaQuery          QueryType    = "schema"
 	EntitiesQuery        QueryType    = "entities"
@@ -104,6 +106,7 @@ const (
 	IDType                            = "ID"
 	InputArgName                      = "input"
 	UpsertArgName                     = "upsert"
+	OnConflictArgName                 = "onConflict"
 	FilterArgName                     = "filter"
 )
 
@@ -128,6 +131,11 @@ type Operation interface {
 	IsMutation() bool
 	IsSubscription() bool
 	CacheControl() string
+	// Transactional returns true if the mutations of the operation must all be run in a single
+	// transaction, which is only committed if all of them succeed.
+	Transactional() bool
+	// Cost returns the estimated cost of the operation, as computed during validation.
+	Cost() uint64
 }
 
 // A Field is one field from an Operation.
@@ -226,6 +234,13 @@ type Query interface {
 	// RepresentationsArg returns a parsed version of the `representations` argument for `_entities`
 	// query
 	RepresentationsArg() (*EntityRepresentations, error)
+	// ConnectionArgs returns the page asked for by a connection query: the cursor it starts
+	// after, which is empty for the first page, and the number of nodes in it, which is -1 if the
+	// page goes on till the last node.
+	ConnectionArgs() (after string, first int64, err error)
+	// ConnectionNode returns the node field selected in the edges of a connection query, or nil
+	// if the nodes weren't selected.
+	ConnectionNode() (Field, error)
 	AuthFor(jwtVars map[string]interface{}) Query
 	Schema() Schema
 }
@@ -332,6 +347,7 @@ type operation struct {
 	op     *ast.OperationDefinition
 	vars   map[string]interface{}
 	header http.Header
+	cost   uint64
 	// interfaceImplFragFields stores a mapping from a field collected from a fragment inside an
 	// interface to its typeCondition. It is used during completion to find out if a field should
 	// be included in GraphQL response or not.
@@ -373,7 +389,7 @@ func (s *schema) Queries(t QueryType) []string {
 	}
 	var result []string
 	for _, q := range s.schema.Query.Fields {
-		if queryType(q.Name, s.customDirectives["Query"][q.Name]) == t {
+		if queryType(q.Name, q.Type.Elem != nil, s.customDirectives["Query"][q.Name]) == t {
 			result = append(result, q.Name)
 		}
 	}
@@ -467,6 +483,14 @@ func (o *operation) CacheControl() string {
 	return "public,max-age=" + o.op.Directives.ForName(cacheControlDirective).Arguments[0].Value.Raw
 }
 
+func (o *operation) Transactional() bool {
+	return o.IsMutation() && o.op.Directives.ForName(transactionDirective) != nil
+}
+
+func (o *operation) Cost() uint64 {
+	return o.cost
+}
+
 // parentInterface returns the name of an interface that a field belonging to a type definition
 // typDef inherited from. If there is no such interface, then it returns an empty string.
 //
@@ -650,6 +674,8 @@ func mutatedTypeMapping(s *schema,
 		switch {
 		case strings.HasPrefix(field.Name, "add"):
 			mutatedTypeName = strings.TrimPrefix(field.Name, "add")
+		case strings.HasPrefix(field.Name, "upsert"):
+			mutatedTypeName = strings.TrimPrefix(field.Name, "upsert")
 		case strings.HasPrefix(field.Name, "update"):
 			mutatedTypeName = strings.TrimPrefix(field.Name, "update")
 		case strings.HasPrefix(field.Name, "delete"):
@@ -833,6 +859,14 @@ func hasExternal(f *ast.FieldDefinition) bool {
 	return f.Directives.ForName(apolloExternalDirective) != nil
 }
 
+func hasCount(f *ast.FieldDefinition) bool {
+	return f.Directives.ForName(countDirective) != nil
+}
+
+func hasLang(f *ast.FieldDefinition) bool {
+	return f.Directives.ForName(langDirective) != nil
+}
+
 func isEntityUnion(typ *ast.Definition) bool {
 	return typ.Kind == ast.Union && typ.Name == "_Entity"
 }
@@ -1794,6 +1828,59 @@ func (q *query) RepresentationsArg() (*EntityRepresentations, error) {
 	return entityReprs, nil
 }
 
+// checkConnectionCursor checks that the cursor of a connection query is one returned by Dgraph.
+// The cursors are the ones of DQL, which hold the values of the sort keys of a node along with its
+// uid, so that the next page starts right after that node, even if nodes were added or removed
+// before it. They are opaque to the clients, and only valid for the filter and the order they
+// were returned for.
+func checkConnectionCursor(cursor string) error {
+	if _, err := base64.RawURLEncoding.DecodeString(cursor); err != nil || cursor == "" {
+		return errors.Errorf("invalid cursor %q", cursor)
+	}
+	return nil
+}
+
+func (q *query) ConnectionArgs() (string, int64, error) {
+	var after string
+	if arg := q.ArgValue("after"); arg != nil {
+		after, _ = arg.(string)
+		if err := checkConnectionCursor(after); err != nil {
+			return "", 0, err
+		}
+	}
+
+	first := int64(-1)
+	if arg := q.ArgValue("first"); arg != nil {
+		n, err := strconv.ParseInt(fmt.Sprintf("%v", arg), 10, 64)
+		if err != nil || n < 0 {
+			return "", 0, errors.Errorf("invalid value %v for argument first, it can't be "+
+				"negative", arg)
+		}
+		first = n
+	}
+	return after, first, nil
+}
+
+func (q *query) ConnectionNode() (Field, error) {
+	var node Field
+	for _, edges := range q.SelectionSet() {
+		if edges.Name() != "edges" {
+			continue
+		}
+		for _, f := range edges.SelectionSet() {
+			if f.Name() != "node" {
+				continue
+			}
+			if node != nil {
+				return nil, errors.Errorf("the node of the edges can only be selected once in "+
+					"query %s", q.Name())
+			}
+			node = f
+		}
+	}
+	return node, nil
+}
+
 func (q *query) AuthFor(jwtVars map[string]interface{}) Query {
 	// copy the template, so that multiple queries can run rewriting for the rule.
 	return &query{
@@ -1943,6 +2030,16 @@ func (f *field) ConstructedFor() Type {
 }
 
 func (q *query) ConstructedFor() Type {
+	if q.QueryType() == ConnectionQuery {
+		// Connection queries return a type of the form <SomeTypeName>Connection
+		return &astType{
+			typ: &ast.Type{
+				NamedType: strings.TrimSuffix(q.Type().Name(), "Connection"),
+			},
+			inSchema:        q.op.inSchema,
+			dgraphPredicate: q.op.inSchema.dgraphPredicate,
+		}
+	}
 	if q.QueryType() != AggregateQuery {
 		return q.Type()
 	}
@@ -2020,7 +2117,8 @@ func (f *field) DgraphPredicateForAggregateField() string {
 }
 
 func (q *query) QueryType() QueryType {
-	return queryType(q.Name(), q.op.inSchema.customDirectives["Query"][q.Name()])
+	return queryType(q.Name(), q.Type().ListType() != nil,
+		q.op.inSchema.customDirectives["Query"][q.Name()])
 }
 
 func (q *query) DQLQuery() string {
@@ -2032,7 +2130,7 @@ func (q *query) DQLQuery() string {
 	return ""
 }
 
-func queryType(name string, custom *ast.Directive) QueryType {
+func queryType(name string, list bool, custom *ast.Directive) QueryType {
 	switch {
 	case custom != nil:
 		if custom.Arguments.ForName(dqlArg) != nil {
@@ -2045,6 +2143,9 @@ func queryType(name string, custom *ast.Directive) QueryType {
 		return GetQuery
 	case name == "__schema" || name == "__type" || name == "__typename":
 		return SchemaQuery
+	case strings.HasPrefix(name, "query") && !list:
+		// Filter queries return a list of nodes, and connection queries a page of them.
+		return ConnectionQuery
 	case strings.HasPrefix(name, "query"):
 		return FilterQuery
 	case strings.HasPrefix(name, "check"):
@@ -2233,6 +2334,9 @@ func mutationType(name string, custom *ast.Directive) MutationType {
 		return HTTPMutation
 	case strings.HasPrefix(name, "add"):
 		return AddMutation
+	case strings.HasPrefix(name, "upsert"):
+		// upsertT is an addT which merges the input into the existing nodes on conflicts.
+		return AddMutation
 	case strings.HasPrefix(name, "update"):
 		return UpdateMutation
 	case strings.HasPrefix(name, "delete"):
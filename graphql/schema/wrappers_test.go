@@ -1352,3 +1352,82 @@ func TestParseSecrets(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionCursor(t *testing.T) {
+	require.NoError(t, checkConnectionCursor("eyJ1IjoxMDAwMn0"))
+	for _, cursor := range []string{"", "eyJ1IjoxMDAwMn0=", "a+b/", `x") { secret }`} {
+		require.Error(t, checkConnectionCursor(cursor), cursor)
+	}
+}
+
+func TestOperationComplexity(t *testing.T) {
+	schemaStr := `
+	type Author {
+		id: ID!
+		name: String
+		posts: [Post]
+	}
+
+	type Post {
+		id: ID!
+		title: String
+	}`
+	schHandler, errs := NewHandler(schemaStr, false)
+	require.NoError(t, errs)
+	sch, err := FromString(schHandler.GQLSchema(), x.GalaxyNamespace)
+	require.NoError(t, err)
+
+	nested := `query { queryAuthor(first: 10) { name posts(first: 5) { title __typename } } }`
+	tcases := []struct {
+		name   string
+		query  string
+		limits x.GraphQLOptions
+		cost   uint64
+		err    string
+	}{
+		{
+			name:  "no limits",
+			query: nested,
+			cost:  120,
+		},
+		{
+			name:   "list without first",
+			query:  `query { queryAuthor { name } }`,
+			limits: x.GraphQLOptions{MaxBreadth: 20},
+			cost:   40,
+		},
+		{
+			name:   "max depth",
+			query:  nested,
+			limits: x.GraphQLOptions{MaxDepth: 2},
+			err:    "Field title is nested 3 levels deep, more than the maximum depth of 2.",
+		},
+		{
+			name:   "max breadth",
+			query:  nested,
+			limits: x.GraphQLOptions{MaxBreadth: 5},
+			err:    "Field queryAuthor asks for 10 items, more than the maximum breadth of 5.",
+		},
+		{
+			name:   "max cost",
+			query:  nested,
+			limits: x.GraphQLOptions{MaxCost: 100},
+			err:    "The operation has an estimated cost of 120, more than the maximum cost of 100.",
+		},
+	}
+
+	defer func(opts x.GraphQLOptions) { x.Config.GraphQL = opts }(x.Config.GraphQL)
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			x.Config.GraphQL = tcase.limits
+			op, err := sch.Operation(&Request{Query: tcase.query})
+			if tcase.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.cost, op.Cost())
+		})
+	}
+}
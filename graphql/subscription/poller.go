@@ -29,12 +29,14 @@ import (
 
 	"github.com/dgraph-io/dgraph/graphql/resolve"
 	"github.com/dgraph-io/dgraph/graphql/schema"
+	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/dgryski/go-farm"
 	"github.com/golang/glog"
 )
 
-// Poller is used to poll user subscription query.
+// Poller is used to poll user subscription query. The query of a subscription is only run again
+// when a commit writes one of the predicates it reads.
 type Poller struct {
 	sync.RWMutex
 	resolver       *resolve.RequestResolver
@@ -75,6 +77,10 @@ func (p *Poller) AddSubscriber(req *schema.Request) (*SubscriberResponse, error)
 	if err := resolver.ValidateSubscription(req); err != nil {
 		return nil, err
 	}
+	op, err := resolver.Schema().Operation(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// find out the custom claims for auth, if any. As,
 	// We also need to use authVariables in generating the hashed bucketID
@@ -110,9 +116,12 @@ func (p *Poller) AddSubscriber(req *schema.Request) (*SubscriberResponse, error)
 	p.Lock()
 	defer p.Unlock()
 
+	// The commits are watched before the query runs, so that none is missed between the two.
+	watcher := worker.WatchCommits()
 	res := resolver.Resolve(x.AttachAccessJwt(context.Background(),
 		&http.Request{Header: req.Header}), req)
 	if len(res.Errors) != 0 {
+		watcher.Stop()
 		return nil, res.Errors
 	}
 
@@ -137,6 +146,7 @@ func (p *Poller) AddSubscriber(req *schema.Request) (*SubscriberResponse, error)
 	if ok {
 		// Already there is a running go routine for this bucket. So,no need to poll the server.
 		// We can use the existing polling routine to publish the update.
+		watcher.Stop()
 		return &SubscriberResponse{
 			BucketID:       bucketID,
 			SubscriptionID: subscriptionID,
@@ -152,6 +162,8 @@ func (p *Poller) AddSubscriber(req *schema.Request) (*SubscriberResponse, error)
 		graphqlReq:    req,
 		authVariables: customClaims.AuthVariables,
 		localEpoch:    localEpoch,
+		preds:         resolve.ReadPredicates(op),
+		watcher:       watcher,
 	}
 	go p.poll(pollR)
 
@@ -168,6 +180,23 @@ type pollRequest struct {
 	bucketID      uint64
 	localEpoch    uint64
 	authVariables map[string]interface{}
+	// preds are the predicates read by the subscription. It's nil if they aren't known, then
+	// the subscription runs again after every commit.
+	preds   map[string]struct{}
+	watcher *worker.CommitWatcher
+}
+
+// readsAny returns whether the subscription reads any of the given namespaced predicates.
+func (req *pollRequest) readsAny(preds map[string]struct{}) bool {
+	if req.preds == nil {
+		return len(preds) > 0
+	}
+	for pred := range preds {
+		if _, ok := req.preds[x.ParseAttr(pred)]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Poller) poll(req *pollRequest) {
@@ -175,10 +204,21 @@ func (p *Poller) poll(req *pollRequest) {
 	resolver := p.resolver
 	p.RUnlock()
 
-	pollID := uint64(0)
+	defer req.watcher.Stop()
+	// The ticker is only used to check the schema epoch and the subscribers. The query runs
+	// again when a commit touches the predicates it reads.
+	ticker := time.NewTicker(x.Config.GraphQL.PollInterval)
+	defer ticker.Stop()
+
 	for {
-		pollID++
-		time.Sleep(x.Config.GraphQL.PollInterval)
+		var changed bool
+		select {
+		case <-req.watcher.C():
+			if changed = req.readsAny(req.watcher.Take()); !changed {
+				continue
+			}
+		case <-ticker.C:
+		}
 
 		globalEpoch := atomic.LoadUint64(p.globalEpoch)
 		if req.localEpoch != globalEpoch || globalEpoch == math.MaxUint64 {
@@ -186,20 +226,12 @@ func (p *Poller) poll(req *pollRequest) {
 			// We'll terminate all the subscription for this bucket. So, that all client can
 			// reconnect and listen for new schema.
 			p.terminateSubscriptions(req.bucketID)
+			return
 		}
 
-		ctx := x.AttachAccessJwt(context.Background(), &http.Request{Header: req.graphqlReq.Header})
-		res := resolver.Resolve(ctx, req.graphqlReq)
-
-		currentHash := farm.Fingerprint64(res.Data.Bytes())
-
-		if req.prevHash == currentHash {
-			if pollID%2 != 0 {
-				// Don't update if there is no change in response.
-				continue
-			}
-			// Every second poll, we'll check if there is any active subscription for the
-			// current goroutine. If not we'll terminate this poll.
+		if !changed {
+			// Check if there is any active subscription for the current goroutine. If not
+			// we'll terminate this poll.
 			p.Lock()
 			subscribers, ok := p.pollRegistry[req.bucketID]
 			if !ok || len(subscribers) == 0 {
@@ -216,6 +248,15 @@ func (p *Poller) poll(req *pollRequest) {
 			p.Unlock()
 			continue
 		}
+
+		ctx := x.AttachAccessJwt(context.Background(), &http.Request{Header: req.graphqlReq.Header})
+		res := resolver.Resolve(ctx, req.graphqlReq)
+
+		currentHash := farm.Fingerprint64(res.Data.Bytes())
+		if req.prevHash == currentHash {
+			// Don't update if there is no change in response.
+			continue
+		}
 		req.prevHash = currentHash
 
 		p.Lock()
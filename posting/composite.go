@@ -0,0 +1,227 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/dgryski/go-farm"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A composite index maps the combined values of a list of predicates to the nodes that have
+// them. It's owned by the first predicate of the list, among whose index keys it's stored, and
+// only covers the untagged value of each predicate. The entry of a node depends on the values
+// of several predicates, so it can't be maintained edge by edge like the other indexes.
+// Instead, the entries of the nodes touched by a batch of mutations are read before the batch
+// is applied and replaced by their new versions afterwards.
+
+// CompositeIndexUpdate maintains the composite indexes for a batch of mutations.
+type CompositeIndexUpdate struct {
+	txn     *Txn
+	entries []*compositeIndexEntry
+}
+
+type compositeIndexEntry struct {
+	preds []string
+	uid   uint64
+	// key is the index key of the node before the mutations, or nil if it had no entry.
+	key []byte
+}
+
+// NewCompositeIndexUpdate reads the composite index entries of the nodes touched by the edges,
+// before they are applied. Done must be called once they have been.
+func (txn *Txn) NewCompositeIndexUpdate(ctx context.Context,
+	edges []*pb.DirectedEdge) (*CompositeIndexUpdate, error) {
+	ctx = schema.GetWriteContext(ctx)
+	update := &CompositeIndexUpdate{txn: txn}
+	type entryID struct {
+		preds string
+		uid   uint64
+	}
+	seen := make(map[entryID]struct{})
+	for _, edge := range edges {
+		for _, preds := range schema.State().CompositeIndexes(ctx, edge.Attr) {
+			id := entryID{preds: strings.Join(preds, ","), uid: edge.Entity}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+
+			key, err := txn.compositeIndexKey(preds, edge.Entity)
+			if err != nil {
+				return nil, err
+			}
+			update.entries = append(update.entries,
+				&compositeIndexEntry{preds: preds, uid: edge.Entity, key: key})
+		}
+	}
+	return update, nil
+}
+
+// Done moves the nodes whose values changed to their new composite index entries.
+func (u *CompositeIndexUpdate) Done(ctx context.Context) error {
+	ctx = schema.GetWriteContext(ctx)
+	for _, entry := range u.entries {
+		// The entry depends on values that other transactions can change without touching any
+		// of the keys written here, so the transactions must conflict on all of them.
+		for _, pred := range entry.preds {
+			u.txn.addConflictKey(farm.Fingerprint64(x.DataKey(pred, entry.uid)))
+		}
+
+		key, err := u.txn.compositeIndexKey(entry.preds, entry.uid)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(key, entry.key) {
+			continue
+		}
+		if entry.key != nil {
+			if err := u.txn.addCompositeIndexMutation(ctx, entry.key, entry.preds[0], entry.uid,
+				pb.DirectedEdge_DEL); err != nil {
+				return err
+			}
+		}
+		if key != nil {
+			if err := u.txn.addCompositeIndexMutation(ctx, key, entry.preds[0], entry.uid,
+				pb.DirectedEdge_SET); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compositeIndexKey returns the key of the composite index entry of the node, or nil if the
+// node doesn't have a value for all the predicates in the index.
+func (txn *Txn) compositeIndexKey(preds []string, uid uint64) ([]byte, error) {
+	vals := make([][]byte, 0, len(preds))
+	for _, pred := range preds {
+		pl, err := txn.Get(x.DataKey(pred, uid))
+		if err != nil {
+			return nil, err
+		}
+		val, err := pl.Value(txn.StartTs)
+		switch {
+		case err == ErrNoValue:
+			return nil, nil
+		case err != nil:
+			return nil, err
+		}
+		data, ok := val.Value.([]byte)
+		if !ok {
+			return nil, errors.Errorf("Invalid value %v of predicate %s for composite index",
+				val.Value, x.ParseAttr(pred))
+		}
+		vals = append(vals, data)
+	}
+	return x.CompositeIndexKey(preds[0], tok.IdentComposite, vals), nil
+}
+
+func (txn *Txn) addCompositeIndexMutation(ctx context.Context, key []byte, attr string,
+	uid uint64, op pb.DirectedEdge_Op) error {
+	plist, err := txn.cache.GetFromDelta(key)
+	if err != nil {
+		return err
+	}
+	return plist.addMutation(ctx, txn, &pb.DirectedEdge{ValueId: uid, Attr: attr, Op: op})
+}
+
+// compositeIndexes returns the composite indexes owned by the predicate of the schema.
+func compositeIndexes(su *pb.SchemaUpdate) [][]string {
+	ns := x.ParseNamespace(su.Predicate)
+	var out [][]string
+	for _, composite := range su.Composite {
+		preds := x.NamespaceAttrList(ns, strings.Split(composite, ","))
+		out = append(out, append([]string{su.Predicate}, preds...))
+	}
+	return out
+}
+
+func (rb *IndexRebuild) needsCompositeIndexRebuild() indexOp {
+	x.AssertTruef(rb.CurrentSchema != nil, "Current schema cannot be nil.")
+
+	// If the old schema is nil, treat it as an empty schema. Copy it to avoid
+	// overwriting it in rb.
+	old := rb.OldSchema
+	if old == nil {
+		old = &pb.SchemaUpdate{}
+	}
+
+	curr := rb.CurrentSchema.Composite
+	switch {
+	case len(curr) == 0 && len(old.Composite) == 0:
+		return indexNoop
+	case len(curr) == 0:
+		return indexDelete
+	case rb.CurrentSchema.ValueType == old.ValueType &&
+		strings.Join(curr, " ") == strings.Join(old.Composite, " "):
+		return indexNoop
+	}
+	return indexRebuild
+}
+
+func prefixesToDropCompositeIndex(ctx context.Context, rb *IndexRebuild) [][]byte {
+	if rb.needsCompositeIndexRebuild() == indexNoop {
+		return nil
+	}
+
+	pk := x.ParsedKey{Attr: rb.Attr}
+	prefix := append(pk.IndexPrefix(), tok.IdentComposite)
+	// All the parts of any list that has been split into multiple parts.
+	splitPrefix := pk.IndexPrefix()
+	splitPrefix[0] = x.ByteSplit
+	splitPrefix = append(splitPrefix, tok.IdentComposite)
+	return [][]byte{prefix, splitPrefix}
+}
+
+// rebuildCompositeIndex rebuilds the composite indexes owned by the predicate.
+func rebuildCompositeIndex(ctx context.Context, rb *IndexRebuild) error {
+	if rb.needsCompositeIndexRebuild() != indexRebuild {
+		return nil
+	}
+
+	glog.Infof("Rebuilding composite index for attr %s", rb.Attr)
+	composites := compositeIndexes(rb.CurrentSchema)
+	pk := x.ParsedKey{Attr: rb.Attr}
+	builder := rebuilder{attr: rb.Attr, prefix: pk.DataPrefix(), startTs: rb.StartTs}
+	builder.fn = func(uid uint64, pl *List, txn *Txn) error {
+		for _, preds := range composites {
+			key, err := txn.compositeIndexKey(preds, uid)
+			if err != nil {
+				return err
+			}
+			if key == nil {
+				continue
+			}
+			if err := txn.addCompositeIndexMutation(ctx, key, rb.Attr, uid,
+				pb.DirectedEdge_SET); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return builder.Run(ctx)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package posting
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3/y"
+	"github.com/dgryski/go-farm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const compositeSchema = `
+country: string @index(exact) .
+status: string .
+index composite(country, status)
+`
+
+func compositeEdge(attr string, uid uint64, val string, op pb.DirectedEdge_Op) *pb.DirectedEdge {
+	return &pb.DirectedEdge{Attr: x.GalaxyAttr(attr), Entity: uid, Value: []byte(val), Op: op}
+}
+
+// applyEdges applies the edges in a txn, the way the mutations of a proposal are, and commits it.
+// It returns the txn.
+func applyEdges(t *testing.T, edges []*pb.DirectedEdge, startTs, commitTs uint64) *Txn {
+	ctx := context.Background()
+	txn, _ := Oracle().RegisterStartTs(startTs)
+	update, err := txn.NewCompositeIndexUpdate(ctx, edges)
+	require.NoError(t, err)
+	for _, edge := range edges {
+		l, err := txn.Get(x.DataKey(edge.Attr, edge.Entity))
+		require.NoError(t, err)
+		require.NoError(t, l.AddMutationWithIndex(ctx, edge, txn))
+	}
+	require.NoError(t, update.Done(ctx))
+
+	txn.Update(ctx)
+	sl := txn.Skiplist()
+	itr := sl.NewUniIterator(false)
+	itr.Rewind()
+	for itr.Valid() {
+		y.SetKeyTs(itr.Key(), commitTs)
+		itr.Next()
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	require.NoError(t, pstore.HandoverSkiplist(sl, wg.Done))
+	wg.Wait()
+	return txn
+}
+
+// compositeUids returns the nodes in the entry of the country and status.
+func compositeUids(t *testing.T, country, status string, readTs uint64) []uint64 {
+	key := x.CompositeIndexKey(x.GalaxyAttr("country"), tok.IdentComposite,
+		[][]byte{[]byte(country), []byte(status)})
+	l, err := GetNoStore(key, readTs)
+	require.NoError(t, err)
+	return uids(l, readTs)
+}
+
+func TestCompositeIndexMaintenance(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(compositeSchema), 1))
+	set, del := pb.DirectedEdge_SET, pb.DirectedEdge_DEL
+
+	// Both values are set in the same txn.
+	applyEdges(t, []*pb.DirectedEdge{
+		compositeEdge("country", 1, "in", set),
+		compositeEdge("status", 1, "active", set),
+	}, 8001, 8002)
+	// The node doesn't get an entry until it has a value for both predicates.
+	applyEdges(t, []*pb.DirectedEdge{compositeEdge("country", 2, "in", set)}, 8003, 8004)
+	require.Equal(t, []uint64{1}, compositeUids(t, "in", "active", 8005))
+
+	// The value of the other predicate is read from the store.
+	txn := applyEdges(t, []*pb.DirectedEdge{compositeEdge("status", 2, "active", set)},
+		8005, 8006)
+	require.Equal(t, []uint64{1, 2}, compositeUids(t, "in", "active", 8007))
+	// The txn conflicts with the ones changing the other predicate of the node.
+	require.Contains(t, txn.conflicts,
+		farm.Fingerprint64(x.DataKey(x.GalaxyAttr("country"), 2)))
+
+	// Changing a value moves the node to its new entry.
+	applyEdges(t, []*pb.DirectedEdge{compositeEdge("status", 1, "inactive", set)}, 8007, 8008)
+	require.Equal(t, []uint64{2}, compositeUids(t, "in", "active", 8009))
+	require.Equal(t, []uint64{1}, compositeUids(t, "in", "inactive", 8009))
+
+	// Deleting a value removes the node from the index.
+	applyEdges(t, []*pb.DirectedEdge{compositeEdge("country", 2, "in", del)}, 8009, 8010)
+	require.Empty(t, compositeUids(t, "in", "active", 8011))
+	require.Equal(t, []uint64{1}, compositeUids(t, "in", "inactive", 8011))
+}
+
+func TestRebuildCompositeIndex(t *testing.T) {
+	// The data is added without the index, which is then built from it.
+	addEdgeToValue(t, x.GalaxyAttr("country"), 11, "fr", 8101, 8102)
+	addEdgeToValue(t, x.GalaxyAttr("status"), 11, "active", 8103, 8104)
+	addEdgeToValue(t, x.GalaxyAttr("country"), 12, "fr", 8105, 8106)
+
+	require.NoError(t, schema.ParseBytes([]byte(compositeSchema), 1))
+	currentSchema, _ := schema.State().Get(context.Background(), x.GalaxyAttr("country"))
+	rb := IndexRebuild{
+		Attr:          x.GalaxyAttr("country"),
+		StartTs:       8107,
+		CurrentSchema: &currentSchema,
+	}
+	require.Equal(t, indexOp(indexRebuild), rb.needsCompositeIndexRebuild())
+	require.NoError(t, rebuildCompositeIndex(context.Background(), &rb))
+	require.Equal(t, []uint64{11}, compositeUids(t, "fr", "active", 8108))
+
+	// The index isn't rebuilt if it didn't change.
+	rb.OldSchema = &currentSchema
+	require.Equal(t, indexOp(indexNoop), rb.needsCompositeIndexRebuild())
+}
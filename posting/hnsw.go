@@ -0,0 +1,681 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgryski/go-farm"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Vector indexes are hierarchical navigable small world graphs (https://arxiv.org/abs/1603.09320).
+// Every node is given a level and is linked to its closest neighbours in all the layers up to
+// its level, the upper layers being exponentially sparser. A search starts from the entry point
+// in the top layer and greedily walks towards the query, going down a layer every time it can't
+// get any closer.
+//
+// The graph is kept in index posting lists of the predicate, so that it is versioned and
+// replicated like any other index:
+//
+//	<IdentVector> 'e'               -> the entry point, a node in the top layer of the graph
+//	<IdentVector> 'n' <level> <uid> -> the neighbours of the node in the layer
+//
+// The level of a node is derived from its uid, so it doesn't need to be stored.
+//
+// Concurrent transactions read the graph at their own StartTs, so every key written by an update
+// of the graph is also a conflict key. Two transactions relinking the same nodes can't both
+// commit, and the one which aborts is retried on the graph left by the other one. The entry point
+// only changes when a node is inserted above the top layer, which gets exponentially rarer as the
+// graph grows, so inserts otherwise only conflict when they link to the same nodes.
+const (
+	// hnswM is the number of neighbours a node is linked to in every layer when it's inserted.
+	// Nodes can end up with up to hnswM neighbours in the upper layers and twice as many in the
+	// bottom one as later nodes link to them.
+	hnswM = 16
+	// hnswEfConstruction is the number of candidates considered for the neighbours of a node.
+	hnswEfConstruction = 100
+	// hnswEfSearch is the min number of candidates considered by a search.
+	hnswEfSearch = 64
+	// hnswMaxLevels is the number of layers of the graph.
+	hnswMaxLevels = 16
+)
+
+var hnswLevelMult = 1 / math.Log(hnswM)
+
+// hnswLock serializes the updates to the graph of the predicate made by the txn, as an insert
+// first reads the parts of the graph it then links the node to, and the edges of a mutation are
+// applied concurrently.
+func (txn *Txn) hnswLock(attr string) *sync.Mutex {
+	mu, _ := txn.hnswLocks.LoadOrStore(attr, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func hnswEntryKey(attr string) []byte {
+	return x.IndexKey(attr, string([]byte{tok.IdentVector, 'e'}))
+}
+
+func hnswNeighboursKey(attr string, level int, uid uint64) []byte {
+	token := make([]byte, 11)
+	token[0], token[1], token[2] = tok.IdentVector, 'n', byte(level)
+	binary.BigEndian.PutUint64(token[3:], uid)
+	return x.IndexKey(attr, string(token))
+}
+
+// hnswLevel returns the top layer of the node. The levels follow an exponentially decaying
+// distribution, so that every layer has about hnswM times fewer nodes than the one below.
+func hnswLevel(uid uint64) int {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uid)
+	// u is uniformly distributed in (0, 1].
+	u := float64(farm.Fingerprint64(buf[:])>>11+1) / (1 << 53)
+	level := int(-math.Log(u) * hnswLevelMult)
+	if level >= hnswMaxLevels {
+		level = hnswMaxLevels - 1
+	}
+	return level
+}
+
+type hnswCandidate struct {
+	uid  uint64
+	dist float64
+}
+
+// insertCandidate adds the candidate to the list, which is sorted by distance.
+func insertCandidate(list []hnswCandidate, c hnswCandidate) []hnswCandidate {
+	i := sort.Search(len(list), func(i int) bool { return list[i].dist > c.dist })
+	list = append(list, hnswCandidate{})
+	copy(list[i+1:], list[i:])
+	list[i] = c
+	return list
+}
+
+// hnswGraph reads the graph of a predicate at readTs. Updating the graph requires a txn, whose
+// cache is then used for reads so that they see the updates made so far.
+type hnswGraph struct {
+	attr   string
+	readTs uint64
+	cache  *LocalCache
+	txn    *Txn
+	vecs   map[uint64][]float32
+}
+
+func newHNSWGraph(attr string, cache *LocalCache, readTs uint64) *hnswGraph {
+	return &hnswGraph{
+		attr:   attr,
+		readTs: readTs,
+		cache:  cache,
+		vecs:   make(map[uint64][]float32),
+	}
+}
+
+func newHNSWGraphForTxn(attr string, txn *Txn) *hnswGraph {
+	g := newHNSWGraph(attr, txn.cache, txn.StartTs)
+	g.txn = txn
+	return g
+}
+
+// vector returns the vector of the node, or nil if it doesn't have one.
+func (g *hnswGraph) vector(uid uint64) ([]float32, error) {
+	if vec, ok := g.vecs[uid]; ok {
+		return vec, nil
+	}
+	pl, err := g.cache.Get(x.DataKey(g.attr, uid))
+	if err != nil {
+		return nil, err
+	}
+	var vec []float32
+	val, err := pl.Value(g.readTs)
+	switch {
+	case err == ErrNoValue:
+	case err != nil:
+		return nil, err
+	default:
+		v, err := types.Convert(val, types.VFloatID)
+		if err != nil {
+			return nil, err
+		}
+		vec = v.Value.([]float32)
+	}
+	g.vecs[uid] = vec
+	return vec, nil
+}
+
+func (g *hnswGraph) uids(key []byte) ([]uint64, error) {
+	pl, err := g.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := pl.Bitmap(ListOptions{ReadTs: g.readTs})
+	if err != nil {
+		return nil, err
+	}
+	return bm.ToArray(), nil
+}
+
+func (g *hnswGraph) neighbours(level int, uid uint64) ([]uint64, error) {
+	return g.uids(hnswNeighboursKey(g.attr, level, uid))
+}
+
+// entry returns the entry point of the graph, which is a node in its top layer, along with the
+// level of the layer. The level is -1 if the graph is empty.
+func (g *hnswGraph) entry() (uint64, []float32, int, error) {
+	uids, err := g.uids(hnswEntryKey(g.attr))
+	if err != nil || len(uids) == 0 {
+		return 0, nil, -1, err
+	}
+	vec, err := g.vector(uids[0])
+	if err != nil || vec == nil {
+		return 0, nil, -1, err
+	}
+	return uids[0], vec, hnswLevel(uids[0]), nil
+}
+
+// setEntry makes the node the entry point of the graph, in place of the current one if any. The
+// graph has no entry point if uid is 0.
+func (g *hnswGraph) setEntry(ctx context.Context, uid uint64) error {
+	key := hnswEntryKey(g.attr)
+	cur, err := g.uids(key)
+	if err != nil {
+		return err
+	}
+	for _, c := range cur {
+		if err := g.update(ctx, key, c, pb.DirectedEdge_DEL); err != nil {
+			return err
+		}
+	}
+	if uid == 0 {
+		return nil
+	}
+	return g.update(ctx, key, uid, pb.DirectedEdge_SET)
+}
+
+// searchLayer returns the (up to) ef nodes of the layer closest to the query that are found by
+// walking from the entry points, closest first.
+func (g *hnswGraph) searchLayer(ctx context.Context, query []float32, entries []hnswCandidate,
+	ef, level int) ([]hnswCandidate, error) {
+	visited := make(map[uint64]struct{})
+	var candidates, results []hnswCandidate
+	for _, e := range entries {
+		visited[e.uid] = struct{}{}
+		candidates = insertCandidate(candidates, e)
+		results = insertCandidate(results, e)
+	}
+	if len(results) > ef {
+		results = results[:ef]
+	}
+
+	for len(candidates) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c := candidates[0]
+		candidates = candidates[1:]
+		if len(results) >= ef && c.dist > results[len(results)-1].dist {
+			// All the remaining candidates are farther than the results.
+			break
+		}
+		nbrs, err := g.neighbours(level, c.uid)
+		if err != nil {
+			return nil, err
+		}
+		for _, uid := range nbrs {
+			if _, ok := visited[uid]; ok {
+				continue
+			}
+			visited[uid] = struct{}{}
+			vec, err := g.vector(uid)
+			if err != nil {
+				return nil, err
+			}
+			if vec == nil {
+				// The value was deleted after the node was linked to.
+				continue
+			}
+			dist, err := types.EuclideanDistanceSq(query, vec)
+			if err != nil {
+				return nil, err
+			}
+			if len(results) < ef || dist < results[len(results)-1].dist {
+				n := hnswCandidate{uid: uid, dist: dist}
+				candidates = insertCandidate(candidates, n)
+				results = insertCandidate(results, n)
+				if len(results) > ef {
+					results = results[:ef]
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// descend walks from the entry point of the graph down to the given layer, returning the
+// closest node to the query found in it. It returns nil if the graph is empty.
+func (g *hnswGraph) descend(ctx context.Context, query []float32, level int) (
+	[]hnswCandidate, int, error) {
+	ep, vec, top, err := g.entry()
+	if err != nil || top < 0 {
+		return nil, top, err
+	}
+	dist, err := types.EuclideanDistanceSq(query, vec)
+	if err != nil {
+		return nil, top, err
+	}
+	entries := []hnswCandidate{{uid: ep, dist: dist}}
+	for l := top; l > level; l-- {
+		if entries, err = g.searchLayer(ctx, query, entries, 1, l); err != nil {
+			return nil, top, err
+		}
+	}
+	return entries, top, nil
+}
+
+func (g *hnswGraph) update(ctx context.Context, key []byte, uid uint64,
+	op pb.DirectedEdge_Op) error {
+	// We use Get and not GetFromDelta, as the graph is read back while it's being updated.
+	pl, err := g.txn.cache.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := pl.addMutation(ctx, g.txn, &pb.DirectedEdge{ValueId: uid, Attr: g.attr,
+		Op: op}); err != nil {
+		return err
+	}
+	g.txn.addConflictKey(farm.Fingerprint64(key))
+	return nil
+}
+
+func (g *hnswGraph) link(ctx context.Context, level int, from, to uint64) error {
+	return g.update(ctx, hnswNeighboursKey(g.attr, level, from), to, pb.DirectedEdge_SET)
+}
+
+// prune unlinks the farthest neighbours of the node in the layer if it has too many.
+func (g *hnswGraph) prune(ctx context.Context, level int, uid uint64) error {
+	max := hnswM
+	if level == 0 {
+		max = 2 * hnswM
+	}
+	nbrs, err := g.neighbours(level, uid)
+	if err != nil || len(nbrs) <= max {
+		return err
+	}
+	vec, err := g.vector(uid)
+	if err != nil || vec == nil {
+		return err
+	}
+	cands := make([]hnswCandidate, 0, len(nbrs))
+	for _, n := range nbrs {
+		nvec, err := g.vector(n)
+		if err != nil {
+			return err
+		}
+		dist := math.Inf(1)
+		if nvec != nil {
+			if dist, err = types.EuclideanDistanceSq(vec, nvec); err != nil {
+				return err
+			}
+		}
+		cands = append(cands, hnswCandidate{uid: n, dist: dist})
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	key := hnswNeighboursKey(g.attr, level, uid)
+	for _, c := range cands[max:] {
+		if err := g.update(ctx, key, c.uid, pb.DirectedEdge_DEL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert adds the node with the given vector to the graph.
+func (g *hnswGraph) insert(ctx context.Context, uid uint64, vec []float32) error {
+	g.vecs[uid] = vec
+	level := hnswLevel(uid)
+	entries, top, err := g.descend(ctx, vec, level)
+	if err != nil {
+		return err
+	}
+	if top < level {
+		// The node is above the top layer, so it becomes the entry point.
+		if err := g.setEntry(ctx, uid); err != nil {
+			return err
+		}
+	}
+	if top > level {
+		top = level
+	}
+	for l := top; l >= 0; l-- {
+		cands, err := g.searchLayer(ctx, vec, entries, hnswEfConstruction, l)
+		if err != nil {
+			return err
+		}
+		var linked int
+		for _, c := range cands {
+			if linked == hnswM {
+				break
+			}
+			if c.uid == uid {
+				continue
+			}
+			if err := g.link(ctx, l, uid, c.uid); err != nil {
+				return err
+			}
+			if err := g.link(ctx, l, c.uid, uid); err != nil {
+				return err
+			}
+			if err := g.prune(ctx, l, c.uid); err != nil {
+				return err
+			}
+			linked++
+		}
+		entries = cands
+	}
+	return nil
+}
+
+// remove takes the node out of the graph. Its neighbours are linked to the closest of the
+// others, so that the graph stays connected around it. If the node is the entry point, the
+// neighbour with the highest level replaces it, or any other node of the graph if none of the
+// neighbours is left.
+func (g *hnswGraph) remove(ctx context.Context, uid uint64) error {
+	// The entry point is read directly, as the value of the node may already be deleted.
+	ep, err := g.uids(hnswEntryKey(g.attr))
+	if err != nil {
+		return err
+	}
+	delete(g.vecs, uid)
+	var next uint64
+	for l := 0; l <= hnswLevel(uid); l++ {
+		nbrs, err := g.neighbours(l, uid)
+		if err != nil {
+			return err
+		}
+		for _, n := range nbrs {
+			if next != 0 && hnswLevel(n) <= hnswLevel(next) {
+				continue
+			}
+			if vec, err := g.vector(n); err != nil {
+				return err
+			} else if vec != nil {
+				next = n
+			}
+		}
+		for _, n := range nbrs {
+			if err := g.update(ctx, hnswNeighboursKey(g.attr, l, n), uid,
+				pb.DirectedEdge_DEL); err != nil {
+				return err
+			}
+			if err := g.update(ctx, hnswNeighboursKey(g.attr, l, uid), n,
+				pb.DirectedEdge_DEL); err != nil {
+				return err
+			}
+		}
+		for _, n := range nbrs {
+			if err := g.relink(ctx, l, n, nbrs); err != nil {
+				return err
+			}
+		}
+	}
+	if len(ep) == 0 || ep[0] != uid {
+		return nil
+	}
+	if next == 0 {
+		if next, err = g.anyNode(uid); err != nil {
+			return err
+		}
+	}
+	return g.setEntry(ctx, next)
+}
+
+// anyNode returns a node of the graph other than the given one which still has a vector, or 0 if
+// there is none. The layers are scanned from the top, so that the node is in the upper ones if
+// possible.
+func (g *hnswGraph) anyNode(uid uint64) (uint64, error) {
+	btxn := pstore.NewTransactionAt(g.readTs, false)
+	defer btxn.Discard()
+
+	scan := func(level int) (uint64, error) {
+		prefix := x.IndexKey(g.attr, string([]byte{tok.IdentVector, 'n', byte(level)}))
+		itOpt := badger.DefaultIteratorOptions
+		itOpt.PrefetchValues = false
+		itOpt.Prefix = prefix
+		it := btxn.NewIterator(itOpt)
+		defer it.Close()
+		for it.Seek(prefix); it.Valid(); it.Next() {
+			n := binary.BigEndian.Uint64(it.Item().Key()[len(prefix):])
+			if n == uid {
+				continue
+			}
+			if vec, err := g.vector(n); err != nil || vec != nil {
+				return n, err
+			}
+		}
+		return 0, nil
+	}
+	for l := hnswMaxLevels - 1; l >= 0; l-- {
+		if n, err := scan(l); err != nil || n != 0 {
+			return n, err
+		}
+	}
+	return 0, nil
+}
+
+// relink links the node to the closest of the other nodes in the layer.
+func (g *hnswGraph) relink(ctx context.Context, level int, uid uint64, others []uint64) error {
+	vec, err := g.vector(uid)
+	if err != nil || vec == nil {
+		return err
+	}
+	closest := hnswCandidate{dist: math.Inf(1)}
+	for _, o := range others {
+		if o == uid {
+			continue
+		}
+		ovec, err := g.vector(o)
+		if err != nil {
+			return err
+		}
+		if ovec == nil {
+			continue
+		}
+		dist, err := types.EuclideanDistanceSq(vec, ovec)
+		if err != nil {
+			return err
+		}
+		if dist < closest.dist {
+			closest = hnswCandidate{uid: o, dist: dist}
+		}
+	}
+	if closest.uid == 0 {
+		return nil
+	}
+	if err := g.link(ctx, level, uid, closest.uid); err != nil {
+		return err
+	}
+	if err := g.link(ctx, level, closest.uid, uid); err != nil {
+		return err
+	}
+	if err := g.prune(ctx, level, uid); err != nil {
+		return err
+	}
+	return g.prune(ctx, level, closest.uid)
+}
+
+// addVectorIndexMutation updates the hnsw index of the predicate for the value of the edge.
+func (txn *Txn) addVectorIndexMutation(ctx context.Context, info *indexMutationInfo) error {
+	attr := info.edge.Attr
+	mu := txn.hnswLock(attr)
+	mu.Lock()
+	defer mu.Unlock()
+
+	g := newHNSWGraphForTxn(attr, txn)
+	if info.op == pb.DirectedEdge_DEL {
+		return g.remove(ctx, info.edge.Entity)
+	}
+	val, err := types.Convert(info.val, types.VFloatID)
+	if err != nil {
+		return err
+	}
+	return g.insert(ctx, info.edge.Entity, val.Value.([]float32))
+}
+
+// SearchVectorIndex returns up to k nodes of the predicate that are closest to the query vector
+// according to its hnsw index, closest first.
+func SearchVectorIndex(ctx context.Context, cache *LocalCache, attr string, readTs uint64,
+	query []float32, k int) ([]uint64, error) {
+	g := newHNSWGraph(attr, cache, readTs)
+	entries, top, err := g.descend(ctx, query, 0)
+	if err != nil || top < 0 {
+		return nil, err
+	}
+	ef := hnswEfSearch
+	if k > ef {
+		ef = k
+	}
+	res, err := g.searchLayer(ctx, query, entries, ef, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > k {
+		res = res[:k]
+	}
+	uids := make([]uint64, 0, len(res))
+	for _, c := range res {
+		uids = append(uids, c.uid)
+	}
+	return uids, nil
+}
+
+// hnswRebuildBatch is the number of nodes inserted by a rebuild before the updates of the graph
+// are written, so that the memory used by the rebuild doesn't grow with the predicate.
+const hnswRebuildBatch = 10000
+
+// rebuildVectorIndex builds the hnsw index of the predicate. Unlike the other indexes it can't
+// be built from the keys in parallel, as every insert reads the graph built so far. So the nodes
+// are inserted one by one, in batches whose updates are written at StartTs before the next one
+// reads them.
+func rebuildVectorIndex(ctx context.Context, rb *IndexRebuild) error {
+	glog.Infof("Rebuilding hnsw index for attr %s", rb.Attr)
+	txn := NewTxn(rb.StartTs)
+	g := newHNSWGraphForTxn(rb.Attr, txn)
+
+	btxn := pstore.NewTransactionAt(rb.StartTs, false)
+	defer btxn.Discard()
+	pk := x.ParsedKey{Attr: rb.Attr}
+	prefix := pk.DataPrefix()
+	itOpt := badger.DefaultIteratorOptions
+	itOpt.PrefetchValues = false
+	itOpt.Prefix = prefix
+	it := btxn.NewIterator(itOpt)
+	defer it.Close()
+
+	var count int
+	var prevKey []byte
+	for it.Seek(prefix); it.Valid(); it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key := it.Item().Key()
+		if bytes.Equal(key, prevKey) {
+			continue
+		}
+		prevKey = append(prevKey[:0], key...)
+		pk, err := x.Parse(key)
+		if err != nil {
+			return errors.Wrapf(err, "while rebuilding hnsw index for %s", rb.Attr)
+		}
+		if pk.HasStartUid {
+			// Parts of split lists are read through their main key.
+			continue
+		}
+		vec, err := g.vector(pk.Uid)
+		if err != nil {
+			return err
+		}
+		if vec == nil {
+			continue
+		}
+		if err := g.insert(ctx, pk.Uid, vec); err != nil {
+			return err
+		}
+		count++
+		if count%hnswRebuildBatch == 0 {
+			if err := writeVectorIndex(txn); err != nil {
+				return err
+			}
+			glog.V(1).Infof("Rebuilding hnsw index for attr %s: inserted %d nodes so far",
+				rb.Attr, count)
+			txn = NewTxn(rb.StartTs)
+			g = newHNSWGraphForTxn(rb.Attr, txn)
+		}
+	}
+	glog.Infof("Rebuilding hnsw index for attr %s: inserted %d nodes", rb.Attr, count)
+	return writeVectorIndex(txn)
+}
+
+// writeVectorIndex writes the lists of the graph updated by the txn at its StartTs. A list may
+// already have been written at StartTs by an earlier batch of the rebuild, so the updates are
+// rolled up with it and the complete list replaces it.
+func writeVectorIndex(txn *Txn) error {
+	txn.cache.UpdateDeltasAndDiscardLists()
+	writer := pstore.NewManagedWriteBatch()
+	for key, data := range txn.cache.deltas {
+		l, err := getNew([]byte(key), pstore, txn.StartTs)
+		if err != nil {
+			return err
+		}
+		// The updates are read back as if they were committed at StartTs.
+		delta := new(pb.PostingList)
+		if err := delta.Unmarshal(data); err != nil {
+			return err
+		}
+		delta.CommitTs = txn.StartTs
+		for _, p := range delta.Postings {
+			p.CommitTs = txn.StartTs
+		}
+		l.mutationMap = map[uint64]*pb.PostingList{txn.StartTs: delta}
+
+		kvs, err := l.Rollup(nil)
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			e := &badger.Entry{Key: kv.Key, Value: kv.Value, UserMeta: kv.UserMeta[0]}
+			if err := writer.SetEntryAt(e.WithDiscard(), txn.StartTs); err != nil {
+				return errors.Wrap(err, "error in writing hnsw index to pstore")
+			}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	// The next batch must read the lists from the disk and not from the cache.
+	txn.UpdateCachedKeys(txn.StartTs)
+	return nil
+}
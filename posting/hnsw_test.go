@@ -0,0 +1,244 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package posting
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const vectorSchema = `embedding: float32vector @index(hnsw) .`
+
+func randomVector(rnd *rand.Rand, dim int) []float32 {
+	vec := make([]float32, dim)
+	for i := range vec {
+		vec[i] = rnd.Float32()
+	}
+	return vec
+}
+
+func vectorEdge(attr string, uid uint64, vec []float32) *pb.DirectedEdge {
+	return &pb.DirectedEdge{
+		Attr:      attr,
+		Entity:    uid,
+		Value:     types.FloatArrayAsBytes(vec),
+		ValueType: pb.Posting_VFLOAT,
+	}
+}
+
+// addVectors sets the vectors of the nodes one txn after the other, starting at startTs. It
+// returns the ts which the vectors can be read at.
+func addVectors(t *testing.T, attr string, vecs map[uint64][]float32, startTs uint64) uint64 {
+	var uidList []uint64
+	for uid := range vecs {
+		uidList = append(uidList, uid)
+	}
+	sort.Slice(uidList, func(i, j int) bool { return uidList[i] < uidList[j] })
+	for _, uid := range uidList {
+		l, err := GetNoStore(x.DataKey(attr, uid), startTs)
+		require.NoError(t, err)
+		addMutation(t, l, vectorEdge(attr, uid, vecs[uid]), Set, startTs, startTs+1, true)
+		startTs += 2
+	}
+	return startTs
+}
+
+// closest returns the k nodes closest to the query, closest first.
+func closest(t *testing.T, vecs map[uint64][]float32, query []float32, k int) []uint64 {
+	var cands []hnswCandidate
+	for uid, vec := range vecs {
+		dist, err := types.EuclideanDistanceSq(query, vec)
+		require.NoError(t, err)
+		cands = append(cands, hnswCandidate{uid: uid, dist: dist})
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	var res []uint64
+	for _, c := range cands[:k] {
+		res = append(res, c.uid)
+	}
+	return res
+}
+
+// searchRecall runs the queries against the index and returns the fraction of the k closest
+// nodes which were found. It checks that the results are ordered by distance.
+func searchRecall(t *testing.T, attr string, vecs map[uint64][]float32, readTs uint64,
+	queries [][]float32, k int) float64 {
+	var found int
+	for _, query := range queries {
+		res, err := SearchVectorIndex(context.Background(), NewLocalCache(readTs), attr, readTs,
+			query, k)
+		require.NoError(t, err)
+		require.Len(t, res, k)
+
+		prev := -1.0
+		for _, uid := range res {
+			vec, ok := vecs[uid]
+			require.True(t, ok, "found %#x which doesn't have a vector", uid)
+			dist, err := types.EuclideanDistanceSq(query, vec)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, dist, prev)
+			prev = dist
+		}
+
+		want := make(map[uint64]bool)
+		for _, uid := range closest(t, vecs, query, k) {
+			want[uid] = true
+		}
+		for _, uid := range res {
+			if want[uid] {
+				found++
+			}
+		}
+	}
+	return float64(found) / float64(k*len(queries))
+}
+
+func TestVectorIndexSearch(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(vectorSchema), 1))
+	attr := x.GalaxyAttr("embedding")
+	rnd := rand.New(rand.NewSource(1))
+
+	vecs := make(map[uint64][]float32)
+	for uid := uint64(1); uid <= 300; uid++ {
+		vecs[uid] = randomVector(rnd, 8)
+	}
+	readTs := addVectors(t, attr, vecs, 1000)
+
+	var queries [][]float32
+	for i := 0; i < 20; i++ {
+		queries = append(queries, randomVector(rnd, 8))
+	}
+	require.GreaterOrEqual(t, searchRecall(t, attr, vecs, readTs, queries, 10), 0.95)
+
+	// The entry point is the node with the highest level.
+	g := newHNSWGraph(attr, NewLocalCache(readTs), readTs)
+	ep, _, top, err := g.entry()
+	require.NoError(t, err)
+	require.Equal(t, hnswLevel(ep), top)
+	for uid := range vecs {
+		require.LessOrEqual(t, hnswLevel(uid), top)
+	}
+
+	// Removing the entry point keeps the graph searchable.
+	l, err := GetNoStore(x.DataKey(attr, ep), readTs)
+	require.NoError(t, err)
+	addMutation(t, l, vectorEdge(attr, ep, vecs[ep]), Del, readTs, readTs+1, true)
+	delete(vecs, ep)
+	readTs += 2
+
+	g = newHNSWGraph(attr, NewLocalCache(readTs), readTs)
+	next, _, _, err := g.entry()
+	require.NoError(t, err)
+	require.NotEqual(t, ep, next)
+	require.Contains(t, vecs, next)
+	require.GreaterOrEqual(t, searchRecall(t, attr, vecs, readTs, queries, 10), 0.95)
+}
+
+func TestVectorIndexRemoveIsolatedEntry(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(`isolated: float32vector @index(hnsw) .`), 1))
+	attr := x.GalaxyAttr("isolated")
+	rnd := rand.New(rand.NewSource(3))
+
+	vecs := make(map[uint64][]float32)
+	for uid := uint64(1); uid <= 30; uid++ {
+		vecs[uid] = randomVector(rnd, 4)
+	}
+	readTs := addVectors(t, attr, vecs, 7000)
+
+	// The entry point is unlinked from all its neighbours, so none of them can replace it.
+	g := newHNSWGraph(attr, NewLocalCache(readTs), readTs)
+	ep, _, _, err := g.entry()
+	require.NoError(t, err)
+	unlink := func(level int, from, to uint64) {
+		l, err := GetNoStore(hnswNeighboursKey(attr, level, from), readTs)
+		require.NoError(t, err)
+		addMutation(t, l, &pb.DirectedEdge{Attr: attr, ValueId: to}, Del, readTs, readTs+1,
+			false)
+		readTs += 2
+	}
+	for level := 0; level <= hnswLevel(ep); level++ {
+		nbrs, err := g.neighbours(level, ep)
+		require.NoError(t, err)
+		for _, n := range nbrs {
+			unlink(level, ep, n)
+			unlink(level, n, ep)
+		}
+	}
+
+	l, err := GetNoStore(x.DataKey(attr, ep), readTs)
+	require.NoError(t, err)
+	addMutation(t, l, vectorEdge(attr, ep, vecs[ep]), Del, readTs, readTs+1, true)
+	delete(vecs, ep)
+	readTs += 2
+
+	// Another node becomes the entry point, and the remaining nodes are still found.
+	g = newHNSWGraph(attr, NewLocalCache(readTs), readTs)
+	next, _, _, err := g.entry()
+	require.NoError(t, err)
+	require.Contains(t, vecs, next)
+	var queries [][]float32
+	for i := 0; i < 10; i++ {
+		queries = append(queries, randomVector(rnd, 4))
+	}
+	require.GreaterOrEqual(t, searchRecall(t, attr, vecs, readTs, queries, 5), 0.9)
+}
+
+func TestVectorIndexConcurrentInserts(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(vectorSchema), 1))
+	attr := x.GalaxyAttr("embedding")
+	rnd := rand.New(rand.NewSource(2))
+
+	// The vectors have the same dimension as the ones of TestVectorIndexSearch, as they can end up
+	// in the same graph.
+	vecs := make(map[uint64][]float32)
+	for uid := uint64(1001); uid <= 1050; uid++ {
+		vecs[uid] = randomVector(rnd, 8)
+	}
+	startTs := addVectors(t, attr, vecs, 5000)
+
+	// Two txns running at the same time insert nodes close to each other. They link to the same
+	// nodes, so they must conflict.
+	insert := func(uid uint64, vec []float32) *Txn {
+		txn := NewTxn(startTs)
+		l, err := txn.Get(x.DataKey(attr, uid))
+		require.NoError(t, err)
+		edge := vectorEdge(attr, uid, vec)
+		edge.Op = pb.DirectedEdge_SET
+		require.NoError(t, l.AddMutationWithIndex(context.Background(), edge, txn))
+		return txn
+	}
+	vec := randomVector(rnd, 8)
+	txn1 := insert(1051, vec)
+	txn2 := insert(1052, vec)
+
+	var common int
+	for key := range txn1.conflicts {
+		if _, ok := txn2.conflicts[key]; ok {
+			common++
+		}
+	}
+	require.Greater(t, common, 0)
+}
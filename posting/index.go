@@ -113,6 +113,12 @@ func (txn *Txn) addIndexMutations(ctx context.Context, info *indexMutationInfo)
 			return err
 		}
 	}
+	for _, it := range info.tokenizers {
+		// The vector index is a graph rather than a set of tokens, so it's updated separately.
+		if it.Identifier() == tok.IdentVector {
+			return txn.addVectorIndexMutation(ctx, info)
+		}
+	}
 	return nil
 }
 
@@ -752,6 +758,9 @@ func (rb *IndexRebuild) GetQuerySchema() *pb.SchemaUpdate {
 	if rb.needsReverseEdgesRebuild() == indexRebuild {
 		querySchema.Directive = pb.SchemaUpdate_NONE
 	}
+	if rb.needsCompositeIndexRebuild() == indexRebuild {
+		querySchema.Composite = nil
+	}
 	return &querySchema
 }
 
@@ -763,6 +772,7 @@ func (rb *IndexRebuild) DropIndexes(ctx context.Context) error {
 	}
 	prefixes = append(prefixes, prefixesToDropReverseEdges(ctx, rb)...)
 	prefixes = append(prefixes, prefixesToDropCountIndex(ctx, rb)...)
+	prefixes = append(prefixes, prefixesToDropCompositeIndex(ctx, rb)...)
 	glog.Infof("Deleting indexes for %s", rb.Attr)
 	return pstore.DropPrefix(prefixes...)
 }
@@ -772,12 +782,13 @@ func (rb *IndexRebuild) BuildData(ctx context.Context) error {
 	return rebuildListType(ctx, rb)
 }
 
-// NeedIndexRebuild returns true if any of the tokenizer, reverse
-// or count indexes need to be rebuilt.
+// NeedIndexRebuild returns true if any of the tokenizer, reverse,
+// count or composite indexes need to be rebuilt.
 func (rb *IndexRebuild) NeedIndexRebuild() bool {
 	return rb.needsTokIndexRebuild().op == indexRebuild ||
 		rb.needsReverseEdgesRebuild() == indexRebuild ||
-		rb.needsCountIndexRebuild() == indexRebuild
+		rb.needsCountIndexRebuild() == indexRebuild ||
+		rb.needsCompositeIndexRebuild() == indexRebuild
 }
 
 // BuildIndexes builds indexes.
@@ -788,7 +799,10 @@ func (rb *IndexRebuild) BuildIndexes(ctx context.Context) error {
 	if err := rebuildReverseEdges(ctx, rb); err != nil {
 		return err
 	}
-	return rebuildCountIndex(ctx, rb)
+	if err := rebuildCountIndex(ctx, rb); err != nil {
+		return err
+	}
+	return rebuildCompositeIndex(ctx, rb)
 }
 
 type indexRebuildInfo struct {
@@ -932,6 +946,19 @@ func rebuildTokIndex(ctx context.Context, rb *IndexRebuild) error {
 	if err != nil {
 		return err
 	}
+	for i, t := range tokenizers {
+		if t.Identifier() != tok.IdentVector {
+			continue
+		}
+		if err := rebuildVectorIndex(ctx, rb); err != nil {
+			return err
+		}
+		tokenizers = append(tokenizers[:i], tokenizers[i+1:]...)
+		break
+	}
+	if len(tokenizers) == 0 {
+		return nil
+	}
 
 	pk := x.ParsedKey{Attr: rb.Attr}
 	builder := rebuilder{attr: rb.Attr, prefix: pk.DataPrefix(), startTs: rb.StartTs}
@@ -1366,6 +1366,39 @@ func (l *List) Uids(opt ListOptions) (*pb.List, error) {
 	return codec.ToList(bm), nil
 }
 
+// Intersects returns whether the list has any of the UIDs in opt.Intersect, as read at
+// opt.ReadTs. Unlike Uids, it stops at the first part of a split list which has one of them.
+// The other fields of opt are ignored.
+func (l *List) Intersects(opt ListOptions) (bool, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	deleteBelow, posts := l.pickPostings(opt.ReadTs)
+	if deleteBelow > 0 || len(posts) > 0 || opt.Intersect == nil {
+		// The mutable layer can add or remove any UID, so the whole list is needed.
+		bm, err := l.bitmap(ListOptions{ReadTs: opt.ReadTs, Intersect: opt.Intersect})
+		if err != nil {
+			return false, err
+		}
+		return !bm.IsEmpty(), nil
+	}
+
+	iw := codec.FromListNoCopy(opt.Intersect)
+	if !sroar.And(codec.FromBytes(l.plist.Bitmap), iw).IsEmpty() {
+		return true, nil
+	}
+	for _, startUid := range l.plist.Splits {
+		split, err := l.readListPart(startUid)
+		if err != nil {
+			return false, errors.Wrapf(err, "while reading a split with startUid: %d", startUid)
+		}
+		if !sroar.And(codec.FromBytes(split.Bitmap), iw).IsEmpty() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Postings calls postFn with the postings that are common with
 // UIDs in the opt ListOptions.
 func (l *List) Postings(opt ListOptions, postFn func(*pb.Posting) error) error {
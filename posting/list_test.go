@@ -1251,6 +1251,36 @@ func TestMultiPartListIterAfterUid(t *testing.T) {
 	}
 }
 
+func TestMultiPartListIntersects(t *testing.T) {
+	size := int(1e5)
+	ol, _ := createMultiPartList(t, size, false)
+
+	found, err := ol.Intersects(ListOptions{
+		ReadTs:    math.MaxUint64,
+		Intersect: &pb.List{SortedUids: []uint64{uint64(size) - 1, uint64(size) + 1}},
+	})
+	require.NoError(t, err)
+	require.True(t, found)
+
+	found, err = ol.Intersects(ListOptions{
+		ReadTs:    math.MaxUint64,
+		Intersect: &pb.List{SortedUids: []uint64{uint64(size) + 1, uint64(size) + 2}},
+	})
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// The mutable layer is taken into account.
+	txn := Txn{StartTs: math.MaxUint64 - 2}
+	addMutationHelper(t, ol, &pb.DirectedEdge{ValueId: uint64(size) + 2}, Set, &txn)
+	require.NoError(t, ol.commitMutation(txn.StartTs, txn.StartTs+1))
+	found, err = ol.Intersects(ListOptions{
+		ReadTs:    math.MaxUint64,
+		Intersect: &pb.List{SortedUids: []uint64{uint64(size) + 1, uint64(size) + 2}},
+	})
+	require.NoError(t, err)
+	require.True(t, found)
+}
+
 // Verify that postings can be retrieved in multi-part lists.
 func TestMultiPartListWithPostings(t *testing.T) {
 	size := int(1e5)
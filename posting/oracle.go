@@ -70,6 +70,9 @@ type Txn struct {
 	cache *LocalCache // This pointer does not get modified.
 	ErrCh chan error
 
+	// hnswLocks holds the locks of the hnsw indexes updated by the txn, keyed by predicate.
+	hnswLocks sync.Map
+
 	slWait sync.WaitGroup
 	sl     *skl.Skiplist
 }
@@ -18,7 +18,10 @@ package query
 
 import (
 	"bytes"
+	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/dgraph-io/dgraph/protos/pb"
@@ -31,6 +34,77 @@ type aggregator struct {
 	name   string
 	result types.Val
 	count  int // used when we need avergae.
+	// percent is the requested percentile, used only by the percentile aggregator.
+	percent float64
+	// state is used by the aggregators which can't be computed from a single running value.
+	state *aggState
+}
+
+// maxExactVals is the number of values up to which percentile and median are computed exactly.
+// Past it, the values are summarized by a digest, to keep the memory bounded.
+const maxExactVals = 1 << 14
+
+// aggState holds the partial state of the statistical aggregators (percentile, median, stddev,
+// variance and count distinct), which can't be computed from the running value kept for
+// min/max/sum/avg. It can be merged with the state built over a different set of values, so that
+// partial aggregations computed separately, e.g. by different groups, can be combined.
+type aggState struct {
+	// vals holds the values seen so far by percentile and median, until there are more than
+	// maxExactVals of them. They're then moved to digest, which approximates the percentiles.
+	vals   []float64
+	digest *digest
+	// n, mean and m2 hold the running moments used by stddev and variance. They are updated
+	// using Welford's algorithm and merged using Chan et al's parallel algorithm.
+	n    int64
+	mean float64
+	m2   float64
+	// distinct holds the string form of every distinct value seen so far.
+	distinct map[string]struct{}
+}
+
+// newAggregator returns an aggregator for the given aggregation function.
+func newAggregator(fn *Function) (*aggregator, error) {
+	ag := &aggregator{name: fn.Name}
+	if !isStatAggregatorFn(fn.Name) {
+		return ag, nil
+	}
+	ag.state = &aggState{}
+	switch fn.Name {
+	case "median":
+		ag.percent = 50
+	case "percentile":
+		if len(fn.Args) != 1 {
+			return nil, errors.Errorf("percentile expects exactly one percent argument")
+		}
+		p, err := strconv.ParseFloat(fn.Args[0].Value, 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, errors.Errorf("Invalid percent %q for percentile, it should be a "+
+				"number between 0 and 100", fn.Args[0].Value)
+		}
+		ag.percent = p
+	case "distinct":
+		ag.state.distinct = make(map[string]struct{})
+	}
+	return ag, nil
+}
+
+// isStatAggregatorFn returns true for the aggregators which keep their partial result in
+// aggState.
+func isStatAggregatorFn(f string) bool {
+	switch f {
+	case "percentile", "median", "stddev", "variance", "distinct":
+		return true
+	}
+	return false
+}
+
+// aggregatorFieldName returns the name under which the result of applying the aggregation
+// function over arg is returned, when no alias has been given.
+func aggregatorFieldName(fn *Function, arg string) string {
+	if fn.Name == "distinct" {
+		return fmt.Sprintf("count(distinct %s)", arg)
+	}
+	return fmt.Sprintf("%s(%s)", fn.Name, arg)
 }
 
 func isUnary(f string) bool {
@@ -527,6 +601,10 @@ func (ag *aggregator) ApplyVal(v types.Val) error {
 }
 
 func (ag *aggregator) Apply(val types.Val) {
+	if ag.state != nil {
+		ag.applyStat(val)
+		return
+	}
 	if ag.result.Value == nil {
 		ag.result = val
 		ag.count++
@@ -569,6 +647,9 @@ func (ag *aggregator) Apply(val types.Val) {
 
 func (ag *aggregator) ValueMarshalled() (*pb.TaskValue, error) {
 	data := types.ValueForType(types.BinaryID)
+	if ag.state != nil {
+		ag.result = ag.statValue()
+	}
 	ag.divideByCount()
 	res := &pb.TaskValue{ValType: ag.result.Tid.Enum(), Val: x.Nilbyte}
 	if ag.result.Value == nil {
@@ -600,6 +681,9 @@ func (ag *aggregator) divideByCount() {
 }
 
 func (ag *aggregator) Value() (types.Val, error) {
+	if ag.state != nil {
+		ag.result = ag.statValue()
+	}
 	if ag.result.Value == nil {
 		return ag.result, ErrEmptyVal
 	}
@@ -616,3 +700,139 @@ func (ag *aggregator) Value() (types.Val, error) {
 	}
 	return ag.result, nil
 }
+
+func (ag *aggregator) applyStat(val types.Val) {
+	st := ag.state
+	if ag.name == "distinct" {
+		if val.Value == nil {
+			return
+		}
+		key := types.Val{Tid: types.StringID}
+		if err := types.Marshal(val, &key); err != nil {
+			return
+		}
+		st.distinct[key.Value.(string)] = struct{}{}
+		ag.count++
+		return
+	}
+
+	var v float64
+	switch val.Tid {
+	case types.IntID:
+		v = float64(val.Value.(int64))
+	case types.FloatID:
+		v = val.Value.(float64)
+	default:
+		// Skipping the values which aren't numeric since they can't be aggregated.
+		return
+	}
+	ag.count++
+	switch ag.name {
+	case "percentile", "median":
+		if st.digest == nil && len(st.vals) < maxExactVals {
+			st.vals = append(st.vals, v)
+			break
+		}
+		st.toDigest()
+		st.digest.add(v)
+	case "stddev", "variance":
+		st.n++
+		delta := v - st.mean
+		st.mean += delta / float64(st.n)
+		st.m2 += delta * (v - st.mean)
+	}
+}
+
+// toDigest moves the values kept for percentile and median to a digest, if they aren't in one yet.
+func (st *aggState) toDigest() {
+	if st.digest != nil {
+		return
+	}
+	st.digest = newDigest()
+	for _, v := range st.vals {
+		st.digest.add(v)
+	}
+	st.vals = nil
+}
+
+// Merge combines the partial state of other into ag. Both aggregators should have been
+// created for the same aggregation function.
+func (ag *aggregator) Merge(other *aggregator) error {
+	if ag.name != other.name || ag.percent != other.percent {
+		return errors.Errorf("Can't merge aggregator %q with %q", other.name, ag.name)
+	}
+	if ag.state == nil {
+		if other.result.Value != nil {
+			ag.Apply(other.result)
+			// Apply counts the merged result as a single value.
+			ag.count += other.count - 1
+		}
+		return nil
+	}
+
+	st, ost := ag.state, other.state
+	ag.count += other.count
+	switch ag.name {
+	case "distinct":
+		for k := range ost.distinct {
+			st.distinct[k] = struct{}{}
+		}
+	case "percentile", "median":
+		if st.digest == nil && ost.digest == nil && len(st.vals)+len(ost.vals) <= maxExactVals {
+			st.vals = append(st.vals, ost.vals...)
+			break
+		}
+		st.toDigest()
+		if ost.digest != nil {
+			st.digest.merge(ost.digest)
+			break
+		}
+		for _, v := range ost.vals {
+			st.digest.add(v)
+		}
+	case "stddev", "variance":
+		if ost.n == 0 {
+			return nil
+		}
+		n := st.n + ost.n
+		delta := ost.mean - st.mean
+		st.m2 += ost.m2 + delta*delta*float64(st.n)*float64(ost.n)/float64(n)
+		st.mean += delta * float64(ost.n) / float64(n)
+		st.n = n
+	}
+	return nil
+}
+
+// statValue computes the result of the statistical aggregators from their partial state.
+// Variance and standard deviation are computed over the whole population of values.
+func (ag *aggregator) statValue() types.Val {
+	st := ag.state
+	switch ag.name {
+	case "distinct":
+		return types.Val{Tid: types.IntID, Value: int64(len(st.distinct))}
+	case "percentile", "median":
+		if st.digest != nil {
+			return types.Val{Tid: types.FloatID, Value: st.digest.quantile(ag.percent / 100)}
+		}
+		if len(st.vals) == 0 {
+			return types.Val{Tid: types.FloatID}
+		}
+		sort.Float64s(st.vals)
+		// Linearly interpolate between the closest ranks.
+		rank := ag.percent / 100 * float64(len(st.vals)-1)
+		lo := int(math.Floor(rank))
+		hi := int(math.Ceil(rank))
+		v := st.vals[lo] + (st.vals[hi]-st.vals[lo])*(rank-float64(lo))
+		return types.Val{Tid: types.FloatID, Value: v}
+	case "variance", "stddev":
+		if st.n == 0 {
+			return types.Val{Tid: types.FloatID}
+		}
+		v := st.m2 / float64(st.n)
+		if ag.name == "stddev" {
+			v = math.Sqrt(v)
+		}
+		return types.Val{Tid: types.FloatID, Value: v}
+	}
+	return types.Val{}
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/stretchr/testify/require"
+)
+
+func intVals(vals ...int64) []types.Val {
+	res := make([]types.Val, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, types.Val{Tid: types.IntID, Value: v})
+	}
+	return res
+}
+
+func aggregate(t *testing.T, fn *Function, vals []types.Val) types.Val {
+	ag, err := newAggregator(fn)
+	require.NoError(t, err)
+	for _, v := range vals {
+		ag.Apply(v)
+	}
+	res, err := ag.Value()
+	require.NoError(t, err)
+	return res
+}
+
+func TestStatAggregators(t *testing.T) {
+	vals := intVals(7, 1, 3, 5, 9, 3)
+	tests := []struct {
+		fn  *Function
+		out float64
+	}{
+		{fn: &Function{Name: "median"}, out: 4},
+		{fn: &Function{Name: "percentile", Args: []gql.Arg{{Value: "0"}}}, out: 1},
+		{fn: &Function{Name: "percentile", Args: []gql.Arg{{Value: "100"}}}, out: 9},
+		{fn: &Function{Name: "percentile", Args: []gql.Arg{{Value: "90"}}}, out: 8},
+		{fn: &Function{Name: "variance"}, out: 65.0 / 9},
+		{fn: &Function{Name: "stddev"}, out: math.Sqrt(65.0 / 9)},
+	}
+	for _, tc := range tests {
+		res := aggregate(t, tc.fn, vals)
+		require.Equal(t, types.FloatID, res.Tid, tc.fn.Name)
+		require.InDelta(t, tc.out, res.Value.(float64), 1e-9, tc.fn.Name)
+	}
+
+	res := aggregate(t, &Function{Name: "distinct"}, vals)
+	require.Equal(t, types.Val{Tid: types.IntID, Value: int64(5)}, res)
+}
+
+func TestStatAggregatorsEmpty(t *testing.T) {
+	for _, name := range []string{"median", "stddev", "variance"} {
+		ag, err := newAggregator(&Function{Name: name})
+		require.NoError(t, err)
+		_, err = ag.Value()
+		require.Equal(t, ErrEmptyVal, err, name)
+	}
+}
+
+func TestPercentileInvalidArg(t *testing.T) {
+	_, err := newAggregator(&Function{Name: "percentile"})
+	require.Error(t, err)
+	_, err = newAggregator(&Function{Name: "percentile", Args: []gql.Arg{{Value: "101"}}})
+	require.Error(t, err)
+}
+
+func TestPercentileDigest(t *testing.T) {
+	// Past maxExactVals, the percentiles are approximated by a digest.
+	n := 10 * maxExactVals
+	vals := make([]types.Val, 0, n)
+	for _, v := range rand.Perm(n) {
+		vals = append(vals, types.Val{Tid: types.IntID, Value: int64(v)})
+	}
+	for _, p := range []float64{0, 1, 50, 99, 99.9, 100} {
+		fn := &Function{Name: "percentile",
+			Args: []gql.Arg{{Value: strconv.FormatFloat(p, 'f', -1, 64)}}}
+		res := aggregate(t, fn, vals)
+		require.InDelta(t, p/100*float64(n-1), res.Value.(float64), 0.001*float64(n), p)
+	}
+}
+
+// mergeAggregate aggregates each part separately and merges the partial states.
+func mergeAggregate(t *testing.T, fn *Function, parts ...[]types.Val) types.Val {
+	ag, err := newAggregator(fn)
+	require.NoError(t, err)
+	for _, part := range parts {
+		partial, err := newAggregator(fn)
+		require.NoError(t, err)
+		for _, v := range part {
+			partial.Apply(v)
+		}
+		require.NoError(t, ag.Merge(partial))
+	}
+	res, err := ag.Value()
+	require.NoError(t, err)
+	return res
+}
+
+func TestAggregatorMerge(t *testing.T) {
+	left := intVals(7, 1, 3)
+	right := intVals(5, 9, 3)
+	all := append(append([]types.Val{}, left...), right...)
+
+	for _, fn := range []*Function{{Name: "median"},
+		{Name: "percentile", Args: []gql.Arg{{Value: "90"}}}, {Name: "stddev"},
+		{Name: "variance"}, {Name: "distinct"}, {Name: "sum"}, {Name: "avg"}, {Name: "min"},
+		{Name: "max"}} {
+		merged := mergeAggregate(t, fn, left, right)
+		expected := aggregate(t, fn, all)
+		if expected.Tid == types.FloatID {
+			require.InDelta(t, expected.Value.(float64), merged.Value.(float64), 1e-9, fn.Name)
+			continue
+		}
+		require.Equal(t, expected, merged, fn.Name)
+	}
+
+	a, err := newAggregator(&Function{Name: "sum"})
+	require.NoError(t, err)
+	b, err := newAggregator(&Function{Name: "max"})
+	require.NoError(t, err)
+	require.Error(t, a.Merge(b))
+}
+
+func TestAggregatorMergeDigest(t *testing.T) {
+	// The partial states are summarized by digests, which are merged.
+	n := 10 * maxExactVals
+	vals := make([]types.Val, 0, n)
+	for _, v := range rand.Perm(n) {
+		vals = append(vals, types.Val{Tid: types.IntID, Value: int64(v % (n / 2))})
+	}
+	left, right := vals[:n/3], vals[n/3:]
+
+	for _, p := range []float64{1, 50, 99} {
+		fn := &Function{Name: "percentile",
+			Args: []gql.Arg{{Value: strconv.FormatFloat(p, 'f', -1, 64)}}}
+		res := mergeAggregate(t, fn, left, right)
+		require.InDelta(t, p/100*float64(n/2-1), res.Value.(float64), 0.001*float64(n), p)
+	}
+
+	// The values are uniformly spread over [0, n/2).
+	res := mergeAggregate(t, &Function{Name: "stddev"}, left, right)
+	expected := aggregate(t, &Function{Name: "stddev"}, vals)
+	require.InDelta(t, expected.Value.(float64), res.Value.(float64), 1e-6)
+	require.InDelta(t, float64(n/2)/math.Sqrt(12), res.Value.(float64), 1)
+
+	res = mergeAggregate(t, &Function{Name: "distinct"}, left, right)
+	require.Equal(t, types.Val{Tid: types.IntID, Value: int64(n / 2)}, res)
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+	"github.com/dgraph-io/sroar"
+	"github.com/pkg/errors"
+)
+
+// Graph algorithms are run by the query blocks with the directive named after them. The graph is
+// formed by the nodes returned by the root function and all the nodes reachable from them through
+// the edges of the given predicate. The result is stored in the variable assigned to the block,
+// as a value variable which holds a value for every node of the graph.
+//
+//	rank as me(func: has(follows)) @pagerank(pred: follows, iterations: 20, damping: 0.85)
+//	comp as me(func: has(follows)) @components(pred: follows)
+//	tri as me(func: has(follows)) @triangles(pred: follows)
+const (
+	pageRankAlgo   = "pagerank"
+	componentsAlgo = "components"
+	trianglesAlgo  = "triangles"
+)
+
+// graph is a directed graph formed by the edges of a predicate.
+type graph struct {
+	nodes []uint64            // sorted list of all the nodes in the graph.
+	out   map[uint64][]uint64 // sorted list of outgoing edges of every node.
+}
+
+// fetchGraph fetches the edges of the algorithm predicate starting from the DestMap of the
+// SubGraph, till all the reachable nodes have been visited.
+func (sg *SubGraph) fetchGraph(ctx context.Context) (*graph, error) {
+	g := &graph{out: make(map[uint64][]uint64)}
+	seen := sroar.NewBitmap()
+	frontier := sg.DestMap.ToArray()
+	seen.SetMany(frontier)
+
+	var numEdges uint64
+	for len(frontier) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		temp := &SubGraph{
+			Attr:    sg.Params.GraphAlgo.Pred,
+			SrcUIDs: &pb.List{SortedUids: frontier},
+			ReadTs:  sg.ReadTs,
+		}
+		taskQuery, err := createTaskQuery(ctx, temp)
+		if err != nil {
+			return nil, err
+		}
+		result, err := worker.ProcessTaskOverNetwork(ctx, taskQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []uint64
+		for i, ul := range result.UidMatrix {
+			if i >= len(frontier) {
+				break
+			}
+			dst := codec.GetUids(ul)
+			g.out[frontier[i]] = dst
+			numEdges += uint64(len(dst))
+			for _, uid := range dst {
+				if !seen.Contains(uid) {
+					seen.Set(uid)
+					next = append(next, uid)
+				}
+			}
+		}
+		if numEdges > x.Config.LimitQueryEdge {
+			return nil, errors.Errorf("Exceeded query edge limit = %v. Found %v edges.",
+				x.Config.LimitQueryEdge, numEdges)
+		}
+		sort.Slice(next, func(i, j int) bool { return next[i] < next[j] })
+		frontier = next
+	}
+	g.nodes = seen.ToArray()
+	return g, nil
+}
+
+// runGraphAlgo processes the root of the graph algorithm block and runs the algorithm over the
+// graph formed by the nodes returned by it.
+func runGraphAlgo(ctx context.Context, sg *SubGraph) error {
+	rch := make(chan error, 1)
+	ProcessGraph(ctx, sg, nil, rch)
+	if err := <-rch; err != nil {
+		return err
+	}
+
+	g, err := sg.fetchGraph(ctx)
+	if err != nil {
+		return err
+	}
+	vals := make(map[uint64]types.Val, len(g.nodes))
+	switch sg.Params.GraphAlgo.Algo {
+	case pageRankAlgo:
+		for uid, rank := range pageRank(g, sg.Params.GraphAlgo.Iterations,
+			sg.Params.GraphAlgo.Damping) {
+			vals[uid] = types.Val{Tid: types.FloatID, Value: rank}
+		}
+	case componentsAlgo:
+		for uid, comp := range connectedComponents(g) {
+			vals[uid] = types.Val{Tid: types.IntID, Value: int64(comp)}
+		}
+	case trianglesAlgo:
+		for uid, count := range triangleCount(g) {
+			vals[uid] = types.Val{Tid: types.IntID, Value: count}
+		}
+	default:
+		return errors.Errorf("Unknown graph algorithm %q", sg.Params.GraphAlgo.Algo)
+	}
+	sg.algoVals = vals
+	return nil
+}
+
+// pageRank computes the pagerank of every node of the graph. The rank of the nodes without any
+// outgoing edges is distributed evenly over all the nodes.
+func pageRank(g *graph, iterations int, damping float64) map[uint64]float64 {
+	n := float64(len(g.nodes))
+	rank := make(map[uint64]float64, len(g.nodes))
+	if n == 0 {
+		return rank
+	}
+	for _, uid := range g.nodes {
+		rank[uid] = 1 / n
+	}
+
+	for i := 0; i < iterations; i++ {
+		var dangling float64
+		next := make(map[uint64]float64, len(g.nodes))
+		for _, uid := range g.nodes {
+			out := g.out[uid]
+			if len(out) == 0 {
+				dangling += rank[uid]
+				continue
+			}
+			share := rank[uid] / float64(len(out))
+			for _, dst := range out {
+				next[dst] += share
+			}
+		}
+		for _, uid := range g.nodes {
+			next[uid] = (1-damping)/n + damping*(next[uid]+dangling/n)
+		}
+		rank = next
+	}
+	return rank
+}
+
+// connectedComponents finds the weakly connected components of the graph. Every node is mapped
+// to the smallest uid in its component.
+func connectedComponents(g *graph) map[uint64]uint64 {
+	parent := make(map[uint64]uint64, len(g.nodes))
+	for _, uid := range g.nodes {
+		parent[uid] = uid
+	}
+	var find func(uint64) uint64
+	find = func(uid uint64) uint64 {
+		for parent[uid] != uid {
+			parent[uid] = parent[parent[uid]]
+			uid = parent[uid]
+		}
+		return uid
+	}
+	for src, out := range g.out {
+		for _, dst := range out {
+			a, b := find(src), find(dst)
+			switch {
+			case a < b:
+				parent[b] = a
+			case b < a:
+				parent[a] = b
+			}
+		}
+	}
+
+	comp := make(map[uint64]uint64, len(g.nodes))
+	for _, uid := range g.nodes {
+		comp[uid] = find(uid)
+	}
+	return comp
+}
+
+// triangleCount counts the number of triangles every node of the graph is part of. The direction
+// of the edges is ignored.
+func triangleCount(g *graph) map[uint64]int64 {
+	// Build the undirected adjacency sets without self loops.
+	adj := make(map[uint64]map[uint64]struct{}, len(g.nodes))
+	for _, uid := range g.nodes {
+		adj[uid] = make(map[uint64]struct{})
+	}
+	for src, out := range g.out {
+		for _, dst := range out {
+			if src == dst {
+				continue
+			}
+			adj[src][dst] = struct{}{}
+			adj[dst][src] = struct{}{}
+		}
+	}
+
+	count := make(map[uint64]int64, len(g.nodes))
+	for _, u := range g.nodes {
+		count[u] = 0
+	}
+	for _, u := range g.nodes {
+		for v := range adj[u] {
+			if v <= u {
+				continue
+			}
+			// Count each triangle u < v < w only once.
+			for w := range adj[v] {
+				if w <= v {
+					continue
+				}
+				if _, ok := adj[u][w]; ok {
+					count[u]++
+					count[v]++
+					count[w]++
+				}
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGraph is made of the triangles 1-2-3 and 2-3-4 and the separate edge 5 -> 6.
+func testGraph() *graph {
+	return &graph{
+		nodes: []uint64{1, 2, 3, 4, 5, 6},
+		out: map[uint64][]uint64{
+			1: {2},
+			2: {3, 4},
+			3: {1, 4},
+			4: {2},
+			5: {6},
+		},
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	rank := pageRank(testGraph(), 50, 0.85)
+	var total float64
+	for _, r := range rank {
+		total += r
+	}
+	require.InDelta(t, 1.0, total, 1e-9)
+	// 6 only gets the rank from 5 while 4 gets it from both 2 and 3.
+	require.Greater(t, rank[4], rank[6])
+	require.Greater(t, rank[6], rank[5])
+
+	require.Empty(t, pageRank(&graph{}, 20, 0.85))
+}
+
+func TestConnectedComponents(t *testing.T) {
+	comp := connectedComponents(testGraph())
+	require.Equal(t, map[uint64]uint64{1: 1, 2: 1, 3: 1, 4: 1, 5: 5, 6: 5}, comp)
+}
+
+func TestTriangleCount(t *testing.T) {
+	count := triangleCount(testGraph())
+	require.Equal(t, map[uint64]int64{1: 1, 2: 2, 3: 2, 4: 1, 5: 0, 6: 0}, count)
+}
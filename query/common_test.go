@@ -336,6 +336,10 @@ tweet-a                        : string @index(trigram) .
 tweet-b                        : string @index(term) .
 tweet-c                        : string @index(fulltext) .
 tweet-d                        : string @index(trigram) .
+embedding                      : float32vector @index(hnsw) .
+country                        : string @index(exact) .
+status                         : string .
+index composite(country, status)
 `
 
 func populateCluster() {
@@ -998,4 +1002,32 @@ func populateCluster() {
 		panic(fmt.Sprintf("Could not able add triple to the cluster. Got error %v", err.Error()))
 	}
 
+	// Add data for vector tests.
+	err = addTriplesToCluster(`
+		<40001> <embedding> "[0.0, 0.0]" .
+		<40002> <embedding> "[3.0, 0.0]" .
+		<40003> <embedding> "[1.0, 1.0]" .
+		<40004> <embedding> "[0.0, 2.0]" .
+		<40005> <embedding> "[5.0, 5.0]" .
+	`)
+	if err != nil {
+		panic(fmt.Sprintf("Could not able add triple to the cluster. Got error %v", err.Error()))
+	}
+
+	// Add data for composite index tests.
+	err = addTriplesToCluster(`
+		<41001> <country> "in" .
+		<41001> <status> "active" .
+		<41002> <country> "in" .
+		<41002> <status> "inactive" .
+		<41003> <country> "fr" .
+		<41003> <status> "active" .
+		<41004> <country> "in" .
+		<41004> <status> "active" .
+		<41005> <country> "in" .
+	`)
+	if err != nil {
+		panic(fmt.Sprintf("Could not able add triple to the cluster. Got error %v", err.Error()))
+	}
+
 }
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/pkg/errors"
+)
+
+// Cursors are opaque continuation tokens for paginating the results of a block. A cursor holds
+// the values of the sort keys of the last node of a page along with its uid, which breaks the
+// ties between nodes with the same values. Passing it back to the same block resumes right after
+// that node, without having to skip over the previous pages like offset does.
+//
+//	me(func: has(name), orderasc: name, first: 10, cursor: "<cursor>") { name }
+//
+// The cursor of a block is returned when the block has a first argument and its page is full.
+// It is only returned for the root blocks and for the nested blocks which were expanded from a
+// single node, as the other nested blocks hold a page for every parent. A cursor passed to a
+// nested block applies to all the lists of the block though.
+type cursor struct {
+	Keys []cursorKey `json:"k,omitempty"`
+	UID  uint64      `json:"u"`
+}
+
+// cursorKey is the value of a sort key in a cursor. The value is nil if the node doesn't have a
+// value for the key.
+type cursorKey struct {
+	Tid types.TypeID `json:"t"`
+	Val []byte       `json:"v,omitempty"`
+}
+
+func newCursor(vals []types.Val, uid uint64) (string, error) {
+	c := &cursor{UID: uid}
+	for _, v := range vals {
+		if v.Value == nil {
+			c.Keys = append(c.Keys, cursorKey{})
+			continue
+		}
+		bv := types.ValueForType(types.BinaryID)
+		if err := types.Marshal(v, &bv); err != nil {
+			return "", err
+		}
+		c.Keys = append(c.Keys, cursorKey{Tid: v.Tid, Val: bv.Value.([]byte)})
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func parseCursor(s string) (*cursor, error) {
+	if strings.HasPrefix(s, `"`) {
+		uq, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while unquoting cursor")
+		}
+		s = uq
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Errorf("Invalid cursor: %s", s)
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.UID == 0 {
+		return nil, errors.Errorf("Invalid cursor: %s", s)
+	}
+	return c, nil
+}
+
+// taskValues returns the values of the sort keys of the cursor, for the sort message. The
+// values are left empty for the keys which the node doesn't have a value for.
+func (c *cursor) taskValues() []*pb.TaskValue {
+	vals := make([]*pb.TaskValue, 0, len(c.Keys))
+	for _, key := range c.Keys {
+		vals = append(vals, &pb.TaskValue{Val: key.Val, ValType: pb.Posting_ValType(key.Tid)})
+	}
+	return vals
+}
+
+// val returns the value of the i-th sort key of the cursor.
+func (c *cursor) val(i int) (types.Val, error) {
+	key := c.Keys[i]
+	if key.Val == nil {
+		return types.Val{}, nil
+	}
+	return types.Convert(types.Val{Tid: types.BinaryID, Value: key.Val}, key.Tid)
+}
+
+func (args *params) fillCursor(gq *gql.GraphQuery) error {
+	v, ok := gq.Args["cursor"]
+	if !ok {
+		return nil
+	}
+	if _, ok := gq.Args["after"]; ok {
+		return errors.Errorf("after and cursor can't be used together")
+	}
+	if len(args.FacetsOrder) > 0 {
+		return errors.Errorf("cursor can't be used when ordering by facets")
+	}
+	c, err := parseCursor(v)
+	if err != nil {
+		return err
+	}
+	if len(c.Keys) != len(args.Order) {
+		return errors.Errorf("The cursor doesn't match the order of the block")
+	}
+	if len(c.Keys) == 0 {
+		// The results are sorted by uid, so resume right after the uid of the cursor.
+		args.AfterUID = c.UID
+		return nil
+	}
+	if _, err := c.val(0); err != nil {
+		return errors.Errorf("Invalid cursor: %s", v)
+	}
+	args.Cursor = c
+	return nil
+}
+
+// fetchSortValues returns the values of the sort keys of the block for the given uids. Missing
+// values are left nil.
+func (sg *SubGraph) fetchSortValues(ctx context.Context, order []*pb.Order,
+	uids []uint64) (map[uint64][]types.Val, error) {
+	vals := make(map[uint64][]types.Val, len(uids))
+	for _, uid := range uids {
+		vals[uid] = make([]types.Val, len(order))
+	}
+	for i, o := range order {
+		temp := &SubGraph{
+			Attr:    o.Attr,
+			SrcUIDs: &pb.List{SortedUids: uids},
+			ReadTs:  sg.ReadTs,
+			Cache:   sg.Cache,
+			Params:  params{Langs: o.Langs},
+		}
+		taskQuery, err := createTaskQuery(ctx, temp)
+		if err != nil {
+			return nil, err
+		}
+		result, err := worker.ProcessTaskOverNetwork(ctx, taskQuery)
+		if err != nil {
+			return nil, err
+		}
+		for j, vl := range result.ValueMatrix {
+			if j >= len(uids) || len(vl.Values) == 0 {
+				continue
+			}
+			v, err := convertWithBestEffort(vl.Values[0], o.Attr)
+			if err != nil {
+				return nil, err
+			}
+			vals[uids[j]][i] = v
+		}
+	}
+	return vals, nil
+}
+
+// updateCursor sets the cursor to resume from after the last node returned by the block, if the
+// block is paginated and returned a full page. With Params.NodeCursors, it also keeps the values
+// of the sort keys of all the nodes, so that their cursors can be built.
+func (sg *SubGraph) updateCursor(ctx context.Context) error {
+	if len(sg.uidMatrix) != 1 || len(sg.Params.FacetsOrder) > 0 || sg.Params.Random > 0 ||
+		sg.isOrderedByVar() || sg.isOrderedByDistance() {
+		return nil
+	}
+	uids := codec.GetUids(sg.uidMatrix[0])
+	fullPage := sg.Params.Count > 0 && len(uids) >= sg.Params.Count
+	if len(uids) == 0 || (!fullPage && !sg.Params.NodeCursors) {
+		return nil
+	}
+	last := uids[len(uids)-1]
+
+	var valMap map[uint64][]types.Val
+	if len(sg.Params.Order) > 0 {
+		fetch := []uint64{last}
+		if sg.Params.NodeCursors {
+			fetch = uids
+		}
+		var err error
+		if valMap, err = sg.fetchSortValues(ctx, sg.Params.Order, fetch); err != nil {
+			return err
+		}
+	}
+	if sg.Params.NodeCursors {
+		sg.sortVals = valMap
+	}
+	if !fullPage {
+		return nil
+	}
+	next, err := newCursor(valMap[last], last)
+	if err != nil {
+		return err
+	}
+	sg.nextCursor = next
+	return nil
+}
+
+// isOrderedByVar returns true if the block is sorted by a value variable.
+func (sg *SubGraph) isOrderedByVar() bool {
+	if len(sg.Params.Order) == 0 {
+		return false
+	}
+	for _, it := range sg.Params.NeedsVar {
+		if it.Name == sg.Params.Order[0].Attr && it.Typ == gql.ValueVar {
+			return true
+		}
+	}
+	return false
+}
+
+// collectCursors adds the cursors of the SubGraph and its children to the map. They are keyed
+// by the path of the block in the response, e.g. "me" or "me.friend".
+func collectCursors(sg *SubGraph, path string, cursors map[string]string) {
+	if sg.nextCursor != "" {
+		cursors[path] = sg.nextCursor
+	}
+	for _, child := range sg.Children {
+		if child.IsInternal() || child.Params.IgnoreResult {
+			continue
+		}
+		name := child.Params.Alias
+		if name == "" {
+			name = child.Attr
+		}
+		collectCursors(child, path+"."+name, cursors)
+	}
+}
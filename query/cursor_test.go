@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	date := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	vals := []types.Val{
+		{Tid: types.StringID, Value: "Alice"},
+		{Tid: types.FloatID, Value: 1.5},
+		{Tid: types.DateTimeID, Value: date},
+		{},
+	}
+	s, err := newCursor(vals, 0x2a)
+	require.NoError(t, err)
+
+	// Cursors can be passed back quoted or not.
+	for _, arg := range []string{s, strconv.Quote(s)} {
+		c, err := parseCursor(arg)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0x2a), c.UID)
+		require.Len(t, c.Keys, len(vals))
+		for i, v := range vals {
+			cv, err := c.val(i)
+			require.NoError(t, err)
+			require.Equal(t, v, cv)
+		}
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	for _, s := range []string{"", "not a cursor", "e30"} {
+		_, err := parseCursor(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestCursorTaskValues(t *testing.T) {
+	s, err := newCursor([]types.Val{{Tid: types.IntID, Value: int64(30)}, {}}, 10)
+	require.NoError(t, err)
+	c, err := parseCursor(s)
+	require.NoError(t, err)
+
+	vals := c.taskValues()
+	require.Len(t, vals, 2)
+	require.Equal(t, pb.Posting_INT, vals[0].ValType)
+	v, err := types.Convert(types.Val{Tid: types.BinaryID, Value: vals[0].Val}, types.IntID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), v.Value)
+	require.Empty(t, vals[1].Val)
+}
+
+func TestFillCursor(t *testing.T) {
+	s, err := newCursor([]types.Val{{Tid: types.StringID, Value: "Alice"}}, 10)
+	require.NoError(t, err)
+
+	args := params{Order: []*pb.Order{{Attr: "name"}}}
+	require.NoError(t, args.fillCursor(&gql.GraphQuery{Args: map[string]string{"cursor": s}}))
+	require.Equal(t, uint64(10), args.Cursor.UID)
+
+	args = params{}
+	require.Error(t, args.fillCursor(&gql.GraphQuery{Args: map[string]string{"cursor": s}}))
+
+	// The cursor of a node without a value for the first key.
+	s, err = newCursor([]types.Val{{}}, 10)
+	require.NoError(t, err)
+	args = params{Order: []*pb.Order{{Attr: "name"}}}
+	require.NoError(t, args.fillCursor(&gql.GraphQuery{Args: map[string]string{"cursor": s}}))
+	require.Equal(t, uint64(10), args.Cursor.UID)
+
+	s, err = newCursor(nil, 10)
+	require.NoError(t, err)
+	args = params{}
+	require.NoError(t, args.fillCursor(&gql.GraphQuery{Args: map[string]string{"cursor": s}}))
+	require.Nil(t, args.Cursor)
+	require.Equal(t, uint64(10), args.AfterUID)
+
+	args = params{}
+	require.Error(t, args.fillCursor(&gql.GraphQuery{Args: map[string]string{"cursor": s,
+		"after": "0x1"}}))
+}
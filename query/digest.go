@@ -0,0 +1,124 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// digestCompression sets the accuracy of the digests: the higher it is, the more centroids
+	// they keep.
+	digestCompression = 200
+	// digestBufferSize is the number of values which are buffered before being merged into the
+	// centroids.
+	digestBufferSize = 5 * digestCompression
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// digest is a merging t-digest, which approximates the quantiles of a stream of values in a
+// bounded memory. The centroids near the ends are kept small, so the extreme quantiles, like the
+// latency percentiles, are the most accurate.
+type digest struct {
+	centroids []centroid
+	buf       []centroid
+	total     float64
+	min, max  float64
+}
+
+func newDigest() *digest {
+	return &digest{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (d *digest) add(v float64) {
+	d.buf = append(d.buf, centroid{mean: v, weight: 1})
+	d.total++
+	d.min = math.Min(d.min, v)
+	d.max = math.Max(d.max, v)
+	if len(d.buf) >= digestBufferSize {
+		d.compress()
+	}
+}
+
+// merge adds the values summarized by other to d.
+func (d *digest) merge(other *digest) {
+	other.compress()
+	if other.total == 0 {
+		return
+	}
+	d.buf = append(d.buf, other.centroids...)
+	d.total += other.total
+	d.min = math.Min(d.min, other.min)
+	d.max = math.Max(d.max, other.max)
+	d.compress()
+}
+
+// compress merges the buffered values into the centroids. A centroid can take the weight
+// 4 * total * q * (1-q) / compression, where q is its quantile.
+func (d *digest) compress() {
+	if len(d.buf) == 0 {
+		return
+	}
+	all := append(d.centroids, d.buf...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, 2*digestCompression)
+	merged = append(merged, all[0])
+	var before float64
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		q := (before + last.weight + c.weight/2) / d.total
+		if last.weight+c.weight <= 4*d.total*q*(1-q)/digestCompression {
+			last.weight += c.weight
+			last.mean += (c.mean - last.mean) * c.weight / last.weight
+			continue
+		}
+		before += last.weight
+		merged = append(merged, c)
+	}
+	d.centroids = merged
+	d.buf = d.buf[:0]
+}
+
+// quantile returns the approximate value at the quantile q, between 0 and 1, by interpolating
+// between the centers of the centroids.
+func (d *digest) quantile(q float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	target := q * d.total
+	prevMean, prevCenter := d.min, 0.0
+	var cum float64
+	for _, c := range d.centroids {
+		center := cum + c.weight/2
+		if target < center {
+			return prevMean + (c.mean-prevMean)*(target-prevCenter)/(center-prevCenter)
+		}
+		prevMean, prevCenter = c.mean, center
+		cum += c.weight
+	}
+	if cum == prevCenter {
+		return d.max
+	}
+	return prevMean + (d.max-prevMean)*(target-prevCenter)/(cum-prevCenter)
+}
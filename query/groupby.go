@@ -17,7 +17,6 @@
 package query
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 
@@ -59,7 +58,7 @@ func (grp *groupResult) aggregateChild(child *SubGraph) error {
 	}
 	if child.SrcFunc != nil && isAggregatorFn(child.SrcFunc.Name) {
 		if fieldName == "" {
-			fieldName = fmt.Sprintf("%s(%s)", child.SrcFunc.Name, child.Attr)
+			fieldName = aggregatorFieldName(child.SrcFunc, child.Attr)
 		}
 		finalVal, err := aggregateGroup(grp, child)
 		if err != nil {
@@ -140,8 +139,9 @@ func (d *dedup) addValue(attr string, value types.Val, uid uint64) {
 }
 
 func aggregateGroup(grp *groupResult, child *SubGraph) (types.Val, error) {
-	ag := aggregator{
-		name: child.SrcFunc.Name,
+	ag, err := newAggregator(child.SrcFunc)
+	if err != nil {
+		return types.Val{}, err
 	}
 	for _, uid := range grp.uids {
 		// TODO(Ahsan): We can have Rank API on sroar.
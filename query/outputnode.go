@@ -684,6 +684,8 @@ func valToBytes(v types.Val) ([]byte, error) {
 		return []byte(fmt.Sprintf("\"%#x\"", v.Value)), nil
 	case types.PasswordID:
 		return []byte(fmt.Sprintf("%q", v.Value.(string))), nil
+	case types.VFloatID:
+		return json.Marshal(v.Value.([]float32))
 	default:
 		return nil, errors.New("Unsupported types.Val.Tid")
 	}
@@ -1066,6 +1068,9 @@ func processNodeUids(fj fastJsonNode, enc *encoder, sg *SubGraph) error {
 	enc.curSize += uint64(len(sg.Params.Alias))
 
 	attrID := enc.idForAttr(sg.Params.Alias)
+	if sg.pathMeta != nil && len(sg.pathMeta.nodes) > 0 {
+		return sg.addPathNode(enc, fj, attrID)
+	}
 	if sg.uidMatrix == nil {
 		enc.AddListChild(fj, enc.newNode(attrID))
 		return nil
@@ -1136,11 +1141,44 @@ func processNodeUids(fj fastJsonNode, enc *encoder, sg *SubGraph) error {
 	return nil
 }
 
+// addPathNode adds a path found by the path enumeration mode of shortest path queries. It is
+// encoded as the list of the uids of the nodes of the path, the list of the predicates of the
+// edges between them and the weight of the path, e.g.
+// {"uids": ["0x1", "0x2", "0x3"], "preds": ["friend", "works_at"], "_weight_": 2}
+func (sg *SubGraph) addPathNode(enc *encoder, fj fastJsonNode, attrID uint16) error {
+	n := enc.newNode(attrID)
+	uidsAttr := enc.idForAttr("uids")
+	for _, uid := range sg.pathMeta.nodes {
+		if err := enc.AddListValue(n, uidsAttr,
+			types.Val{Tid: types.UidID, Value: uid}, true); err != nil {
+			return err
+		}
+	}
+	predsAttr := enc.idForAttr("preds")
+	for _, pred := range sg.pathMeta.preds {
+		if err := enc.AddListValue(n, predsAttr,
+			types.Val{Tid: types.StringID, Value: pred}, true); err != nil {
+			return err
+		}
+	}
+	totalWeight := types.Val{
+		Tid:   types.FloatID,
+		Value: sg.pathMeta.weight,
+	}
+	if err := enc.AddValue(n, enc.idForAttr("_weight_"), totalWeight); err != nil {
+		return err
+	}
+	enc.AddListChild(fj, n)
+	return nil
+}
+
 // Extensions represents the extra information appended to query results.
 type Extensions struct {
-	Latency *api.Latency    `json:"server_latency,omitempty"`
-	Txn     *api.TxnContext `json:"txn,omitempty"`
-	Metrics *api.Metrics    `json:"metrics,omitempty"`
+	Latency *api.Latency      `json:"server_latency,omitempty"`
+	Txn     *api.TxnContext   `json:"txn,omitempty"`
+	Metrics *api.Metrics      `json:"metrics,omitempty"`
+	Cursors map[string]string `json:"cursors,omitempty"`
+	Plan    *Plan             `json:"plan,omitempty"`
 }
 
 func (sg *SubGraph) toFastJSON(
@@ -1203,17 +1241,33 @@ func (sg *SubGraph) toGraphqlJSON(genc *graphQLEncoder, n fastJsonNode, f gqlSch
 	// implying that the root fastJson node will always have at least one child. So, no need
 	// to check for the case where there are no children for the root fastJson node.
 
-	// if this field has any @custom(http: {...}) children,
-	// then need to resolve them first before encoding the final GraphQL result.
-	genc.processCustomFields(f, n)
-	// now encode the GraphQL results.
-	if !genc.encode(encodeInput{
-		parentField: nil,
-		parentPath:  f.PreAllocatePathSlice(),
-		fj:          n,
-		fjIsRoot:    true,
-		childSelSet: []gqlSchema.Field{f},
-	}) {
+	var ok bool
+	if q, isQuery := f.(gqlSchema.Query); isQuery && q.QueryType() == gqlSchema.ConnectionQuery {
+		// the @custom(http: {...}) fields of a connection query can only be in its nodes.
+		if node, _ := q.ConnectionNode(); node != nil {
+			genc.processCustomFields(node, n)
+		}
+		var block *SubGraph
+		for _, child := range sg.Children {
+			if child.fieldName() == q.DgraphAlias() {
+				block = child
+			}
+		}
+		ok = genc.completeRootConnectionQuery(n, q, block)
+	} else {
+		// if this field has any @custom(http: {...}) children,
+		// then need to resolve them first before encoding the final GraphQL result.
+		genc.processCustomFields(f, n)
+		// now encode the GraphQL results.
+		ok = genc.encode(encodeInput{
+			parentField: nil,
+			parentPath:  f.PreAllocatePathSlice(),
+			fj:          n,
+			fjIsRoot:    true,
+			childSelSet: []gqlSchema.Field{f},
+		})
+	}
+	if !ok {
 		// if genc.encode() didn't finish successfully here, that means we need to send
 		// data as null in the GraphQL response like this:
 		// 		{
@@ -1262,7 +1316,7 @@ func (sg *SubGraph) aggWithVarFieldName() string {
 	if len(sg.Params.NeedsVar) > 0 {
 		fieldName = fmt.Sprintf("val(%v)", sg.Params.NeedsVar[0].Name)
 		if sg.SrcFunc != nil {
-			fieldName = fmt.Sprintf("%s(%v)", sg.SrcFunc.Name, fieldName)
+			fieldName = aggregatorFieldName(sg.SrcFunc, fieldName)
 		}
 	}
 	return fieldName
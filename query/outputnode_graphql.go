@@ -19,12 +19,15 @@ package query
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/pkg/errors"
+
 	gqlSchema "github.com/dgraph-io/dgraph/graphql/schema"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -1206,6 +1209,212 @@ func (genc *graphQLEncoder) completeAggregateChildren(fj fastJsonNode,
 	return fj
 }
 
+// completeRootConnectionQuery builds GraphQL JSON for Relay connection queries at root.
+// Dgraph returns the nodes of the page like it does for filter queries, along with one more node
+// if there is a next page. The nodes are wrapped into edges with their cursors, and the page info
+// is added. The cursors are the DQL cursors of the nodes in block, the query block which returned
+// them.
+// Dgraph result:
+// 		{
+// 		  "queryPostConnection": [
+// 		    {
+// 		      "Post.title": "GraphQL",
+// 		      "dgraph.uid": "0x2712"
+// 		    }, {
+// 		      "Post.title": "Dgraph",
+// 		      "dgraph.uid": "0x2713"
+// 		    }
+// 		  ]
+// 		}
+// GraphQL result, for a page of one node:
+// 		{
+// 		  "queryPostConnection": {
+// 		    "edges": [
+// 		      {
+// 		        "node": {
+// 		          "title": "GraphQL"
+// 		        },
+// 		        "cursor": "eyJ1IjoxMDAwMn0"
+// 		      }
+// 		    ],
+// 		    "pageInfo": {
+// 		      "endCursor": "eyJ1IjoxMDAwMn0",
+// 		      "hasNextPage": true
+// 		    }
+// 		  }
+// 		}
+func (genc *graphQLEncoder) completeRootConnectionQuery(fj fastJsonNode,
+	query gqlSchema.Query, block *SubGraph) bool {
+	qryPath := append(query.PreAllocatePathSlice(), query.ResponseName())
+	_, first, err := query.ConnectionArgs()
+	if err != nil {
+		genc.errs = append(genc.errs, query.GqlErrorf(qryPath, err.Error()))
+		return false
+	}
+
+	var nodes []fastJsonNode
+	for child := genc.children(fj); child != nil; child = child.next {
+		nodes = append(nodes, child)
+	}
+	hasNextPage := first >= 0 && int64(len(nodes)) > first
+	if hasNextPage {
+		nodes = nodes[:first]
+	}
+	cursors, err := genc.connectionCursors(nodes, block)
+	if err != nil {
+		genc.errs = append(genc.errs, query.GqlErrorf(qryPath, err.Error()))
+		return false
+	}
+
+	x.Check2(genc.buf.WriteRune('{'))
+	query.CompleteAlias(genc.buf)
+	keyEndPos := genc.buf.Len()
+	x.Check2(genc.buf.WriteRune('{'))
+	comma := ""
+	for _, f := range query.SelectionSet() {
+		if f.Skip() || !f.Include() {
+			continue
+		}
+		x.Check2(genc.buf.WriteString(comma))
+		f.CompleteAlias(genc.buf)
+
+		switch f.Name() {
+		case gqlSchema.Typename:
+			x.Check2(genc.buf.Write(getTypename(f, nil)))
+		case "edges":
+			if !genc.completeConnectionEdges(nodes, cursors, f,
+				append(qryPath, f.ResponseName())) {
+				// The edges can't be null, so the whole connection is null.
+				genc.buf.Truncate(keyEndPos)
+				x.Check2(genc.buf.Write(gqlSchema.JsonNull))
+				x.Check2(genc.buf.WriteRune('}'))
+				return true
+			}
+		case "pageInfo":
+			genc.completePageInfo(cursors, f, hasNextPage)
+		}
+		comma = ","
+	}
+	x.Check2(genc.buf.WriteString("}}"))
+	return true
+}
+
+// connectionCursors returns the cursors of the nodes of a connection query, which are built from
+// the uids of the nodes and the values of their sort keys, kept by block while it was processed.
+func (genc *graphQLEncoder) connectionCursors(nodes []fastJsonNode,
+	block *SubGraph) ([]string, error) {
+	if block == nil {
+		return nil, errors.Errorf("couldn't find the query block of the connection")
+	}
+	cursors := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		uid, err := genc.nodeUID(node)
+		if err != nil {
+			return nil, err
+		}
+		c, err := newCursor(block.sortVals[uid], uid)
+		if err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, c)
+	}
+	return cursors, nil
+}
+
+// nodeUID returns the uid of the node fj in the Dgraph result. The uid is requested for all the
+// nodes by the GraphQL layer, and it's the only value of a node which is stored as a uid.
+func (genc *graphQLEncoder) nodeUID(fj fastJsonNode) (uint64, error) {
+	for child := genc.children(fj); child != nil; child = child.next {
+		if child.meta&uidNodeBit == 0 {
+			continue
+		}
+		data, err := genc.arena.get(uint32(child.meta & setBytes4321))
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(data), nil
+	}
+	return 0, errors.Errorf("couldn't find the uid of a node")
+}
+
+// completeConnectionEdges writes the edges of a connection query for the given nodes and their
+// cursors. It returns false if a node couldn't be written, as the edges can't be null then.
+func (genc *graphQLEncoder) completeConnectionEdges(nodes []fastJsonNode, cursors []string,
+	edges gqlSchema.Field, edgesPath []interface{}) bool {
+	x.Check2(genc.buf.WriteRune('['))
+	for i, node := range nodes {
+		if i > 0 {
+			x.Check2(genc.buf.WriteRune(','))
+		}
+		x.Check2(genc.buf.WriteRune('{'))
+		comma := ""
+		for _, f := range edges.SelectionSet() {
+			if f.Skip() || !f.Include() {
+				continue
+			}
+			x.Check2(genc.buf.WriteString(comma))
+			f.CompleteAlias(genc.buf)
+
+			switch f.Name() {
+			case gqlSchema.Typename:
+				x.Check2(genc.buf.Write(getTypename(f, nil)))
+			case "cursor":
+				x.Check2(genc.buf.WriteString(strconv.Quote(cursors[i])))
+			case "node":
+				if !genc.encode(encodeInput{
+					parentField: f,
+					parentPath:  append(edgesPath, i, f.ResponseName()),
+					fj:          node,
+					fjIsRoot:    false,
+					childSelSet: f.SelectionSet(),
+				}) {
+					return false
+				}
+			}
+			comma = ","
+		}
+		x.Check2(genc.buf.WriteRune('}'))
+	}
+	x.Check2(genc.buf.WriteRune(']'))
+	return true
+}
+
+// completePageInfo writes the page info of a connection query, for a page with the nodes of the
+// given cursors. Only forward pagination is supported, for which Relay lets hasPreviousPage be
+// false when telling whether there are nodes before the page would take another query.
+func (genc *graphQLEncoder) completePageInfo(cursors []string, pageInfo gqlSchema.Field,
+	hasNextPage bool) {
+	x.Check2(genc.buf.WriteRune('{'))
+	comma := ""
+	for _, f := range pageInfo.SelectionSet() {
+		if f.Skip() || !f.Include() {
+			continue
+		}
+		x.Check2(genc.buf.WriteString(comma))
+		f.CompleteAlias(genc.buf)
+
+		switch f.Name() {
+		case gqlSchema.Typename:
+			x.Check2(genc.buf.Write(getTypename(f, nil)))
+		case "startCursor", "endCursor":
+			switch {
+			case len(cursors) == 0:
+				x.Check2(genc.buf.Write(gqlSchema.JsonNull))
+			case f.Name() == "startCursor":
+				x.Check2(genc.buf.WriteString(strconv.Quote(cursors[0])))
+			default:
+				x.Check2(genc.buf.WriteString(strconv.Quote(cursors[len(cursors)-1])))
+			}
+		case "hasNextPage":
+			x.Check2(genc.buf.WriteString(strconv.FormatBool(hasNextPage)))
+		case "hasPreviousPage":
+			x.Check2(genc.buf.WriteString("false"))
+		}
+		comma = ","
+	}
+	x.Check2(genc.buf.WriteRune('}'))
+}
+
 // completeGeoObject builds a json GraphQL result object for the underlying geo type.
 // Currently, it supports Point, Polygon and MultiPolygon.
 func completeGeoObject(path []interface{}, field gqlSchema.Field, val map[string]interface{},
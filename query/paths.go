@@ -0,0 +1,412 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+	"github.com/dgraph-io/sroar"
+	"github.com/pkg/errors"
+)
+
+// Shortest path queries can also enumerate all the simple paths, i.e. paths which don't visit a
+// node twice, between the from and to nodes. This is done by passing allpaths: true or a path
+// pattern. The paths are explored in the order of their number of hops, up to the given depth,
+// and at most numpaths of them are returned if it is given.
+//
+//	shortest(from: 0x1, to: 0x2, allpaths: true, depth: 4) { friend colleague }
+//	shortest(from: 0x1, to: 0x2, pattern: "friend+/works_at") { friend works_at }
+//
+// A pattern is a sequence of steps separated by '/'. A step is a predicate or an alternation of
+// predicates like (friend|colleague), optionally followed by one of the quantifiers '?' (zero or
+// one edge), '+' (one or more edges) and '*' (zero or more edges). Without a quantifier, a step
+// matches exactly one edge. Without a pattern, the paths can follow the edges of any of the
+// predicates of the block.
+const (
+	pathsAlias = "_paths_"
+	// maxPatternSteps is the number of steps which fit in the pathStates bitmap.
+	maxPatternSteps = 31
+)
+
+// pathStep is a single step of a path pattern.
+type pathStep struct {
+	preds    map[string]struct{}
+	optional bool // The step can be skipped, for the ? and * quantifiers.
+	repeat   bool // The step can match more than one edge, for the + and * quantifiers.
+}
+
+// pathPattern is a compiled path pattern. It is matched by simulating the automaton formed by its
+// steps over the predicates of the edges of a path.
+type pathPattern struct {
+	steps []pathStep
+	preds []string // sorted list of all the predicates in the pattern.
+}
+
+// pathStates is the set of positions the pattern can be in after matching a path. Position 2*i
+// means that step i hasn't matched any edge yet and 2*i+1 that it has matched at least one edge.
+// Position 2*len(steps) means that all the steps have been matched.
+type pathStates uint64
+
+func parsePathPattern(pattern string) (*pathPattern, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.Errorf("Path pattern can't be empty")
+	}
+	pp := &pathPattern{}
+	seen := make(map[string]struct{})
+	for _, s := range strings.Split(pattern, "/") {
+		s = strings.TrimSpace(s)
+		var step pathStep
+		if len(s) > 0 {
+			switch s[len(s)-1] {
+			case '?':
+				step.optional = true
+			case '+':
+				step.repeat = true
+			case '*':
+				step.optional, step.repeat = true, true
+			}
+			if step.optional || step.repeat {
+				s = strings.TrimSpace(s[:len(s)-1])
+			}
+		}
+		if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+			s = s[1 : len(s)-1]
+		}
+		step.preds = make(map[string]struct{})
+		for _, pred := range strings.Split(s, "|") {
+			pred = strings.TrimSpace(pred)
+			if pred == "" || strings.ContainsAny(pred, "()?+*") {
+				return nil, errors.Errorf("Invalid step %q in path pattern %q", s, pattern)
+			}
+			step.preds[pred] = struct{}{}
+			if _, ok := seen[pred]; !ok {
+				seen[pred] = struct{}{}
+				pp.preds = append(pp.preds, pred)
+			}
+		}
+		pp.steps = append(pp.steps, step)
+	}
+	if len(pp.steps) > maxPatternSteps {
+		return nil, errors.Errorf("Path pattern can have at most %d steps. Got: %d",
+			maxPatternSteps, len(pp.steps))
+	}
+	sort.Strings(pp.preds)
+	return pp, nil
+}
+
+// anyPathPattern returns the pattern which matches the paths made of the given predicates.
+func anyPathPattern(preds []string) *pathPattern {
+	step := pathStep{preds: make(map[string]struct{}), optional: true, repeat: true}
+	pp := &pathPattern{}
+	for _, pred := range preds {
+		if _, ok := step.preds[pred]; ok {
+			continue
+		}
+		step.preds[pred] = struct{}{}
+		pp.preds = append(pp.preds, pred)
+	}
+	sort.Strings(pp.preds)
+	pp.steps = []pathStep{step}
+	return pp
+}
+
+// addChildren adds the predicates of the pattern which aren't already queried inside the
+// shortest path block as its children, so that their edges can be expanded.
+func (pp *pathPattern) addChildren(gq *gql.GraphQuery) {
+	attrs := make(map[string]struct{})
+	for _, child := range gq.Children {
+		attrs[child.Attr] = struct{}{}
+	}
+	for _, pred := range pp.preds {
+		if _, ok := attrs[pred]; !ok {
+			gq.Children = append(gq.Children, &gql.GraphQuery{Attr: pred})
+		}
+	}
+}
+
+// closure adds the positions reachable by skipping the steps which are optional or have already
+// matched an edge.
+func (pp *pathPattern) closure(states pathStates) pathStates {
+	for i, step := range pp.steps {
+		if states&(1<<(2*i+1)) != 0 || (step.optional && states&(1<<(2*i)) != 0) {
+			states |= 1 << (2*i + 2)
+		}
+	}
+	return states
+}
+
+func (pp *pathPattern) start() pathStates {
+	return pp.closure(1)
+}
+
+// next returns the positions of the pattern after following an edge of the predicate. No
+// positions are returned if the pattern doesn't allow the edge.
+func (pp *pathPattern) next(states pathStates, pred string) pathStates {
+	var res pathStates
+	for i, step := range pp.steps {
+		if _, ok := step.preds[pred]; !ok {
+			continue
+		}
+		if states&(1<<(2*i)) != 0 || (step.repeat && states&(1<<(2*i+1)) != 0) {
+			res |= 1 << (2*i + 1)
+		}
+	}
+	return pp.closure(res)
+}
+
+func (pp *pathPattern) accepts(states pathStates) bool {
+	return states&(1<<(2*len(pp.steps))) != 0
+}
+
+// enumeratedPath is a path found while enumerating the paths from the source node.
+type enumeratedPath struct {
+	nodes  []uint64
+	preds  []string
+	weight float64
+	states pathStates
+}
+
+func (p *enumeratedPath) contains(uid uint64) bool {
+	for _, n := range p.nodes {
+		if n == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *enumeratedPath) extend(uid uint64, pred string, weight float64,
+	states pathStates) *enumeratedPath {
+	np := &enumeratedPath{
+		nodes:  make([]uint64, len(p.nodes), len(p.nodes)+1),
+		preds:  make([]string, len(p.preds), len(p.preds)+1),
+		weight: weight,
+		states: states,
+	}
+	copy(np.nodes, p.nodes)
+	copy(np.preds, p.preds)
+	np.nodes = append(np.nodes, uid)
+	np.preds = append(np.preds, pred)
+	return np
+}
+
+type pathEdge struct {
+	uid  uint64
+	cost float64
+}
+
+// fetchPathEdges expands the last nodes of the paths through the predicates the pattern allows
+// them to follow. The edges are returned by predicate and source node.
+func (sg *SubGraph) fetchPathEdges(ctx context.Context, pattern *pathPattern,
+	weights *pathWeights, paths []*enumeratedPath) (map[string]map[uint64][]pathEdge, error) {
+
+	children := make(map[string]*SubGraph)
+	for _, child := range sg.Children {
+		if _, ok := children[child.Attr]; !ok {
+			children[child.Attr] = child
+		}
+	}
+
+	var exec []*SubGraph
+	for _, pred := range pattern.preds {
+		child, ok := children[pred]
+		if !ok {
+			continue
+		}
+		src := sroar.NewBitmap()
+		for _, p := range paths {
+			if pattern.next(p.states, pred) != 0 {
+				src.Set(p.nodes[len(p.nodes)-1])
+			}
+		}
+		if src.IsEmpty() {
+			continue
+		}
+		temp := new(SubGraph)
+		temp.copyFiltersRecurse(child)
+		temp.SrcUIDs = codec.ToSortedList(src)
+		exec = append(exec, temp)
+	}
+
+	rch := make(chan error, len(exec))
+	dummy := &SubGraph{}
+	for _, subgraph := range exec {
+		go ProcessGraph(ctx, subgraph, dummy, rch)
+	}
+	var rerr error
+	for range exec {
+		select {
+		case err := <-rch:
+			if err != nil && rerr == nil {
+				rerr = err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+
+	edges := make(map[string]map[uint64][]pathEdge)
+	for _, subgraph := range exec {
+		if subgraph.UnknownAttr {
+			continue
+		}
+		// See expandOut for why updateUidMatrix needs to be called here.
+		subgraph.updateUidMatrix()
+		if err := weights.fetch(ctx, sg, subgraph.DestMap.ToArray()); err != nil {
+			return nil, err
+		}
+		bySrc := make(map[uint64][]pathEdge)
+		for mIdx, fromUID := range codec.GetUids(subgraph.SrcUIDs) {
+			if mIdx >= len(subgraph.uidMatrix) {
+				continue
+			}
+			for lIdx, toUID := range codec.GetUids(subgraph.uidMatrix[mIdx]) {
+				cost, _, err := subgraph.edgeCost(weights, mIdx, lIdx, toUID)
+				switch {
+				case err == errFacet:
+					continue
+				case err != nil:
+					return nil, err
+				}
+				bySrc[fromUID] = append(bySrc[fromUID], pathEdge{uid: toUID, cost: cost})
+			}
+		}
+		edges[subgraph.Attr] = bySrc
+	}
+	return edges, nil
+}
+
+// allPaths enumerates the simple paths between the from and to nodes of the shortest path query
+// which match its path pattern. The paths are found level by level, so shorter paths are
+// returned first.
+func allPaths(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
+	pattern := sg.Params.PathPattern
+	if pattern == nil {
+		var preds []string
+		for _, child := range sg.Children {
+			preds = append(preds, child.Attr)
+		}
+		pattern = anyPathPattern(preds)
+	}
+	maxHops := math.MaxInt32
+	if sg.Params.ExploreDepth != nil {
+		maxHops = int(*sg.Params.ExploreDepth)
+	}
+	numPaths := sg.Params.NumPaths
+
+	weights := newPathWeights(sg)
+	if err := weights.fetch(ctx, sg, []uint64{sg.Params.From}); err != nil {
+		return nil, err
+	}
+
+	var paths []*enumeratedPath
+	found := func(p *enumeratedPath) bool {
+		if p.weight >= sg.Params.MinWeight && pattern.accepts(p.states) {
+			paths = append(paths, p)
+		}
+		return numPaths > 0 && len(paths) >= numPaths
+	}
+
+	src := &enumeratedPath{nodes: []uint64{sg.Params.From}, states: pattern.start()}
+	frontier := []*enumeratedPath{src}
+	if sg.Params.From == sg.Params.To {
+		// A simple path can't come back to its source, so the source is the only path.
+		found(src)
+		frontier = nil
+	}
+
+	var numPartial uint64
+loop:
+	for hop := 0; hop < maxHops && len(frontier) > 0; hop++ {
+		edges, err := sg.fetchPathEdges(ctx, pattern, weights, frontier)
+		if err != nil {
+			return nil, err
+		}
+		var next []*enumeratedPath
+		for _, p := range frontier {
+			last := p.nodes[len(p.nodes)-1]
+			for _, pred := range pattern.preds {
+				states := pattern.next(p.states, pred)
+				if states == 0 {
+					continue
+				}
+				for _, edge := range edges[pred][last] {
+					weight := p.weight + edge.cost
+					if weight > sg.Params.MaxWeight || p.contains(edge.uid) {
+						continue
+					}
+					np := p.extend(edge.uid, pred, weight, states)
+					if edge.uid != sg.Params.To {
+						next = append(next, np)
+						continue
+					}
+					// The path can't be extended past the destination as it would have to
+					// visit it again to end there.
+					if found(np) {
+						break loop
+					}
+				}
+			}
+		}
+		numPartial += uint64(len(next))
+		if numPartial > x.Config.LimitQueryEdge {
+			// Like with the edges of shortest path queries, stop if too many paths are explored.
+			return nil, errors.Errorf("Exceeded query edge limit = %v. Found %v partial paths.",
+				x.Config.LimitQueryEdge, numPartial)
+		}
+		frontier = next
+	}
+
+	sg.DestMap = sroar.NewBitmap()
+	for _, p := range paths {
+		sg.DestMap.SetMany(p.nodes)
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	sg.OrderedUIDs = &pb.List{SortedUids: sg.DestMap.ToArray()}
+	return createPathsSubgraphs(paths), nil
+}
+
+// createPathsSubgraphs returns a subgraph for every path, which is encoded as a list of the uids
+// of its nodes and a list of the predicates followed between them.
+func createPathsSubgraphs(paths []*enumeratedPath) []*SubGraph {
+	res := make([]*SubGraph, 0, len(paths))
+	for _, p := range paths {
+		pathSg := new(SubGraph)
+		pathSg.Params = params{
+			Alias: pathsAlias,
+		}
+		pathSg.pathMeta = &pathMetadata{
+			weight: p.weight,
+			nodes:  p.nodes,
+			preds:  p.preds,
+		}
+		res = append(res, pathSg)
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/stretchr/testify/require"
+)
+
+// matchPath returns true if the pattern matches the sequence of predicates.
+func matchPath(pp *pathPattern, preds ...string) bool {
+	states := pp.start()
+	for _, pred := range preds {
+		states = pp.next(states, pred)
+		if states == 0 {
+			return false
+		}
+	}
+	return pp.accepts(states)
+}
+
+func TestPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   [][]string
+		noMatch [][]string
+	}{
+		{
+			pattern: "friend+/works_at",
+			match:   [][]string{{"friend", "works_at"}, {"friend", "friend", "works_at"}},
+			noMatch: [][]string{{"works_at"}, {"friend"}, {"friend", "works_at", "works_at"}},
+		},
+		{
+			pattern: "(friend|colleague)*/works_at?",
+			match: [][]string{{}, {"colleague", "friend"}, {"works_at"},
+				{"friend", "works_at"}},
+			noMatch: [][]string{{"works_at", "friend"}, {"manager"}},
+		},
+		{
+			pattern: "friend/friend",
+			match:   [][]string{{"friend", "friend"}},
+			noMatch: [][]string{{"friend"}, {"friend", "friend", "friend"}},
+		},
+	}
+	for _, tc := range tests {
+		pp, err := parsePathPattern(tc.pattern)
+		require.NoError(t, err)
+		for _, preds := range tc.match {
+			require.True(t, matchPath(pp, preds...), "%s should match %v", tc.pattern, preds)
+		}
+		for _, preds := range tc.noMatch {
+			require.False(t, matchPath(pp, preds...), "%s shouldn't match %v", tc.pattern,
+				preds)
+		}
+	}
+}
+
+func TestPathPatternPreds(t *testing.T) {
+	pp, err := parsePathPattern("works_at/(friend|~friend)+/works_at")
+	require.NoError(t, err)
+	require.Equal(t, []string{"friend", "works_at", "~friend"}, pp.preds)
+
+	gq := &gql.GraphQuery{Children: []*gql.GraphQuery{{Attr: "friend"}}}
+	pp.addChildren(gq)
+	require.Len(t, gq.Children, 3)
+	require.Equal(t, "works_at", gq.Children[1].Attr)
+	require.Equal(t, "~friend", gq.Children[2].Attr)
+}
+
+func TestInvalidPathPattern(t *testing.T) {
+	for _, pattern := range []string{"", "friend//works_at", "(friend+)", "friend|", "+"} {
+		_, err := parsePathPattern(pattern)
+		require.Error(t, err, pattern)
+	}
+}
+
+func TestAnyPathPattern(t *testing.T) {
+	pp := anyPathPattern([]string{"friend", "colleague", "friend"})
+	require.Equal(t, []string{"colleague", "friend"}, pp.preds)
+	require.True(t, matchPath(pp))
+	require.True(t, matchPath(pp, "friend", "colleague", "friend"))
+	require.False(t, matchPath(pp, "works_at"))
+}
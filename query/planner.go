@@ -0,0 +1,304 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The planner rewrites a query block before it's executed, using estimates of the number of
+// UIDs matched by its functions. The estimates are the lengths of the index posting lists that
+// the functions read, so only the functions answered from an index can be estimated. The
+// planner only looks at the function and the filters of the root of the block:
+//  * A has function at root is swapped with the most selective eq filter ANDed with it, so that
+//    the UIDs come from the index lookup and has only has to check them.
+//  * The branches of an AND filter that can be estimated run first, from the most selective one.
+//    Each branch then only checks the UIDs that passed the previous ones.
+// Each estimate is a round trip to the group serving the predicate, so the planner is disabled
+// unless the query-planner limit allows it to make some estimates for each block. The explain
+// mode doesn't change the plan: it only estimates the functions that the planner didn't, once
+// the block has been planned, so that all the estimates can be reported.
+
+// planner holds the state of the planning of a query block.
+type planner struct {
+	// estimates is the number of estimates that the planner can still make.
+	estimates int
+	// explaining is set once the block has been planned, when the estimates are only made to
+	// be reported. They aren't limited then.
+	explaining bool
+}
+
+// planInfo holds the decisions of the planner for a SubGraph.
+type planInfo struct {
+	// estimate is the number of UIDs expected to match the function of the node. It's only set
+	// if estimated is true.
+	estimate  uint64
+	estimated bool
+	// rewrite describes how the planner changed the node, if it did.
+	rewrite string
+	// ordered is set if the filters of the node must run one after the other, in order.
+	ordered bool
+}
+
+// Plan describes how the blocks of a query were executed, for the explain mode.
+type Plan struct {
+	Blocks []*PlanNode `json:"blocks"`
+}
+
+// PlanNode describes how a SubGraph was executed.
+type PlanNode struct {
+	Alias string `json:"alias,omitempty"`
+	Attr  string `json:"attr,omitempty"`
+	Func  string `json:"func,omitempty"`
+	Op    string `json:"op,omitempty"`
+	// Rewrite describes how the planner changed the node, if it did.
+	Rewrite string `json:"rewrite,omitempty"`
+	// Ordered is set if the filters ran one after the other, in order.
+	Ordered bool `json:"ordered,omitempty"`
+	// Estimated is the number of UIDs the planner expected the function to match.
+	Estimated *uint64 `json:"estimated,omitempty"`
+	// Actual is the number of UIDs the node ended up with.
+	Actual   *uint64     `json:"actual,omitempty"`
+	Filters  []*PlanNode `json:"filters,omitempty"`
+	Children []*PlanNode `json:"children,omitempty"`
+}
+
+func isExplain(ctx context.Context) bool {
+	_, ok := ctx.Value(ExplainKey).(*Plan)
+	return ok
+}
+
+// planBlock plans the root of a query block. In explain mode, all the functions that can be
+// estimated are, so that the estimates can be compared with the results.
+func (sg *SubGraph) planBlock(ctx context.Context) error {
+	p := &planner{estimates: x.Config.LimitQueryPlanner}
+	if p.estimates > 0 {
+		if err := sg.swapHasAtRoot(ctx, p); err != nil {
+			return err
+		}
+		for _, filter := range sg.Filters {
+			if err := filter.planFilter(ctx, p); err != nil {
+				return err
+			}
+		}
+	}
+	if !isExplain(ctx) {
+		return nil
+	}
+	p.explaining = true
+	return sg.estimateAll(ctx, p)
+}
+
+// estimateAll estimates the function of the node and of all the filters under it.
+func (sg *SubGraph) estimateAll(ctx context.Context, p *planner) error {
+	if err := sg.estimate(ctx, p); err != nil {
+		return err
+	}
+	for _, filter := range sg.Filters {
+		if err := filter.estimateAll(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swapHasAtRoot replaces a has function at root by the most selective eq filter ANDed with it.
+// The has function becomes a filter instead, which only checks the UIDs matched by eq rather
+// than iterating over the whole predicate.
+func (sg *SubGraph) swapHasAtRoot(ctx context.Context, p *planner) error {
+	fn := sg.SrcFunc
+	if fn == nil || fn.Name != "has" || len(sg.Filters) != 1 || len(sg.Params.Langs) > 0 ||
+		strings.HasPrefix(sg.Attr, "~") {
+		return nil
+	}
+
+	parent, candidates := sg, sg.Filters
+	if f := sg.Filters[0]; f.FilterOp == "and" {
+		parent, candidates = f, f.Filters
+	}
+	best := -1
+	for i, f := range candidates {
+		if f.SrcFunc == nil || f.SrcFunc.Name != "eq" || len(f.Params.Langs) > 0 ||
+			strings.HasPrefix(f.Attr, "~") {
+			continue
+		}
+		if err := f.estimate(ctx, p); err != nil {
+			return err
+		}
+		if !f.isEstimated() {
+			continue
+		}
+		if best < 0 || f.plan.estimate < candidates[best].plan.estimate {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	eqSg := candidates[best]
+	parent.Filters[best] = &SubGraph{
+		ReadTs:  sg.ReadTs,
+		Cache:   sg.Cache,
+		Attr:    sg.Attr,
+		SrcFunc: sg.SrcFunc,
+		plan:    &planInfo{rewrite: "moved from the root"},
+	}
+	sg.plan = &planInfo{
+		estimate:  eqSg.plan.estimate,
+		estimated: true,
+		rewrite:   fmt.Sprintf("swapped with the filter has(%s)", sg.Attr),
+	}
+	sg.Attr, sg.SrcFunc = eqSg.Attr, eqSg.SrcFunc
+	return nil
+}
+
+// planFilter plans the filter tree under the node, running the branches of AND filters from
+// the most selective one.
+func (sg *SubGraph) planFilter(ctx context.Context, p *planner) error {
+	for _, filter := range sg.Filters {
+		if err := filter.planFilter(ctx, p); err != nil {
+			return err
+		}
+	}
+	if sg.FilterOp != "and" || len(sg.Filters) < 2 {
+		return nil
+	}
+
+	var estimated bool
+	for _, filter := range sg.Filters {
+		if err := filter.estimate(ctx, p); err != nil {
+			return err
+		}
+		estimated = estimated || filter.isEstimated()
+	}
+	if !estimated {
+		return nil
+	}
+	// The branches that can't be estimated keep their order, after the others.
+	sort.SliceStable(sg.Filters, func(i, j int) bool {
+		a, b := sg.Filters[i], sg.Filters[j]
+		if a.isEstimated() && b.isEstimated() {
+			return a.plan.estimate < b.plan.estimate
+		}
+		return a.isEstimated()
+	})
+	sg.plan = &planInfo{ordered: true}
+	return nil
+}
+
+func (sg *SubGraph) isEstimated() bool {
+	return sg.plan != nil && sg.plan.estimated
+}
+
+// canEstimate returns whether the number of UIDs matched by the function of the node can be
+// estimated. The worker only gives an estimate if the function is answered from an index.
+func canEstimate(sg *SubGraph) bool {
+	fn := sg.SrcFunc
+	if fn == nil || fn.IsCount || fn.IsValueVar || fn.IsLenVar || len(sg.Params.NeedsVar) > 0 {
+		return false
+	}
+	for _, arg := range fn.Args {
+		if arg.IsValueVar {
+			return false
+		}
+	}
+	switch fn.Name {
+	case "uid", "eq", "anyofterms", "allofterms", "anyoftext", "alloftext":
+		return true
+	}
+	return false
+}
+
+// estimate estimates the number of UIDs matched by the function of the node. Each node is only
+// estimated once, and the plan of the node is left without an estimate if it can't be made, or
+// if the planner ran out of estimates.
+func (sg *SubGraph) estimate(ctx context.Context, p *planner) error {
+	if sg.plan != nil || !canEstimate(sg) {
+		return nil
+	}
+	if sg.SrcFunc.Name == "uid" {
+		sg.plan = &planInfo{estimate: codec.ListCardinality(sg.SrcUIDs), estimated: true}
+		return nil
+	}
+	if !p.explaining {
+		if p.estimates <= 0 {
+			return nil
+		}
+		p.estimates--
+	}
+	sg.plan = &planInfo{}
+
+	q, err := createTaskQuery(ctx, sg)
+	if err != nil {
+		return err
+	}
+	q.UidList, q.First, q.Offset, q.DoCount = nil, 0, 0, false
+	q.Estimate = true
+	result, err := worker.ProcessTaskOverNetwork(ctx, q)
+	switch {
+	case err != nil && strings.Contains(err.Error(), worker.ErrNonExistentTabletMessage):
+		// Nothing has been stored for the predicate yet.
+		sg.plan.estimated = true
+	case err != nil:
+		return err
+	case len(result.Counts) == 1:
+		sg.plan.estimate, sg.plan.estimated = uint64(result.Counts[0]), true
+	}
+	return nil
+}
+
+// planNode describes how the SubGraph was executed, once it has been.
+func (sg *SubGraph) planNode() *PlanNode {
+	node := &PlanNode{Alias: sg.Params.Alias, Attr: sg.Attr, Op: sg.FilterOp}
+	if fn := sg.SrcFunc; fn != nil {
+		args := make([]string, 0, len(fn.Args)+1)
+		if sg.Attr != "" {
+			args = append(args, sg.Attr)
+		}
+		for _, arg := range fn.Args {
+			args = append(args, arg.Value)
+		}
+		node.Func = fmt.Sprintf("%s(%s)", fn.Name, strings.Join(args, ", "))
+	}
+	if sg.plan != nil {
+		node.Rewrite = sg.plan.rewrite
+		node.Ordered = sg.plan.ordered
+		if sg.plan.estimated {
+			estimate := sg.plan.estimate
+			node.Estimated = &estimate
+		}
+	}
+	if sg.DestMap != nil {
+		actual := uint64(sg.DestMap.GetCardinality())
+		node.Actual = &actual
+	}
+	for _, filter := range sg.Filters {
+		node.Filters = append(node.Filters, filter.planNode())
+	}
+	for _, child := range sg.Children {
+		node.Children = append(node.Children, child.planNode())
+	}
+	return node
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+	"github.com/stretchr/testify/require"
+)
+
+func estimated(sg *SubGraph, n uint64) *SubGraph {
+	sg.plan = &planInfo{estimate: n, estimated: true}
+	return sg
+}
+
+func eqFilter(attr, val string) *SubGraph {
+	return &SubGraph{Attr: attr, SrcFunc: &Function{Name: "eq", Args: []gql.Arg{{Value: val}}}}
+}
+
+func TestCanEstimate(t *testing.T) {
+	require.True(t, canEstimate(eqFilter("email", "a@b.c")))
+	require.False(t, canEstimate(&SubGraph{Attr: "name", SrcFunc: &Function{Name: "has"}}))
+	require.False(t, canEstimate(&SubGraph{FilterOp: "and"}))
+
+	sg := eqFilter("age", "a")
+	sg.SrcFunc.Args[0].IsValueVar = true
+	require.False(t, canEstimate(sg))
+}
+
+func TestSwapHasAtRoot(t *testing.T) {
+	email := estimated(eqFilter("email", "a@b.c"), 1)
+	status := estimated(eqFilter("status", "active"), 500)
+	root := &SubGraph{
+		Attr:    "name",
+		SrcFunc: &Function{Name: "has"},
+		Filters: []*SubGraph{{FilterOp: "and", Filters: []*SubGraph{status, email}}},
+	}
+	require.NoError(t, root.swapHasAtRoot(context.Background(), &planner{}))
+
+	require.Equal(t, "email", root.Attr)
+	require.Equal(t, "eq", root.SrcFunc.Name)
+	require.True(t, root.isEstimated())
+	require.Equal(t, uint64(1), root.plan.estimate)
+
+	and := root.Filters[0]
+	require.Equal(t, status, and.Filters[0])
+	require.Equal(t, "name", and.Filters[1].Attr)
+	require.Equal(t, "has", and.Filters[1].SrcFunc.Name)
+}
+
+func TestPlanFilterOrder(t *testing.T) {
+	has := &SubGraph{Attr: "name", SrcFunc: &Function{Name: "has"}}
+	email := estimated(eqFilter("email", "a@b.c"), 1)
+	status := estimated(eqFilter("status", "active"), 500)
+	and := &SubGraph{FilterOp: "and", Filters: []*SubGraph{has, status, email}}
+	require.NoError(t, and.planFilter(context.Background(), &planner{}))
+
+	require.Equal(t, []*SubGraph{email, status, has}, and.Filters)
+	require.True(t, and.plan.ordered)
+
+	node := and.planNode()
+	require.True(t, node.Ordered)
+	require.Equal(t, `eq(email, a@b.c)`, node.Filters[0].Func)
+	require.Equal(t, uint64(1), *node.Filters[0].Estimated)
+	require.Nil(t, node.Filters[2].Estimated)
+}
+
+func TestPlannerEstimates(t *testing.T) {
+	ctx := context.Background()
+
+	// Without estimates left, the functions aren't estimated, apart from uid which doesn't need
+	// a round trip.
+	email := eqFilter("email", "a@b.c")
+	uids := &SubGraph{
+		SrcFunc: &Function{Name: "uid"},
+		SrcUIDs: &pb.List{SortedUids: []uint64{1, 2}},
+	}
+	p := &planner{}
+	require.NoError(t, email.estimate(ctx, p))
+	require.NoError(t, uids.estimate(ctx, p))
+	require.False(t, email.isEstimated())
+	require.Nil(t, email.plan)
+	require.True(t, uids.isEstimated())
+	require.Equal(t, uint64(2), uids.plan.estimate)
+
+	// The planner is disabled by default, so the block is left as it is.
+	x.Config.LimitQueryPlanner = 0
+	root := &SubGraph{
+		Attr:    "name",
+		SrcFunc: &Function{Name: "has"},
+		Filters: []*SubGraph{eqFilter("email", "a@b.c")},
+	}
+	require.NoError(t, root.planBlock(ctx))
+	require.Equal(t, "has", root.SrcFunc.Name)
+	require.Nil(t, root.plan)
+	require.Nil(t, root.Filters[0].plan)
+
+	// The explain mode only reports the estimates, and leaves the block as it runs without it.
+	email = estimated(eqFilter("email", "a@b.c"), 1)
+	status := estimated(eqFilter("status", "active"), 500)
+	root = &SubGraph{
+		Attr:    "name",
+		SrcFunc: &Function{Name: "has"},
+		Filters: []*SubGraph{{FilterOp: "and", Filters: []*SubGraph{status, email}}},
+	}
+	explainCtx := context.WithValue(ctx, ExplainKey, &Plan{})
+	require.NoError(t, root.planBlock(explainCtx))
+	require.Equal(t, "has", root.SrcFunc.Name)
+	require.Nil(t, root.Filters[0].plan)
+	require.Equal(t, []*SubGraph{status, email}, root.Filters[0].Filters)
+}
@@ -119,6 +119,11 @@ type params struct {
 	Random int
 	// AfterUID is the value of the "after" parameter.
 	AfterUID uint64
+	// Cursor is the cursor to resume a sorted block from, given by the "cursor" parameter.
+	Cursor *cursor
+	// NodeCursors is true if a cursor is needed for every node returned by the block, rather
+	// than only for the last one. Used for the GraphQL connection queries.
+	NodeCursors bool
 	// DoCount is true if the count of the predicate is requested instead of its value.
 	DoCount bool
 	// GetUid is true if the uid should be returned. Used for debug requests.
@@ -180,6 +185,20 @@ type params struct {
 	MaxWeight float64
 	// MinWeight is the min weight allowed in a path returned by the shortest path algorithm.
 	MinWeight float64
+	// NodeCost holds the values of the value variable passed as cost to a shortest path query.
+	NodeCost map[uint64]types.Val
+	// Heuristic holds the values of the value variable passed as heuristic to a shortest path
+	// query.
+	Heuristic map[uint64]types.Val
+	// AllPaths is true when a shortest path query should return all the simple paths between the
+	// from and to nodes instead of the cheapest ones.
+	AllPaths bool
+	// PathPattern restricts the sequence of predicates of the paths returned by a shortest path
+	// query. It also makes the query return all the paths matching it.
+	PathPattern *pathPattern
+	// GraphAlgo holds the arguments of the graph algorithm run by the block, i.e. @pagerank,
+	// @components or @triangles.
+	GraphAlgo *gql.GraphAlgoArgs
 
 	// ExploreDepth is used by recurse and shortest path queries to specify the maximum graph
 	// depth to explore.
@@ -221,6 +240,10 @@ type CascadeArgs struct {
 
 type pathMetadata struct {
 	weight float64 // Total weight of the path.
+	// nodes and preds hold the uids of the nodes of an enumerated path and the predicates of the
+	// edges between them. They are only set for the results of the path enumeration mode.
+	nodes []uint64
+	preds []string
 }
 
 // Function holds the information about gql functions.
@@ -230,6 +253,8 @@ type Function struct {
 	IsCount    bool      // gt(count(friends),0)
 	IsValueVar bool      // eq(val(s), 10)
 	IsLenVar   bool      // eq(len(s), 10)
+	// UIDs are the UIDs checked by uid_in(pred, uid(s)) and uid_in(pred, { ... }).
+	UIDs *pb.List
 }
 
 // SubGraph is the way to represent data. It contains both the request parameters and the response.
@@ -305,6 +330,20 @@ type SubGraph struct {
 	List        bool // whether predicate is of list type
 
 	pathMeta *pathMetadata
+	// nextCursor is the cursor to fetch the next page of the results of this node.
+	nextCursor string
+	// sortVals holds the values of the sort keys of the nodes returned by this node, when
+	// Params.NodeCursors is set.
+	sortVals map[uint64][]types.Val
+	// algoVals holds the result of the graph algorithm run by this block, if any.
+	algoVals map[uint64]types.Val
+	// plan holds the decisions of the query planner for this node, if it planned it.
+	plan *planInfo
+	// subquery is the query block nested in the function of a filter, for uid_in and exists.
+	subquery *gql.GraphQuery
+	// subqueryVars holds the variables that were done when the vars of the node were filled.
+	// The subquery can use them.
+	subqueryVars map[string]varValue
 }
 
 func (sg *SubGraph) recurse(set func(sg *SubGraph)) {
@@ -340,6 +379,11 @@ func (sg *SubGraph) createSrcFunction(gf *gql.Function) {
 		IsLenVar:   gf.IsLenVar,
 	}
 
+	// exists without a filter on the edges is the same as has.
+	if gf.Name == "exists" && gf.Subquery == nil {
+		sg.SrcFunc.Name = "has"
+	}
+
 	// type function is just an alias for eq(type, "dgraph.type").
 	if gf.Name == "type" {
 		sg.Attr = "dgraph.type"
@@ -486,6 +530,7 @@ func filterCopy(sg *SubGraph, ft *gql.FilterTree) error {
 			}
 			sg.createSrcFunction(ft.Func)
 			sg.Params.NeedsVar = append(sg.Params.NeedsVar, ft.Func.NeedsVar...)
+			sg.subquery = ft.Func.Subquery
 		}
 	}
 	for _, ftc := range ft.Child {
@@ -498,6 +543,106 @@ func filterCopy(sg *SubGraph, ft *gql.FilterTree) error {
 	return nil
 }
 
+// mergeEqFilters replaces the eq filters over different predicates that are ANDed together by a
+// composite_eq filter for each composite index over their predicates, which is answered from the
+// index. The indexes over more predicates are used first. The eq filters that no composite index
+// covers are left as they are.
+func (sg *SubGraph) mergeEqFilters(ctx context.Context) error {
+	// eqs maps the predicates of the eq filters to their position in the filters, or to -1 if
+	// they are filtered more than once.
+	eqs := make(map[string]int)
+	for i, f := range sg.Filters {
+		fn := f.SrcFunc
+		mergeable := fn != nil && fn.Name == "eq" && len(fn.Args) == 1 && !fn.IsCount &&
+			!fn.IsValueVar && !fn.IsLenVar && !fn.Args[0].IsValueVar &&
+			len(f.Params.NeedsVar) == 0 && len(f.Params.Langs) == 0 &&
+			!strings.HasPrefix(f.Attr, "~") && !x.IsReservedPredicate(f.Attr)
+		if !mergeable {
+			continue
+		}
+		if _, ok := eqs[f.Attr]; ok {
+			eqs[f.Attr] = -1
+			continue
+		}
+		eqs[f.Attr] = i
+	}
+	if len(eqs) < 2 {
+		return nil
+	}
+
+	namespace, err := x.ExtractNamespace(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "While extracting namespace in mergeEqFilters")
+	}
+	attrs := make([]string, 0, len(eqs))
+	for attr := range eqs {
+		attrs = append(attrs, x.NamespaceAttr(namespace, attr))
+	}
+	nodes, err := worker.GetSchemaOverNetwork(ctx,
+		&pb.SchemaRequest{Predicates: attrs, Fields: []string{"composite"}})
+	if err != nil {
+		return err
+	}
+	var composites [][]string
+	for _, node := range nodes {
+		for _, composite := range node.Composite {
+			composites = append(composites, append([]string{x.ParseAttr(node.Predicate)},
+				strings.Split(composite, ",")...))
+		}
+	}
+	sort.SliceStable(composites, func(i, j int) bool {
+		return len(composites[i]) > len(composites[j])
+	})
+
+	// Each composite_eq filter takes the place of the first eq filter it replaces, so that the
+	// order chosen by the planner is kept.
+	merged := make(map[int]*SubGraph)
+	covered := make(map[string]bool)
+	for _, preds := range composites {
+		first := len(sg.Filters)
+		for _, pred := range preds {
+			i, ok := eqs[pred]
+			if !ok || i < 0 || covered[pred] {
+				first = -1
+				break
+			}
+			if i < first {
+				first = i
+			}
+		}
+		if first < 0 {
+			continue
+		}
+
+		fn := &Function{Name: worker.CompositeEqFunc,
+			Args: []gql.Arg{sg.Filters[eqs[preds[0]]].SrcFunc.Args[0]}}
+		for _, pred := range preds[1:] {
+			fn.Args = append(fn.Args, gql.Arg{Value: pred}, sg.Filters[eqs[pred]].SrcFunc.Args[0])
+		}
+		for _, pred := range preds {
+			covered[pred] = true
+		}
+		merged[first] = &SubGraph{ReadTs: sg.ReadTs, Cache: sg.Cache, Attr: preds[0], SrcFunc: fn}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+
+	filters := make([]*SubGraph, 0, len(sg.Filters))
+	for i, f := range sg.Filters {
+		switch {
+		case merged[i] != nil:
+			filters = append(filters, merged[i])
+		case covered[f.Attr] && eqs[f.Attr] == i:
+			// Replaced by a composite_eq filter.
+		default:
+			filters = append(filters, f)
+		}
+	}
+	sg.Filters = filters
+	return nil
+}
+
 func uniqueKey(gchild *gql.GraphQuery) string {
 	key := gchild.Attr
 	if gchild.Func != nil {
@@ -692,6 +837,14 @@ func (args *params) fill(gq *gql.GraphQuery) error {
 		}
 		args.AfterUID = after
 	}
+	if err := args.fillCursor(gq); err != nil {
+		return err
+	}
+
+	if args.GraphAlgo != nil && gq.Var == "" {
+		return errors.Errorf("The result of @%s should be assigned to a variable",
+			args.GraphAlgo.Algo)
+	}
 
 	if args.Alias == "shortest" {
 		if v, ok := gq.Args["depth"]; ok {
@@ -710,6 +863,22 @@ func (args *params) fill(gq *gql.GraphQuery) error {
 			args.NumPaths = int(numPaths)
 		}
 
+		if v, ok := gq.Args["allpaths"]; ok {
+			allPaths, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			args.AllPaths = allPaths
+		}
+
+		if v, ok := gq.Args["pattern"]; ok {
+			pattern, err := parsePathPattern(v)
+			if err != nil {
+				return err
+			}
+			args.PathPattern = pattern
+		}
+
 		if v, ok := gq.Args["maxweight"]; ok {
 			maxWeight, err := strconv.ParseFloat(v, 64)
 			if err != nil {
@@ -766,6 +935,9 @@ func ToSubGraph(ctx context.Context, gq *gql.GraphQuery) (*SubGraph, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sg.Params.PathPattern != nil {
+		sg.Params.PathPattern.addChildren(gq)
+	}
 	err = treeCopy(gq, sg)
 	if err != nil {
 		return nil, err
@@ -779,6 +951,9 @@ type ContextKey int
 const (
 	// DebugKey is the key used to toggle debug mode.
 	DebugKey ContextKey = iota
+	// ExplainKey is the key used to toggle explain mode. Its value must be a *Plan, which gets
+	// filled with the plan of the query.
+	ExplainKey
 )
 
 func isDebug(ctx context.Context) bool {
@@ -830,6 +1005,7 @@ func newGraph(ctx context.Context, gq *gql.GraphQuery) (*SubGraph, error) {
 		ParentVars:       make(map[string]varValue),
 		Recurse:          gq.Recurse,
 		RecurseArgs:      gq.RecurseArgs,
+		GraphAlgo:        gq.GraphAlgo,
 		ShortestPathArgs: gq.ShortestPathArgs,
 		Var:              gq.Var,
 		GroupbyAttrs:     gq.GroupbyAttrs,
@@ -940,6 +1116,7 @@ func createTaskQuery(ctx context.Context, sg *SubGraph) (*pb.Query, error) {
 		srcFunc = &pb.SrcFunction{}
 		srcFunc.Name = sg.SrcFunc.Name
 		srcFunc.IsCount = sg.SrcFunc.IsCount
+		srcFunc.Uids = sg.SrcFunc.UIDs
 		for _, arg := range sg.SrcFunc.Args {
 			srcFunc.Args = append(srcFunc.Args, arg.Value)
 			if arg.IsValueVar {
@@ -1008,7 +1185,7 @@ func calculatePaginationParams(sg *SubGraph) (int32, int32) {
 	shouldExclude := false
 	if sg.SrcFunc != nil {
 		switch sg.SrcFunc.Name {
-		case "regexp", "alloftext", "allofterms", "match":
+		case "regexp", "alloftext", "allofterms", "match", "similar_to":
 			shouldExclude = true
 		default:
 			shouldExclude = false
@@ -1052,8 +1229,9 @@ func evalLevelAgg(
 		// corresponding to uid 0 to avoid defining another field in SubGraph.
 		vals := doneVars[needsVar].Vals
 
-		ag := aggregator{
-			name: sg.SrcFunc.Name,
+		ag, err := newAggregator(sg.SrcFunc)
+		if err != nil {
+			return nil, err
 		}
 		for _, val := range vals {
 			ag.Apply(val)
@@ -1094,8 +1272,9 @@ func evalLevelAgg(
 	mp = make(map[uint64]types.Val)
 	// Go over the sibling node and aggregate.
 	for i, list := range relSG.uidMatrix {
-		ag := aggregator{
-			name: sg.SrcFunc.Name,
+		ag, err := newAggregator(sg.SrcFunc)
+		if err != nil {
+			return nil, err
 		}
 		for _, uid := range codec.GetUids(list) {
 			if val, ok := vals[uid]; ok {
@@ -1500,6 +1679,18 @@ func (sg *SubGraph) populateUidValVar(doneVars map[string]varValue, sgPath []*Su
 
 	srcUids := codec.GetUids(sg.SrcUIDs)
 	switch {
+	case sg.Params.GraphAlgo != nil:
+		// 0. The result of a graph algorithm is a value variable holding a value for every node
+		// of the graph, so its uids are the nodes of the graph.
+		uids := sroar.NewBitmap()
+		for uid := range sg.algoVals {
+			uids.Set(uid)
+		}
+		doneVars[sg.Params.Var] = varValue{
+			UidMap: uids,
+			Vals:   sg.algoVals,
+			path:   sgPath,
+		}
 	case len(sg.counts) > 0:
 		// 1. When count of a predicate is assigned a variable, we store the mapping of uid =>
 		// count(predicate).
@@ -1695,6 +1886,9 @@ func (sg *SubGraph) recursiveFillVars(doneVars map[string]varValue) error {
 	if err != nil {
 		return err
 	}
+	if sg.subquery != nil {
+		sg.subqueryVars = doneVars
+	}
 	for _, child := range sg.Children {
 		err = child.recursiveFillVars(doneVars)
 		if err != nil {
@@ -1746,6 +1940,13 @@ func (sg *SubGraph) fillShortestPathVars(mp map[string]varValue) error {
 			sg.Params.To = uidVar.UidMap.Minimum()
 		}
 	}
+
+	if cost := sg.Params.ShortestPathArgs.Cost; cost != nil && len(cost.NeedsVar) > 0 {
+		sg.Params.NodeCost = mp[cost.NeedsVar[0].Name].Vals
+	}
+	if h := sg.Params.ShortestPathArgs.Heuristic; h != nil && len(h.NeedsVar) > 0 {
+		sg.Params.Heuristic = mp[h.NeedsVar[0].Name].Vals
+	}
 	return nil
 }
 
@@ -1775,14 +1976,9 @@ func (sg *SubGraph) fillVars(mp map[string]varValue) error {
 			sg.ExpandPreds = l.strList
 
 		case (v.Typ == gql.UidVar && sg.SrcFunc != nil && sg.SrcFunc.Name == "uid_in"):
-			srcFuncArgs := sg.SrcFunc.Args[:0]
-			itr := l.UidMap.NewIterator()
-			for uid := itr.Next(); uid > 0; uid = itr.Next() {
-				// We use base 10 here because the uid parser expects the uid to be in base 10.
-				arg := gql.Arg{Value: strconv.FormatUint(uid, 10)}
-				srcFuncArgs = append(srcFuncArgs, arg)
-			}
-			sg.SrcFunc.Args = srcFuncArgs
+			// The argument is the name of the variable, which is replaced by its UIDs.
+			sg.SrcFunc.Args = nil
+			sg.SrcFunc.UIDs = codec.ToList(l.UidMap)
 
 		case (v.Typ == gql.AnyVar || v.Typ == gql.UidVar) && !l.UidMap.IsEmpty():
 			if l.OrderedUIDs != nil {
@@ -2186,7 +2382,19 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			} else {
 				sg.DestMap = nil
 			}
+		case sg.SrcFunc != nil && sg.SrcFunc.Name == "exists":
+			if err = sg.processExists(ctx); err != nil {
+				rch <- err
+				return
+			}
 		default:
+			if sg.subquery != nil {
+				// uid_in(pred, { ... }) runs the nested block first, to get its arguments.
+				if err = sg.processUidInSubquery(ctx); err != nil {
+					rch <- err
+					return
+				}
+			}
 			taskQuery, err := createTaskQuery(ctx, sg)
 			if err != nil {
 				rch <- err
@@ -2225,7 +2433,7 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 				sg.DestMap = codec.Merge(result.UidMatrix)
 			}
 
-			if parent == nil {
+			if parent == nil && !sg.isOrderedByDistance() {
 				// I'm root. We reach here if root had a function.
 				sg.uidMatrix = []*pb.List{codec.ToList(sg.DestMap)}
 			}
@@ -2233,9 +2441,18 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 	}
 
 	// Run filters if any.
+	if sg.FilterOp == "and" {
+		if err := sg.mergeEqFilters(ctx); err != nil {
+			rch <- err
+			return
+		}
+	}
 	if len(sg.Filters) > 0 {
-		// Run all filters in parallel.
+		// Run all filters in parallel, unless the planner ordered them. Then, they run one after
+		// the other and each filter only checks the UIDs that passed the previous ones.
+		ordered := sg.plan != nil && sg.plan.ordered
 		filterChan := make(chan error, len(sg.Filters))
+		destMap := sg.DestMap
 		for _, filter := range sg.Filters {
 			isUidFuncWithoutVar := filter.SrcFunc != nil && filter.SrcFunc.Name == "uid" &&
 				len(filter.Params.NeedsVar) == 0
@@ -2244,17 +2461,27 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			if isUidFuncWithoutVar {
 				filter.DestMap = codec.FromList(filter.SrcUIDs)
 				filterChan <- nil
+				if ordered {
+					destMap = sroar.And(destMap, filter.DestMap)
+				}
 				continue
 			}
 
-			filter.SrcUIDs = codec.ToList(sg.DestMap)
+			filter.SrcUIDs = codec.ToList(destMap)
 			if codec.ListCardinality(filter.SrcUIDs) == 0 {
 				filterChan <- nil
 				continue
 			}
 			// Passing the pointer is okay since the filter only reads.
 			filter.Params.ParentVars = sg.Params.ParentVars // Pass to the child.
-			go ProcessGraph(ctx, filter, sg, filterChan)
+			if !ordered {
+				go ProcessGraph(ctx, filter, sg, filterChan)
+				continue
+			}
+			ProcessGraph(ctx, filter, sg, filterChan)
+			if filter.DestMap != nil {
+				destMap = sroar.And(destMap, filter.DestMap)
+			}
 		}
 
 		var filterErr error
@@ -2342,6 +2569,13 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 		}
 	}
 
+	if !sg.Params.DoCount {
+		if err = sg.updateCursor(ctx); err != nil {
+			rch <- err
+			return
+		}
+	}
+
 	// Here we consider handling count with filtering. We do this after
 	// pagination because otherwise, we need to do the count with pagination
 	// taken into account. For example, a PL might have only 50 entries but the
@@ -2490,15 +2724,21 @@ func (sg *SubGraph) applyPagination(ctx context.Context) error {
 		uids := codec.GetUids(sg.uidMatrix[i])
 		// Apply the offsets.
 		start, end := x.PageRange(sg.Params.Count, sg.Params.Offset, len(uids))
-		r := sroar.NewBitmap()
-		r.SetMany(uids[start:end])
-		sg.uidMatrix[i].Bitmap = r.ToBuffer()
+		// The lists are kept in their order, if they aren't sorted by uid.
+		codec.SetUids(sg.uidMatrix[i], uids[start:end])
 	}
 	// Re-merge the UID matrix.
 	sg.DestMap = codec.Merge(sg.uidMatrix)
 	return nil
 }
 
+// isOrderedByDistance returns true if the results of the block are the nodes found by similar_to
+// at root, which are returned closest first unless the block is ordered otherwise.
+func (sg *SubGraph) isOrderedByDistance() bool {
+	return sg.SrcFunc != nil && sg.SrcFunc.Name == "similar_to" && len(sg.Params.Order) == 0 &&
+		len(sg.Params.FacetsOrder) == 0
+}
+
 // applyOrderAndPagination orders each posting list by a given attribute
 // before applying pagination.
 func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
@@ -2513,6 +2753,10 @@ func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
 		return sg.sortAndPaginateUsingFacet(ctx)
 	}
 
+	if sg.Params.Cursor != nil && sg.isOrderedByVar() {
+		return errors.Errorf("cursor can't be used when ordering by a value variable")
+	}
+
 	for _, it := range sg.Params.NeedsVar {
 		// TODO(pawan) - Return error if user uses var order with predicates.
 		if len(sg.Params.Order) > 0 && it.Name == sg.Params.Order[0].Attr &&
@@ -2542,6 +2786,10 @@ func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
 		Count:     int32(sg.Params.Count),
 		ReadTs:    sg.ReadTs,
 	}
+	if c := sg.Params.Cursor; c != nil {
+		// The sort skips the nodes up to the cursor.
+		sortMsg.AfterVals, sortMsg.AfterUid = c.taskValues(), c.UID
+	}
 
 	// Convert the bitmaps to Sorted, as now we need to store the uids in order.
 	for _, ul := range sortMsg.UidMatrix {
@@ -2731,7 +2979,10 @@ func (sg *SubGraph) sortAndPaginateUsingVar(ctx context.Context) error {
 func isValidArg(a string) bool {
 	switch a {
 	case "numpaths", "from", "to", "orderasc", "orderdesc", "first", "offset", "after", "depth",
-		"minweight", "maxweight", "random":
+		"minweight", "maxweight", "random", "cursor":
+		return true
+	case "allpaths", "pattern":
+		// Arguments of the path enumeration mode of shortest path queries.
 		return true
 	}
 	return false
@@ -2741,7 +2992,7 @@ func isValidArg(a string) bool {
 func isValidFuncName(f string) bool {
 	switch f {
 	case "anyofterms", "allofterms", "val", "regexp", "anyoftext", "alloftext",
-		"has", "uid", "uid_in", "anyof", "allof", "type", "match":
+		"has", "uid", "uid_in", "anyof", "allof", "type", "match", "similar_to", "exists":
 		return true
 	}
 	return isInequalityFn(f) || types.IsGeoFunc(f)
@@ -2760,7 +3011,7 @@ func isAggregatorFn(f string) bool {
 	case "min", "max", "sum", "avg":
 		return true
 	}
-	return false
+	return isStatAggregatorFn(f)
 }
 
 func isUidFnWithoutVar(f *gql.Function) bool {
@@ -2840,6 +3091,9 @@ type Request struct {
 	Cache    int    // 0 represents use txn cache, 1 represents not to use cache.
 	Latency  *Latency
 	GqlQuery *gql.Result
+	// CursorBlock is the alias of the root block which needs a cursor for every node it returns,
+	// if any.
+	CursorBlock string
 
 	Subgraphs []*SubGraph
 
@@ -2875,6 +3129,9 @@ func (req *Request) ProcessQuery(ctx context.Context) (err error) {
 			sg.ReadTs = req.ReadTs
 			sg.Cache = req.Cache
 		})
+		if req.CursorBlock != "" && sg.Params.Alias == req.CursorBlock {
+			sg.Params.NodeCursors = true
+		}
 		span.Annotate(nil, "Query parsed")
 		req.Subgraphs = append(req.Subgraphs, sg)
 	}
@@ -2951,8 +3208,18 @@ func (req *Request) ProcessQuery(ctx context.Context) (err error) {
 				go func() {
 					errChan <- recurse(ctx, sg)
 				}()
+			case sg.Params.GraphAlgo != nil:
+				go func() {
+					errChan <- runGraphAlgo(ctx, sg)
+				}()
 			default:
-				go ProcessGraph(ctx, sg, nil, errChan)
+				go func(sg *SubGraph) {
+					if err := sg.planBlock(ctx); err != nil {
+						errChan <- err
+						return
+					}
+					ProcessGraph(ctx, sg, nil, errChan)
+				}(sg)
 			}
 		}
 
@@ -3016,6 +3283,9 @@ type ExecutionResult struct {
 	SchemaNode []*pb.SchemaNode
 	Types      []*pb.TypeUpdate
 	Metrics    map[string]uint64
+	// Cursors holds the cursors to fetch the next page of the paginated blocks, keyed by the
+	// path of the block in the response.
+	Cursors map[string]string
 }
 
 // Process handles a query request.
@@ -3031,6 +3301,20 @@ func (req *Request) Process(ctx context.Context) (er ExecutionResult, err error)
 		calculateMetrics(sg, metrics)
 	}
 	er.Metrics = metrics
+	cursors := make(map[string]string)
+	for _, sg := range er.Subgraphs {
+		switch sg.Params.Alias {
+		case "var", "shortest":
+		default:
+			collectCursors(sg, sg.Params.Alias, cursors)
+		}
+	}
+	er.Cursors = cursors
+	if plan, ok := ctx.Value(ExplainKey).(*Plan); ok {
+		for _, sg := range er.Subgraphs {
+			plan.Blocks = append(plan.Blocks, sg.planNode())
+		}
+	}
 	namespace, err := x.ExtractNamespace(ctx)
 	if err != nil {
 		return er, errors.Wrapf(err, "While processing query")
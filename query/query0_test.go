@@ -1653,6 +1653,24 @@ func TestGroupByAggval(t *testing.T) {
 		js)
 }
 
+func TestGroupByStatAggregators(t *testing.T) {
+	query := `
+		{
+			me(func: uid(1)) {
+				friend @groupby(school) {
+					median(age)
+					stddev(age)
+					count(distinct age)
+				}
+			}
+		}
+	`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `{"data":{"me":[{"friend":[{"@groupby":[`+
+		`{"school":"0x1388","median(age)":16,"stddev(age)":1,"count(distinct age)":2},`+
+		`{"school":"0x1389","median(age)":17,"stddev(age)":2,"count(distinct age)":2}]}]}]}}`, js)
+}
+
 func TestGroupByAlias(t *testing.T) {
 	query := `
 		{
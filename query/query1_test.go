@@ -1435,6 +1435,29 @@ func TestAggregateRoot2(t *testing.T) {
 	require.JSONEq(t, `{"data": {"me":[{"avg(val(a))":24.000000},{"min(val(a))":15},{"max(val(a))":38}]}}`, js)
 }
 
+func TestAggregateRootStats(t *testing.T) {
+
+	query := `
+		{
+			var(func: anyofterms(name, "Rick Michonne Andrea")) {
+				a as age
+			}
+
+			me() {
+				median(val(a))
+				p100: percentile(val(a), 100)
+				variance(val(a))
+				stddev(val(a))
+				count(distinct val(a))
+			}
+		}
+	`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"median(val(a))":19.000000},{"p100":38.000000},`+
+		`{"variance(val(a))":100.666667},{"stddev(val(a))":10.033278},`+
+		`{"count(distinct val(a))":3}]}}`, js)
+}
+
 func TestAggregateRoot3(t *testing.T) {
 
 	query := `
@@ -18,9 +18,11 @@ package query
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/dgraph/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -361,6 +363,96 @@ func TestToFastJSONOrderName(t *testing.T) {
 		js)
 }
 
+func TestOrderWithCursor(t *testing.T) {
+	// The cursor of the second node of the first page, Daryl Dixon.
+	cursor, err := newCursor([]types.Val{{Tid: types.StringID, Value: "Daryl Dixon"}}, 25)
+	require.NoError(t, err)
+	query := fmt.Sprintf(`
+		{
+			me(func: uid(1, 23, 24, 25, 31), orderasc: name, first: 2, cursor: %q) {
+				name
+			}
+		}
+	`, cursor)
+
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Glenn Rhee"},{"name":"Michonne"}]}}`,
+		js)
+}
+
+func TestNestedOrderWithCursor(t *testing.T) {
+	cursor, err := newCursor([]types.Val{{Tid: types.StringID, Value: "Glenn Rhee"}}, 24)
+	require.NoError(t, err)
+	query := fmt.Sprintf(`
+		{
+			me(func: uid(0x01)) {
+				friend(orderdesc: name, cursor: %q) {
+					name
+				}
+			}
+		}
+	`, cursor)
+
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"name":"Daryl Dixon"},{"name":"Andrea"}]}]}}`,
+		js)
+}
+
+func TestOrderWithCursorMissingValues(t *testing.T) {
+	// 101, 1000 and 1001 don't have an age, so they are sorted last, by uid.
+	query := `
+		{
+			me(func: uid(1, 23, 24, 25, 31, 101, 1000, 1001), orderasc: age, first: 2,
+				cursor: %q) {
+				uid
+			}
+		}
+	`
+	// The cursor of the last node with an age, 1.
+	cursor, err := newCursor([]types.Val{{Tid: types.IntID, Value: int64(38)}}, 1)
+	require.NoError(t, err)
+	js := processQueryNoErr(t, fmt.Sprintf(query, cursor))
+	require.JSONEq(t, `{"data": {"me":[{"uid":"0x65"},{"uid":"0x3e8"}]}}`, js)
+
+	cursor, err = newCursor([]types.Val{{}}, 1000)
+	require.NoError(t, err)
+	js = processQueryNoErr(t, fmt.Sprintf(query, cursor))
+	require.JSONEq(t, `{"data": {"me":[{"uid":"0x3e9"}]}}`, js)
+}
+
+func TestCursorWithoutOrder(t *testing.T) {
+	cursor, err := newCursor(nil, 24)
+	require.NoError(t, err)
+	query := fmt.Sprintf(`
+		{
+			me(func: uid(1, 23, 24, 25, 31), first: 2, cursor: %q) {
+				uid
+			}
+		}
+	`, cursor)
+
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"uid":"0x19"},{"uid":"0x1f"}]}}`, js)
+}
+
+func TestCursorNotMatchingOrder(t *testing.T) {
+	cursor, err := newCursor(nil, 24)
+	require.NoError(t, err)
+	query := fmt.Sprintf(`
+		{
+			me(func: uid(1, 23, 24, 25, 31), orderasc: name, cursor: %q) {
+				name
+			}
+		}
+	`, cursor)
+
+	_, err = processQuery(context.Background(), t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "The cursor doesn't match the order of the block")
+}
+
 func TestToFastJSONOrderNameDesc(t *testing.T) {
 
 	query := `
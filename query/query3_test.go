@@ -1746,6 +1746,244 @@ func TestShortestPath4(t *testing.T) {
 	}`, js)
 }
 
+func TestShortestPathWithNodeCost(t *testing.T) {
+	// Both the paths from 1 to 1003 take 4 hops. The cost of entering a node is the number of
+	// its outgoing path edges, so the path through 1002 is cheaper than the one through 1001.
+	query := `
+		{
+			var(func: uid(31, 1000, 1001, 1002, 1003)) {
+				c as count(path)
+			}
+
+			shortest(from: 1, to: 1003, cost: val(c)) {
+				path
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"_path_":[
+				{
+					"uid":"0x1",
+					"_weight_":5,
+					"path":{
+						"uid":"0x1f",
+						"path":{
+							"uid":"0x3e8",
+							"path":{
+								"uid":"0x3ea",
+								"path":{
+									"uid":"0x3eb"
+								}
+							}
+						}
+					}
+				}
+			]
+		}
+	}`, js)
+}
+
+func TestShortestPathWithCostPredicate(t *testing.T) {
+	// The cost of entering a node is its age, so the direct friend edge to 24 costs 15 and the
+	// path through 31 costs 19 + 15.
+	query := `
+		{
+			shortest(from: 1, to: 24, numpaths: 2, cost: age) {
+				friend
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"_path_":[
+				{
+					"uid":"0x1",
+					"_weight_":15,
+					"friend":{
+						"uid":"0x18"
+					}
+				},
+				{
+					"uid":"0x1",
+					"_weight_":34,
+					"friend":{
+						"uid":"0x1f",
+						"friend":{
+							"uid":"0x18"
+						}
+					}
+				}
+			]
+		}
+	}`, js)
+}
+
+func TestShortestPathWithCostPredicateMissing(t *testing.T) {
+	// 101 has no age, so it can't be reached when the cost is the age.
+	query := `
+		{
+			A as shortest(from: 1, to: 101, cost: age) {
+				friend
+			}
+
+			me(func: uid(A)) {
+				name
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `{"data": {"me": []}}`, js)
+}
+
+func TestShortestPathWithHeuristic(t *testing.T) {
+	// The shortest path from 1 to 1003 goes through 1001 and 1002 and weighs 1. The heuristic is
+	// 0.7 for the nodes with two outgoing path edges and 0 for the others, which never
+	// overestimates the remaining weight. It makes 1002 look closer than 1001 when coming from
+	// 1000, so the search first reaches 1003 through the edge from 1000 to 1002, which weighs
+	// 0.7, and has to expand 1002 again once it's reached through 1001.
+	query := `
+		{
+			var(func: uid(1, 31, 1000, 1001, 1002, 1003)) {
+				c as count(path)
+				h as math((c - 1) * 0.7)
+			}
+
+			shortest(from: 1, to: 1003, heuristic: val(h)) {
+				path @facets(weight)
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"_path_":[
+				{
+					"uid":"0x1",
+					"_weight_":1,
+					"path":{
+						"uid":"0x1f",
+						"path|weight":0.1,
+						"path":{
+							"uid":"0x3e8",
+							"path|weight":0.1,
+							"path":{
+								"uid":"0x3e9",
+								"path|weight":0.1,
+								"path":{
+									"uid":"0x3ea",
+									"path|weight":0.1,
+									"path":{
+										"uid":"0x3eb",
+										"path|weight":0.6
+									}
+								}
+							}
+						}
+					}
+				}
+			]
+		}
+	}`, js)
+}
+
+func TestTrianglesAlgorithm(t *testing.T) {
+	query := `
+		{
+			t as graph(func: uid(1)) @triangles(pred: path) {
+				name
+			}
+
+			me(func: uid(t)) @filter(gt(val(t), 1)) {
+				name
+				val(t)
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"graph": [{"name": "Michonne"}],
+			"me": [{"name": "Bob", "val(t)": 2}, {"name": "Matt", "val(t)": 2}]
+		}
+	}`, js)
+}
+
+func TestGraphAlgorithmWithoutVar(t *testing.T) {
+	query := `
+		{
+			graph(func: uid(1)) @pagerank(pred: path) {
+				name
+			}
+		}`
+	_, err := processQuery(context.Background(), t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "The result of @pagerank should be assigned to a variable")
+}
+
+func TestGraphAlgorithmBlockName(t *testing.T) {
+	// The blocks named after a graph algorithm are ordinary blocks.
+	query := `
+		{
+			pagerank(func: uid(1)) {
+				name
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `{"data": {"pagerank": [{"name": "Michonne"}]}}`, js)
+}
+
+func TestAllPaths(t *testing.T) {
+	query := `
+		{
+			shortest(from: 1, to: 1003, allpaths: true, depth: 4) {
+				path
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"_paths_":[
+				{
+					"uids":["0x1","0x1f","0x3e8","0x3e9","0x3eb"],
+					"preds":["path","path","path","path"],
+					"_weight_":4
+				},
+				{
+					"uids":["0x1","0x1f","0x3e8","0x3ea","0x3eb"],
+					"preds":["path","path","path","path"],
+					"_weight_":4
+				}
+			]
+		}
+	}`, js)
+}
+
+func TestAllPathsWithPattern(t *testing.T) {
+	// Only the path through both 1001 and 1002 takes five hops.
+	query := `
+		{
+			shortest(from: 1, to: 1003, pattern: "path/path/path/path/path") {
+				path
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"_paths_":[
+				{
+					"uids":["0x1","0x1f","0x3e8","0x3e9","0x3ea","0x3eb"],
+					"preds":["path","path","path","path","path"],
+					"_weight_":5
+				}
+			]
+		}
+	}`, js)
+}
+
 func TestShortestPath_filter(t *testing.T) {
 	query := `
 		{
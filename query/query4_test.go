@@ -1561,3 +1561,60 @@ func TestNumUids(t *testing.T) {
 	require.Equal(t, metrics.NumUids["name"], uint64(16))
 	require.Equal(t, metrics.NumUids["_total"], uint64(26))
 }
+
+func TestSimilarToOrderedByDistance(t *testing.T) {
+	// The squared distances to [2, 0] are 4, 1, 2, 8 and 34.
+	query := `
+		{
+			me(func: similar_to(embedding, 3, [2.0, 0.0])) {
+				uid
+			}
+			first(func: similar_to(embedding, 3, [2.0, 0.0]), first: 2) {
+				uid
+			}
+			filtered(func: uid(40001, 40002, 40003, 40004, 40005))
+				@filter(similar_to(embedding, 2, [2.0, 0.0])) {
+				uid
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"me": [{"uid": "0x9c42"}, {"uid": "0x9c43"}, {"uid": "0x9c41"}],
+			"first": [{"uid": "0x9c42"}, {"uid": "0x9c43"}],
+			"filtered": [{"uid": "0x9c42"}, {"uid": "0x9c43"}]
+		}
+	}`, js)
+}
+
+func TestCompositeEqFilter(t *testing.T) {
+	query := `
+		{
+			both(func: uid(41001, 41002, 41003, 41004, 41005))
+				@filter(eq(status, "active") AND eq(country, "in")) {
+				uid
+			}
+			root(func: eq(country, "in")) @filter(eq(status, "active") AND eq(country, "in")) {
+				uid
+			}
+			repeated(func: uid(41001, 41002, 41003, 41004, 41005))
+				@filter(eq(country, "in") AND eq(status, "active") AND eq(country, "fr")) {
+				uid
+			}
+			uncovered(func: uid(41001, 41002, 41003, 41004, 41005))
+				@filter(eq(status, "active") AND NOT eq(country, "in")) {
+				uid
+			}
+		}`
+	js := processQueryNoErr(t, query)
+	require.JSONEq(t, `
+	{
+		"data": {
+			"both": [{"uid": "0xa029"}, {"uid": "0xa02c"}],
+			"root": [{"uid": "0xa029"}, {"uid": "0xa02c"}],
+			"repeated": [],
+			"uncovered": [{"uid": "0xa02b"}]
+		}
+	}`, js)
+}
@@ -20,13 +20,16 @@ import (
 	"container/heap"
 	"context"
 	"math"
+	"sort"
 	"sync"
 
 	"github.com/dgraph-io/dgraph/algo"
 	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/protos/pb"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/dgraph-io/sroar"
 	"github.com/pkg/errors"
@@ -52,6 +55,10 @@ type queueItem struct {
 	hop   int
 	index int
 	path  route // used in k shortest path.
+	// estimate of the cost of the remaining path till the destination, given by the heuristic
+	// argument. It is always 0 when no heuristic is given which makes the search Dijkstra's.
+	// The path found is only the shortest one if the heuristic never overestimates the cost.
+	estimate float64
 }
 
 var pathPool = sync.Pool{
@@ -76,7 +83,9 @@ func (r *route) indexOf(uid uint64) int {
 
 func (h priorityQueue) Len() int { return len(h) }
 
-func (h priorityQueue) Less(i, j int) bool { return h[i].cost < h[j].cost }
+func (h priorityQueue) Less(i, j int) bool {
+	return h[i].cost+h[i].estimate < h[j].cost+h[j].estimate
+}
 
 func (h priorityQueue) Swap(i, j int) {
 	h[i], h[j] = h[j], h[i]
@@ -114,6 +123,125 @@ type nodeInfo struct {
 	node *queueItem
 }
 
+// nodeWeight holds the per node values of the cost or the heuristic argument of a shortest path
+// query. The values are either taken from a value variable or read from a predicate as the
+// nodes are reached while expanding the graph.
+type nodeWeight struct {
+	attr    string // predicate to read the values from, empty for a value variable.
+	vals    map[uint64]float64
+	fetched map[uint64]struct{}
+}
+
+func newNodeWeight(fn *gql.Function, vals map[uint64]types.Val) *nodeWeight {
+	if fn == nil {
+		return nil
+	}
+	nw := &nodeWeight{
+		attr:    fn.Attr,
+		vals:    make(map[uint64]float64, len(vals)),
+		fetched: make(map[uint64]struct{}),
+	}
+	for uid, v := range vals {
+		if w, ok := toWeight(v); ok {
+			nw.vals[uid] = w
+		}
+	}
+	return nw
+}
+
+// toWeight converts the value to a weight. Values which aren't numeric are ignored.
+func toWeight(v types.Val) (float64, bool) {
+	switch v.Tid {
+	case types.IntID:
+		return float64(v.Value.(int64)), true
+	case types.FloatID:
+		return v.Value.(float64), true
+	}
+	return 0, false
+}
+
+func (nw *nodeWeight) get(uid uint64) (float64, bool) {
+	if nw == nil {
+		return 0, false
+	}
+	w, ok := nw.vals[uid]
+	return w, ok
+}
+
+// fetch reads the values of the predicate for the given uids which haven't been fetched yet.
+func (nw *nodeWeight) fetch(ctx context.Context, sg *SubGraph, uids []uint64) error {
+	if nw == nil || nw.attr == "" {
+		return nil
+	}
+	var toFetch []uint64
+	for _, uid := range uids {
+		if _, ok := nw.fetched[uid]; ok {
+			continue
+		}
+		nw.fetched[uid] = struct{}{}
+		toFetch = append(toFetch, uid)
+	}
+	if len(toFetch) == 0 {
+		return nil
+	}
+	sort.Slice(toFetch, func(i, j int) bool { return toFetch[i] < toFetch[j] })
+
+	temp := &SubGraph{
+		Attr:    nw.attr,
+		SrcUIDs: &pb.List{SortedUids: toFetch},
+		ReadTs:  sg.ReadTs,
+	}
+	taskQuery, err := createTaskQuery(ctx, temp)
+	if err != nil {
+		return err
+	}
+	result, err := worker.ProcessTaskOverNetwork(ctx, taskQuery)
+	if err != nil {
+		return err
+	}
+	for i, vl := range result.ValueMatrix {
+		if i >= len(toFetch) || len(vl.Values) == 0 {
+			continue
+		}
+		v, err := convertWithBestEffort(vl.Values[0], nw.attr)
+		if err != nil {
+			continue
+		}
+		if w, ok := toWeight(v); ok {
+			nw.vals[toFetch[i]] = w
+		}
+	}
+	return nil
+}
+
+// pathWeights holds the node weights given by the cost and heuristic arguments of a shortest
+// path query.
+type pathWeights struct {
+	cost      *nodeWeight
+	heuristic *nodeWeight
+}
+
+func newPathWeights(sg *SubGraph) *pathWeights {
+	return &pathWeights{
+		cost:      newNodeWeight(sg.Params.ShortestPathArgs.Cost, sg.Params.NodeCost),
+		heuristic: newNodeWeight(sg.Params.ShortestPathArgs.Heuristic, sg.Params.Heuristic),
+	}
+}
+
+func (pw *pathWeights) fetch(ctx context.Context, sg *SubGraph, uids []uint64) error {
+	if err := pw.cost.fetch(ctx, sg, uids); err != nil {
+		return err
+	}
+	return pw.heuristic.fetch(ctx, sg, uids)
+}
+
+// estimate returns the heuristic estimate of the cost from the node to the destination. Nodes
+// for which the heuristic has no value are estimated to be 0 away from the destination.
+func (pw *pathWeights) estimate(uid uint64) float64 {
+	w, _ := pw.heuristic.get(uid)
+	return w
+}
+
 func (sg *SubGraph) getCost(matrix, list int) (cost float64,
 	fcs *pb.Facets, rerr error) {
 
@@ -150,7 +278,27 @@ func (sg *SubGraph) getCost(matrix, list int) (cost float64,
 	return cost, fcs, rerr
 }
 
-func (sg *SubGraph) expandOut(ctx context.Context,
+// edgeCost returns the cost of following the edge at the given position of the uidMatrix. If a
+// cost argument was given, the cost comes from the node being entered instead of the facet, which
+// is only kept for the output if present. errFacet is returned for the edges to skip.
+func (sg *SubGraph) edgeCost(weights *pathWeights, matrix, list int,
+	toUID uint64) (float64, *pb.Facets, error) {
+	cost, facet, err := sg.getCost(matrix, list)
+	if weights.cost == nil {
+		return cost, facet, err
+	}
+	if err != nil {
+		facet = nil
+	}
+	nodeCost, ok := weights.cost.get(toUID)
+	if !ok {
+		// Skip the edge as the node has no cost.
+		return 0, nil, errFacet
+	}
+	return nodeCost, facet, nil
+}
+
+func (sg *SubGraph) expandOut(ctx context.Context, weights *pathWeights,
 	adjacencyMap map[uint64]map[uint64]mapItem, next chan bool, rch chan error) {
 
 	var numEdges uint64
@@ -204,6 +352,12 @@ func (sg *SubGraph) expandOut(ctx context.Context,
 				// processing but doesn't seem to be called for shortest path queries. So we call
 				// it explicitly here to ensure the results are correct.
 				subgraph.updateUidMatrix()
+				// Read the cost and heuristic of the newly reached nodes, if they come from a
+				// predicate.
+				if err := weights.fetch(ctx, sg, subgraph.DestMap.ToArray()); err != nil {
+					rch <- err
+					return
+				}
 				// Send the destuids in res chan.
 				for mIdx, fromUID := range codec.GetUids(subgraph.SrcUIDs) {
 					// This can happen when trying to go traverse a predicate of type password
@@ -217,7 +371,7 @@ func (sg *SubGraph) expandOut(ctx context.Context,
 							adjacencyMap[fromUID] = make(map[uint64]mapItem)
 						}
 						// The default cost we'd use is 1.
-						cost, facet, err := subgraph.getCost(mIdx, lIdx)
+						cost, facet, err := subgraph.edgeCost(weights, mIdx, lIdx, toUID)
 						switch {
 						case err == errFacet:
 							// Ignore the edge and continue.
@@ -305,12 +459,18 @@ func runKShortestPaths(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 	var kroutes []route
 	pq := make(priorityQueue, 0)
 
+	weights := newPathWeights(sg)
+	if err := weights.fetch(ctx, sg, []uint64{sg.Params.From}); err != nil {
+		return nil, err
+	}
+
 	// Initialize and push the source node.
 	srcNode := &queueItem{
-		uid:  sg.Params.From,
-		cost: 0,
-		hop:  0,
-		path: route{route: &[]pathInfo{{uid: sg.Params.From}}},
+		uid:      sg.Params.From,
+		cost:     0,
+		hop:      0,
+		path:     route{route: &[]pathInfo{{uid: sg.Params.From}}},
+		estimate: weights.estimate(sg.Params.From),
 	}
 	heap.Push(&pq, srcNode)
 
@@ -328,7 +488,7 @@ func runKShortestPaths(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 	next := make(chan bool, 2)
 	expandErr := make(chan error, 2)
 	adjacencyMap := make(map[uint64]map[uint64]mapItem)
-	go sg.expandOut(ctx, adjacencyMap, next, expandErr)
+	go sg.expandOut(ctx, weights, adjacencyMap, next, expandErr)
 
 	// In k shortest path we can't have this. We store the path till a node in every
 	// node.
@@ -412,10 +572,11 @@ func runKShortestPaths(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 				facet: info.facet,
 			}
 			node := &queueItem{
-				uid:  toUid,
-				cost: item.cost + cost,
-				hop:  item.hop + 1,
-				path: route{route: curPath},
+				uid:      toUid,
+				cost:     item.cost + cost,
+				hop:      item.hop + 1,
+				path:     route{route: curPath},
+				estimate: weights.estimate(toUid),
 			}
 			heap.Push(&pq, node)
 		}
@@ -481,16 +642,25 @@ func shortestPath(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 		numPaths = 1
 	}
 
+	if sg.Params.AllPaths || sg.Params.PathPattern != nil {
+		return allPaths(ctx, sg)
+	}
 	if numPaths > 1 {
 		return runKShortestPaths(ctx, sg)
 	}
 	pq := make(priorityQueue, 0)
 
+	weights := newPathWeights(sg)
+	if err := weights.fetch(ctx, sg, []uint64{sg.Params.From}); err != nil {
+		return nil, err
+	}
+
 	// Initialize and push the source node.
 	srcNode := &queueItem{
-		uid:  sg.Params.From,
-		cost: 0,
-		hop:  0,
+		uid:      sg.Params.From,
+		cost:     0,
+		hop:      0,
+		estimate: weights.estimate(sg.Params.From),
 	}
 	heap.Push(&pq, srcNode)
 
@@ -509,7 +679,7 @@ func shortestPath(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 	adjacencyMap := make(map[uint64]map[uint64]mapItem)
 	// TODO - Check if this goroutine actually improves performance. It doesn't look like it
 	// because we need to fill the adjacency map before we can make progress.
-	go sg.expandOut(ctx, adjacencyMap, next, expandErr)
+	go sg.expandOut(ctx, weights, adjacencyMap, next, expandErr)
 
 	// map to store the min cost and parent of nodes.
 	dist := make(map[uint64]nodeInfo)
@@ -570,9 +740,10 @@ func shortestPath(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 				// This is the first time we're seeing this node. So
 				// create a new node and add it to the heap and map.
 				node = &queueItem{
-					uid:  toUID,
-					cost: nodeCost,
-					hop:  item.hop + 1,
+					uid:      toUID,
+					cost:     nodeCost,
+					hop:      item.hop + 1,
+					estimate: weights.estimate(toUID),
 				}
 				heap.Push(&pq, node)
 			} else {
@@ -581,7 +752,13 @@ func shortestPath(ctx context.Context, sg *SubGraph) ([]*SubGraph, error) {
 				node = dist[toUID].node
 				node.cost = nodeCost
 				node.hop = item.hop + 1
-				heap.Fix(&pq, node.index)
+				if node.index < 0 {
+					// The node was already popped, which can happen with a heuristic that
+					// doesn't overestimate but isn't consistent. It has to be expanded again.
+					heap.Push(&pq, node)
+				} else {
+					heap.Fix(&pq, node.index)
+				}
 			}
 			dist[toUID] = nodeInfo{
 				parent: item.uid,
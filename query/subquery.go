@@ -0,0 +1,208 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dgraph-io/sroar"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/worker"
+)
+
+// Filters can nest a query block in their function:
+//  * uid_in(pred, { q(func: ...) }) runs the block once, and then checks the edges of the nodes
+//    against the UIDs matched by the block, like uid_in(pred, uid(var)) does.
+//  * exists(pred @filter(...)) keeps the nodes with at least one edge which passes the filter.
+//    It's evaluated as a semi-join: the edges of each node are checked in rounds of growing
+//    size, and a node isn't checked anymore once one of its edges passed the filter. So the
+//    filter doesn't run over all the edges, unless no edge of a node passes it.
+
+// existsFirstRound is the number of edges per node checked in the first round of exists. Each
+// round checks as many edges as all the previous ones did.
+const existsFirstRound = 8
+
+// subqueryVarMap returns the variables that the subquery of the node can use.
+func (sg *SubGraph) subqueryVarMap() map[string]varValue {
+	if len(sg.Params.ParentVars) == 0 {
+		return sg.subqueryVars
+	}
+	vars := make(map[string]varValue, len(sg.subqueryVars)+len(sg.Params.ParentVars))
+	for name, val := range sg.subqueryVars {
+		vars[name] = val
+	}
+	for name, val := range sg.Params.ParentVars {
+		vars[name] = val
+	}
+	return vars
+}
+
+// processUidInSubquery runs the query block nested in uid_in, and passes the UIDs it matched
+// to uid_in. The block only runs once, even if the filter runs again.
+func (sg *SubGraph) processUidInSubquery(ctx context.Context) error {
+	gq := sg.subquery
+	if gq.Func == nil && len(gq.UID) == 0 && len(gq.NeedsVar) == 0 {
+		return errors.Errorf("Invalid query block nested in %s. No function used at root.",
+			sg.SrcFunc.Name)
+	}
+	sub, err := ToSubGraph(ctx, gq)
+	if err != nil {
+		return errors.Wrapf(err, "while converting nested query block to subgraph")
+	}
+	sub.recurse(func(s *SubGraph) {
+		s.ReadTs = sg.ReadTs
+		s.Cache = sg.Cache
+	})
+	if err := sub.recursiveFillVars(sg.subqueryVarMap()); err != nil {
+		return err
+	}
+
+	uids := &pb.List{}
+	if !isEmptyIneqFnWithVar(sub) {
+		if err := sub.planBlock(ctx); err != nil {
+			return err
+		}
+		rch := make(chan error, 1)
+		ProcessGraph(ctx, sub, nil, rch)
+		if err := <-rch; err != nil {
+			return errors.Wrapf(err, "while running nested query block")
+		}
+		uids = codec.ToList(sub.DestMap)
+	}
+
+	// The function can be shared with copies of the node, so it's replaced instead of updated.
+	fn := *sg.SrcFunc
+	fn.Args = nil
+	fn.UIDs = uids
+	sg.SrcFunc = &fn
+	sg.subquery = nil
+	return nil
+}
+
+// processExists finds the nodes in SrcUIDs which have an edge of the predicate that passes the
+// filter of the subquery.
+func (sg *SubGraph) processExists(ctx context.Context) error {
+	matched := sroar.NewBitmap()
+	pending := codec.GetUids(sg.SrcUIDs)
+	var checked int
+	for limit := existsFirstRound; len(pending) > 0; limit *= 2 {
+		edges := &SubGraph{
+			Attr:    sg.Attr,
+			ReadTs:  sg.ReadTs,
+			Cache:   sg.Cache,
+			SrcUIDs: codec.ToList(sroar.FromSortedList(pending)),
+			Params:  params{Count: limit},
+		}
+		taskQuery, err := createTaskQuery(ctx, edges)
+		if err != nil {
+			return err
+		}
+		result, err := worker.ProcessTaskOverNetwork(ctx, taskQuery)
+		switch {
+		case err != nil && strings.Contains(err.Error(), worker.ErrNonExistentTabletMessage):
+			// Nothing has been stored for the predicate yet.
+			result = &pb.Result{}
+		case err != nil:
+			return err
+		}
+		if len(result.UidMatrix) != len(pending) {
+			break
+		}
+
+		// The edges are sorted, so the ones checked in the previous rounds come first.
+		newEdges := make([][]uint64, len(pending))
+		candidates := sroar.NewBitmap()
+		for i := range pending {
+			uids := codec.GetUids(result.UidMatrix[i])
+			if len(uids) > checked {
+				newEdges[i] = uids[checked:]
+				candidates.SetMany(newEdges[i])
+			}
+		}
+		if candidates.IsEmpty() {
+			break
+		}
+		passed, err := sg.filterExistsCandidates(ctx, candidates)
+		if err != nil {
+			return err
+		}
+
+		var next []uint64
+		for i, uid := range pending {
+			var found bool
+			for _, edge := range newEdges[i] {
+				if passed.Contains(edge) {
+					found = true
+					break
+				}
+			}
+			switch {
+			case found:
+				matched.Set(uid)
+			case checked+len(newEdges[i]) == limit:
+				// The node might have more edges, which are checked in the next round.
+				next = append(next, uid)
+			}
+		}
+		pending, checked = next, limit
+	}
+
+	sg.DestMap = matched
+	sg.uidMatrix = []*pb.List{codec.ToList(matched)}
+	return nil
+}
+
+// filterExistsCandidates returns the UIDs in candidates which pass the filter of the subquery.
+func (sg *SubGraph) filterExistsCandidates(ctx context.Context,
+	candidates *sroar.Bitmap) (*sroar.Bitmap, error) {
+	if sg.subquery.Filter == nil {
+		return candidates, nil
+	}
+
+	// ProcessGraph updates the filters it runs, so they're built again for each round.
+	filter := &SubGraph{}
+	if err := filterCopy(filter, sg.subquery.Filter); err != nil {
+		return nil, err
+	}
+	holder := &SubGraph{
+		SrcUIDs: codec.ToList(candidates),
+		Filters: []*SubGraph{filter},
+	}
+	holder.recurse(func(s *SubGraph) {
+		s.ReadTs = sg.ReadTs
+		s.Cache = sg.Cache
+	})
+	vars := sg.subqueryVarMap()
+	holder.Params.ParentVars = vars
+	if err := holder.recursiveFillVars(vars); err != nil {
+		return nil, err
+	}
+
+	rch := make(chan error, 1)
+	ProcessGraph(ctx, holder, sg, rch)
+	if err := <-rch; err != nil {
+		return nil, err
+	}
+	if holder.DestMap == nil {
+		return sroar.NewBitmap(), nil
+	}
+	return holder.DestMap, nil
+}
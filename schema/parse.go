@@ -129,7 +129,8 @@ func parseScalarPair(it *lex.ItemIterator, predicate string, ns uint64) (*pb.Sch
 		return nil, next.Errorf("Undefined Type")
 	}
 	if schema.List {
-		if uint32(t) == uint32(types.PasswordID) || uint32(t) == uint32(types.BoolID) {
+		if uint32(t) == uint32(types.PasswordID) || uint32(t) == uint32(types.BoolID) ||
+			uint32(t) == uint32(types.VFloatID) {
 			return nil, next.Errorf("Unsupported type for list: [%s].", types.TypeID(t).Name())
 		}
 	}
@@ -246,6 +247,123 @@ func parseIndexDirective(it *lex.ItemIterator, predicate string,
 	return tokenizers, nil
 }
 
+// isCompositeIndexDeclaration returns whether the item starts a composite index declaration.
+func isCompositeIndexDeclaration(item lex.Item, it *lex.ItemIterator) bool {
+	if item.Val != "index" {
+		return false
+	}
+
+	nextItems, err := it.Peek(2)
+	switch {
+	case err != nil || len(nextItems) != 2:
+		return false
+
+	case nextItems[0].Typ != itemText || nextItems[0].Val != "composite":
+		return false
+
+	case nextItems[1].Typ != itemLeftRound:
+		return false
+	}
+
+	return true
+}
+
+// parseCompositeIndexDeclaration works on "index composite(pred1, pred2, ...)", which defines an
+// index over the combined values of the predicates. It returns the predicates.
+func parseCompositeIndexDeclaration(it *lex.ItemIterator, ns uint64) ([]string, error) {
+	// Iterator is currently on the token corresponding to the keyword index, followed by
+	// composite and (.
+	it.Next()
+	it.Next()
+
+	var preds []string
+	seen := make(map[string]bool)
+	expectArg := true
+	for it.Next() {
+		next := it.Item()
+		switch {
+		case next.Typ == itemRightRound && !expectArg:
+			if len(preds) < 2 {
+				return nil, next.Errorf("Composite index requires at least two predicates")
+			}
+			// The declaration can end with a dot, like the predicate definitions.
+			if it.Next() && it.Item().Typ == itemDot {
+				it.Next()
+			}
+			if it.Item().Typ != itemNewLine && it.Item().Typ != lex.ItemEOF {
+				return nil, it.Item().Errorf(
+					"Expected new line or EOF after composite index. Got %v", it.Item())
+			}
+			it.Prev()
+			return preds, nil
+		case next.Typ == itemComma && !expectArg:
+			expectArg = true
+		case next.Typ == itemText && expectArg:
+			if seen[next.Val] || strings.ContainsAny(next.Val, ",@") {
+				return nil, next.Errorf("Invalid predicate %s in composite index", next.Val)
+			}
+			seen[next.Val] = true
+			preds = append(preds, x.NamespaceAttr(ns, next.Val))
+			expectArg = false
+		default:
+			return nil, next.Errorf("Unexpected token %v in composite index", next.Val)
+		}
+	}
+	return nil, it.Item().Errorf("Invalid ending")
+}
+
+// addComposites stores each composite index on the schema of its first predicate, which owns
+// it, as the list of the other predicates joined by commas. The owner must be defined in the
+// same schema, as its definition replaces its composite indexes.
+func addComposites(updates []*pb.SchemaUpdate, composites [][]string) error {
+	preds := make(map[string]*pb.SchemaUpdate)
+	for _, schema := range updates {
+		preds[schema.Predicate] = schema
+	}
+	for _, composite := range composites {
+		owner, ok := preds[composite[0]]
+		if !ok {
+			return errors.Errorf("Predicate %s of the composite index %v must be defined in "+
+				"the same schema", x.ParseAttr(composite[0]), x.ParseAttrList(composite))
+		}
+		others := strings.Join(x.ParseAttrList(composite[1:]), ",")
+		if x.HasString(owner.Composite, others) {
+			return errors.Errorf("Duplicate composite index %v", x.ParseAttrList(composite))
+		}
+		owner.Composite = append(owner.Composite, others)
+	}
+	return nil
+}
+
+// resolveComposites verifies that the composite indexes only use predicates that they can
+// index, for the predicates defined in the same schema.
+func resolveComposites(updates []*pb.SchemaUpdate) error {
+	preds := make(map[string]*pb.SchemaUpdate)
+	for _, schema := range updates {
+		preds[schema.Predicate] = schema
+	}
+	for _, schema := range updates {
+		ns := x.ParseNamespace(schema.Predicate)
+		for _, composite := range schema.Composite {
+			list := append([]string{x.ParseAttr(schema.Predicate)},
+				strings.Split(composite, ",")...)
+			for _, pred := range list {
+				other, ok := preds[x.NamespaceAttr(ns, pred)]
+				if !ok {
+					continue
+				}
+				typ := types.TypeID(other.ValueType)
+				if !typ.IsScalar() || typ == types.PasswordID || typ == types.VFloatID ||
+					other.List {
+					return errors.Errorf("Predicate %s of type %s can't be part of the "+
+						"composite index %v", pred, typ.Name(), list)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // resolveTokenizers resolves default tokenizers and verifies tokenizers definitions.
 func resolveTokenizers(updates []*pb.SchemaUpdate) error {
 	for _, schema := range updates {
@@ -477,7 +595,17 @@ func parse(s string, namespace uint64) (*ParsedSchema, error) {
 		return nil, err
 	}
 
+	var composites [][]string
 	parseTypeOrSchema := func(item lex.Item, it *lex.ItemIterator, ns uint64) error {
+		if isCompositeIndexDeclaration(item, it) {
+			preds, err := parseCompositeIndexDeclaration(it, ns)
+			if err != nil {
+				return err
+			}
+			composites = append(composites, preds)
+			return nil
+		}
+
 		if isTypeDeclaration(item, it) {
 			typeUpdate, err := parseTypeDeclaration(it, ns)
 			if err != nil {
@@ -503,6 +631,12 @@ func parse(s string, namespace uint64) (*ParsedSchema, error) {
 			if err := resolveTokenizers(result.Preds); err != nil {
 				return nil, errors.Wrapf(err, "failed to enrich schema")
 			}
+			if err := addComposites(result.Preds, composites); err != nil {
+				return nil, err
+			}
+			if err := resolveComposites(result.Preds); err != nil {
+				return nil, err
+			}
 			return &result, nil
 
 		case itemText:
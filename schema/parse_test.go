@@ -326,6 +326,65 @@ func TestParseScalarListError3(t *testing.T) {
 	require.Contains(t, err.Error(), "Unsupported type for list: [bool]")
 }
 
+func TestParseVectorIndex(t *testing.T) {
+	reset()
+	result, err := Parse(`
+		embedding: float32vector @index(hnsw) .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Preds))
+	require.EqualValues(t, &pb.SchemaUpdate{
+		Predicate: x.GalaxyAttr("embedding"),
+		ValueType: pb.Posting_VFLOAT,
+		Directive: pb.SchemaUpdate_INDEX,
+		Tokenizer: []string{"hnsw"},
+	}, result.Preds[0])
+
+	_, err = Parse(`
+		embedding: [float32vector] .
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unsupported type for list: [float32vector]")
+}
+
+func TestParseComposite(t *testing.T) {
+	reset()
+	result, err := Parse(`
+		country: string @index(exact) .
+		status: string .
+		index composite(country, status, city)
+		index composite(country, zip) .
+		[0x2] index: string .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(result.Preds))
+	require.EqualValues(t, &pb.SchemaUpdate{
+		Predicate: x.GalaxyAttr("country"),
+		ValueType: pb.Posting_STRING,
+		Directive: pb.SchemaUpdate_INDEX,
+		Tokenizer: []string{"exact"},
+		Composite: []string{"status,city", "zip"},
+	}, result.Preds[0])
+	require.Equal(t, x.NamespaceAttr(2, "index"), result.Preds[2].Predicate)
+
+	for _, s := range []string{
+		"country: [string] .\n index composite(country, status)",
+		"country: uid .\n index composite(country, status)",
+		"country: string .\n index composite()",
+		"country: string .\n index composite(country)",
+		"country: string .\n index composite(country, status, status)",
+		"country: string .\n index composite(country, status) status: string .",
+		"country: string .\n status: [string] .\n index composite(country, status)",
+		"country: string .\n index composite(country, status)\n index composite(country, status)",
+		// The owner of the index must be defined along with it.
+		"status: string .\n index composite(country, status)",
+		"country: string @composite(status) .",
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
 func TestParseUidList(t *testing.T) {
 	reset()
 	result, err := Parse(`
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 
 	"github.com/golang/glog"
@@ -62,6 +63,7 @@ func (s *state) init() {
 	s.types = make(map[string]*pb.TypeUpdate)
 	s.elog = trace.NewEventLog("Dgraph", "Schema")
 	s.mutSchema = make(map[string]*pb.SchemaUpdate)
+	s.composites = make(map[string][]string)
 }
 
 type state struct {
@@ -72,6 +74,9 @@ type state struct {
 	elog      trace.EventLog
 	// mutSchema holds the schema update that is being applied in the background.
 	mutSchema map[string]*pb.SchemaUpdate
+	// composites maps each predicate to the predicates owning the composite indexes that it
+	// is part of, in either of the schemas above.
+	composites map[string][]string
 }
 
 // State returns the struct holding the current schema.
@@ -94,6 +99,10 @@ func (s *state) DeleteAll() {
 	for pred := range s.mutSchema {
 		delete(s.mutSchema, pred)
 	}
+
+	for pred := range s.composites {
+		delete(s.composites, pred)
+	}
 }
 
 // Delete updates the schema in memory and disk
@@ -111,8 +120,12 @@ func (s *state) Delete(attr string, ts uint64) error {
 		return err
 	}
 
+	hadComposite := s.hasComposite(attr)
 	delete(s.predicate, attr)
 	delete(s.mutSchema, attr)
+	if hadComposite {
+		s.updateComposites()
+	}
 	return nil
 }
 
@@ -175,6 +188,7 @@ func (s *state) DeletePredsForNs(delNs uint64) {
 			delete(s.types, typ)
 		}
 	}
+	s.updateComposites()
 }
 
 func logUpdate(schema *pb.SchemaUpdate, pred string) string {
@@ -203,7 +217,11 @@ func (s *state) Set(pred string, schema *pb.SchemaUpdate) {
 
 	s.Lock()
 	defer s.Unlock()
+	hadComposite := s.hasComposite(pred)
 	s.predicate[pred] = schema
+	if hadComposite || len(schema.Composite) > 0 {
+		s.updateComposites()
+	}
 	s.elog.Printf(logUpdate(schema, pred))
 }
 
@@ -211,14 +229,53 @@ func (s *state) Set(pred string, schema *pb.SchemaUpdate) {
 func (s *state) SetMutSchema(pred string, schema *pb.SchemaUpdate) {
 	s.Lock()
 	defer s.Unlock()
+	hadComposite := s.hasComposite(pred)
 	s.mutSchema[pred] = schema
+	if hadComposite || len(schema.GetComposite()) > 0 {
+		s.updateComposites()
+	}
 }
 
 // DeleteMutSchema deletes the schema for given predicate from mutSchema.
 func (s *state) DeleteMutSchema(pred string) {
 	s.Lock()
 	defer s.Unlock()
+	hadComposite := s.hasComposite(pred)
 	delete(s.mutSchema, pred)
+	if hadComposite {
+		s.updateComposites()
+	}
+}
+
+// hasComposite returns whether the predicate owns a composite index in either schema. It must
+// be called with the lock held.
+func (s *state) hasComposite(pred string) bool {
+	return len(s.predicate[pred].GetComposite()) > 0 || len(s.mutSchema[pred].GetComposite()) > 0
+}
+
+// updateComposites rebuilds the map from predicates to the owners of the composite indexes
+// they are part of. It must be called with the lock held.
+func (s *state) updateComposites() {
+	composites := make(map[string][]string)
+	add := func(owner string, su *pb.SchemaUpdate) {
+		ns := x.ParseNamespace(owner)
+		for _, composite := range su.GetComposite() {
+			preds := append([]string{owner},
+				x.NamespaceAttrList(ns, strings.Split(composite, ","))...)
+			for _, pred := range preds {
+				if !x.HasString(composites[pred], owner) {
+					composites[pred] = append(composites[pred], owner)
+				}
+			}
+		}
+	}
+	for pred, su := range s.predicate {
+		add(pred, su)
+	}
+	for pred, su := range s.mutSchema {
+		add(pred, su)
+	}
+	s.composites = composites
 }
 
 // GetIndexingPredicates returns the list of predicates for which we are building indexes.
@@ -306,6 +363,32 @@ func (s *state) IsIndexed(ctx context.Context, pred string) bool {
 	return false
 }
 
+// CompositeIndexes returns the composite indexes that the predicate is part of. Each index is
+// returned as the list of its predicates, starting with the predicate that owns it.
+func (s *state) CompositeIndexes(ctx context.Context, pred string) [][]string {
+	isWrite, _ := ctx.Value(isWrite).(bool)
+	s.RLock()
+	defer s.RUnlock()
+	var out [][]string
+	for _, owner := range s.composites[pred] {
+		su := s.predicate[owner]
+		if isWrite {
+			if schema, ok := s.mutSchema[owner]; ok {
+				su = schema
+			}
+		}
+		ns := x.ParseNamespace(owner)
+		for _, composite := range su.GetComposite() {
+			preds := append([]string{owner},
+				x.NamespaceAttrList(ns, strings.Split(composite, ","))...)
+			if x.HasString(preds, pred) {
+				out = append(out, preds)
+			}
+		}
+	}
+	return out
+}
+
 // Predicates returns the list of predicates for given group
 func (s *state) Predicates() []string {
 	if s == nil {
@@ -714,6 +797,9 @@ func initialSchemaInternal(namespace uint64, all bool) []*pb.SchemaUpdate {
 			ValueType: pb.Posting_STRING,
 			Directive: pb.SchemaUpdate_INDEX,
 			Tokenizer: []string{"sha256"},
+		}, &pb.SchemaUpdate{
+			Predicate: "dgraph.cdc.subscriptions",
+			ValueType: pb.Posting_STRING,
 		})
 
 	if all || x.WorkerConfig.AclEnabled {
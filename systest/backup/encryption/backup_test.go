@@ -337,7 +337,7 @@ func runRestore(t *testing.T, lastDir string, commitTs uint64) map[string]string
 	restoredPreds, err := testutil.GetPredicateNames(pdir)
 	require.NoError(t, err)
 	require.ElementsMatch(t, []string{"dgraph.graphql.schema", "dgraph.graphql.xid", "dgraph.type",
-		"movie", "dgraph.graphql.p_query", "dgraph.drop.op"},
+		"movie", "dgraph.graphql.p_query", "dgraph.drop.op", "dgraph.cdc.subscriptions"},
 		restoredPreds)
 
 	restoredTypes, err := testutil.GetTypeNames(pdir)
@@ -84,6 +84,7 @@ var expectedSchema = `[0x0] <movie>:string .` + " " + `
 [0x0] <dgraph.graphql.xid>:string @index(exact) @upsert .` + " " + `
 [0x0] <dgraph.graphql.schema>:string .` + " " + `
 [0x0] <dgraph.graphql.p_query>:string @index(sha256) .` + " " + `
+[0x0] <dgraph.cdc.subscriptions>:string .` + " " + `
 [0x0] type <Node> {
 	movie
 }
@@ -220,6 +221,85 @@ func TestExportAndLoadJsonFacets(t *testing.T) {
 	dirCleanup(t)
 }
 
+var typesSchema = `
+	type Author {
+		id: ID!
+		name: String! @id
+		posts: [Post] @hasInverse(field: author)
+	}
+
+	type Post {
+		title: String! @id
+		score: Float
+		author: Author
+	}`
+
+// TestExportTypes checks the files of the typed export formats, which have the objects of each
+// GraphQL type, with references to the objects of the other types keyed by their @id fields.
+func TestExportTypes(t *testing.T) {
+	require.NoError(t, os.MkdirAll("./data", os.ModePerm))
+	dg, err := testutil.DgraphClient(testutil.SockAddr)
+	require.NoError(t, err)
+	require.NoError(t, testutil.RetryAlter(dg, &api.Operation{DropAll: true}))
+
+	resp := testutil.MakeGQLRequest(t, &testutil.GraphQLParams{
+		Query: `mutation updateGQLSchema($sch: String!) {
+			updateGQLSchema(input: { set: { schema: $sch }}) {
+				gqlSchema { id }
+			}
+		}`,
+		Variables: map[string]interface{}{"sch": typesSchema},
+	})
+	resp.RequireNoGraphQLErrors(t)
+
+	mu, err := dg.NewTxn().Mutate(context.Background(), &api.Mutation{
+		CommitNow: true,
+		SetNquads: []byte(`
+			_:a <dgraph.type> "Author" .
+			_:a <Author.name> "Alice" .
+			_:a <Author.posts> _:p .
+			_:p <dgraph.type> "Post" .
+			_:p <Post.title> "Hello" .
+			_:p <Post.score> "4.5" .
+			_:p <Post.author> _:a .`),
+	})
+	require.NoError(t, err)
+	alice := mu.Uids["a"]
+
+	readExport := func(name string) string {
+		files, err := ioutil.ReadDir(copyExportDir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		f, err := os.Open(filepath.Join(copyExportDir, files[0].Name(), name))
+		require.NoError(t, err)
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	requestExport(t, "/data/export-data", "graphql")
+	copyToLocalFs(t)
+	require.JSONEq(t, `[{"id": "`+alice+`", "name": "Alice", "posts": [{"title": "Hello"}]}]`,
+		readExport("0x0.Author.json.gz"))
+	require.JSONEq(t, `[{"title": "Hello", "score": 4.5,
+		"author": {"name": "Alice", "id": "`+alice+`"}}]`,
+		readExport("0x0.Post.json.gz"))
+	dirCleanup(t)
+
+	// The CSV files reference the objects by their first @id field.
+	require.NoError(t, os.MkdirAll("./data", os.ModePerm))
+	requestExport(t, "/data/export-data", "csv")
+	copyToLocalFs(t)
+	require.Equal(t, "id,name,posts\n"+alice+`,Alice,"[""Hello""]"`+"\n",
+		readExport("0x0.Author.csv.gz"))
+	require.Equal(t, "title,score,author\nHello,4.5,Alice\n",
+		readExport("0x0.Post.csv.gz"))
+	dirCleanup(t)
+}
+
 func runQuery(t *testing.T, q string) string {
 	dg, err := testutil.DgraphClient(testutil.SockAddr)
 	require.NoError(t, err)
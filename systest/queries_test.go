@@ -374,6 +374,9 @@ func SchemaQueryTestPredicate1(t *testing.T, c *dgo.Dgraph) {
 	js := `
   {
     "schema": [
+	  {
+		"predicate": "dgraph.cdc.subscriptions"
+	  },
 	  {
 		"predicate": "dgraph.drop.op"
 	  },
@@ -53,6 +53,8 @@ const (
 	IdentTrigram   = 0xA
 	IdentHash      = 0xB
 	IdentSha       = 0xC
+	IdentVector    = 0xD
+	IdentComposite = 0xE // Not a tokenizer, composite index terms are built by x.CompositeIndexKey.
 	IdentCustom    = 0x80
 	IdentDelimiter = 0x1f // ASCII 31 - Unit seperator
 )
@@ -101,6 +103,7 @@ func init() {
 	registerTokenizer(TermTokenizer{})
 	registerTokenizer(FullTextTokenizer{})
 	registerTokenizer(Sha256Tokenizer{})
+	registerTokenizer(HNSWTokenizer{})
 	setupBleve()
 }
 
@@ -464,6 +467,23 @@ func (t HashTokenizer) IsSortable() bool { return false }
 // query operations using the hash index.
 func (t HashTokenizer) IsLossy() bool { return false }
 
+// HNSWTokenizer indexes vectors in a hierarchical navigable small world graph, which is used to
+// answer approximate nearest neighbour queries. The graph is kept under the index keys of the
+// predicate by the posting package instead of being built from tokens, so it returns none.
+type HNSWTokenizer struct{}
+
+func (t HNSWTokenizer) Name() string { return "hnsw" }
+func (t HNSWTokenizer) Type() string { return "float32vector" }
+func (t HNSWTokenizer) Tokens(v interface{}) ([]string, error) {
+	if _, ok := v.([]float32); !ok {
+		return nil, errors.Errorf("HNSW indices only supported for float32vector types")
+	}
+	return nil, nil
+}
+func (t HNSWTokenizer) Identifier() byte { return IdentVector }
+func (t HNSWTokenizer) IsSortable() bool { return false }
+func (t HNSWTokenizer) IsLossy() bool    { return true }
+
 // PluginTokenizer is implemented by external plugins loaded dynamically via
 // *.so files. It follows the implementation semantics of the Tokenizer
 // interface.
@@ -91,6 +91,12 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				*res = w
 			case PasswordID:
 				*res = string(data)
+			case VFloatID:
+				v, err := BytesAsFloatArray(data)
+				if err != nil {
+					return to, err
+				}
+				*res = v
 			default:
 				return to, cantConvert(fromID, toID)
 			}
@@ -144,6 +150,12 @@ func Convert(from Val, toID TypeID) (Val, error) {
 					return to, err
 				}
 				*res = p
+			case VFloatID:
+				v, err := ParseVFloat(vc)
+				if err != nil {
+					return to, err
+				}
+				*res = v
 			default:
 				return to, cantConvert(fromID, toID)
 			}
@@ -304,6 +316,23 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				return to, cantConvert(fromID, toID)
 			}
 		}
+	case VFloatID:
+		{
+			vc, err := BytesAsFloatArray(data)
+			if err != nil {
+				return to, err
+			}
+			switch toID {
+			case VFloatID:
+				*res = vc
+			case BinaryID:
+				*res = data
+			case StringID, DefaultID:
+				*res = FloatArrayAsString(vc)
+			default:
+				return to, cantConvert(fromID, toID)
+			}
+		}
 	default:
 		return to, cantConvert(fromID, toID)
 	}
@@ -435,6 +464,16 @@ func Marshal(from Val, to *Val) error {
 		default:
 			return cantConvert(fromID, toID)
 		}
+	case VFloatID:
+		vc := val.([]float32)
+		switch toID {
+		case StringID, DefaultID:
+			*res = FloatArrayAsString(vc)
+		case BinaryID:
+			*res = FloatArrayAsBytes(vc)
+		default:
+			return cantConvert(fromID, toID)
+		}
 	default:
 		return cantConvert(fromID, toID)
 	}
@@ -503,6 +542,14 @@ func ObjectValue(id TypeID, value interface{}) (*api.Value, error) {
 			return def, errors.Errorf("Expected value of type password. Got : %v", value)
 		}
 		return &api.Value{Val: &api.Value_PasswordVal{PasswordVal: v}}, nil
+	// There is no vector type in api.Value, so vectors are sent in their string form and
+	// converted back using the schema of the predicate.
+	case VFloatID:
+		var v []float32
+		if v, ok = value.([]float32); !ok {
+			return def, errors.Errorf("Expected value of type float32vector. Got : %v", value)
+		}
+		return &api.Value{Val: &api.Value_DefaultVal{DefaultVal: FloatArrayAsString(v)}}, nil
 	default:
 		return def, errors.Errorf("ObjectValue not available for: %v", id)
 	}
@@ -537,6 +584,8 @@ func (v Val) MarshalJSON() ([]byte, error) {
 		return json.Marshal(v.Safe().(string))
 	case PasswordID:
 		return json.Marshal(v.Value.(string))
+	case VFloatID:
+		return json.Marshal(v.Value.([]float32))
 	}
 	return nil, errors.Errorf("Invalid type for MarshalJSON: %v", v.Tid)
 }
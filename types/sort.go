@@ -60,9 +60,9 @@ func (s byValue) Less(i, j int) bool {
 	}
 	for vidx := range first {
 		// Null values are appended at the end of the sort result for both ascending and descending.
-		// If both first and second has nil values, then maintain the order by UID.
+		// If both first and second has nil values, then look at the next value.
 		if first[vidx].Value == nil && second[vidx].Value == nil {
-			return s.desc[vidx]
+			continue
 		}
 
 		if first[vidx].Value == nil {
@@ -99,7 +99,7 @@ func IsSortable(tid TypeID) bool {
 }
 
 // SortWithFacet sorts the given array in-place and considers the given facets to calculate
-// the proper ordering.
+// the proper ordering. The values which are equal keep their order.
 func SortWithFacet(v [][]Val, ul *[]uint64, l []*pb.Facets, desc []bool, lang string) error {
 	if len(v) == 0 || len(v[0]) == 0 {
 		return nil
@@ -122,7 +122,8 @@ func SortWithFacet(v [][]Val, ul *[]uint64, l []*pb.Facets, desc []bool, lang st
 
 	b := sortBase{v, desc, ul, l, cl}
 	toBeSorted := byValue{b}
-	sort.Sort(toBeSorted)
+	// The cursor pagination relies on the equal values keeping the order of their UIDs.
+	sort.Stable(toBeSorted)
 	return nil
 }
 
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Vectors are stored as the little endian encoding of their float32 components and written as
+// a list of numbers, e.g. "[0.1, 0.2, 0.3]".
+
+// BytesAsFloatArray decodes a vector from its binary form.
+func BytesAsFloatArray(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, errors.Errorf("Invalid data for float32vector %v", data)
+	}
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return v, nil
+}
+
+// FloatArrayAsBytes encodes a vector into its binary form.
+func FloatArrayAsBytes(v []float32) []byte {
+	data := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(f))
+	}
+	return data
+}
+
+// ParseVFloat parses a vector from a list of numbers like "[0.1, 0.2, 0.3]".
+func ParseVFloat(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, errors.Errorf("Invalid value for float32vector: %q. Expected a list of "+
+			"numbers like [0.1, 0.2]", s)
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return nil, errors.Errorf("float32vector can't be empty")
+	}
+	parts := strings.Split(inner, ",")
+	v := make([]float32, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "while parsing float32vector %q", s)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.Errorf("Got invalid value in float32vector: %v", f)
+		}
+		v = append(v, float32(f))
+	}
+	return v, nil
+}
+
+// FloatArrayAsString formats a vector in the form accepted by ParseVFloat.
+func FloatArrayAsString(v []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// EuclideanDistanceSq returns the squared euclidean distance between the vectors, which must
+// have the same number of dimensions.
+func EuclideanDistanceSq(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, errors.Errorf("Can't compare vectors of %d and %d dimensions", len(a), len(b))
+	}
+	var dist float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		dist += d * d
+	}
+	return dist, nil
+}
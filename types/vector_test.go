@@ -0,0 +1,62 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVFloat(t *testing.T) {
+	v, err := ParseVFloat(" [0.5, -1,2e3 ] ")
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.5, -1, 2000}, v)
+	require.Equal(t, "[0.5, -1, 2000]", FloatArrayAsString(v))
+
+	for _, s := range []string{"", "[]", "0.5, 1", "[0.5, a]", "[1, NaN]", "[1, Inf]"} {
+		_, err := ParseVFloat(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestConvertVFloat(t *testing.T) {
+	src := Val{Tid: StringID, Value: []byte("[0.1, 0.2, 0.3]")}
+	vec, err := Convert(src, VFloatID)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.1, 0.2, 0.3}, vec.Value)
+
+	b := ValueForType(BinaryID)
+	require.NoError(t, Marshal(vec, &b))
+	require.Len(t, b.Value, 12)
+
+	out, err := Convert(b, VFloatID)
+	require.NoError(t, err)
+	require.Equal(t, vec.Value, out.Value)
+
+	_, err = Convert(Val{Tid: BinaryID, Value: []byte{1, 2, 3}}, VFloatID)
+	require.Error(t, err)
+}
+
+func TestEuclideanDistanceSq(t *testing.T) {
+	d, err := EuclideanDistanceSq([]float32{1, 2}, []float32{4, 6})
+	require.NoError(t, err)
+	require.Equal(t, float64(25), d)
+
+	_, err = EuclideanDistanceSq([]float32{1, 2}, []float32{1})
+	require.Error(t, err)
+}
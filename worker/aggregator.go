@@ -29,9 +29,11 @@ func couldApplyAggregatorOn(agrtr string, typ types.TypeID) bool {
 			typ == types.DateTimeID ||
 			typ == types.StringID ||
 			typ == types.DefaultID)
-	case "sum", "avg":
+	case "sum", "avg", "percentile", "median", "stddev", "variance":
 		return (typ == types.IntID ||
 			typ == types.FloatID)
+	case "distinct":
+		return true
 	default:
 		return false
 	}
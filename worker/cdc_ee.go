@@ -24,6 +24,7 @@ import (
 
 	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/dgraph-io/ristretto/z"
@@ -32,10 +33,6 @@ import (
 	"go.etcd.io/etcd/raft/raftpb"
 )
 
-const (
-	defaultEventTopic = "dgraph-cdc"
-)
-
 // CDC struct is being used to send out change data capture events. There are two ways to do this:
 // 1. Use Badger Subscribe.
 // 2. Use Raft WAL.
@@ -50,6 +47,9 @@ type CDC struct {
 	sink             Sink
 	closer           *z.Closer
 	pendingTxnEvents map[uint64][]CDCEvent
+	// beforeImage is set if the mutation events carry the values which the predicates had
+	// before the txn.
+	beforeImage bool
 
 	// dont use mutex, use atomic for the following.
 
@@ -68,10 +68,20 @@ func newCDC() *CDC {
 	cdcFlag := z.NewSuperFlag(Config.ChangeDataConf).MergeAndCheckDefault(CDCDefaults)
 	sink, err := GetSink(cdcFlag)
 	x.Check(err)
+	if path := cdcFlag.GetPath("subscriptions"); path != "" {
+		x.Check(loadCDCSubscriptions(path))
+	}
 	cdc := &CDC{
 		sink:             sink,
 		closer:           z.NewCloser(1),
 		pendingTxnEvents: make(map[uint64][]CDCEvent),
+		beforeImage:      cdcFlag.GetBool("before-image"),
+	}
+	switch s := sink.(type) {
+	case *webhookSink:
+		s.closer = cdc.closer
+	case *grpcSink:
+		s.checkpoint = func() uint64 { return atomic.LoadUint64(&cdc.sentTs) }
 	}
 	return cdc
 }
@@ -193,19 +203,7 @@ func (cdc *CDC) processCDCEvents() {
 	}
 
 	sendToSink := func(pending []CDCEvent, commitTs uint64) error {
-		batch := make([]SinkMessage, len(pending))
-		for i, e := range pending {
-			e.Meta.CommitTs = commitTs
-			b, err := json.Marshal(e)
-			x.Check(err)
-			batch[i] = SinkMessage{
-				Meta: SinkMeta{
-					Topic: defaultEventTopic,
-				},
-				Key:   e.Meta.Namespace,
-				Value: b,
-			}
-		}
+		batch := cdc.sinkMessages(GetCDCSubscriptions(), pending, commitTs)
 		if err := cdc.sink.Send(batch); err != nil {
 			glog.Errorf("error while sending cdc event to sink %+v", err)
 			return err
@@ -236,7 +234,7 @@ func (cdc *CDC) processCDCEvents() {
 			return
 		}
 		if proposal.Mutations != nil {
-			events := toCDCEvent(entry.Index, proposal.Mutations)
+			events := toCDCEvent(entry.Index, proposal.Mutations, cdc.beforeImage)
 			if len(events) == 0 {
 				return
 			}
@@ -271,6 +269,18 @@ func (cdc *CDC) processCDCEvents() {
 					}
 				}
 				return
+			case len(proposal.Mutations.Schema) > 0 || len(proposal.Mutations.Types) > 0:
+				// Like dropping a predicate, a schema update only succeeds if there were no
+				// pending txns for its predicates.
+				for _, update := range proposal.Mutations.Schema {
+					if cdc.hasPending(x.ParseAttr(update.Predicate)) {
+						return
+					}
+				}
+				if err := sendToSink(events, proposal.Mutations.StartTs); err != nil {
+					rerr = errors.Wrapf(err, "unable to send messages to sink")
+				}
+				return
 			default:
 				cdc.addToPending(proposal.Mutations.StartTs, events)
 			}
@@ -325,6 +335,10 @@ func (cdc *CDC) processCDCEvents() {
 		return nil
 	}
 
+	// The subscriptions are kept up to date with the ones stored in group 1, so that the leaders
+	// of all the groups route the events the same way.
+	go watchCDCSubscriptions(cdc.closer)
+
 	jobTick := time.NewTicker(time.Second)
 	proposalTick := time.NewTicker(3 * time.Minute)
 	defer cdc.closer.Done()
@@ -336,7 +350,13 @@ func (cdc *CDC) processCDCEvents() {
 		case <-cdc.closer.HasBeenClosed():
 			return
 		case <-jobTick.C:
-			if groups().Node.AmLeader() && EnterpriseEnabled() {
+			if !groups().Node.AmLeader() {
+				if s, ok := cdc.sink.(*grpcSink); ok {
+					s.reset()
+				}
+				continue
+			}
+			if EnterpriseEnabled() && cdcSubscriptionsReady() {
 				if err := sendEvents(); err != nil {
 					glog.Errorf("unable to send events %+v", err)
 				}
@@ -361,6 +381,39 @@ func (cdc *CDC) processCDCEvents() {
 	}
 }
 
+// sinkMessages returns the messages of the events committed at commitTs, for the topics of the
+// subscriptions they match.
+func (cdc *CDC) sinkMessages(subs []*CDCSubscription, pending []CDCEvent,
+	commitTs uint64) []SinkMessage {
+	_, hasTopics := cdc.sink.(*kafkaSinkClient)
+	batch := make([]SinkMessage, 0, len(pending))
+	for _, e := range pending {
+		e.Meta.CommitTs = commitTs
+		topics := eventTopics(subs, e)
+		if !hasTopics && len(topics) > 0 {
+			// The other sinks don't route the messages by topic, so the events are sent once,
+			// with the topics of the subscriptions they match.
+			if len(subs) > 0 {
+				e.Meta.Topics = topics
+			}
+			topics = topics[:1]
+		}
+		b, err := json.Marshal(e)
+		x.Check(err)
+		for _, topic := range topics {
+			batch = append(batch, SinkMessage{
+				Meta: SinkMeta{
+					Topic:    topic,
+					CommitTs: commitTs,
+				},
+				Key:   e.Meta.Namespace,
+				Value: b,
+			})
+		}
+	}
+	return batch
+}
+
 type CDCEvent struct {
 	Meta  *EventMeta  `json:"meta"`
 	Type  string      `json:"type"`
@@ -371,6 +424,9 @@ type EventMeta struct {
 	RaftIndex uint64 `json:"-"`
 	Namespace uint64 `json:"namespace"`
 	CommitTs  uint64 `json:"commit_ts"`
+	// Topics are the topics of the subscriptions the event matches, for the sinks which don't
+	// route the events by topic.
+	Topics []string `json:"topics,omitempty"`
 }
 
 type MutationEvent struct {
@@ -379,6 +435,18 @@ type MutationEvent struct {
 	Attr      string      `json:"attr"`
 	Value     interface{} `json:"value"`
 	ValueType string      `json:"value_type"`
+	// Before has the values of the predicate for the node before the txn, when --cdc
+	// before-image is set. It's empty if the node didn't have any, and it's left out if it
+	// couldn't be read, see readBeforeImage.
+	Before interface{} `json:"before,omitempty"`
+}
+
+// SchemaEvent is sent when the schema of a predicate or a type is set.
+type SchemaEvent struct {
+	Operation string `json:"operation"`
+	Pred      string `json:"pred,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Schema    string `json:"schema"`
 }
 
 type DropEvent struct {
@@ -390,13 +458,15 @@ type DropEvent struct {
 const (
 	EventTypeDrop     = "drop"
 	EventTypeMutation = "mutation"
+	EventTypeSchema   = "schema"
 	OpDropPred        = "predicate"
+	OpSchemaPred      = "predicate"
+	OpSchemaType      = "type"
 )
 
-func toCDCEvent(index uint64, mutation *pb.Mutations) []CDCEvent {
-	// todo(Aman): we are skipping schema updates for now. Fix this later.
+func toCDCEvent(index uint64, mutation *pb.Mutations, beforeImage bool) []CDCEvent {
 	if len(mutation.Schema) > 0 || len(mutation.Types) > 0 {
-		return nil
+		return toSchemaEvents(index, mutation)
 	}
 
 	// If drop operation
@@ -437,6 +507,14 @@ func toCDCEvent(index uint64, mutation *pb.Mutations) []CDCEvent {
 		}
 	}
 
+	// The before images are read once per node and predicate, even if the txn wrote several
+	// edges for them.
+	type nodeAttr struct {
+		uid  uint64
+		attr string
+	}
+	before := make(map[nodeAttr]interface{})
+
 	cdcEvents := make([]CDCEvent, 0)
 	for _, edge := range mutation.Edges {
 		if x.IsReservedPredicate(edge.Attr) {
@@ -475,21 +553,139 @@ func toCDCEvent(index uint64, mutation *pb.Mutations) []CDCEvent {
 				glog.Errorf("error while converting value %v", err)
 			}
 		}
+		event := &MutationEvent{
+			Operation: strings.ToLower(edge.Op.String()),
+			Uid:       edge.Entity,
+			Attr:      attr,
+			Value:     val,
+			ValueType: posting.TypeID(edge).Name(),
+		}
+		if beforeImage {
+			key := nodeAttr{uid: edge.Entity, attr: edge.Attr}
+			if _, ok := before[key]; !ok {
+				vals, err := readBeforeImage(edge, mutation.StartTs)
+				if err != nil {
+					glog.Errorf("CDC: error while reading the before image of %s for %#x: %v",
+						attr, edge.Entity, err)
+				}
+				before[key] = vals
+			}
+			event.Before = before[key]
+		}
 		cdcEvents = append(cdcEvents, CDCEvent{
 			Meta: &EventMeta{
 				RaftIndex: index,
 				Namespace: ns,
 			},
-			Type: EventTypeMutation,
-			Event: &MutationEvent{
-				Operation: strings.ToLower(edge.Op.String()),
-				Uid:       edge.Entity,
-				Attr:      attr,
-				Value:     val,
-				ValueType: posting.TypeID(edge).Name(),
-			},
+			Type:  EventTypeMutation,
+			Event: event,
 		})
 	}
 
 	return cdcEvents
 }
+
+// readBeforeImage returns the values of the predicate of edge for its node, as the txn which
+// started at startTs saw them. The txn was already applied, so they're read at startTs-1, which
+// sees the same commits without the uncommitted writes of the txn.
+//
+// The CDC loop can lag behind the applied entries, e.g. while the sink is down. Once a snapshot
+// is taken at a ReadTs above startTs-1, the older versions of the keys can be rolled up and
+// discarded, so the before image can't be read anymore and an error is returned instead.
+func readBeforeImage(edge *pb.DirectedEdge, startTs uint64) (interface{}, error) {
+	readTs := startTs - 1
+	if discardTs := atomic.LoadUint64(&snapshotReadTs); readTs < discardTs {
+		return nil, errors.Errorf("the versions before the snapshot at ts %d can be discarded",
+			discardTs)
+	}
+	pl, err := posting.GetNoStore(x.DataKey(edge.Attr, edge.Entity), readTs)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := schema.State().TypeOf(edge.Attr)
+	if err != nil {
+		typ = posting.TypeID(edge)
+	}
+
+	if typ == types.UidID {
+		uids, err := pl.Uids(posting.ListOptions{ReadTs: readTs})
+		if err != nil {
+			return nil, err
+		}
+		if len(uids.Uids) == 0 {
+			return []uint64{}, nil
+		}
+		return uids.Uids, nil
+	}
+
+	vals, err := pl.AllValues(readTs)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(vals))
+	for _, val := range vals {
+		if val.Tid == types.PasswordID {
+			out = append(out, "****")
+			continue
+		}
+		src := types.Val{Tid: types.BinaryID, Value: val.Value}
+		v, err := types.Convert(src, val.Tid)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v.Value)
+	}
+	return out, nil
+}
+
+// toSchemaEvents returns the events for the schema of the predicates and the types set by
+// mutation. The schema is given in the DQL format, without the namespace.
+func toSchemaEvents(index uint64, mutation *pb.Mutations) []CDCEvent {
+	// The schema is formatted as it's exported, where it's prefixed with the namespace.
+	trimNamespace := func(exported []byte) string {
+		s := string(exported)
+		if i := strings.IndexByte(s, ' '); i >= 0 {
+			s = s[i+1:]
+		}
+		return strings.TrimSpace(s)
+	}
+
+	var cdcEvents []CDCEvent
+	for _, update := range mutation.Schema {
+		if x.IsReservedPredicate(update.Predicate) {
+			continue
+		}
+		ns, attr := x.ParseNamespaceAttr(update.Predicate)
+		cdcEvents = append(cdcEvents, CDCEvent{
+			Meta: &EventMeta{
+				RaftIndex: index,
+				Namespace: ns,
+			},
+			Type: EventTypeSchema,
+			Event: &SchemaEvent{
+				Operation: OpSchemaPred,
+				Pred:      attr,
+				Schema:    trimNamespace(toSchema(update.Predicate, update).Value),
+			},
+		})
+	}
+	for _, update := range mutation.Types {
+		if x.IsReservedType(update.TypeName) {
+			continue
+		}
+		ns, typ := x.ParseNamespaceAttr(update.TypeName)
+		cdcEvents = append(cdcEvents, CDCEvent{
+			Meta: &EventMeta{
+				RaftIndex: index,
+				Namespace: ns,
+			},
+			Type: EventTypeSchema,
+			Event: &SchemaEvent{
+				Operation: OpSchemaType,
+				Type:      typ,
+				Schema:    trimNamespace(toType(update.TypeName, *update).Value),
+			},
+		})
+	}
+	return cdcEvents
+}
@@ -0,0 +1,174 @@
+// +build !oss
+
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package worker
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestSchemaCDCEvents(t *testing.T) {
+	events := toCDCEvent(5, &pb.Mutations{
+		StartTs: 10,
+		Schema: []*pb.SchemaUpdate{
+			{
+				Predicate: x.NamespaceAttr(2, "name"),
+				ValueType: pb.Posting_STRING,
+				Directive: pb.SchemaUpdate_INDEX,
+				Tokenizer: []string{"exact"},
+			},
+			{Predicate: x.NamespaceAttr(2, "dgraph.type"), ValueType: pb.Posting_STRING},
+		},
+		Types: []*pb.TypeUpdate{{
+			TypeName: x.NamespaceAttr(2, "Person"),
+			Fields:   []*pb.SchemaUpdate{{Predicate: x.NamespaceAttr(2, "name")}},
+		}},
+	}, true)
+
+	require.Equal(t, []CDCEvent{
+		{
+			Meta: &EventMeta{RaftIndex: 5, Namespace: 2},
+			Type: EventTypeSchema,
+			Event: &SchemaEvent{
+				Operation: OpSchemaPred,
+				Pred:      "name",
+				Schema:    "<name>:string @index(exact) .",
+			},
+		},
+		{
+			Meta: &EventMeta{RaftIndex: 5, Namespace: 2},
+			Type: EventTypeSchema,
+			Event: &SchemaEvent{
+				Operation: OpSchemaType,
+				Type:      "Person",
+				Schema:    "type <Person> {\n\tname\n}",
+			},
+		},
+	}, events)
+}
+
+func TestCDCSubscriptionTopics(t *testing.T) {
+	subs := []*CDCSubscription{
+		{Name: "tenant1", Namespaces: []uint64{1}, Topic: "cdc.{namespace}.{predicate}"},
+		{Name: "names", IncludePredicates: []string{"name", "<http://a/b>"}, Topic: "names"},
+		{Name: "all", ExcludePredicates: []string{"password"}},
+	}
+	event := func(ns uint64, e interface{}) CDCEvent {
+		return CDCEvent{Meta: &EventMeta{Namespace: ns}, Event: e}
+	}
+
+	require.Equal(t, []string{defaultEventTopic}, eventTopics(nil,
+		event(0, &MutationEvent{Attr: "password"})))
+	require.Equal(t, []string{"cdc.1.name", "names", defaultEventTopic}, eventTopics(subs,
+		event(1, &MutationEvent{Attr: "name"})))
+	require.Equal(t, []string{"cdc.1._http___a_b_", "names", defaultEventTopic}, eventTopics(subs,
+		event(1, &MutationEvent{Attr: "<http://a/b>"})))
+	require.Equal(t, []string(nil), eventTopics(subs, event(2, &MutationEvent{Attr: "password"})))
+	require.Equal(t, []string{defaultEventTopic}, eventTopics(subs,
+		event(2, &SchemaEvent{Operation: OpSchemaType, Type: "Person"})))
+	require.Equal(t, []string{"cdc.1.", "names", defaultEventTopic}, eventTopics(subs,
+		event(1, &DropEvent{Operation: "data"})))
+
+	require.NoError(t, validateCDCSubscriptions(subs))
+	require.Error(t, validateCDCSubscriptions([]*CDCSubscription{{Name: "a", Topic: "{pred}"}}))
+	require.Error(t, validateCDCSubscriptions([]*CDCSubscription{{Name: "a"}, {Name: "a"}}))
+
+	// The subscriptions are only replaced by newer ones.
+	setCDCSubscriptions(subs, 10)
+	require.Equal(t, subs, GetCDCSubscriptions())
+	setCDCSubscriptions(nil, 5)
+	require.Equal(t, subs, GetCDCSubscriptions())
+	setCDCSubscriptions(nil, 20)
+	require.Empty(t, GetCDCSubscriptions())
+
+	// The events are sent to each topic they match with Kafka, and once with the other sinks.
+	mutation := func() []CDCEvent {
+		return []CDCEvent{event(1, &MutationEvent{Attr: "name"})}
+	}
+	kafka := &CDC{sink: &kafkaSinkClient{}}
+	msgs := kafka.sinkMessages(subs, mutation(), 30)
+	require.Len(t, msgs, 3)
+	require.Equal(t, "cdc.1.name", msgs[0].Meta.Topic)
+	require.JSONEq(t, `{"meta":{"namespace":1,"commit_ts":30},"type":"",`+
+		`"event":{"operation":"","uid":0,"attr":"name","value":null,"value_type":""}}`,
+		string(msgs[0].Value))
+	file := &CDC{sink: &fileSink{}}
+	msgs = file.sinkMessages(subs, mutation(), 30)
+	require.Len(t, msgs, 1)
+	require.JSONEq(t, `{"meta":{"namespace":1,"commit_ts":30,`+
+		`"topics":["cdc.1.name","names","dgraph-cdc"]},"type":"",`+
+		`"event":{"operation":"","uid":0,"attr":"name","value":null,"value_type":""}}`,
+		string(msgs[0].Value))
+	msgs = file.sinkMessages(nil, mutation(), 30)
+	require.Len(t, msgs, 1)
+	require.Equal(t, defaultEventTopic, msgs[0].Meta.Topic)
+	require.JSONEq(t, `{"meta":{"namespace":1,"commit_ts":30},"type":"",`+
+		`"event":{"operation":"","uid":0,"attr":"name","value":null,"value_type":""}}`,
+		string(msgs[0].Value))
+}
+
+func TestReadBeforeImage(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(`
+		cdc_name: string .
+		cdc_friend: [uid] .
+	`), 1))
+	name := x.GalaxyAttr("cdc_name")
+	friend := x.GalaxyAttr("cdc_friend")
+
+	addEdge(t, &pb.DirectedEdge{
+		Entity:    1,
+		Attr:      name,
+		Value:     []byte("alice"),
+		ValueType: pb.Posting_STRING,
+	}, getOrCreate(x.DataKey(name, 1)))
+	addEdge(t, &pb.DirectedEdge{Entity: 1, Attr: friend, ValueId: 2},
+		getOrCreate(x.DataKey(friend, 1)))
+	startTs := timestamp()
+
+	// The txn which started at startTs sees the commits before it.
+	before, err := readBeforeImage(&pb.DirectedEdge{Entity: 1, Attr: name}, startTs)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"alice"}, before)
+	before, err = readBeforeImage(&pb.DirectedEdge{Entity: 1, Attr: friend}, startTs)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, before)
+	before, err = readBeforeImage(&pb.DirectedEdge{Entity: 3, Attr: friend}, startTs)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{}, before)
+
+	// The commits after startTs aren't seen.
+	addEdge(t, &pb.DirectedEdge{
+		Entity:    3,
+		Attr:      name,
+		Value:     []byte("bob"),
+		ValueType: pb.Posting_STRING,
+	}, getOrCreate(x.DataKey(name, 3)))
+	before, err = readBeforeImage(&pb.DirectedEdge{Entity: 3, Attr: name}, startTs)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{}, before)
+
+	// Once a snapshot is taken after startTs, the versions it reads can be discarded.
+	atomic.StoreUint64(&snapshotReadTs, startTs)
+	defer atomic.StoreUint64(&snapshotReadTs, 0)
+	_, err = readBeforeImage(&pb.DirectedEdge{Entity: 1, Attr: name}, startTs)
+	require.Error(t, err)
+	before, err = readBeforeImage(&pb.DirectedEdge{Entity: 1, Attr: name}, startTs+1)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"alice"}, before)
+}
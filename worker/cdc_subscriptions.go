@@ -0,0 +1,367 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	badgerpb "github.com/dgraph-io/badger/v3/pb"
+	"github.com/dgraph-io/ristretto/z"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/codec"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// defaultEventTopic is the topic the CDC events are sent to when no subscription is set.
+	defaultEventTopic = "dgraph-cdc"
+	// cdcSubscriptionsPred is the reserved predicate the CDC subscriptions are stored in, in JSON.
+	// Like all the reserved predicates, it's served by group 1, whose updates are streamed to all
+	// the alphas.
+	cdcSubscriptionsPred = "dgraph.cdc.subscriptions"
+)
+
+// CDCSubscription routes the CDC events of some namespaces, predicates and types to a topic. Once
+// subscriptions are set, the events are only sent to the topics of the subscriptions they match.
+type CDCSubscription struct {
+	Name string `json:"name"`
+	// Namespaces are the namespaces whose events are sent. If it's empty, all of them are.
+	Namespaces []uint64 `json:"namespaces,omitempty"`
+	// IncludePredicates are the predicates whose events are sent. If it's empty, all of them are,
+	// except for ExcludePredicates.
+	IncludePredicates []string `json:"includePredicates,omitempty"`
+	ExcludePredicates []string `json:"excludePredicates,omitempty"`
+	// Types restricts the events to the ones of the predicates of the types, and of the types.
+	Types []string `json:"types,omitempty"`
+	// Topic is the template of the topic the events are sent to. {namespace}, {predicate} and
+	// {type} are replaced with the ones of each event, or with nothing for the events which don't
+	// have one. The topic is dgraph-cdc if it's not set.
+	Topic string `json:"topic,omitempty"`
+}
+
+var (
+	cdcSubscriptions struct {
+		sync.RWMutex
+		subs []*CDCSubscription
+		// version is the commit ts of the subscriptions, they're only replaced by newer ones.
+		version uint64
+		// loaded is set once the stored subscriptions were read. Group 1 doesn't send its
+		// events before, see cdcSubscriptionsReady.
+		loaded bool
+	}
+
+	topicPlaceholderRe = regexp.MustCompile(`{[^{}]*}`)
+	// invalidTopicCharRe matches the characters which can't be used in the name of a Kafka topic.
+	invalidTopicCharRe = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+// UpdateCDCSubscriptions replaces the CDC subscriptions with subs. If subs is empty, all the
+// events are sent to the default topic. The subscriptions are stored in group 1, so that the
+// leaders of all the groups route the events with them, and keep them after restarts.
+func UpdateCDCSubscriptions(ctx context.Context, subs []*CDCSubscription) error {
+	if err := validateCDCSubscriptions(subs); err != nil {
+		return err
+	}
+	commitTs, err := storeCDCSubscriptions(ctx, subs)
+	if err != nil {
+		return errors.Wrap(err, "unable to store the CDC subscriptions")
+	}
+	// The update is streamed to this alpha too, it's only set now so that it's already
+	// returned by GetCDCSubscriptions.
+	setCDCSubscriptions(subs, commitTs)
+	return nil
+}
+
+func validateCDCSubscriptions(subs []*CDCSubscription) error {
+	names := make(map[string]struct{}, len(subs))
+	for _, sub := range subs {
+		if sub.Name == "" {
+			return errors.New("CDC subscriptions must have a name")
+		}
+		if _, ok := names[sub.Name]; ok {
+			return errors.Errorf("there are several CDC subscriptions named %s", sub.Name)
+		}
+		names[sub.Name] = struct{}{}
+		for _, placeholder := range topicPlaceholderRe.FindAllString(sub.Topic, -1) {
+			switch placeholder {
+			case "{namespace}", "{predicate}", "{type}":
+			default:
+				return errors.Errorf("CDC subscription %s: unknown placeholder %s in the topic. "+
+					"Valid placeholders are {namespace}, {predicate} and {type}",
+					sub.Name, placeholder)
+			}
+		}
+	}
+	return nil
+}
+
+// setCDCSubscriptions sets the subscriptions of this alpha to subs, unless the ones set are newer.
+func setCDCSubscriptions(subs []*CDCSubscription, version uint64) {
+	cdcSubscriptions.Lock()
+	defer cdcSubscriptions.Unlock()
+	if version < cdcSubscriptions.version {
+		return
+	}
+	cdcSubscriptions.subs = subs
+	cdcSubscriptions.version = version
+}
+
+// GetCDCSubscriptions returns the CDC subscriptions of this alpha.
+func GetCDCSubscriptions() []*CDCSubscription {
+	cdcSubscriptions.RLock()
+	defer cdcSubscriptions.RUnlock()
+	return cdcSubscriptions.subs
+}
+
+// cdcSubscriptionsReady returns whether the leader of the group of this alpha can send its
+// events. Group 1 serves the stored subscriptions, so it only sends its events once it read them,
+// which doesn't take a network round trip. The other groups don't wait for group 1 though: till
+// the stored subscriptions are read, their events are routed with the ones of the subscriptions
+// file, if any.
+func cdcSubscriptionsReady() bool {
+	if groups().groupId() != 1 {
+		return true
+	}
+	cdcSubscriptions.RLock()
+	defer cdcSubscriptions.RUnlock()
+	return cdcSubscriptions.loaded
+}
+
+// loadCDCSubscriptions sets the CDC subscriptions from the JSON array in the file at path. They're
+// replaced by the ones stored with UpdateCDCSubscriptions, if any.
+func loadCDCSubscriptions(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read the CDC subscriptions")
+	}
+	var subs []*CDCSubscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return errors.Wrap(err, "unable to parse the CDC subscriptions")
+	}
+	if err := validateCDCSubscriptions(subs); err != nil {
+		return err
+	}
+	setCDCSubscriptions(subs, 0)
+	return nil
+}
+
+// storeCDCSubscriptions stores subs in the CDC subscriptions node, and returns the commit ts.
+func storeCDCSubscriptions(ctx context.Context, subs []*CDCSubscription) (uint64, error) {
+	val, err := json.Marshal(subs)
+	if err != nil {
+		return 0, err
+	}
+	startTs := State.GetTimestamp(false)
+	uids, err := cdcSubscriptionsNodes(ctx, startTs)
+	if err != nil {
+		return 0, err
+	}
+
+	attr := x.GalaxyAttr(cdcSubscriptionsPred)
+	m := &pb.Mutations{StartTs: startTs}
+	var uid uint64
+	if len(uids) == 0 {
+		res, err := AssignUidsOverNetwork(ctx, &pb.Num{Val: 1, Type: pb.Num_UID})
+		if err != nil {
+			return 0, err
+		}
+		uid = res.StartId
+	} else {
+		// There can only be several nodes if they were created concurrently, the last one is
+		// kept.
+		uid = uids[len(uids)-1]
+		for _, other := range uids[:len(uids)-1] {
+			m.Edges = append(m.Edges, &pb.DirectedEdge{
+				Entity: other,
+				Attr:   attr,
+				Value:  []byte(x.Star),
+				Op:     pb.DirectedEdge_DEL,
+			})
+		}
+	}
+	m.Edges = append(m.Edges, &pb.DirectedEdge{
+		Entity:    uid,
+		Attr:      attr,
+		Value:     val,
+		ValueType: pb.Posting_STRING,
+		Op:        pb.DirectedEdge_SET,
+	})
+	tctx, err := MutateOverNetwork(ctx, m)
+	if err != nil {
+		return 0, err
+	}
+	return CommitOverNetwork(ctx, tctx)
+}
+
+// cdcSubscriptionsNodes returns the uids of the CDC subscriptions nodes, in ascending order.
+func cdcSubscriptionsNodes(ctx context.Context, readTs uint64) ([]uint64, error) {
+	res, err := ProcessTaskOverNetwork(ctx, &pb.Query{
+		Attr:    x.GalaxyAttr(cdcSubscriptionsPred),
+		SrcFunc: &pb.SrcFunction{Name: "has"},
+		ReadTs:  readTs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.GetUidMatrix()) == 0 {
+		return nil, nil
+	}
+	return codec.GetUids(res.UidMatrix[0]), nil
+}
+
+// readCDCSubscriptions reads the stored CDC subscriptions, and sets them if there are any.
+func readCDCSubscriptions(ctx context.Context) error {
+	readTs := State.GetTimestamp(true)
+	uids, err := cdcSubscriptionsNodes(ctx, readTs)
+	if err != nil || len(uids) == 0 {
+		return err
+	}
+	res, err := ProcessTaskOverNetwork(ctx, &pb.Query{
+		Attr:    x.GalaxyAttr(cdcSubscriptionsPred),
+		UidList: &pb.List{SortedUids: uids[len(uids)-1:]},
+		ReadTs:  readTs,
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.GetValueMatrix()) == 0 || len(res.ValueMatrix[0].GetValues()) == 0 {
+		return nil
+	}
+	var subs []*CDCSubscription
+	if err := json.Unmarshal(res.ValueMatrix[0].Values[0].Val, &subs); err != nil {
+		return errors.Wrap(err, "unable to parse the CDC subscriptions")
+	}
+	setCDCSubscriptions(subs, readTs)
+	return nil
+}
+
+// watchCDCSubscriptions keeps the CDC subscriptions of this alpha up to date with the stored ones,
+// until closer is closed.
+func watchCDCSubscriptions(closer *z.Closer) {
+	prefix := x.PredicatePrefix(x.GalaxyAttr(cdcSubscriptionsPred))
+	closer.AddRunning(1)
+	go SubscribeForUpdates([][]byte{prefix}, x.IgnoreBytes, func(kvs *badgerpb.KVList) {
+		// The subscriptions of the nodes which aren't kept are deleted in the same commit, so
+		// the latest ones are the ones set.
+		var set *pb.Posting
+		var version uint64
+		for _, kv := range kvs.GetKv() {
+			pl := &pb.PostingList{}
+			if err := pl.Unmarshal(kv.GetValue()); err != nil {
+				glog.Errorf("CDC: unable to unmarshal the subscriptions: %v", err)
+				continue
+			}
+			if len(pl.Postings) == 1 && pl.Postings[0].Op != posting.Del &&
+				kv.GetVersion() >= version {
+				set, version = pl.Postings[0], kv.GetVersion()
+			}
+		}
+		if set == nil {
+			return
+		}
+		var subs []*CDCSubscription
+		if err := json.Unmarshal(set.Value, &subs); err != nil {
+			glog.Errorf("CDC: unable to parse the subscriptions: %v", err)
+			return
+		}
+		glog.Infof("CDC: updating the subscriptions from group 1")
+		setCDCSubscriptions(subs, version)
+	}, 1, closer)
+
+	// The updates are only streamed once they're committed, so the subscriptions stored before
+	// are read once group 1 can be reached.
+	for {
+		err := readCDCSubscriptions(closer.Ctx())
+		if err == nil {
+			break
+		}
+		glog.Warningf("CDC: unable to read the subscriptions, retrying: %v", err)
+		select {
+		case <-closer.HasBeenClosed():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+	cdcSubscriptions.Lock()
+	cdcSubscriptions.loaded = true
+	cdcSubscriptions.Unlock()
+}
+
+// topicFor returns the topic of the subscription for an event about the predicate or the type in
+// the namespace.
+func (sub *CDCSubscription) topicFor(ns uint64, pred, typ string) string {
+	if sub.Topic == "" {
+		return defaultEventTopic
+	}
+	return strings.NewReplacer(
+		"{namespace}", strconv.FormatUint(ns, 10),
+		"{predicate}", invalidTopicCharRe.ReplaceAllString(pred, "_"),
+		"{type}", invalidTopicCharRe.ReplaceAllString(typ, "_"),
+	).Replace(sub.Topic)
+}
+
+func (sub *CDCSubscription) hasNamespace(ns uint64) bool {
+	if len(sub.Namespaces) == 0 {
+		return true
+	}
+	for _, n := range sub.Namespaces {
+		if n == ns {
+			return true
+		}
+	}
+	return false
+}
+
+func (sub *CDCSubscription) hasPredicate(pred string) bool {
+	for _, p := range sub.ExcludePredicates {
+		if p == pred {
+			return false
+		}
+	}
+	if len(sub.IncludePredicates) == 0 {
+		return true
+	}
+	for _, p := range sub.IncludePredicates {
+		if p == pred {
+			return true
+		}
+	}
+	return false
+}
+
+func (sub *CDCSubscription) hasType(typ string) bool {
+	if len(sub.Types) == 0 {
+		return true
+	}
+	for _, t := range sub.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
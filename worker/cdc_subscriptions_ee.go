@@ -0,0 +1,99 @@
+// +build !oss
+
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package worker
+
+import (
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// eventTopics returns the topics the event is sent to: the ones of the subscriptions it matches,
+// or the default topic if there's no subscription.
+func eventTopics(subs []*CDCSubscription, e CDCEvent) []string {
+	if len(subs) == 0 {
+		return []string{defaultEventTopic}
+	}
+
+	var pred, typ string
+	switch event := e.Event.(type) {
+	case *MutationEvent:
+		pred = event.Attr
+	case *SchemaEvent:
+		pred, typ = event.Pred, event.Type
+	case *DropEvent:
+		pred, typ = event.Pred, event.Type
+	}
+
+	var topics []string
+	seen := make(map[string]struct{})
+	for _, sub := range subs {
+		eventType, ok := sub.match(e.Meta.Namespace, pred, typ)
+		if !ok {
+			continue
+		}
+		topic := sub.topicFor(e.Meta.Namespace, pred, eventType)
+		if _, ok := seen[topic]; !ok {
+			seen[topic] = struct{}{}
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// match returns whether the subscription gets the event about the predicate pred or the type typ
+// in the namespace ns. The events which are about neither, like dropping all the data, only need
+// to be in the namespaces of the subscription. It also returns the type of the event, which is the
+// first type of the subscription the predicate is in, if it's about a predicate.
+func (sub *CDCSubscription) match(ns uint64, pred, typ string) (string, bool) {
+	if !sub.hasNamespace(ns) {
+		return "", false
+	}
+	switch {
+	case pred != "":
+		if !sub.hasPredicate(pred) {
+			return "", false
+		}
+		if len(sub.Types) == 0 {
+			return "", true
+		}
+		for _, t := range sub.Types {
+			if typeHasPredicate(ns, t, pred) {
+				return t, true
+			}
+		}
+		return "", false
+	case typ != "":
+		// The subscriptions restricted to some predicates only get the events of the types
+		// they list.
+		if len(sub.Types) == 0 {
+			return typ, len(sub.IncludePredicates) == 0
+		}
+		return typ, sub.hasType(typ)
+	default:
+		return "", true
+	}
+}
+
+// typeHasPredicate returns whether pred is a field of the type typ in the namespace ns.
+func typeHasPredicate(ns uint64, typ, pred string) bool {
+	update, ok := schema.State().GetType(x.NamespaceAttr(ns, typ))
+	if !ok {
+		return false
+	}
+	for _, field := range update.Fields {
+		if x.ParseAttr(field.Predicate) == pred {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"sync"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+// CommitWatcher collects the predicates written by the txns committed in the cluster, once this
+// Alpha can serve reads which see them. Zero sends the predicates along with the commits to
+// all the groups, so every Alpha sees all of them, including the ones for predicates served by
+// other groups.
+type CommitWatcher struct {
+	sync.Mutex
+	preds map[string]struct{}
+	ch    chan struct{}
+}
+
+var commitWatchers = struct {
+	sync.RWMutex
+	m map[*CommitWatcher]struct{}
+}{m: make(map[*CommitWatcher]struct{})}
+
+// WatchCommits returns a new CommitWatcher. It must be stopped once it's not needed anymore.
+func WatchCommits() *CommitWatcher {
+	w := &CommitWatcher{
+		preds: make(map[string]struct{}),
+		ch:    make(chan struct{}, 1),
+	}
+	commitWatchers.Lock()
+	commitWatchers.m[w] = struct{}{}
+	commitWatchers.Unlock()
+	return w
+}
+
+// Stop stops collecting predicates for w.
+func (w *CommitWatcher) Stop() {
+	commitWatchers.Lock()
+	delete(commitWatchers.m, w)
+	commitWatchers.Unlock()
+}
+
+// C returns a channel which is notified when predicates were collected. Notifications are
+// coalesced, so Take must be called to get all the predicates collected since the last call.
+func (w *CommitWatcher) C() <-chan struct{} {
+	return w.ch
+}
+
+// Take returns the namespaced predicates collected since the last call, and forgets them.
+func (w *CommitWatcher) Take() map[string]struct{} {
+	w.Lock()
+	defer w.Unlock()
+	preds := w.preds
+	w.preds = make(map[string]struct{})
+	return preds
+}
+
+func (w *CommitWatcher) add(preds []string) {
+	w.Lock()
+	for _, pred := range preds {
+		w.preds[pred] = struct{}{}
+	}
+	w.Unlock()
+	select {
+	case w.ch <- struct{}{}:
+	default:
+	}
+}
+
+// notifyCommits passes the predicates written by the committed txns in delta to the watchers.
+// It must be called after the delta was applied, so that the commits can be read.
+func notifyCommits(delta *pb.OracleDelta) {
+	var preds []string
+	for _, status := range delta.Txns {
+		if status.CommitTs > 0 {
+			preds = append(preds, status.Preds...)
+		}
+	}
+	if len(preds) == 0 {
+		return
+	}
+
+	commitWatchers.RLock()
+	defer commitWatchers.RUnlock()
+	for w := range commitWatchers.m {
+		w.add(preds)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+func TestCommitWatcher(t *testing.T) {
+	w := WatchCommits()
+	defer w.Stop()
+
+	notifyCommits(&pb.OracleDelta{Txns: []*pb.TxnStatus{
+		{StartTs: 1, CommitTs: 2, Preds: []string{"name", "age"}},
+		{StartTs: 3, CommitTs: 0, Preds: []string{"aborted"}},
+	}})
+	notifyCommits(&pb.OracleDelta{Txns: []*pb.TxnStatus{
+		{StartTs: 4, CommitTs: 5, Preds: []string{"friend"}},
+	}})
+
+	// The notifications are coalesced.
+	<-w.C()
+	select {
+	case <-w.C():
+		t.Fatal("expected a single notification")
+	default:
+	}
+	require.Equal(t, map[string]struct{}{"name": {}, "age": {}, "friend": {}}, w.Take())
+	require.Empty(t, w.Take())
+
+	w.Stop()
+	notifyCommits(&pb.OracleDelta{Txns: []*pb.TxnStatus{
+		{StartTs: 6, CommitTs: 7, Preds: []string{"name"}},
+	}})
+	require.Empty(t, w.Take())
+}
@@ -62,6 +62,12 @@ type Options struct {
 
 	// Define different ChangeDataCapture configurations
 	ChangeDataConf string
+
+	// StatsInterval is how often the statistics of the predicates are collected. Zero disables
+	// the collection.
+	StatsInterval time.Duration
+	// StatsTopTokens is the number of tokens with the longest posting lists kept per index.
+	StatsTopTokens int
 }
 
 // Config holds an instance of the server options..
@@ -368,7 +368,7 @@ func newNode(store *raftwal.DiskStorage, gid uint32, id uint64, myAddr string) *
 		concApplyCh:  make(chan *pb.Proposal, 100),
 		drainApplyCh: make(chan struct{}),
 		elog:         trace.NewEventLog("Dgraph", "ApplyCh"),
-		closer:       z.NewCloser(4), // Matches CLOSER:1
+		closer:       z.NewCloser(5), // Matches CLOSER:1
 		ops:          make(map[op]operation),
 		cdcTracker:   newCDC(),
 		keysWritten:  newKeysWritten(),
@@ -408,7 +408,17 @@ var errHasPendingTxns = errors.New("Pending transactions found. Please retry ope
 // transactions. We're now applying all updates serially, so blocking for one
 // operation is not an option.
 func detectPendingTxns(attr string) error {
-	tctxs := posting.Oracle().IterateTxns(func(key []byte) bool {
+	tctxs := pendingTxns(attr)
+	if len(tctxs) == 0 {
+		return nil
+	}
+	go tryAbortTransactions(tctxs)
+	return errHasPendingTxns
+}
+
+// pendingTxns returns the start timestamps of the pending txns which have written to attr.
+func pendingTxns(attr string) []uint64 {
+	return posting.Oracle().IterateTxns(func(key []byte) bool {
 		pk, err := x.Parse(key)
 		if err != nil {
 			glog.Errorf("error %v while parsing key %v", err, hex.EncodeToString(key))
@@ -416,11 +426,6 @@ func detectPendingTxns(attr string) error {
 		}
 		return pk.Attr == attr
 	})
-	if len(tctxs) == 0 {
-		return nil
-	}
-	go tryAbortTransactions(tctxs)
-	return errHasPendingTxns
 }
 
 func (n *node) mutationWorker(workerId int) {
@@ -489,6 +494,13 @@ func (n *node) concMutations(ctx context.Context, m *pb.Mutations, txn *posting.
 	// check if everything that we read is still valid, or was it changed. If
 	// it was indeed changed, we can re-do the work.
 
+	// Composite index entries depend on more than one edge, so they are read before the edges
+	// are applied and updated once all of them have been.
+	composites, err := txn.NewCompositeIndexUpdate(ctx, m.Edges)
+	if err != nil {
+		return err
+	}
+
 	process := func(edges []*pb.DirectedEdge) error {
 		var retries int
 		for _, edge := range edges {
@@ -512,8 +524,11 @@ func (n *node) concMutations(ctx context.Context, m *pb.Mutations, txn *posting.
 	span.Annotatef(nil, "To apply: %d edges. NumGo: %d. Width: %d", len(m.Edges), numGo, width)
 
 	if numGo == 1 {
+		if err := process(m.Edges); err != nil {
+			return err
+		}
 		span.Annotate(nil, "Process mutations done.")
-		return process(m.Edges)
+		return composites.Done(ctx)
 	}
 	errCh := make(chan error, numGo)
 	for i := 0; i < numGo; i++ {
@@ -533,7 +548,10 @@ func (n *node) concMutations(ctx context.Context, m *pb.Mutations, txn *posting.
 		}
 	}
 	span.Annotate(nil, "Process mutations done.")
-	return rerr
+	if rerr != nil {
+		return rerr
+	}
+	return composites.Done(ctx)
 }
 
 // We don't support schema mutations across nodes in a transaction.
@@ -558,6 +576,7 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 		if err := posting.DeleteData(ns); err != nil {
 			return err
 		}
+		localStats.reset()
 
 		// TODO: Revisit this when we work on posting cache. Clear entire cache.
 		// We don't want to drop entire cache, just due to one namespace.
@@ -579,6 +598,7 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 		if err := posting.DeleteAll(); err != nil {
 			return err
 		}
+		localStats.reset()
 
 		// Clear entire cache.
 		posting.ResetCache()
@@ -632,6 +652,9 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 		if err := runSchemaMutation(ctx, proposal.Mutations.Schema, startTs); err != nil {
 			return err
 		}
+		for _, supdate := range proposal.Mutations.Schema {
+			localStats.markChanged(supdate.Predicate)
+		}
 
 		// Clear the entire cache if there is a schema update because the index rebuild
 		// will invalidate the state.
@@ -656,6 +679,8 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 
 	// Stores a map of predicate and type of first mutation for each predicate.
 	schemaMap := make(map[string]types.TypeID)
+	// The statistics of the modified predicates have to be collected again.
+	modified := make(map[string]struct{})
 	for _, edge := range proposal.Mutations.Edges {
 		if edge.Entity == 0 && bytes.Equal(edge.Value, []byte(x.Star)) {
 			// We should only drop the predicate if there is no pending
@@ -668,6 +693,7 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 			n.keysWritten.rejectBeforeIndex = proposal.Index
 			return posting.DeletePredicate(ctx, edge.Attr, proposal.StartTs)
 		}
+		modified[edge.Attr] = struct{}{}
 		// Don't derive schema when doing deletion.
 		if edge.Op == pb.DirectedEdge_DEL {
 			continue
@@ -676,6 +702,9 @@ func (n *node) applyMutations(ctx context.Context, proposal *pb.Proposal) (rerr
 			schemaMap[edge.Attr] = posting.TypeID(edge)
 		}
 	}
+	for attr := range modified {
+		localStats.markChanged(attr)
+	}
 
 	total := len(proposal.Mutations.Edges)
 
@@ -817,6 +846,7 @@ func (n *node) applyCommitted(proposal *pb.Proposal) error {
 		atomic.StoreInt64(&lastSnapshotTime, time.Now().Unix())
 		// We can now discard all invalid versions of keys below this ts.
 		pstore.SetDiscardTs(snap.ReadTs)
+		atomic.StoreUint64(&snapshotReadTs, snap.ReadTs)
 		return nil
 	case proposal.Restore != nil:
 		// Enable draining mode for the duration of the restore processing.
@@ -1173,6 +1203,7 @@ func (n *node) commitOrAbort(_ uint64, delta *pb.OracleDelta) error {
 	// Now advance Oracle(), so we can service waiting reads.
 	posting.Oracle().ProcessDelta(delta)
 	span.Annotate(nil, "process delta done")
+	notifyCommits(delta)
 	return nil
 }
 
@@ -1344,6 +1375,7 @@ func (n *node) updateRaftProgress() error {
 		return err
 	}
 	atomic.StoreUint64(&n.checkpointTs, snap.ReadTs)
+	atomic.StoreUint64(&snapshotReadTs, snap.ReadTs)
 
 	n.Store.SetUint(raftwal.CheckpointIndex, snap.GetIndex())
 	glog.V(2).Infof("[%#x] Set Raft checkpoint to index: %d, ts: %d.",
@@ -1353,6 +1385,10 @@ func (n *node) updateRaftProgress() error {
 
 var lastSnapshotTime int64 = time.Now().Unix()
 
+// snapshotReadTs is the ReadTs of the last snapshot of this node. The versions of the keys below
+// it can be discarded, so they can't be read reliably anymore.
+var snapshotReadTs uint64
+
 func (n *node) checkpointAndClose(done chan struct{}) {
 	snapshotAfterEntries := x.WorkerConfig.Raft.GetUint64("snapshot-after-entries")
 	x.AssertTruef(snapshotAfterEntries > 10, "raft.snapshot-after must be a number greater than 10")
@@ -2176,6 +2212,10 @@ func (n *node) InitAndStartNode() {
 			// zero-member Raft group.
 			n.SetConfState(&sp.Metadata.ConfState)
 
+			var snap pb.Snapshot
+			x.Check(snap.Unmarshal(sp.Data))
+			atomic.StoreUint64(&snapshotReadTs, snap.ReadTs)
+
 			// TODO: Making connections here seems unnecessary, evaluate.
 			members := groups().members(n.gid)
 			for _, id := range sp.Metadata.ConfState.Nodes {
@@ -2213,6 +2253,7 @@ func (n *node) InitAndStartNode() {
 		}
 	}
 	go n.processTabletSizes()
+	go n.processStats()
 	go n.processApplyCh()
 	go n.BatchAndSendMessages()
 	go n.monitorRaftMetrics()
@@ -28,6 +28,7 @@ import (
 	"math"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -44,6 +45,7 @@ import (
 	"github.com/dgraph-io/dgraph/ee/enc"
 	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/types/facets"
 	"github.com/dgraph-io/dgraph/x"
@@ -56,6 +58,9 @@ type exportFormat struct {
 	ext  string // file extension
 	pre  string // string to write before exported records
 	post string // string to write after exported records
+	// typed formats are shaped by the GraphQL schema, and are exported with TypedExport into a
+	// file per GraphQL type.
+	typed bool
 }
 
 var exportFormats = map[string]exportFormat{
@@ -69,8 +74,25 @@ var exportFormats = map[string]exportFormat{
 		pre:  "",
 		post: "",
 	},
+	"graphql": {
+		ext:   ".json",
+		pre:   "[\n",
+		post:  "\n]\n",
+		typed: true,
+	},
+	"csv": {
+		ext:   ".csv",
+		typed: true,
+	},
 }
 
+// TypedExport exports the data of the namespace ns at readTs in the typed format, by writing the
+// objects of each GraphQL type to the writer that create returns for it. The objects of the JSON
+// formats must be separated by commas. It's set by the GraphQL admin server, which can read the
+// GraphQL schemas.
+var TypedExport func(ctx context.Context, ns, readTs uint64, format string,
+	create func(typeName string) (io.Writer, error)) error
+
 type exporter struct {
 	pl        *posting.List
 	uid       uint64
@@ -91,6 +113,7 @@ var rdfTypeMap = map[types.TypeID]string{
 	types.GeoID:      "geo:geojson",
 	types.BinaryID:   "xs:base64Binary",
 	types.PasswordID: "xs:password",
+	types.VFloatID:   "xs:[]float32",
 }
 
 // UIDs like 0x1 look weird but 64-bit ones like 0x0000000000000001 are too long.
@@ -324,6 +347,13 @@ func toSchema(attr string, update *pb.SchemaUpdate) *bpb.KV {
 		x.Check2(buf.WriteString(" @upsert"))
 	}
 	x.Check2(buf.WriteString(" . \n"))
+	for _, composite := range update.GetComposite() {
+		x.Check2(fmt.Fprintf(&buf, "[%#x] index composite(<%s>", ns, attr))
+		for _, pred := range strings.Split(composite, ",") {
+			x.Check2(fmt.Fprintf(&buf, ", <%s>", pred))
+		}
+		x.Check2(buf.WriteString(")\n"))
+	}
 	//TODO(Naman): We don't need the version anymore.
 	return &bpb.KV{
 		Value:   buf.Bytes(),
@@ -445,6 +475,7 @@ func ToExportKvList(pk x.ParsedKey, pl *posting.List, in *pb.ExportRequest) (*bp
 	case e.attr == "dgraph.graphql.xid":
 	case e.attr == "dgraph.drop.op":
 	case e.attr == "dgraph.graphql.p_query":
+	case e.attr == cdcSubscriptionsPred:
 
 	case pk.IsData() && e.attr == "dgraph.graphql.schema":
 		// Export the graphql schema.
@@ -580,6 +611,33 @@ type Writers struct {
 var _ io.Closer = &Writers{}
 
 func NewWriters(req *pb.ExportRequest) (*Writers, error) {
+	handler, dirName, err := createExportDir(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create writers for each export file.
+	writers := &Writers{}
+	newWriter := func(ext string) (*ExportWriter, error) {
+		fileName := filepath.Join(dirName, fmt.Sprintf("g%02d%s", req.GroupId, ext))
+		return newExportWriter(handler, fileName)
+	}
+	if writers.DataWriter, err = newWriter(exportFormats[req.Format].ext + ".gz"); err != nil {
+		return writers, err
+	}
+	if writers.SchemaWriter, err = newWriter(".schema.gz"); err != nil {
+		return writers, err
+	}
+	if writers.GqlSchemaWriter, err = newWriter(".gql_schema.gz"); err != nil {
+		return writers, err
+	}
+
+	return writers, nil
+}
+
+// createExportDir creates the directory of the export at its destination, and returns the
+// handler of the destination along with the name of the directory.
+func createExportDir(req *pb.ExportRequest) (x.UriHandler, string, error) {
 	// Create a UriHandler for the given destination.
 	destination := req.GetDestination()
 	if destination == "" {
@@ -587,7 +645,7 @@ func NewWriters(req *pb.ExportRequest) (*Writers, error) {
 	}
 	uri, err := url.Parse(destination)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	creds := &x.MinioCredentials{
 		AccessKey:    req.GetAccessKey(),
@@ -597,38 +655,21 @@ func NewWriters(req *pb.ExportRequest) (*Writers, error) {
 	}
 	handler, err := x.NewUriHandler(uri, creds)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Create the export directory.
 	if !handler.DirExists(".") {
 		if err := handler.CreateDir("."); err != nil {
-			return nil, errors.Wrap(err, "while creating export directory")
+			return nil, "", errors.Wrap(err, "while creating export directory")
 		}
 	}
 	uts := time.Unix(req.UnixTs, 0).UTC().Format("0102.1504")
 	dirName := fmt.Sprintf("dgraph.r%d.u%s", req.ReadTs, uts)
 	if err := handler.CreateDir(dirName); err != nil {
-		return nil, errors.Wrap(err, "while creating export directory")
-	}
-
-	// Create writers for each export file.
-	writers := &Writers{}
-	newWriter := func(ext string) (*ExportWriter, error) {
-		fileName := filepath.Join(dirName, fmt.Sprintf("g%02d%s", req.GroupId, ext))
-		return newExportWriter(handler, fileName)
-	}
-	if writers.DataWriter, err = newWriter(exportFormats[req.Format].ext + ".gz"); err != nil {
-		return writers, err
+		return nil, "", errors.Wrap(err, "while creating export directory")
 	}
-	if writers.SchemaWriter, err = newWriter(".schema.gz"); err != nil {
-		return writers, err
-	}
-	if writers.GqlSchemaWriter, err = newWriter(".gql_schema.gz"); err != nil {
-		return writers, err
-	}
-
-	return writers, nil
+	return handler, dirName, nil
 }
 
 // Closes the underlying writers.
@@ -653,6 +694,9 @@ func (w *Writers) Close() error {
 // and types.
 func exportInternal(ctx context.Context, in *pb.ExportRequest, db *badger.DB,
 	skipZero bool) (ExportedFiles, error) {
+	if exportFormats[in.Format].typed {
+		return nil, errors.Errorf("cannot export the %s format per group", in.Format)
+	}
 	writers, err := NewWriters(in)
 	defer writers.Close()
 	if err != nil {
@@ -899,6 +943,17 @@ func ExportOverNetwork(ctx context.Context, input *pb.ExportRequest) (ExportedFi
 	readTs := ts.ReadOnly
 	glog.Infof("Got readonly ts from Zero: %d\n", readTs)
 
+	if exportFormats[input.Format].typed {
+		files, err := exportTyped(ctx, input, readTs)
+		if err != nil {
+			rerr := errors.Wrapf(err, "Export failed at readTs %d", readTs)
+			glog.Errorln(rerr)
+			return nil, rerr
+		}
+		glog.Infof("Export at readTs %d DONE", readTs)
+		return files, nil
+	}
+
 	// Let's first collect all groups.
 	gids := groups().KnownGroups()
 	glog.Infof("Requesting export for groups: %v\n", gids)
@@ -943,6 +998,79 @@ func ExportOverNetwork(ctx context.Context, input *pb.ExportRequest) (ExportedFi
 	return allFiles, nil
 }
 
+// exportTyped exports the data at readTs in a typed format. The objects of the GraphQL types have
+// predicates in all the groups, so unlike the other formats, they're exported by this alpha alone.
+func exportTyped(ctx context.Context, input *pb.ExportRequest, readTs uint64) (
+	ExportedFiles, error) {
+	if TypedExport == nil {
+		return nil, errors.Errorf("the %s export format needs the GraphQL server", input.Format)
+	}
+
+	handler, dirName, err := createExportDir(&pb.ExportRequest{
+		ReadTs:       readTs,
+		UnixTs:       time.Now().Unix(),
+		Destination:  input.Destination,
+		AccessKey:    input.AccessKey,
+		SecretKey:    input.SecretKey,
+		SessionToken: input.SessionToken,
+		Anonymous:    input.Anonymous,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := []uint64{input.Namespace}
+	if input.Namespace == math.MaxUint64 {
+		namespaces = namespaces[:0]
+		for ns := range schema.State().Namespaces() {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Slice(namespaces, func(i, j int) bool { return namespaces[i] < namespaces[j] })
+	}
+
+	xfmt := exportFormats[input.Format]
+	var writers []*ExportWriter
+	defer func() {
+		// The writers are only left open by errors.
+		for _, w := range writers {
+			if err := w.Close(); err != nil {
+				glog.Warningf("Error while closing %s: %v", w.relativePath, err)
+			}
+		}
+	}()
+	for _, ns := range namespaces {
+		create := func(typeName string) (io.Writer, error) {
+			fileName := filepath.Join(dirName,
+				fmt.Sprintf("%#x.%s%s.gz", ns, typeName, xfmt.ext))
+			w, err := newExportWriter(handler, fileName)
+			if err != nil {
+				return nil, err
+			}
+			writers = append(writers, w)
+			_, err = w.gw.Write([]byte(xfmt.pre))
+			return w.gw, err
+		}
+		if err := TypedExport(ctx, ns, readTs, input.Format, create); err != nil {
+			return nil, errors.Wrapf(err, "while exporting namespace %#x", ns)
+		}
+	}
+
+	var files ExportedFiles
+	for len(writers) > 0 {
+		w := writers[0]
+		writers = writers[1:]
+		_, err := w.gw.Write([]byte(xfmt.post))
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, w.relativePath)
+	}
+	return files, nil
+}
+
 // NormalizeExportFormat returns the normalized string for the export format if it is valid, an
 // empty string otherwise.
 func NormalizeExportFormat(format string) string {
@@ -432,13 +432,19 @@ func TestExportFormat(t *testing.T) {
 	taskId := testutil.JsonGet(data, "data", "export", "taskId").(string)
 	testutil.WaitForTask(t, taskId, false)
 
-	params.Variables["format"] = "rdf"
-	b, err = json.Marshal(params)
-	require.NoError(t, err)
+	// The files of the typed formats are checked in systest/export.
+	for _, format := range []string{"rdf", "graphql", "csv"} {
+		params.Variables["format"] = format
+		b, err = json.Marshal(params)
+		require.NoError(t, err)
 
-	resp, err = http.Post(adminUrl, "application/json", bytes.NewBuffer(b))
-	require.NoError(t, err)
-	testutil.RequireNoGraphQLErrors(t, resp)
+		resp, err = http.Post(adminUrl, "application/json", bytes.NewBuffer(b))
+		require.NoError(t, err)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+		require.Equal(t, "Success",
+			testutil.JsonGet(data, "data", "export", "response", "code").(string), format)
+		testutil.WaitForTask(t, testutil.JsonGet(data, "data", "export", "taskId").(string), false)
+	}
 
 	params.Variables["format"] = "xml"
 	b, err = json.Marshal(params)
@@ -571,6 +577,21 @@ func TestToSchema(t *testing.T) {
 			},
 			expected: "[0x0] <data.base>:string @lang . \n",
 		},
+		{
+			skv: &skv{
+				attr: x.GalaxyAttr("country"),
+				schema: pb.SchemaUpdate{
+					Predicate: x.GalaxyAttr(""),
+					ValueType: pb.Posting_STRING,
+					Directive: pb.SchemaUpdate_INDEX,
+					Tokenizer: []string{"exact"},
+					Composite: []string{"status,city", "zip"},
+				},
+			},
+			expected: "[0x0] <country>:string @index(exact) . \n" +
+				"[0x0] index composite(<country>, <status>, <city>)\n" +
+				"[0x0] index composite(<country>, <zip>)\n",
+		},
 	}
 	for _, testCase := range testCases {
 		kv := toSchema(testCase.skv.attr, &testCase.skv.schema)
@@ -236,6 +236,13 @@ func (w *grpcWorker) MovePredicate(ctx context.Context,
 		}
 		return &emptyPayload, groups().Node.proposeAndWait(ctx, p)
 	}
+	// The composite indexes are maintained by a single group, which must serve all of their
+	// predicates, so the predicates which are part of one can't be moved.
+	if composites := schema.State().CompositeIndexes(schema.GetWriteContext(ctx),
+		in.Predicate); len(composites) > 0 {
+		return &emptyPayload, errors.Errorf("Predicate %s is part of the composite index %v "+
+			"and can't be moved", x.ParseAttr(in.Predicate), x.ParseAttrList(composites[0]))
+	}
 	if err := posting.Oracle().WaitForTs(ctx, in.ReadTs); err != nil {
 		return &emptyPayload,
 			errors.Errorf("While waiting for read ts: %d. Error: %v", in.ReadTs, err)
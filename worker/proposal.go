@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -204,6 +205,18 @@ func (n *node) proposeAndWait(ctx context.Context, proposal *pb.Proposal) (perr
 			if err := checkSchema(schema); err != nil {
 				return err
 			}
+			// The composite indexes are maintained and read by the group of their owner, so
+			// all of their predicates must be served by it. The ones which aren't served yet
+			// are assigned to it here.
+			ns := x.ParseNamespace(schema.Predicate)
+			for _, composite := range schema.Composite {
+				for _, pred := range strings.Split(composite, ",") {
+					if err := checkTablet(x.NamespaceAttr(ns, pred)); err != nil {
+						return errors.Wrapf(err, "predicate %s of the composite index on %s "+
+							"must be in the same group", pred, x.ParseAttr(schema.Predicate))
+					}
+				}
+			}
 			noTimeout = true
 		}
 	}
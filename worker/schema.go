@@ -19,6 +19,7 @@ package worker
 import (
 	"context"
 
+	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	otrace "go.opencensus.io/trace"
@@ -57,7 +58,7 @@ func getSchema(ctx context.Context, s *pb.SchemaRequest) (*pb.SchemaResult, erro
 		fields = s.Fields
 	} else {
 		fields = []string{"type", "index", "tokenizer", "reverse", "count", "list", "upsert",
-			"lang", "noconflict"}
+			"lang", "noconflict", "composite"}
 	}
 
 	myGid := groups().groupId()
@@ -114,6 +115,10 @@ func populateSchema(attr string, fields []string) *pb.SchemaNode {
 			schemaNode.Lang = pred.GetLang()
 		case "noconflict":
 			schemaNode.NoConflict = pred.GetNoConflict()
+		case "composite":
+			schemaNode.Composite = pred.GetComposite()
+		case "stats":
+			schemaNode.Stats = localStats.get(attr)
 		default:
 			//pass
 		}
@@ -42,15 +42,20 @@ const (
 	BadgerDefaults = `compression=snappy; numgoroutines=8;`
 	CacheDefaults  = `size-mb=1024; percentage=50,30,20;`
 	CDCDefaults    = `file=; kafka=; sasl_user=; sasl_password=; ca_cert=; client_cert=; ` +
-		`client_key=; sasl-mechanism=PLAIN; tls=false;`
-	GraphQLDefaults = `introspection=true; debug=false; extensions=true; poll-interval=1s; `
-	LambdaDefaults  = `url=; num=1; port=20000; restart-after=30s; `
-	LimitDefaults   = `mutations=allow; query-edge=1000000; normalize-node=10000; ` +
+		`client_key=; sasl-mechanism=PLAIN; tls=false; before-image=false; webhook=; ` +
+		`webhook-secret=; webhook-batch=100; webhook-retries=3; grpc=; grpc-buffer=1000; ` +
+		`grpc-token=; subscriptions=;`
+	GraphQLDefaults = `introspection=true; debug=false; extensions=true; poll-interval=1s; ` +
+		`max-depth=0; max-breadth=0; max-cost=0; persisted-queries-only=false; `
+	LambdaDefaults = `url=; num=1; port=20000; restart-after=30s; `
+	LimitDefaults  = `mutations=allow; query-edge=1000000; normalize-node=10000; ` +
 		`mutations-nquad=1000000; disallow-drop=false; query-timeout=0ms; txn-abort-after=5m;` +
-		`max-pending-queries=64;  max-retries=-1; shared-instance=false; max-splits=1000`
+		`max-pending-queries=64;  max-retries=-1; shared-instance=false; max-splits=1000; ` +
+		`query-planner=0`
 	RaftDefaults = `learner=false; snapshot-after-entries=10000; ` +
 		`snapshot-after-duration=30m; pending-proposals=256; idx=; group=;`
 	SecurityDefaults   = `token=; whitelist=;`
+	StatsDefaults      = `interval=1h; top-tokens=10;`
 	ZeroLimitsDefaults = `uid-lease=0; refill-interval=30s; disable-admin-http=false;`
 )
 
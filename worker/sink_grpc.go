@@ -0,0 +1,301 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"crypto/subtle"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/x"
+	"github.com/dgraph-io/ristretto/z"
+)
+
+const (
+	// grpcSinkSendTimeout is how long a consumer has to take a batch of events. Its stream is
+	// closed if it doesn't.
+	grpcSinkSendTimeout = 10 * time.Second
+	// grpcSinkStreamBuffer is the number of batches queued for a consumer. Its stream is closed
+	// once they're full, so that a slow consumer doesn't hold up the other ones and CDC.
+	grpcSinkStreamBuffer = 100
+)
+
+// grpcSink serves the events with the CDC gRPC service to the consumers which connect to it. Each
+// consumer streams the events committed after a timestamp, so that it can resume from the last
+// commit it processed. The last batches sent are kept for that.
+//
+// The consumers must authenticate, with a client certificate signed by ca-cert, or with the
+// grpc-token, which they send in the authorization metadata as "Bearer <token>".
+//
+// Send only succeeds once the events were queued for a consumer. While none is connected, the
+// events stay pending in CDC, and are sent again later. Only the leader of the group sends the
+// events, the streams of the other alphas are ended.
+type grpcSink struct {
+	sync.Mutex
+	lis    net.Listener
+	server *grpc.Server
+	token  []byte
+
+	streams map[*cdcStream]struct{}
+	// sent are the last batches sent, in the order of their commits, and maxSent is how many of
+	// them are kept. droppedTs is the commit ts of the last batch which isn't kept anymore, the
+	// consumers can only resume from it on.
+	sent      []*pb.CDCEventBatch
+	maxSent   int
+	droppedTs uint64
+	// checkpoint returns the commit ts till which CDC sent the events. CDC sets it, as the
+	// events till then were sent before this alpha became the leader of its group, or before
+	// it restarted, so they can't be streamed.
+	checkpoint func() uint64
+}
+
+type cdcStream struct {
+	srv     pb.CDC_StreamServer
+	batches chan *pb.CDCEventBatch
+	// done is closed to end the stream once it fell behind.
+	done chan struct{}
+}
+
+func newGrpcSink(conf *z.SuperFlag) (Sink, error) {
+	maxSent := conf.GetInt64("grpc-buffer")
+	if maxSent < 0 {
+		return nil, errors.New("grpc-buffer can't be negative")
+	}
+	caCert := conf.GetPath("ca-cert")
+	token := conf.GetString("grpc-token")
+	if caCert == "" && token == "" {
+		return nil, errors.New("the gRPC sink requires the consumers to authenticate, " +
+			"set ca-cert to verify their certificates or grpc-token")
+	}
+
+	var opts []grpc.ServerOption
+	cert, key := conf.GetPath("client-cert"), conf.GetPath("client-key")
+	switch {
+	case cert != "" && key != "":
+		// The certificate of Alpha as the client of the other sinks is the one it serves the
+		// consumers with.
+		clientAuth := "VERIFYIFGIVEN"
+		if caCert != "" {
+			clientAuth = "REQUIREANDVERIFY"
+		}
+		tlsCfg, err := x.GenerateServerTLSConfig(&x.TLSHelperConfig{
+			CertRequired: true,
+			Cert:         cert,
+			Key:          key,
+			RootCACert:   caCert,
+			ClientAuth:   clientAuth,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the TLS config of the gRPC sink")
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	case caCert != "":
+		return nil, errors.New("client-cert and client-key are required to verify the " +
+			"certificates of the gRPC sink consumers")
+	default:
+		glog.Warningf("CDC: the gRPC sink is served without TLS, the token and the events " +
+			"are sent in plain text")
+	}
+
+	lis, err := net.Listen("tcp", conf.GetString("grpc"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to listen for the gRPC sink")
+	}
+	sink := &grpcSink{
+		lis:     lis,
+		server:  grpc.NewServer(opts...),
+		token:   []byte(token),
+		streams: make(map[*cdcStream]struct{}),
+		maxSent: int(maxSent),
+	}
+	pb.RegisterCDCServer(sink.server, sink)
+	go func() {
+		if err := sink.server.Serve(lis); err != nil {
+			glog.Errorf("CDC: gRPC sink stopped serving: %v", err)
+		}
+	}()
+	glog.Infof("CDC: serving the events over gRPC at %s", lis.Addr())
+	return sink, nil
+}
+
+func (s *grpcSink) authenticate(srv pb.CDC_StreamServer) error {
+	if len(s.token) == 0 {
+		// The client certificates were verified by TLS.
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(srv.Context())
+	for _, auth := range md.Get("authorization") {
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), s.token) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid or missing token")
+}
+
+// Stream streams the events committed after req.SinceTs to a consumer, until it disconnects. If
+// SinceTs is 0, all the batches still kept are streamed first.
+func (s *grpcSink) Stream(req *pb.CDCStreamRequest, srv pb.CDC_StreamServer) error {
+	if err := s.authenticate(srv); err != nil {
+		return err
+	}
+	stream := &cdcStream{
+		srv:     srv,
+		batches: make(chan *pb.CDCEventBatch, grpcSinkStreamBuffer),
+		done:    make(chan struct{}),
+	}
+
+	s.Lock()
+	if servedTs := s.servedTsLocked(); req.SinceTs > 0 && req.SinceTs < servedTs {
+		s.Unlock()
+		return status.Errorf(codes.OutOfRange, "the events committed after %d aren't kept "+
+			"anymore, the stream can resume from %d on", req.SinceTs, servedTs)
+	}
+	// The stream is added with s locked, so that the batches sent from now on are queued after
+	// the ones kept.
+	var kept []*pb.CDCEventBatch
+	for _, batch := range s.sent {
+		if batch.CommitTs > req.SinceTs {
+			kept = append(kept, batch)
+		}
+	}
+	s.streams[stream] = struct{}{}
+	s.Unlock()
+	defer s.remove(stream)
+
+	for _, batch := range kept {
+		if err := stream.send(batch); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case batch := <-stream.batches:
+			if err := stream.send(batch); err != nil {
+				return err
+			}
+		case <-stream.done:
+			return status.Error(codes.Unavailable, "the stream fell behind or the alpha isn't "+
+				"the leader of its group anymore, it can resume from the last commit received")
+		case <-srv.Context().Done():
+			return srv.Context().Err()
+		}
+	}
+}
+
+// servedTsLocked returns the commit ts from which on the consumers can resume. Until this alpha
+// sent a batch, the events till the checkpoint of CDC can't be streamed.
+func (s *grpcSink) servedTsLocked() uint64 {
+	if len(s.sent) == 0 && s.checkpoint != nil {
+		return x.Max(s.droppedTs, s.checkpoint())
+	}
+	return s.droppedTs
+}
+
+// reset drops the batches kept and ends the streams, once the alpha isn't the leader of its
+// group. The new leader sends the events, so the batches kept would miss them if this alpha
+// became the leader again.
+func (s *grpcSink) reset() {
+	s.Lock()
+	defer s.Unlock()
+	for stream := range s.streams {
+		s.removeLocked(stream)
+	}
+	s.sent, s.droppedTs = nil, 0
+}
+
+// remove removes the stream from s, and ends it. s must not be locked.
+func (s *grpcSink) remove(stream *cdcStream) {
+	s.Lock()
+	defer s.Unlock()
+	s.removeLocked(stream)
+}
+
+func (s *grpcSink) removeLocked(stream *cdcStream) {
+	if _, ok := s.streams[stream]; ok {
+		delete(s.streams, stream)
+		close(stream.done)
+	}
+}
+
+func (cs *cdcStream) send(batch *pb.CDCEventBatch) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- cs.srv.Send(batch)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(grpcSinkSendTimeout):
+		return status.Error(codes.DeadlineExceeded, "timed out while streaming the events")
+	}
+}
+
+func (s *grpcSink) Send(messages []SinkMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	// CDC sends the events of one commit at a time.
+	batch := &pb.CDCEventBatch{CommitTs: messages[0].Meta.CommitTs}
+	for _, m := range messages {
+		batch.Events = append(batch.Events, m.Value)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	// The batch is only queued for each consumer, which streams it on its own.
+	var delivered bool
+	for stream := range s.streams {
+		select {
+		case stream.batches <- batch:
+			delivered = true
+		default:
+			glog.Warningf("CDC: closing the stream of a gRPC consumer which fell behind")
+			s.removeLocked(stream)
+		}
+	}
+	if !delivered {
+		return errors.New("no consumer is connected to the gRPC sink")
+	}
+
+	if len(s.sent) == 0 {
+		s.droppedTs = s.servedTsLocked()
+	}
+	s.sent = append(s.sent, batch)
+	if len(s.sent) > s.maxSent {
+		drop := len(s.sent) - s.maxSent
+		s.droppedTs = s.sent[drop-1].CommitTs
+		s.sent = append(s.sent[:0], s.sent[drop:]...)
+	}
+	return nil
+}
+
+func (s *grpcSink) Close() error {
+	s.server.Stop()
+	return nil
+}
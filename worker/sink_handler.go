@@ -45,6 +45,9 @@ type SinkMessage struct {
 
 type SinkMeta struct {
 	Topic string
+	// CommitTs is the commit timestamp of the events. The messages sent together are all from the
+	// same commit.
+	CommitTs uint64
 }
 
 type Sink interface {
@@ -62,6 +65,10 @@ func GetSink(conf *z.SuperFlag) (Sink, error) {
 	switch {
 	case conf.GetString("kafka") != "":
 		return newKafkaSink(conf)
+	case conf.GetString("webhook") != "":
+		return newWebhookSink(conf)
+	case conf.GetString("grpc") != "":
+		return newGrpcSink(conf)
 	case conf.GetPath("file") != "":
 		return newFileSink(conf)
 	}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/ristretto/z"
+)
+
+func sinkMessages(commitTs uint64, values ...string) []SinkMessage {
+	messages := make([]SinkMessage, 0, len(values))
+	for _, v := range values {
+		messages = append(messages, SinkMessage{
+			Meta:  SinkMeta{Topic: defaultEventTopic, CommitTs: commitTs},
+			Value: []byte(v),
+		})
+	}
+	return messages
+}
+
+func TestWebhookSink(t *testing.T) {
+	webhookFirstBackoff = time.Millisecond
+
+	var mu sync.Mutex
+	var bodies []string
+	var calls, failWith int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if failWith != 0 {
+			w.WriteHeader(failWith)
+			return
+		}
+		// Every other request fails, so that it's retried.
+		if calls%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)),
+			r.Header.Get(webhookSignatureHeader))
+		require.Equal(t, "7", r.Header.Get(webhookCommitTsHeader))
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	sink, err := GetSink(z.NewSuperFlag("webhook=" + server.URL + "; webhook-secret=secret; " +
+		"webhook-batch=2;").MergeAndCheckDefault(CDCDefaults))
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Send(sinkMessages(7, `{"a":1}`, `{"a":2}`, `{"a":3}`)))
+	require.Equal(t, []string{`[{"a":1},{"a":2}]`, `[{"a":3}]`}, bodies)
+	require.Equal(t, 4, calls)
+
+	// The errors of the client aren't retried.
+	mu.Lock()
+	failWith = http.StatusBadRequest
+	mu.Unlock()
+	require.Error(t, sink.Send(sinkMessages(7, `{"a":4}`)))
+	require.Equal(t, 5, calls)
+
+	// The retries stop once the sink is closed.
+	mu.Lock()
+	failWith = http.StatusServiceUnavailable
+	mu.Unlock()
+	webhookFirstBackoff = time.Hour
+	defer func() { webhookFirstBackoff = time.Millisecond }()
+	time.AfterFunc(10*time.Millisecond, func() { require.NoError(t, sink.Close()) })
+	require.Error(t, sink.Send(sinkMessages(7, `{"a":5}`)))
+	require.Equal(t, 6, calls)
+}
+
+func TestGrpcSink(t *testing.T) {
+	// The consumers must authenticate.
+	_, err := GetSink(z.NewSuperFlag("grpc=localhost:0;").MergeAndCheckDefault(CDCDefaults))
+	require.Error(t, err)
+
+	s, err := GetSink(z.NewSuperFlag("grpc=localhost:0; grpc-buffer=2; grpc-token=secret;").
+		MergeAndCheckDefault(CDCDefaults))
+	require.NoError(t, err)
+	defer s.Close()
+	sink := s.(*grpcSink)
+
+	conn, err := grpc.Dial(sink.lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewCDCClient(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	unauthenticated, err := client.Stream(ctx, &pb.CDCStreamRequest{})
+	require.NoError(t, err)
+	_, err = unauthenticated.Recv()
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+
+	numStreams := func() int {
+		sink.Lock()
+		defer sink.Unlock()
+		return len(sink.streams)
+	}
+	connect := func(sinceTs uint64) pb.CDC_StreamClient {
+		stream, err := client.Stream(ctx, &pb.CDCStreamRequest{SinceTs: sinceTs})
+		require.NoError(t, err)
+		return stream
+	}
+
+	// The events till the checkpoint of CDC were sent before the sink started, so they can't
+	// be streamed.
+	sink.checkpoint = func() uint64 { return 9 }
+	_, err = connect(5).Recv()
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+
+	// The events stay pending in CDC until a consumer is connected.
+	require.Error(t, sink.Send(sinkMessages(10, `{"a":1}`)))
+
+	first := connect(0)
+	require.Eventually(t, func() bool { return numStreams() == 1 },
+		5*time.Second, 10*time.Millisecond)
+	for ts := uint64(10); ts <= 12; ts++ {
+		require.NoError(t, sink.Send(sinkMessages(ts, `{"a":1}`, `{"a":2}`)))
+		batch, err := first.Recv()
+		require.NoError(t, err)
+		require.Equal(t, ts, batch.CommitTs)
+		require.Equal(t, [][]byte{[]byte(`{"a":1}`), []byte(`{"a":2}`)}, batch.Events)
+	}
+
+	// Only the last 2 commits are kept for the consumers which resume.
+	_, err = connect(5).Recv()
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+
+	resumed := connect(11)
+	batch, err := resumed.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), batch.CommitTs)
+	require.Eventually(t, func() bool { return numStreams() == 2 },
+		5*time.Second, 10*time.Millisecond)
+
+	// A consumer which doesn't take the events doesn't hold up the other ones, its stream is
+	// closed once it fell behind.
+	value := `{"a":"` + strings.Repeat("x", 1<<10) + `"}`
+	for ts := uint64(13); numStreams() == 2; ts++ {
+		require.Less(t, ts, uint64(10000))
+		require.NoError(t, sink.Send(sinkMessages(ts, value)))
+		batch, err := first.Recv()
+		require.NoError(t, err)
+		require.Equal(t, ts, batch.CommitTs)
+	}
+
+	// Once the alpha isn't the leader anymore, the streams are ended.
+	sink.reset()
+	require.Equal(t, 0, numStreams())
+	for {
+		if _, err = first.Recv(); err != nil {
+			break
+		}
+	}
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/x"
+	"github.com/dgraph-io/ristretto/z"
+)
+
+const (
+	// webhookSignatureHeader has the HMAC-SHA256 of the body, as sha256=<hex digest>, when a
+	// secret is set for the webhook.
+	webhookSignatureHeader = "X-Dgraph-Signature"
+	webhookCommitTsHeader  = "X-Dgraph-Commit-Ts"
+	webhookTimeout         = 10 * time.Second
+)
+
+// webhookFirstBackoff is how long the first retry of a failed request waits. Each retry then
+// waits twice as long as the previous one.
+var webhookFirstBackoff = 500 * time.Millisecond
+
+// webhookSink posts the events to an HTTP endpoint, as JSON arrays of at most batchSize events.
+// The requests which fail are retried with backoff. If the events still can't be delivered, Send
+// fails, and CDC sends them again later, as its checkpoint isn't moved past them. So, the endpoint
+// can get the same events more than once.
+type webhookSink struct {
+	url       string
+	secret    []byte
+	batchSize int
+	retries   int
+	client    *http.Client
+	// The retries stop once closer is signalled. CDC sets it to its own closer, so that it
+	// doesn't wait for them while closing.
+	closer *z.Closer
+}
+
+func newWebhookSink(conf *z.SuperFlag) (Sink, error) {
+	endpoint := conf.GetString("webhook")
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.Errorf("invalid webhook URL %q, it must be an http(s) URL", endpoint)
+	}
+	batchSize := conf.GetInt64("webhook-batch")
+	if batchSize <= 0 {
+		return nil, errors.New("webhook-batch must be greater than 0")
+	}
+	retries := conf.GetInt64("webhook-retries")
+	if retries < 0 {
+		return nil, errors.New("webhook-retries can't be negative")
+	}
+	return &webhookSink{
+		url:       endpoint,
+		secret:    []byte(conf.GetString("webhook-secret")),
+		batchSize: int(batchSize),
+		retries:   int(retries),
+		client:    &http.Client{Timeout: webhookTimeout},
+		closer:    z.NewCloser(0),
+	}, nil
+}
+
+func (w *webhookSink) Send(messages []SinkMessage) error {
+	for len(messages) > 0 {
+		n := len(messages)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		if err := w.post(messages[:n]); err != nil {
+			return err
+		}
+		messages = messages[n:]
+	}
+	return nil
+}
+
+func (w *webhookSink) post(messages []SinkMessage) error {
+	var body bytes.Buffer
+	body.WriteByte('[')
+	for i, m := range messages {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(m.Value)
+	}
+	body.WriteByte(']')
+	var commitTs uint64
+	for _, m := range messages {
+		commitTs = x.Max(commitTs, m.Meta.CommitTs)
+	}
+
+	var err error
+	backoff := webhookFirstBackoff
+RETRIES:
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = w.try(body.Bytes(), commitTs)
+		if err == nil || !retry || attempt == w.retries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-w.closer.HasBeenClosed():
+			break RETRIES
+		}
+	}
+	return errors.Wrapf(err, "unable to send events to the webhook")
+}
+
+// try posts body once, with the max commit ts of its events. It returns whether the request can
+// be retried if it failed: the errors of the clients are not retried, except for too many
+// requests.
+func (w *webhookSink) try(body []byte, commitTs uint64) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookCommitTsHeader, strconv.FormatUint(commitTs, 10))
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// The body is read, so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, errors.Errorf("got status %s", resp.Status)
+	default:
+		return false, errors.Errorf("got status %s", resp.Status)
+	}
+}
+
+func (w *webhookSink) Close() error {
+	w.closer.Signal()
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -116,6 +116,8 @@ func (n *node) populateSnapshot(snap pb.Snapshot, pl *conn.Pool) error {
 	}
 	// Reset the cache after having received a snapshot.
 	posting.ResetCache()
+	// The statistics describe the data replaced by the snapshot.
+	localStats.reset()
 
 	glog.Infof("Snapshot writes DONE. Sending ACK")
 	// Send an acknowledgement back to the leader.
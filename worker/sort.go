@@ -126,7 +126,7 @@ func resultWithError(err error) *sortresult {
 	return &sortresult{&emptySortResult, nil, nil, err}
 }
 
-func sortWithoutIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
+func sortWithoutIndex(ctx context.Context, ts *pb.SortMessage, c *sortCursor) *sortresult {
 	span := otrace.FromContext(ctx)
 	span.Annotate(nil, "sortWithoutIndex")
 
@@ -149,6 +149,12 @@ func sortWithoutIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
 		default:
 			// Copy, otherwise it'd affect the destUids and hence the srcUids of Next level.
 			tempList := &pb.List{SortedUids: codec.GetUids(ts.UidMatrix[i])}
+			var ties int
+			if c != nil {
+				if ties, err = c.skipTo(ts, tempList, sType); err != nil {
+					return resultWithError(err)
+				}
+			}
 			var vals []types.Val
 			if vals, err = sortByValue(ctx, ts, tempList, sType); err != nil {
 				return resultWithError(err)
@@ -157,6 +163,10 @@ func sortWithoutIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
 			if err != nil {
 				return resultWithError(err)
 			}
+			// The nodes tied with the cursor come first, and multiSort might still drop them.
+			if end += ties; end > len(tempList.SortedUids) {
+				end = len(tempList.SortedUids)
+			}
 			if len(ts.Order) > 1 {
 				var offset int32
 				// Usually start would equal ts.Offset unless the values around the offset index
@@ -176,7 +186,7 @@ func sortWithoutIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
 	return &sortresult{r, multiSortOffsets, multiSortVals, nil}
 }
 
-func sortWithIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
+func sortWithIndex(ctx context.Context, ts *pb.SortMessage, c *sortCursor) *sortresult {
 	if ctx.Err() != nil {
 		return resultWithError(ctx.Err())
 	}
@@ -260,13 +270,31 @@ func sortWithIndex(ctx context.Context, ts *pb.SortMessage) *sortresult {
 		prefix[len(prefix)-1]++
 		seekKey = x.IndexKey(order.Attr, string(prefix))
 	}
+	// With a cursor, resume from the bucket that the value of the cursor falls into, instead of
+	// going over the buckets before it. The cursor comes after all the buckets if it doesn't
+	// have a value.
+	var cursorToken string
+	skipIndex := c != nil && c.vals[0].Value == nil
+	if c != nil && !skipIndex {
+		cv, err := types.Convert(c.vals[0], typ)
+		if err != nil {
+			return resultWithError(err)
+		}
+		tokens, err := tok.BuildTokens(cv.Value, tokenizer)
+		if err != nil || len(tokens) != 1 {
+			return resultWithError(errors.Errorf("Invalid cursor for attribute %s",
+				x.ParseAttr(order.Attr)))
+		}
+		cursorToken = tokens[0]
+		seekKey = x.IndexKey(order.Attr, cursorToken)
+	}
 	itr := txn.NewIterator(iterOpt)
 	defer itr.Close()
 
 	r := new(pb.SortResult)
 BUCKETS:
 	// Outermost loop is over index buckets.
-	for itr.Seek(seekKey); itr.Valid(); itr.Next() {
+	for itr.Seek(seekKey); !skipIndex && itr.Valid(); itr.Next() {
 		item := itr.Item()
 		key := item.Key() // No need to copy.
 		select {
@@ -281,9 +309,14 @@ BUCKETS:
 
 			x.AssertTrue(k.IsIndex())
 			token := k.Term
+			// Only the bucket of the cursor holds nodes up to it.
+			var bc *sortCursor
+			if token == cursorToken {
+				bc = c
+			}
 			// Intersect every UID list with the index bucket, and update their
 			// results (in out).
-			err = intersectBucket(ctx, ts, token, out)
+			err = intersectBucket(ctx, ts, token, out, bc)
 			switch err {
 			case errDone:
 				break BUCKETS
@@ -306,6 +339,18 @@ BUCKETS:
 	}
 
 	for i, ul := range ts.UidMatrix {
+		if c != nil {
+			nullNodes, err := c.nullNodes(ts, ul, &out[i], typ)
+			if err != nil {
+				return resultWithError(err)
+			}
+			r.UidMatrix[i].SortedUids = append(r.UidMatrix[i].SortedUids, nullNodes...)
+			if len(ts.Order) > 1 {
+				values[i] = append(values[i], make([]types.Val, len(nullNodes))...)
+			}
+			continue
+		}
+
 		// nullNodes is list of UIDs for which the value of the sort predicate is null.
 		var nullNodes []uint64
 		// present is a map[uid]->bool to keep track of the UIDs containing the sort predicate.
@@ -360,7 +405,7 @@ type orderResult struct {
 	err error
 }
 
-func multiSort(ctx context.Context, r *sortresult, ts *pb.SortMessage) error {
+func multiSort(ctx context.Context, r *sortresult, ts *pb.SortMessage, c *sortCursor) error {
 	span := otrace.FromContext(ctx)
 	span.Annotate(nil, "multiSort")
 
@@ -454,6 +499,20 @@ func multiSort(ctx context.Context, r *sortresult, ts *pb.SortMessage) error {
 		if err := types.Sort(vals, &ul.SortedUids, desc, ""); err != nil {
 			return err
 		}
+		if c != nil {
+			// Drop the nodes tied with the cursor on the first key which don't come after it.
+			uids := ul.SortedUids[:0]
+			for j, uid := range ul.SortedUids {
+				after, err := c.after(vals[j], uid, ts.Order)
+				if err != nil {
+					return err
+				}
+				if after {
+					uids = append(uids, uid)
+				}
+			}
+			ul.SortedUids = uids
+		}
 		// Paginate
 		start, end := x.PageRange(int(ts.Count), int(r.multiSortOffsets[i]), len(ul.SortedUids))
 		ul.SortedUids = ul.SortedUids[start:end]
@@ -485,6 +544,11 @@ func processSort(ctx context.Context, ts *pb.SortMessage) (*pb.SortResult, error
 	}
 	span.Annotate(nil, "Done waiting")
 
+	c, err := newSortCursor(ts)
+	if err != nil {
+		return nil, err
+	}
+
 	if ts.Count < 0 {
 		return nil, errors.Errorf(
 			"We do not yet support negative or infinite count with sorting: %s %d. "+
@@ -510,12 +574,12 @@ func processSort(ctx context.Context, ts *pb.SortMessage) (*pb.SortResult, error
 			resCh <- &sortresult{err: ctx.Err()}
 			return
 		}
-		r := sortWithoutIndex(cctx, ts)
+		r := sortWithoutIndex(cctx, ts, c)
 		resCh <- r
 	}()
 
 	go func() {
-		sr := sortWithIndex(cctx, ts)
+		sr := sortWithIndex(cctx, ts, c)
 		resCh <- sr
 	}()
 
@@ -537,7 +601,7 @@ func processSort(ctx context.Context, ts *pb.SortMessage) (*pb.SortResult, error
 		return r.reply, nil
 	}
 
-	err := multiSort(ctx, r, ts)
+	err = multiSort(ctx, r, ts, c)
 	return r.reply, err
 }
 
@@ -571,12 +635,16 @@ type intersectedList struct {
 	values          []types.Val
 	uset            map[uint64]struct{}
 	multiSortOffset int32
+	// cursorTies is the number of nodes in ulist tied with the cursor on the first sort key.
+	// multiSort drops the ones which don't come after the cursor, so they don't count towards
+	// filling the page.
+	cursorTies int
 }
 
 // intersectBucket intersects every UID list in the UID matrix with the
-// indexed bucket.
+// indexed bucket. The cursor is only passed for the bucket its value falls into.
 func intersectBucket(ctx context.Context, ts *pb.SortMessage, token string,
-	out []intersectedList) error {
+	out []intersectedList, c *sortCursor) error {
 	count := int(ts.Count)
 	order := ts.Order[0]
 	sType, err := schema.State().TypeOf(order.Attr)
@@ -599,7 +667,7 @@ func intersectBucket(ctx context.Context, ts *pb.SortMessage, token string,
 		// We need to reduce multiSortOffset while checking the count as we might have included
 		// some extra uids from the bucket that the offset falls into. We are going to discard
 		// the first multiSortOffset number of uids later after all sorts are applied.
-		if count > 0 && len(il.ulist.SortedUids)-int(il.multiSortOffset) >= count {
+		if count > 0 && len(il.ulist.SortedUids)-int(il.multiSortOffset)-il.cursorTies >= count {
 			continue
 		}
 
@@ -618,6 +686,11 @@ func intersectBucket(ctx context.Context, ts *pb.SortMessage, token string,
 		// Duplicates will exist between buckets if there are multiple language
 		// variants of a predicate.
 		result.SortedUids = removeDuplicates(result.SortedUids, il.uset)
+		if c != nil {
+			if il.cursorTies, err = c.skipTo(ts, result, scalar); err != nil {
+				return err
+			}
+		}
 
 		// Check offsets[i].
 		n := len(result.SortedUids)
@@ -681,7 +754,7 @@ func intersectBucket(ctx context.Context, ts *pb.SortMessage, token string,
 	for i := 0; i < len(ts.UidMatrix); i++ { // Iterate over UID lists.
 		// We need to reduce multiSortOffset while checking the count as we might have included
 		// some extra uids earlier for the multi-sort case.
-		if len(out[i].ulist.SortedUids)-int(out[i].multiSortOffset) < count {
+		if len(out[i].ulist.SortedUids)-int(out[i].multiSortOffset)-out[i].cursorTies < count {
 			return errContinue
 		}
 
@@ -815,3 +888,163 @@ func fetchValue(uid uint64, attr string, langs []string, scalar types.TypeID,
 
 	return dst, nil
 }
+
+// sortCursor is the node that a sort resumes after, for the cursor pagination. The nodes up to
+// it in the order of the sort are skipped.
+type sortCursor struct {
+	// vals holds the values of the sort keys of the node. A value is nil if the node doesn't
+	// have one for the key.
+	vals []types.Val
+	uid  uint64
+}
+
+// newSortCursor returns the cursor of the sort message, or nil if it doesn't have one.
+func newSortCursor(ts *pb.SortMessage) (*sortCursor, error) {
+	if ts.AfterUid == 0 {
+		return nil, nil
+	}
+	if len(ts.AfterVals) != len(ts.Order) {
+		return nil, errors.Errorf("The cursor doesn't match the order of the sort")
+	}
+	c := &sortCursor{uid: ts.AfterUid}
+	for _, tv := range ts.AfterVals {
+		if len(tv.Val) == 0 {
+			c.vals = append(c.vals, types.Val{})
+			continue
+		}
+		v, err := types.Convert(types.Val{Tid: types.BinaryID, Value: tv.Val},
+			types.TypeID(tv.ValType))
+		if err != nil {
+			return nil, errors.Wrapf(err, "while reading the cursor")
+		}
+		c.vals = append(c.vals, v)
+	}
+	return c, nil
+}
+
+// compare returns -1, 0 or 1 if the value comes before, along with or after the value of the
+// cursor for the i-th sort key. The values which are nil come after all the others, as they do
+// when sorting.
+func (c *sortCursor) compare(i int, v types.Val, desc bool) (int, error) {
+	cv := c.vals[i]
+	switch {
+	case v.Value == nil && cv.Value == nil:
+		return 0, nil
+	case v.Value == nil:
+		return 1, nil
+	case cv.Value == nil:
+		return -1, nil
+	}
+	if cv.Tid != v.Tid {
+		var err error
+		if cv, err = types.Convert(cv, v.Tid); err != nil {
+			return 0, err
+		}
+	}
+	less, err := types.Less(v, cv)
+	if err != nil {
+		return 0, err
+	}
+	greater, err := types.Less(cv, v)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case less == greater:
+		return 0, nil
+	case less != desc:
+		return -1, nil
+	}
+	return 1, nil
+}
+
+// after returns true if the node with the given sort values and uid comes after the cursor. The
+// nodes with the same values are sorted by uid.
+func (c *sortCursor) after(vals []types.Val, uid uint64, order []*pb.Order) (bool, error) {
+	for i, o := range order {
+		cmp, err := c.compare(i, vals[i], o.Desc)
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return cmp > 0, nil
+		}
+	}
+	return uid > c.uid, nil
+}
+
+// skipTo removes the nodes which don't come after the cursor from the list, comparing their
+// values for the first sort key with the one of the cursor. With several sort keys, the nodes
+// tied with the cursor on the first key are kept, as the other keys decide if they come after
+// it. multiSort drops them later, and their number is returned.
+func (c *sortCursor) skipTo(ts *pb.SortMessage, ul *pb.List, typ types.TypeID) (int, error) {
+	order := ts.Order[0]
+	uids := ul.SortedUids[:0]
+	var ties int
+	for _, uid := range ul.SortedUids {
+		val, err := fetchValue(uid, order.Attr, order.Langs, typ, ts.ReadTs)
+		if err != nil {
+			// The node is sorted as if it didn't have a value, like in sortByValue.
+			val = types.Val{}
+		}
+		cmp, err := c.compare(0, val, order.Desc)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case cmp < 0:
+			continue
+		case cmp == 0 && len(ts.Order) > 1:
+			ties++
+		case cmp == 0 && uid <= c.uid:
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	ul.SortedUids = uids
+	return ties, nil
+}
+
+// nullNodes returns the nodes of the list which don't have a value for the first sort key and
+// come after the cursor, after applying the rest of the offset and the count. As the buckets
+// before the one of the cursor weren't read, the nodes which weren't seen in a bucket may still
+// have a value. They are looked up one by one in the order of their uids, and only till the page
+// is full. With several sort keys and a cursor without a value, all of them are needed though,
+// as they are then sorted by the other keys.
+func (c *sortCursor) nullNodes(ts *pb.SortMessage, ul *pb.List, il *intersectedList,
+	typ types.TypeID) ([]uint64, error) {
+	left := int(ts.Count) - (len(il.ulist.SortedUids) - int(il.multiSortOffset) - il.cursorTies)
+	if left <= 0 {
+		return nil, nil
+	}
+	multi := len(ts.Order) > 1
+	all := multi && c.vals[0].Value == nil
+	want := il.offset + left
+
+	order := ts.Order[0]
+	var nulls []uint64
+	for _, uid := range ul.SortedUids {
+		if !all && len(nulls) >= want {
+			break
+		}
+		if _, ok := il.uset[uid]; ok {
+			continue
+		}
+		if !multi && c.vals[0].Value == nil && uid <= c.uid {
+			continue
+		}
+		if _, err := fetchValue(uid, order.Attr, order.Langs, typ, ts.ReadTs); err == nil {
+			continue
+		}
+		nulls = append(nulls, uid)
+	}
+
+	if il.offset >= len(nulls) {
+		return nil, nil
+	}
+	nulls = nulls[il.offset:]
+	if !all && len(nulls) > left {
+		nulls = nulls[:left]
+	}
+	return nulls, nil
+}
@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/types"
 )
 
 func TestRemoveDuplicates(t *testing.T) {
@@ -54,3 +57,57 @@ func TestRemoveDuplicates(t *testing.T) {
 		require.Equal(t, set, toSet(test.setOut))
 	}
 }
+
+func TestSortCursorAfter(t *testing.T) {
+	name := types.Val{Tid: types.StringID, Value: "Alice"}
+	age := func(a int64) types.Val { return types.Val{Tid: types.IntID, Value: a} }
+	toTask := func(v types.Val) *pb.TaskValue {
+		bv := types.ValueForType(types.BinaryID)
+		require.NoError(t, types.Marshal(v, &bv))
+		return &pb.TaskValue{Val: bv.Value.([]byte), ValType: pb.Posting_ValType(v.Tid)}
+	}
+	order := []*pb.Order{{Attr: "name"}, {Attr: "age", Desc: true}}
+	c, err := newSortCursor(&pb.SortMessage{
+		Order:     order,
+		AfterVals: []*pb.TaskValue{toTask(name), toTask(age(30))},
+		AfterUid:  10,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		vals  []types.Val
+		uid   uint64
+		after bool
+	}{
+		{vals: []types.Val{{Tid: types.StringID, Value: "Aaron"}, age(20)}, uid: 1, after: false},
+		{vals: []types.Val{{Tid: types.StringID, Value: "Bob"}, age(40)}, uid: 1, after: true},
+		{vals: []types.Val{name, age(20)}, uid: 1, after: true},
+		{vals: []types.Val{name, age(40)}, uid: 20, after: false},
+		{vals: []types.Val{name, age(30)}, uid: 11, after: true},
+		{vals: []types.Val{name, age(30)}, uid: 10, after: false},
+		{vals: []types.Val{name, {}}, uid: 1, after: true},
+		{vals: []types.Val{{}, age(40)}, uid: 1, after: true},
+	}
+	for _, tc := range tests {
+		after, err := c.after(tc.vals, tc.uid, order)
+		require.NoError(t, err)
+		require.Equal(t, tc.after, after, "%v %d", tc.vals, tc.uid)
+	}
+
+	// The nodes without a value for a key are tied with a cursor without one.
+	c, err = newSortCursor(&pb.SortMessage{
+		Order:     order[:1],
+		AfterVals: []*pb.TaskValue{{}},
+		AfterUid:  10,
+	})
+	require.NoError(t, err)
+	after, err := c.after([]types.Val{name}, 20, order[:1])
+	require.NoError(t, err)
+	require.False(t, after)
+	after, err = c.after([]types.Val{{}}, 20, order[:1])
+	require.NoError(t, err)
+	require.True(t, after)
+
+	_, err = newSortCursor(&pb.SortMessage{Order: order, AfterUid: 10})
+	require.Error(t, err)
+}
@@ -0,0 +1,469 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/dgryski/go-farm"
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos/pb"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The statistics of a predicate are collected periodically by every alpha serving it, from its
+// own copy of the data. They're local to the alpha: they're kept in memory rather than in Badger,
+// so that they're neither replicated nor moved along with the tablet, and they're collected again
+// after a restart. Once collected, the statistics of a predicate are only collected again after
+// it has been modified.
+
+// distinctSampleSize is the number of hashes kept to estimate the number of distinct values of
+// a predicate. Up to that many, the count is exact.
+const distinctSampleSize = 1024
+
+// statsStore holds the statistics collected for the predicates served by this alpha.
+type statsStore struct {
+	sync.RWMutex
+	stats map[string]*pb.PredicateStats
+	// changed has the predicates modified since their statistics were last collected.
+	changed map[string]struct{}
+}
+
+var localStats = newStatsStore()
+
+func newStatsStore() *statsStore {
+	return &statsStore{
+		stats:   make(map[string]*pb.PredicateStats),
+		changed: make(map[string]struct{}),
+	}
+}
+
+// get returns the statistics last collected for the predicate, or nil if there are none.
+func (s *statsStore) get(attr string) *pb.PredicateStats {
+	s.RLock()
+	defer s.RUnlock()
+	return s.stats[attr]
+}
+
+func (s *statsStore) set(attr string, stats *pb.PredicateStats) {
+	s.Lock()
+	defer s.Unlock()
+	s.stats[attr] = stats
+}
+
+// markChanged records that the predicate has been modified, so that its statistics are
+// collected again.
+func (s *statsStore) markChanged(attr string) {
+	s.Lock()
+	defer s.Unlock()
+	s.changed[attr] = struct{}{}
+}
+
+// takeChanged returns true if the statistics of the predicate have to be collected, because it
+// was modified or they never were. The predicate is then no longer marked as modified.
+func (s *statsStore) takeChanged(attr string) bool {
+	s.Lock()
+	defer s.Unlock()
+	_, changed := s.changed[attr]
+	_, collected := s.stats[attr]
+	delete(s.changed, attr)
+	return changed || !collected
+}
+
+// retain drops the statistics of the predicates which aren't in attrs, as they were dropped or
+// moved to another group.
+func (s *statsStore) retain(attrs map[string]struct{}) {
+	s.Lock()
+	defer s.Unlock()
+	for attr := range s.stats {
+		if _, ok := attrs[attr]; !ok {
+			delete(s.stats, attr)
+			delete(s.changed, attr)
+		}
+	}
+}
+
+// reset drops all the statistics, after the data was dropped or replaced by a snapshot.
+func (s *statsStore) reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.stats = make(map[string]*pb.PredicateStats)
+	s.changed = make(map[string]struct{})
+}
+
+func (n *node) processStats() {
+	defer n.closer.Done() // CLOSER:1
+	if Config.StatsInterval <= 0 {
+		return
+	}
+	tick := time.NewTicker(Config.StatsInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-n.closer.HasBeenClosed():
+			return
+		case <-tick.C:
+			if err := collectAllStats(n.closer.Ctx()); err != nil {
+				glog.Errorf("Error while collecting predicate statistics: %v", err)
+			}
+		}
+	}
+}
+
+// collectAllStats collects the statistics of the predicates served by this alpha which were
+// modified since they were last collected.
+func collectAllStats(ctx context.Context) error {
+	if posting.Oracle().MaxAssigned() == 0 {
+		return nil
+	}
+	// The indexes being rebuilt are incomplete. The predicates stay marked as modified until the
+	// next run.
+	if schema.State().IndexingInProgress() {
+		return nil
+	}
+	start := time.Now()
+	var collected int
+	served := make(map[string]struct{})
+	for _, attr := range schema.State().Predicates() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		serves, err := groups().ServesTablet(attr)
+		if err != nil {
+			// Keep the statistics until we know.
+			served[attr] = struct{}{}
+			continue
+		}
+		if !serves {
+			continue
+		}
+		served[attr] = struct{}{}
+		if !localStats.takeChanged(attr) {
+			continue
+		}
+
+		// The txns pending now may commit after readTs, in which case their changes aren't in
+		// the statistics, which must then be collected again.
+		pending := len(pendingTxns(attr)) > 0
+		readTs := posting.Oracle().MaxAssigned()
+		stats, err := collectStats(ctx, attr, readTs, Config.StatsTopTokens)
+		if err != nil {
+			localStats.markChanged(attr)
+			return errors.Wrapf(err, "while collecting statistics of %s", attr)
+		}
+		localStats.set(attr, stats)
+		if pending {
+			localStats.markChanged(attr)
+		}
+		collected++
+	}
+	localStats.retain(served)
+	glog.V(2).Infof("Collected statistics of %d predicates in %s", collected,
+		time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// collectStats reads the data and the indexes of the predicate at readTs and returns their
+// statistics, with up to topTokens tokens per index.
+func collectStats(ctx context.Context, attr string, readTs uint64,
+	topTokens int) (*pb.PredicateStats, error) {
+	stats := &pb.PredicateStats{CollectedAt: time.Now().Unix()}
+	pk := x.ParsedKey{Attr: attr}
+
+	var hist lengthHistogram
+	distinct := newDistinctCounter(distinctSampleSize)
+	err := iteratePostingLists(ctx, pk.DataPrefix(), readTs,
+		func(key x.ParsedKey, pl *posting.List) error {
+			var length uint64
+			err := pl.IterateAll(readTs, 0, func(p *pb.Posting) error {
+				length++
+				if p.PostingType == pb.Posting_REF {
+					var buf [8]byte
+					binary.BigEndian.PutUint64(buf[:], p.Uid)
+					distinct.add(farm.Fingerprint64(buf[:]))
+				} else {
+					val := make([]byte, 0, len(p.LangTag)+len(p.Value))
+					val = append(append(val, p.LangTag...), p.Value...)
+					distinct.add(farm.Fingerprint64(val))
+				}
+				return nil
+			})
+			if err != nil || length == 0 {
+				return err
+			}
+			stats.Count++
+			hist.add(length)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	stats.DistinctValues = distinct.count()
+	stats.Histogram = hist.buckets()
+
+	indexes := make(map[byte]*indexStats)
+	err = iteratePostingLists(ctx, pk.IndexPrefix(), readTs,
+		func(key x.ParsedKey, pl *posting.List) error {
+			if len(key.Term) == 0 || key.Term[0] == tok.IdentVector {
+				// The vector index stores a graph rather than tokens.
+				return nil
+			}
+			length := pl.Length(readTs, 0)
+			if length <= 0 {
+				return nil
+			}
+			idx, ok := indexes[key.Term[0]]
+			if !ok {
+				idx = &indexStats{limit: topTokens}
+				indexes[key.Term[0]] = idx
+			}
+			idx.add(key.Term[1:], uint64(length))
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	for id, idx := range indexes {
+		stats.Indexes = append(stats.Indexes, idx.result(tokenizerName(id)))
+	}
+	sort.Slice(stats.Indexes, func(i, j int) bool {
+		return stats.Indexes[i].Tokenizer < stats.Indexes[j].Tokenizer
+	})
+	return stats, nil
+}
+
+// iteratePostingLists calls fn with every posting list under the prefix, as of readTs.
+func iteratePostingLists(ctx context.Context, prefix []byte, readTs uint64,
+	fn func(key x.ParsedKey, pl *posting.List) error) error {
+	txn := pstore.NewTransactionAt(readTs, false)
+	defer txn.Discard()
+
+	itOpt := badger.DefaultIteratorOptions
+	itOpt.PrefetchValues = false
+	itOpt.AllVersions = true
+	itOpt.Prefix = prefix
+	it := txn.NewIterator(itOpt)
+	defer it.Close()
+
+	var prevKey []byte
+	var seen int
+	for it.Rewind(); it.Valid(); {
+		item := it.Item()
+		if bytes.Equal(item.Key(), prevKey) {
+			it.Next()
+			continue
+		}
+		prevKey = append(prevKey[:0], item.Key()...)
+
+		// Parse the key upfront, otherwise ReadPostingList would advance the iterator.
+		pk, err := x.Parse(item.Key())
+		if err != nil {
+			return err
+		}
+		if pk.HasStartUid || item.UserMeta()&posting.BitEmptyPosting > 0 {
+			it.Next()
+			continue
+		}
+
+		seen++
+		if seen%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		pl, err := posting.ReadPostingList(item.KeyCopy(nil), it)
+		if err != nil {
+			return err
+		}
+		if err := fn(pk, pl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lengthHistogram counts posting lists by length, in buckets whose bounds are powers of two.
+// Bucket i holds the lists with more than 2^(i-1) and up to 2^i postings.
+type lengthHistogram [65]uint64
+
+func (h *lengthHistogram) add(length uint64) {
+	h[bits.Len64(length-1)]++
+}
+
+// buckets returns the non-empty buckets of the histogram.
+func (h *lengthHistogram) buckets() []*pb.StatsBucket {
+	var out []*pb.StatsBucket
+	for i, count := range h {
+		if count == 0 {
+			continue
+		}
+		maxLength := uint64(math.MaxUint64)
+		if i < 64 {
+			maxLength = 1 << uint(i)
+		}
+		out = append(out, &pb.StatsBucket{MaxLength: maxLength, Count: count})
+	}
+	return out
+}
+
+// distinctCounter estimates the number of distinct values it's given from the k smallest of
+// their hashes (KMV): the k-th smallest hash tells which fraction of the hash space holds k
+// values. The count is exact while fewer than k distinct values have been seen.
+type distinctCounter struct {
+	k      int
+	hashes uint64MaxHeap
+	seen   map[uint64]struct{}
+}
+
+func newDistinctCounter(k int) *distinctCounter {
+	return &distinctCounter{k: k, seen: make(map[uint64]struct{}, k)}
+}
+
+func (c *distinctCounter) add(hash uint64) {
+	if _, ok := c.seen[hash]; ok {
+		return
+	}
+	if len(c.hashes) < c.k {
+		c.seen[hash] = struct{}{}
+		heap.Push(&c.hashes, hash)
+		return
+	}
+	if hash >= c.hashes[0] {
+		return
+	}
+	delete(c.seen, c.hashes[0])
+	c.seen[hash] = struct{}{}
+	c.hashes[0] = hash
+	heap.Fix(&c.hashes, 0)
+}
+
+func (c *distinctCounter) count() uint64 {
+	if len(c.hashes) < c.k {
+		return uint64(len(c.hashes))
+	}
+	fraction := float64(c.hashes[0]) / math.MaxUint64
+	return uint64(float64(c.k-1) / fraction)
+}
+
+type uint64MaxHeap []uint64
+
+func (h uint64MaxHeap) Len() int            { return len(h) }
+func (h uint64MaxHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h uint64MaxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *uint64MaxHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *uint64MaxHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// indexStats counts the tokens of an index, keeping the ones with the longest posting lists.
+type indexStats struct {
+	limit     int
+	numTokens uint64
+	top       tokenMinHeap
+}
+
+func (s *indexStats) add(token []byte, count uint64) {
+	s.numTokens++
+	if s.limit <= 0 {
+		return
+	}
+	if len(s.top) < s.limit {
+		heap.Push(&s.top, &pb.TokenCount{Token: displayToken(token), Count: count})
+		return
+	}
+	if count <= s.top[0].Count {
+		return
+	}
+	s.top[0] = &pb.TokenCount{Token: displayToken(token), Count: count}
+	heap.Fix(&s.top, 0)
+}
+
+func (s *indexStats) result(tokenizer string) *pb.IndexStats {
+	top := []*pb.TokenCount(s.top)
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Token < top[j].Token
+	})
+	return &pb.IndexStats{Tokenizer: tokenizer, NumTokens: s.numTokens, TopTokens: top}
+}
+
+type tokenMinHeap []*pb.TokenCount
+
+func (h tokenMinHeap) Len() int            { return len(h) }
+func (h tokenMinHeap) Less(i, j int) bool  { return h[i].Count < h[j].Count }
+func (h tokenMinHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *tokenMinHeap) Push(x interface{}) { *h = append(*h, x.(*pb.TokenCount)) }
+func (h *tokenMinHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+func tokenizerName(id byte) string {
+	if id == tok.IdentComposite {
+		return "composite"
+	}
+	if t, ok := tok.GetTokenizerByID(id); ok {
+		return t.Name()
+	}
+	return fmt.Sprintf("%#x", id)
+}
+
+// displayToken returns the token as text if it's printable, and in hex otherwise, as the
+// tokens of some tokenizers (int, hash, ...) are binary.
+func displayToken(token []byte) string {
+	if utf8.Valid(token) {
+		printable := true
+		for _, r := range string(token) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(token)
+		}
+	}
+	return "0x" + hex.EncodeToString(token)
+}
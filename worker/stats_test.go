@@ -0,0 +1,100 @@
+/*
+ * Copyright 2021 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package worker
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/dgryski/go-farm"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos/pb"
+)
+
+func TestDistinctCounter(t *testing.T) {
+	hash := func(i int) uint64 {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(i))
+		return farm.Fingerprint64(buf[:])
+	}
+
+	c := newDistinctCounter(64)
+	for i := 0; i < 50; i++ {
+		c.add(hash(i))
+		c.add(hash(i))
+	}
+	require.Equal(t, uint64(50), c.count())
+
+	c = newDistinctCounter(1024)
+	for i := 0; i < 100000; i++ {
+		c.add(hash(i % 20000))
+	}
+	require.InDelta(t, 20000, c.count(), 20000*0.1)
+}
+
+func TestLengthHistogram(t *testing.T) {
+	var h lengthHistogram
+	for _, length := range []uint64{1, 2, 3, 4, 5, 1000} {
+		h.add(length)
+	}
+	require.Equal(t, []*pb.StatsBucket{
+		{MaxLength: 1, Count: 1},
+		{MaxLength: 2, Count: 1},
+		{MaxLength: 4, Count: 2},
+		{MaxLength: 8, Count: 1},
+		{MaxLength: 1024, Count: 1},
+	}, h.buckets())
+}
+
+func TestIndexStatsTopTokens(t *testing.T) {
+	s := &indexStats{limit: 2}
+	s.add([]byte("a"), 5)
+	s.add([]byte("b"), 10)
+	s.add([]byte{0x00, 0x01}, 7)
+	s.add([]byte("c"), 1)
+
+	res := s.result("exact")
+	require.Equal(t, uint64(4), res.NumTokens)
+	require.Equal(t, []*pb.TokenCount{
+		{Token: "b", Count: 10},
+		{Token: "0x0001", Count: 7},
+	}, res.TopTokens)
+}
+
+func TestStatsStore(t *testing.T) {
+	s := newStatsStore()
+	// The statistics of a predicate are collected until they have been once.
+	require.True(t, s.takeChanged("name"))
+	require.True(t, s.takeChanged("name"))
+	s.set("name", &pb.PredicateStats{})
+	require.False(t, s.takeChanged("name"))
+
+	s.markChanged("name")
+	require.True(t, s.takeChanged("name"))
+	require.False(t, s.takeChanged("name"))
+
+	s.set("age", &pb.PredicateStats{})
+	s.markChanged("age")
+	s.retain(map[string]struct{}{"name": {}})
+	require.NotNil(t, s.get("name"))
+	require.Nil(t, s.get("age"))
+	require.True(t, s.takeChanged("age"))
+
+	s.reset()
+	require.Nil(t, s.get("name"))
+}
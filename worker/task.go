@@ -19,6 +19,7 @@ package worker
 import (
 	"bytes"
 	"context"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -222,6 +223,8 @@ const (
 	uidInFn
 	customIndexFn
 	matchFn
+	similarToFn
+	compositeEqFn
 	standardFn = 100
 )
 
@@ -248,7 +251,7 @@ func parseFuncTypeHelper(name string) (FuncType, string) {
 	switch f {
 	case "le", "ge", "lt", "gt", "eq", "between":
 		return compareAttrFn, f
-	case "min", "max", "sum", "avg":
+	case "min", "max", "sum", "avg", "percentile", "median", "stddev", "variance", "distinct":
 		return aggregatorFn, f
 	case "checkpwd":
 		return passwordFn, f
@@ -264,6 +267,10 @@ func parseFuncTypeHelper(name string) (FuncType, string) {
 		return customIndexFn, f
 	case "match":
 		return matchFn, f
+	case "similar_to":
+		return similarToFn, f
+	case CompositeEqFunc:
+		return compositeEqFn, f
 	default:
 		if types.IsGeoFunc(f) {
 			return geoFn, f
@@ -284,6 +291,9 @@ func needsIndex(fnType FuncType, uidList *pb.List) bool {
 		return true
 	case geoFn, fullTextSearchFn, standardFn, matchFn:
 		return true
+	case similarToFn:
+		// As a filter, the vectors of the given uids are compared directly.
+		return uidList == nil
 	}
 	return false
 }
@@ -319,6 +329,12 @@ func (srcFn *functionContext) needsValuePostings(typ types.TypeID) (bool, error)
 	case uidInFn, compareScalarFn:
 		// Operate on uid postings
 		return false, nil
+	case similarToFn:
+		// Handled by handleSimilarToFunction.
+		return false, nil
+	case compositeEqFn:
+		// Handled by handleCompositeEqFunction.
+		return false, nil
 	case notAFunction:
 		return typ.IsScalar(), nil
 	}
@@ -810,17 +826,17 @@ func (qs *queryState) handleUidPostings(
 				if i == 0 {
 					span.Annotate(nil, "UidInFn")
 				}
+				// Only whether the list has one of the UIDs matters, so it's enough to find
+				// the first one.
 				topts := posting.ListOptions{
 					ReadTs:    args.q.ReadTs,
-					AfterUid:  0,
 					Intersect: srcFnUidList,
-					First:     int(args.q.First + args.q.Offset),
 				}
-				plist, err := pl.Uids(topts)
+				found, err := pl.Intersects(topts)
 				if err != nil {
 					return err
 				}
-				if codec.ListCardinality(plist) > 0 {
+				if found {
 					tlist := codec.OneUid(uids[i])
 					out.UidMatrix = append(out.UidMatrix, tlist)
 				}
@@ -975,6 +991,9 @@ func (qs *queryState) helpProcessTask(ctx context.Context, q *pb.Query, gid uint
 		// more need to store the star value in this field.
 		q.Langs = nil
 	}
+	if q.Estimate {
+		return qs.estimateFunction(q, srcFn)
+	}
 
 	typ, err := schema.State().TypeOf(attr)
 	if err != nil {
@@ -1048,6 +1067,20 @@ func (qs *queryState) helpProcessTask(ctx context.Context, q *pb.Query, gid uint
 		}
 	}
 
+	if srcFn.fnType == similarToFn {
+		span.Annotate(nil, "handleSimilarToFunction")
+		if err := qs.handleSimilarToFunction(ctx, args); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcFn.fnType == compositeEqFn {
+		span.Annotate(nil, "handleCompositeEqFunction")
+		if err := qs.handleCompositeEqFunction(ctx, args); err != nil {
+			return nil, err
+		}
+	}
+
 	// We fetch the actual value for the uids, compare them to the value in the
 	// request and filter the uids only if the tokenizer IsLossy.
 	if srcFn.fnType == compareAttrFn && len(srcFn.tokens) > 0 {
@@ -1469,6 +1502,203 @@ func (qs *queryState) handleMatchFunction(ctx context.Context, arg funcArgs) err
 	return nil
 }
 
+// handleSimilarToFunction finds the k nodes whose vectors are the closest to the query vector,
+// closest first. At root, the hnsw index of the predicate is searched for them. As a filter, they
+// are picked from the given uids by comparing their vectors.
+func (qs *queryState) handleSimilarToFunction(ctx context.Context, arg funcArgs) error {
+	span := otrace.FromContext(ctx)
+	stop := x.SpanTimer(span, "handleSimilarToFunction")
+	defer stop()
+
+	attr := arg.q.Attr
+	k := int(arg.srcFn.threshold[0])
+	query := arg.srcFn.vector
+	var uids []uint64
+	if arg.srcFn.isFuncAtRoot {
+		if !schema.State().HasTokenizer(ctx, tok.IdentVector, attr) {
+			return errors.Errorf("Attribute %v does not have hnsw index for similar_to. "+
+				"Please add an hnsw index or use has/uid function with similar_to() as filter.",
+				x.ParseAttr(attr))
+		}
+		var err error
+		uids, err = posting.SearchVectorIndex(ctx, qs.cache, attr, arg.q.ReadTs, query, k)
+		if err != nil {
+			return err
+		}
+	} else {
+		type candidate struct {
+			uid  uint64
+			dist float64
+		}
+		var cands []candidate
+		for i, uid := range codec.GetUids(arg.q.UidList) {
+			if i%100 == 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+				}
+			}
+			pl, err := qs.cache.Get(x.DataKey(attr, uid))
+			if err != nil {
+				return err
+			}
+			val, err := pl.Value(arg.q.ReadTs)
+			if err == posting.ErrNoValue {
+				continue
+			} else if err != nil {
+				return err
+			}
+			vec, err := types.Convert(val, types.VFloatID)
+			if err != nil {
+				return err
+			}
+			dist, err := types.EuclideanDistanceSq(query, vec.Value.([]float32))
+			if err != nil {
+				return err
+			}
+			cands = append(cands, candidate{uid: uid, dist: dist})
+		}
+		sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+		if len(cands) > k {
+			cands = cands[:k]
+		}
+		for _, c := range cands {
+			uids = append(uids, c.uid)
+		}
+	}
+	span.Annotatef(nil, "Found %d similar nodes", len(uids))
+
+	// The nodes are returned closest first, which is the order of the results at root.
+	arg.out.UidMatrix = append(arg.out.UidMatrix, &pb.List{SortedUids: uids})
+	return nil
+}
+
+// estimateFunction estimates the number of UIDs matched by the function from the lengths of the
+// index posting lists it would read. Functions that aren't answered from an index can't be
+// estimated, and get a result without counts.
+func (qs *queryState) estimateFunction(q *pb.Query, srcFn *functionContext) (*pb.Result, error) {
+	out := new(pb.Result)
+	switch {
+	case srcFn.fnType == compareAttrFn && srcFn.fname == eq:
+	case srcFn.fnType == standardFn || srcFn.fnType == fullTextSearchFn:
+	default:
+		return out, nil
+	}
+	if len(srcFn.tokens) == 0 {
+		return out, nil
+	}
+
+	var estimate int64
+	for i, token := range srcFn.tokens {
+		pl, err := qs.cache.Get(x.IndexKey(q.Attr, token))
+		if err != nil {
+			return nil, err
+		}
+		n := int64(pl.Length(q.ReadTs, 0))
+		if n < 0 {
+			return nil, errors.Errorf("Unable to read the index of %s for token %q",
+				x.ParseAttr(q.Attr), token)
+		}
+		// The UIDs must have all the tokens if the results get intersected, and any of them
+		// otherwise.
+		switch {
+		case !srcFn.intersectDest:
+			estimate += n
+		case i == 0 || n < estimate:
+			estimate = n
+		}
+	}
+	if estimate > math.MaxUint32 {
+		estimate = math.MaxUint32
+	}
+	out.Counts = []uint32{uint32(estimate)}
+	return out, nil
+}
+
+// handleCompositeEqFunction evaluates a conjunction of eq functions over the predicates of a
+// composite index, which is owned by the attribute of the query, by looking up their values in
+// the index.
+func (qs *queryState) handleCompositeEqFunction(ctx context.Context, arg funcArgs) error {
+	span := otrace.FromContext(ctx)
+	stop := x.SpanTimer(span, "handleCompositeEqFunction")
+	defer stop()
+
+	q := arg.q
+	ns := x.ParseNamespace(q.Attr)
+	vals := map[string]string{q.Attr: q.SrcFunc.Args[0]}
+	for i := 1; i < len(q.SrcFunc.Args); i += 2 {
+		pred := x.NamespaceAttr(ns, q.SrcFunc.Args[i])
+		if _, ok := vals[pred]; ok {
+			return errors.Errorf("Predicate %s is repeated in %s", q.SrcFunc.Args[i],
+				q.SrcFunc.Name)
+		}
+		vals[pred] = q.SrcFunc.Args[i+1]
+	}
+
+	var composite []string
+	for _, preds := range schema.State().CompositeIndexes(ctx, q.Attr) {
+		if preds[0] != q.Attr || len(preds) != len(vals) {
+			continue
+		}
+		composite = preds
+		for _, pred := range preds {
+			if _, ok := vals[pred]; !ok {
+				composite = nil
+				break
+			}
+		}
+		if composite != nil {
+			break
+		}
+	}
+	if composite == nil {
+		preds := make([]string, 0, len(vals))
+		for pred := range vals {
+			preds = append(preds, x.ParseAttr(pred))
+		}
+		sort.Strings(preds)
+		return errors.Errorf("Attribute %s doesn't have a composite index over %v",
+			x.ParseAttr(q.Attr), preds)
+	}
+
+	var data [][]byte
+	for _, pred := range composite {
+		// The index is maintained by the group of its owner, which must serve all of its
+		// predicates.
+		gid, err := groups().BelongsToReadOnly(pred, q.ReadTs)
+		if err != nil {
+			return err
+		}
+		if gid != groups().groupId() {
+			return errors.Errorf("Predicate %s of the composite index %v isn't served by "+
+				"group %d", x.ParseAttr(pred), x.ParseAttrList(composite), groups().groupId())
+		}
+
+		val, err := convertValue(pred, vals[pred])
+		if err != nil {
+			return err
+		}
+		b := types.ValueForType(types.BinaryID)
+		if err := types.Marshal(val, &b); err != nil {
+			return err
+		}
+		data = append(data, b.Value.([]byte))
+	}
+	pl, err := qs.cache.Get(x.CompositeIndexKey(composite[0], tok.IdentComposite, data))
+	if err != nil {
+		return err
+	}
+	bm, err := pl.Bitmap(posting.ListOptions{ReadTs: q.ReadTs, Intersect: q.UidList})
+	if err != nil {
+		return err
+	}
+	span.Annotatef(nil, "Found %d uids in composite index %v",
+		bm.GetCardinality(), x.ParseAttrList(composite))
+	arg.out.UidMatrix = append(arg.out.UidMatrix, &pb.List{Bitmap: bm.ToBuffer()})
+	return nil
+}
+
 func (qs *queryState) filterGeoFunction(ctx context.Context, arg funcArgs) error {
 	span := otrace.FromContext(ctx)
 	stop := x.SpanTimer(span, "filterGeoFunction")
@@ -1687,6 +1917,8 @@ type functionContext struct {
 	isFuncAtRoot   bool
 	isStringFn     bool
 	atype          types.TypeID
+	// vector is the query vector of similar_to.
+	vector []float32
 }
 
 const (
@@ -1694,6 +1926,12 @@ const (
 	between = "between"
 )
 
+// CompositeEqFunc is the name of the function that the query sends for a conjunction of eq
+// functions over the predicates of a composite index, so that it can be answered from it. Its
+// arguments are the value for the attribute of the query, followed by pairs of the other
+// predicates and their values.
+const CompositeEqFunc = "composite_eq"
+
 func ensureArgsCount(srcFunc *pb.SrcFunction, expected int) error {
 	if len(srcFunc.Args) != expected {
 		return errors.Errorf("Function '%s' requires %d arguments, but got %d (%v)",
@@ -1946,6 +2184,8 @@ func parseSrcFn(ctx context.Context, q *pb.Query) (*functionContext, error) {
 		}
 		checkRoot(q, fc)
 	case uidInFn:
+		// The UIDs are either given as arguments, or as a list if they come from a variable or
+		// a nested query block.
 		var uids []uint64
 		for _, arg := range q.SrcFunc.Args {
 			uidParsed, err := strconv.ParseUint(arg, 0, 64)
@@ -1962,10 +2202,38 @@ func parseSrcFn(ctx context.Context, q *pb.Query) (*functionContext, error) {
 			return uids[i] < uids[j]
 		})
 		fc.uidsPresent = sroar.FromSortedList(uids)
+		if q.SrcFunc.Uids != nil {
+			fc.uidsPresent.Or(codec.FromListNoCopy(q.SrcFunc.Uids))
+		}
 		checkRoot(q, fc)
 		if fc.isFuncAtRoot {
 			return nil, errors.Errorf("uid_in function not allowed at root")
 		}
+	case similarToFn:
+		if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
+			return nil, err
+		}
+		if t != types.VFloatID {
+			return nil, errors.Errorf("similar_to can only be used on predicates of type %s,"+
+				" got %s for %s", types.VFloatID.Name(), t.Name(), x.ParseAttr(attr))
+		}
+		k, err := strconv.ParseInt(q.SrcFunc.Args[0], 10, 32)
+		if err != nil || k <= 0 {
+			return nil, errors.Errorf("Number of neighbours in similar_to must be a positive int,"+
+				" got %v", q.SrcFunc.Args[0])
+		}
+		if fc.vector, err = types.ParseVFloat(q.SrcFunc.Args[1]); err != nil {
+			return nil, err
+		}
+		fc.threshold = []int64{k}
+		fc.isFuncAtRoot = q.UidList == nil
+	case compositeEqFn:
+		// The value for the attribute is followed by pairs of other predicates and their values.
+		if len(q.SrcFunc.Args) < 3 || len(q.SrcFunc.Args)%2 == 0 {
+			return nil, errors.Errorf("Function '%s' requires a value followed by pairs of "+
+				"predicates and values, got %v", q.SrcFunc.Name, q.SrcFunc.Args)
+		}
+		fc.isFuncAtRoot = q.UidList == nil
 	default:
 		return nil, errors.Errorf("FnType %d not handled in numFnAttrs.", fnType)
 	}
@@ -40,6 +40,8 @@ type Options struct {
 	// query-timeout duration - Maximum time after which a query execution will fail.
 	// max-retries int64 - maximum number of retries made by dgraph to commit a transaction to disk.
 	// shared-instance bool - if set to true, ACLs will be disabled for non-galaxy users.
+	// query-planner int - maximum number of index estimates that the query planner can make for
+	//                     a query block. The planner is disabled if it's 0.
 	Limit                *z.SuperFlag
 	LimitMutationsNquad  int
 	LimitQueryEdge       uint64
@@ -48,12 +50,17 @@ type Options struct {
 	QueryTimeout         time.Duration
 	MaxRetries           int64
 	SharedInstance       bool
+	LimitQueryPlanner    int
 
 	// GraphQL options:
 	//
 	// extensions bool - Will be set to see extensions in GraphQL results
 	// debug bool - Will enable debug mode in GraphQL.
 	// poll-interval duration - The polling interval for graphql subscription.
+	// max-depth int - The maximum depth of the fields of an operation, 0 means no limit.
+	// max-breadth int - The maximum value of the first argument, 0 means no limit.
+	// max-cost uint64 - The maximum estimated cost of an operation, 0 means no limit.
+	// persisted-queries-only bool - Only serve the operations in the persisted query registry.
 	GraphQL GraphQLOptions
 
 	// Lambda options:
@@ -78,6 +85,11 @@ type GraphQLOptions struct {
 	Debug         bool
 	Extensions    bool
 	PollInterval  time.Duration
+	MaxDepth      int
+	MaxBreadth    int
+	MaxCost       uint64
+
+	PersistedQueriesOnly bool
 }
 
 type LambdaOptions struct {
@@ -260,6 +260,21 @@ func IndexKey(attr, term string) []byte {
 	return buf
 }
 
+// CompositeIndexKey generates the key of an entry in a composite index, which is kept among the
+// index keys of the predicate that owns it. The term starts with ident and is followed by the
+// binary value of each predicate in the index, in order. Each value is prefixed with its
+// length as a uvarint, so that different combinations of values can't share a term.
+func CompositeIndexKey(attr string, ident byte, vals [][]byte) []byte {
+	term := []byte{ident}
+	var buf [binary.MaxVarintLen64]byte
+	for _, val := range vals {
+		n := binary.PutUvarint(buf[:], uint64(len(val)))
+		term = append(term, buf[:n]...)
+		term = append(term, val...)
+	}
+	return IndexKey(attr, string(term))
+}
+
 // CountKey generates a count key with the given attribute and uid.
 // The structure of a count key is as follows:
 //
@@ -648,6 +663,8 @@ var graphqlReservedPredicate = map[string]struct{}{
 	"dgraph.graphql.schema":  {},
 	"dgraph.drop.op":         {},
 	"dgraph.graphql.p_query": {},
+	// The CDC subscriptions are stored by the updateCDCSubscriptions admin mutation.
+	"dgraph.cdc.subscriptions": {},
 }
 
 // internalPredicateMap stores a set of Dgraph's internal predicate. An internal
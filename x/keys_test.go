@@ -304,3 +304,15 @@ func TestNsSeparator(t *testing.T) {
 	require.Equal(t, GalaxyNamespace, ns)
 	require.Equal(t, pred, attr)
 }
+
+func TestCompositeIndexKey(t *testing.T) {
+	attr := GalaxyAttr("country")
+	key := CompositeIndexKey(attr, 0xE, [][]byte{[]byte("ab"), []byte("c")})
+	require.NotEqual(t, key, CompositeIndexKey(attr, 0xE, [][]byte{[]byte("a"), []byte("bc")}))
+
+	pk, err := Parse(key)
+	require.NoError(t, err)
+	require.True(t, pk.IsIndex())
+	require.Equal(t, attr, pk.Attr)
+	require.Equal(t, "\x0e\x02ab\x01c", pk.Term)
+}
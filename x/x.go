@@ -139,6 +139,9 @@ const (
 		"Content-Type, Content-Length, Accept-Encoding, Cache-Control, " +
 		"X-CSRF-Token, X-Auth-Token, X-Requested-With"
 	DgraphCostHeader = "Dgraph-TouchedUids"
+	// DgraphCursorHeader is the gRPC header holding the cursors of the paginated blocks of a
+	// query, as "<block>=<cursor>" pairs.
+	DgraphCursorHeader = "Dgraph-Cursor"
 
 	ManifestVersion = 2105
 